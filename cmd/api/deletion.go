@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/services-api/internal/service"
+)
+
+// purgeDeletedAccounts deletes the accounts whose deletion grace period has
+// ended, once at startup and then every interval until ctx is cancelled
+func purgeDeletedAccounts(ctx context.Context, personalData *service.PersonalDataService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := personalData.PurgeDue(ctx)
+		if err != nil {
+			slog.Error("Failed to purge deleted accounts", "error", err)
+		} else if purged > 0 {
+			slog.Info("Purged deleted accounts", "count", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
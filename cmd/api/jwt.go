@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/services-api/pkg/config"
+	"github.com/services-api/pkg/jwt"
+)
+
+// newJWTManager creates the JWT manager for the algorithm selected by
+// JWT_ALGORITHM. HS256 signs with JWT_SECRET, named JWT_KEY_ID; the asymmetric algorithms sign
+// with the private key in JWT_SIGNING_KEY_FILE. Keys in JWT_VERIFICATION_KEY_FILES
+// are still accepted, so a previous signing key can be listed there during rotation.
+func newJWTManager(cfg *config.Config) (*jwt.Manager, error) {
+	var signingKey *jwt.Key
+	switch cfg.JWTAlgorithm {
+	case jwt.AlgorithmHS256:
+		signingKey = jwt.NewHMACKey(cfg.JWTKeyID, []byte(cfg.JWTSecret))
+	case jwt.AlgorithmRS256, jwt.AlgorithmES256, jwt.AlgorithmEdDSA:
+		if cfg.JWTSigningKeyFile == "" {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE is required for %s", cfg.JWTAlgorithm)
+		}
+		key, err := jwt.LoadPrivateKeyFile(cfg.JWTSigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT signing key: %w", err)
+		}
+		if key.Algorithm() != cfg.JWTAlgorithm {
+			return nil, fmt.Errorf("JWT signing key is for %s, not %s", key.Algorithm(), cfg.JWTAlgorithm)
+		}
+		signingKey = key
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.JWTAlgorithm)
+	}
+
+	verificationKeys := make([]*jwt.Key, 0, len(cfg.JWTVerificationKeys))
+	for _, path := range cfg.JWTVerificationKeys {
+		key, err := jwt.LoadPublicKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT verification key %s: %w", path, err)
+		}
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	slog.Info("Signing JWTs", "algorithm", signingKey.Algorithm(), "kid", signingKey.ID,
+		"verification_keys", len(verificationKeys))
+
+	return jwt.NewManagerWithKeys(signingKey, verificationKeys, cfg.JWTAccessExpiry, cfg.JWTRefreshExpiry, cfg.JWTIssuer), nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/services-api/pkg/config"
+	"github.com/services-api/pkg/mailer"
+)
+
+// newMailer creates the mailer selected by MAILER. It sends synchronously;
+// wrap it in mailer.Async where a request must not reveal whether it sent one.
+func newMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mailer {
+	case config.MailerLog:
+		return mailer.NewLog(), nil
+	case config.MailerSMTP:
+		return mailer.NewSMTP(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported mailer %q", cfg.Mailer)
+	}
+}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,10 +10,12 @@ import (
 	"time"
 
 	"github.com/services-api/internal/handler"
-	"github.com/services-api/internal/repository"
 	"github.com/services-api/internal/service"
 	"github.com/services-api/pkg/config"
-	"github.com/services-api/pkg/jwt"
+	"github.com/services-api/pkg/logging"
+	"github.com/services-api/pkg/mailer"
+	"github.com/services-api/pkg/metrics"
+	"github.com/services-api/pkg/pwned"
 
 	_ "github.com/services-api/docs" // Swagger docs
 )
@@ -45,53 +47,139 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Log through slog, including what other packages write with log
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		fatal("Failed to initialize logging", "error", err)
+	}
+	slog.SetDefault(logger)
+
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Connect to MongoDB
-	mongoClient, err := repository.ConnectMongoDB(ctx, cfg.MongoURI)
+	// Connect to the configured storage backend
+	store, err := openStorage(ctx, cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		fatal("Failed to initialize storage", "backend", cfg.StorageBackend, "error", err)
 	}
 	defer func() {
-		if err := mongoClient.Disconnect(ctx); err != nil {
-			log.Printf("Error disconnecting from MongoDB: %v", err)
+		if err := store.close(ctx); err != nil {
+			slog.Error("Error closing storage", "backend", cfg.StorageBackend, "error", err)
 		}
 	}()
 
-	// Get database and initialize indexes
-	db := mongoClient.Database(cfg.DBName)
-	if err := repository.EnsureIndexes(ctx, db); err != nil {
-		log.Fatalf("Failed to create indexes: %v", err)
+	// "verify" checks the hash chains of the stored history instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		status := runVerify(ctx, store, os.Args[2:])
+		if err := store.close(ctx); err != nil {
+			slog.Error("Error closing storage", "backend", cfg.StorageBackend, "error", err)
+		}
+		os.Exit(status)
+	}
+
+	if cfg.HasAPIKeys() {
+		slog.Warn("API_KEYS is deprecated; create API keys with POST /api/v1/api-keys instead")
 	}
 
 	// Initialize JWT manager
-	jwtManager := jwt.NewManager(
-		cfg.JWTSecret,
-		cfg.JWTAccessExpiry,
-		cfg.JWTRefreshExpiry,
-		cfg.JWTIssuer,
-	)
-
-	// Initialize repositories
-	serviceRepo := repository.NewMongoServiceRepository(db)
-	versionRepo := repository.NewMongoServiceVersionRepository(db)
-	userRepo := repository.NewMongoUserRepository(db)
+	jwtManager, err := newJWTManager(cfg)
+	if err != nil {
+		fatal("Failed to initialize JWT signing", "error", err)
+	}
+
+	mail, err := newMailer(cfg)
+	if err != nil {
+		fatal("Failed to initialize mailer", "error", err)
+	}
+
+	// Passwords are checked against a list of breached passwords when one is configured
+	var breached pwned.Ranger
+	if cfg.PasswordBreachedFile != "" {
+		list, err := pwned.Open(cfg.PasswordBreachedFile)
+		if err != nil {
+			fatal("Failed to open breached password list", "error", err)
+		}
+		defer list.Close()
+		breached = list
+	}
 
 	// Initialize services
-	serviceSvc := service.NewServiceService(serviceRepo, versionRepo)
-	authSvc := service.NewAuthService(userRepo, jwtManager)
-	userSvc := service.NewUserService(userRepo)
+	auditSvc := service.NewAuditService(store.audit)
+	sessionSvc := service.NewSessionService(store.sessions, store.refreshTokens, auditSvc)
+	roleSvc := service.NewRoleService(store.roles, store.users, auditSvc)
+	teamSvc := service.NewTeamService(store.teams, store.users, store.services, roleSvc, auditSvc)
+	serviceSvc := service.NewServiceService(store.services, store.versions, teamSvc, auditSvc)
+	mfaSvc := service.NewMFAService(store.mfa, store.users, cfg.MFAIssuer, cfg.MFARequiredRoles, auditSvc)
+	throttleSvc := service.NewLoginThrottleService(store.loginAttempts, service.LoginPolicy{
+		MaxFailures:     cfg.LoginMaxFailures,
+		IPMaxFailures:   cfg.LoginIPMaxFailures,
+		Window:          cfg.LoginFailureWindow,
+		LockoutDuration: cfg.LoginLockoutDuration,
+		Delay:           cfg.LoginDelay,
+	}, auditSvc)
+	passwordSvc := service.NewPasswordService(store.passwords, breached, service.PasswordPolicy{
+		MinLength:   cfg.PasswordMinLength,
+		MinClasses:  cfg.PasswordMinClasses,
+		MinStrength: cfg.PasswordMinStrength,
+		History:     cfg.PasswordHistory,
+	})
+	// Account emails are sent in the background, so that requests take as long
+	// whether or not they send one. Invites are sent right away, so that an
+	// invite that couldn't be sent fails and is rolled back.
+	accountSvc := service.NewAccountService(store.users, store.userTokens, sessionSvc, throttleSvc, passwordSvc, mailer.Async(mail), service.AccountOptions{
+		PasswordResetURL:     cfg.PasswordResetURL,
+		PasswordResetTTL:     cfg.PasswordResetTTL,
+		EmailVerificationURL: cfg.EmailVerificationURL,
+		EmailVerificationTTL: cfg.EmailVerificationTTL,
+		RequireVerifiedEmail: cfg.RequireEmailVerified,
+	}, auditSvc)
+	authSvc := service.NewAuthService(store.users, store.refreshTokens, sessionSvc, roleSvc, mfaSvc, throttleSvc, accountSvc, passwordSvc, jwtManager, auditSvc)
+	userSvc := service.NewUserService(store.users, store.teams, store.versions, sessionSvc, roleSvc, throttleSvc, accountSvc, passwordSvc, auditSvc)
+	invitationSvc := service.NewInvitationService(store.invitations, store.users, userSvc, passwordSvc, mail, service.InvitationOptions{
+		URL: cfg.InvitationURL,
+		TTL: cfg.InvitationTTL,
+	}, auditSvc)
+	apiKeySvc := service.NewAPIKeyService(store.apiKeys, store.users, roleSvc, cfg.APIKeyRotationOverlap, auditSvc)
+	impersonationSvc := service.NewImpersonationService(store.users, roleSvc, jwtManager, cfg.ImpersonationTTL, auditSvc)
+	personalDataSvc := service.NewPersonalDataService(store.users, userSvc, store.teams, sessionSvc, store.apiKeys, store.services, store.versions, cfg.DeletionGracePeriod, auditSvc)
+
+	if err := roleSvc.EnsureBuiltInRoles(ctx); err != nil {
+		fatal("Failed to create built-in roles", "error", err)
+	}
+	for _, role := range cfg.MFARequiredRoles {
+		if err := roleSvc.ValidateRole(ctx, role); err != nil {
+			fatal("Invalid MFA_REQUIRED_ROLES entry", "role", role, "error", err)
+		}
+	}
+
+	// The services gauge is read from storage on every scrape
+	if err := metrics.RegisterServiceCount(serviceSvc.Count); err != nil {
+		fatal("Failed to register metrics", "error", err)
+	}
 
 	// Initialize handlers
-	serviceHandler := handler.NewServiceHandler(serviceSvc)
-	healthHandler := handler.NewHealthHandler(mongoClient)
+	serviceHandler := handler.NewServiceHandler(serviceSvc, roleSvc)
+	healthHandler := handler.NewHealthHandler(store.health)
+	jwksHandler := handler.NewJWKSHandler(jwtManager)
 	authHandler := handler.NewAuthHandler(authSvc)
-	userHandler := handler.NewUserHandler(userSvc)
+	accountHandler := handler.NewAccountHandler(accountSvc)
+	oidcHandler, err := newOIDCHandler(ctx, cfg, store.users, authSvc, roleSvc)
+	if err != nil {
+		fatal("Failed to initialize single sign-on", "error", err)
+	}
+	userHandler := handler.NewUserHandler(userSvc, teamSvc, personalDataSvc, impersonationSvc, roleSvc)
+	invitationHandler := handler.NewInvitationHandler(invitationSvc, roleSvc)
+	mfaHandler := handler.NewMFAHandler(mfaSvc, roleSvc)
+	sessionHandler := handler.NewSessionHandler(sessionSvc, roleSvc)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeySvc, roleSvc)
+	roleHandler := handler.NewRoleHandler(roleSvc, roleSvc)
+	teamHandler := handler.NewTeamHandler(teamSvc, roleSvc)
+	auditHandler := handler.NewAuditHandler(auditSvc, roleSvc)
+	idempotency := handler.NewIdempotencyMiddleware(store.idempotency, cfg.IdempotencyTTL)
 
 	// Setup router
-	router := handler.NewRouter(cfg, jwtManager, serviceHandler, healthHandler, authHandler, userHandler)
+	router := handler.NewRouter(cfg, jwtManager, serviceHandler, healthHandler, jwksHandler, authHandler, accountHandler, oidcHandler, userHandler, invitationHandler, mfaHandler, sessionHandler, apiKeyHandler, roleHandler, teamHandler, auditHandler, idempotency, sessionSvc, apiKeySvc)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -100,13 +188,17 @@ func main() {
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelError),
 	}
 
+	// Accounts scheduled for deletion are removed once their grace period ends
+	go purgeDeletedAccounts(ctx, personalDataSvc, cfg.DeletionSweepInterval)
+
 	// Start server in goroutine
 	go func() {
-		log.Printf("Starting server on port %s", cfg.Port)
+		slog.Info("Starting server", "port", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v", err)
+			fatal("Server error", "error", err)
 		}
 	}()
 
@@ -115,7 +207,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 
 	// Create shutdown context with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -123,8 +215,14 @@ func main() {
 
 	// Shutdown server gracefully
 	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal("Server forced to shutdown", "error", err)
 	}
 
-	log.Println("Server exited gracefully")
+	slog.Info("Server exited gracefully")
+}
+
+// fatal logs an error that keeps the server from running and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
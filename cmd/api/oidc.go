@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/config"
+	"github.com/services-api/pkg/oidc"
+)
+
+// newOIDCHandler creates the single sign-on handler when OIDC_ISSUER_URL is
+// set, or returns nil to leave the OIDC routes unregistered. The provider is
+// discovered and the roles of OIDC_GROUP_ROLES are checked at startup, so a
+// misconfiguration stops the server instead of failing every login.
+func newOIDCHandler(ctx context.Context, cfg *config.Config, users domain.UserRepository, authSvc *service.AuthService, roleSvc *service.RoleService) (*handler.OIDCHandler, error) {
+	if !cfg.HasOIDC() {
+		return nil, nil
+	}
+	if cfg.OIDCClientID == "" {
+		return nil, fmt.Errorf("OIDC_CLIENT_ID is required with OIDC_ISSUER_URL")
+	}
+
+	groupRoles := make([]service.GroupRole, 0, len(cfg.OIDCGroupRoles))
+	for _, mapping := range cfg.OIDCGroupRoles {
+		group, role, ok := strings.Cut(mapping, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("invalid OIDC_GROUP_ROLES entry %q, expected group=role", mapping)
+		}
+		if err := roleSvc.ValidateRole(ctx, role); err != nil {
+			return nil, fmt.Errorf("OIDC_GROUP_ROLES entry %q: %w", mapping, err)
+		}
+		groupRoles = append(groupRoles, service.GroupRole{Group: group, Role: role})
+	}
+	if err := roleSvc.ValidateRole(ctx, cfg.OIDCDefaultRole); err != nil {
+		return nil, fmt.Errorf("OIDC_DEFAULT_ROLE %q: %w", cfg.OIDCDefaultRole, err)
+	}
+
+	provider, err := oidc.NewProvider(ctx, oidc.Config{
+		IssuerURL:    cfg.OIDCIssuerURL,
+		ClientID:     cfg.OIDCClientID,
+		ClientSecret: cfg.OIDCClientSecret,
+		RedirectURL:  cfg.OIDCRedirectURL,
+		Scopes:       cfg.OIDCScopes,
+		GroupsClaim:  cfg.OIDCGroupsClaim,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Single sign-on enabled", "issuer", cfg.OIDCIssuerURL, "group_role_mappings", len(groupRoles))
+
+	oidcSvc := service.NewOIDCService(provider, users, authSvc, groupRoles, cfg.OIDCDefaultRole)
+	return handler.NewOIDCHandler(oidcSvc, strings.HasPrefix(cfg.OIDCRedirectURL, "https://")), nil
+}
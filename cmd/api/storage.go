@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository"
+	"github.com/services-api/internal/repository/cache"
+	"github.com/services-api/internal/repository/postgres"
+	"github.com/services-api/internal/repository/sqlite"
+	"github.com/services-api/pkg/config"
+)
+
+// storage bundles the repositories of the configured storage backend
+type storage struct {
+	services      domain.ServiceRepository
+	versions      domain.ServiceVersionRepository
+	users         domain.UserRepository
+	refreshTokens domain.RefreshTokenRepository
+	sessions      domain.SessionRepository
+	apiKeys       domain.APIKeyRepository
+	roles         domain.RoleRepository
+	mfa           domain.MFARepository
+	loginAttempts domain.LoginAttemptRepository
+	userTokens    domain.UserTokenRepository
+	passwords     domain.PasswordHistoryRepository
+	invitations   domain.InvitationRepository
+	teams         domain.TeamRepository
+	audit         domain.AuditRepository
+	idempotency   domain.IdempotencyRepository
+	health        handler.HealthChecker
+	close         func(ctx context.Context) error
+}
+
+// openStorage connects to the storage backend selected by STORAGE_BACKEND and
+// wraps it with the cache selected by CACHE_BACKEND
+func openStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	store, err := openBackend(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enableCache(ctx, cfg, store); err != nil {
+		store.close(ctx)
+		return nil, err
+	}
+	return store, nil
+}
+
+// openBackend connects to the storage backend selected by STORAGE_BACKEND
+func openBackend(ctx context.Context, cfg *config.Config) (*storage, error) {
+	switch cfg.StorageBackend {
+	case config.StorageMongo:
+		return openMongoStorage(ctx, cfg)
+	case config.StoragePostgres:
+		return openPostgresStorage(ctx, cfg)
+	case config.StorageSQLite:
+		return openSQLiteStorage(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %q", cfg.StorageBackend)
+	}
+}
+
+// openMongoStorage connects to MongoDB and ensures indexes exist
+func openMongoStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	client, err := repository.ConnectMongoDB(ctx, cfg.MongoURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	// Get database and initialize indexes
+	db := client.Database(cfg.DBName)
+	if err := repository.EnsureIndexes(ctx, db); err != nil {
+		client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return &storage{
+		services:      repository.NewMongoServiceRepository(db),
+		versions:      repository.NewMongoServiceVersionRepository(db),
+		users:         repository.NewMongoUserRepository(db),
+		refreshTokens: repository.NewMongoRefreshTokenRepository(db),
+		sessions:      repository.NewMongoSessionRepository(db),
+		apiKeys:       repository.NewMongoAPIKeyRepository(db),
+		roles:         repository.NewMongoRoleRepository(db),
+		mfa:           repository.NewMongoMFARepository(db),
+		loginAttempts: repository.NewMongoLoginAttemptRepository(db),
+		userTokens:    repository.NewMongoUserTokenRepository(db),
+		passwords:     repository.NewMongoPasswordHistoryRepository(db),
+		invitations:   repository.NewMongoInvitationRepository(db),
+		teams:         repository.NewMongoTeamRepository(db),
+		audit:         repository.NewMongoAuditRepository(db),
+		idempotency:   repository.NewMongoIdempotencyRepository(db),
+		health:        repository.NewMongoHealthChecker(db),
+		close:         client.Disconnect,
+	}, nil
+}
+
+// openPostgresStorage connects to PostgreSQL and applies pending migrations
+func openPostgresStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	db, err := postgres.Open(ctx, cfg.PostgresDSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	if err := postgres.Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return &storage{
+		services:      postgres.NewServiceRepository(db),
+		versions:      postgres.NewServiceVersionRepository(db),
+		users:         postgres.NewUserRepository(db),
+		refreshTokens: postgres.NewRefreshTokenRepository(db),
+		sessions:      postgres.NewSessionRepository(db),
+		apiKeys:       postgres.NewAPIKeyRepository(db),
+		roles:         postgres.NewRoleRepository(db),
+		mfa:           postgres.NewMFARepository(db),
+		loginAttempts: postgres.NewLoginAttemptRepository(db),
+		userTokens:    postgres.NewUserTokenRepository(db),
+		passwords:     postgres.NewPasswordHistoryRepository(db),
+		invitations:   postgres.NewInvitationRepository(db),
+		teams:         postgres.NewTeamRepository(db),
+		audit:         postgres.NewAuditRepository(db),
+		idempotency:   postgres.NewIdempotencyRepository(db),
+		health:        postgres.NewHealthChecker(db),
+		close: func(context.Context) error {
+			return db.Close()
+		},
+	}, nil
+}
+
+// openSQLiteStorage opens the embedded SQLite database and applies pending migrations
+func openSQLiteStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	db, err := sqlite.Open(ctx, cfg.SQLitePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sqlite.Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return &storage{
+		services:      sqlite.NewServiceRepository(db),
+		versions:      sqlite.NewServiceVersionRepository(db),
+		users:         sqlite.NewUserRepository(db),
+		refreshTokens: sqlite.NewRefreshTokenRepository(db),
+		sessions:      sqlite.NewSessionRepository(db),
+		apiKeys:       sqlite.NewAPIKeyRepository(db),
+		roles:         sqlite.NewRoleRepository(db),
+		mfa:           sqlite.NewMFARepository(db),
+		loginAttempts: sqlite.NewLoginAttemptRepository(db),
+		userTokens:    sqlite.NewUserTokenRepository(db),
+		passwords:     sqlite.NewPasswordHistoryRepository(db),
+		invitations:   sqlite.NewInvitationRepository(db),
+		teams:         sqlite.NewTeamRepository(db),
+		audit:         sqlite.NewAuditRepository(db),
+		idempotency:   sqlite.NewIdempotencyRepository(db),
+		health:        sqlite.NewHealthChecker(db, cfg.SQLitePath),
+		close: func(context.Context) error {
+			return db.Close()
+		},
+	}, nil
+}
+
+// enableCache wraps the service, session and role repositories with a
+// read-through cache in the store selected by CACHE_BACKEND. Session and role
+// lookups, made on every authenticated request, are cached in a private LRU
+// even with CACHE_BACKEND=none, which only leaves services uncached.
+func enableCache(ctx context.Context, cfg *config.Config, store *storage) error {
+	var cacheStore cache.Store
+	closeBackend := store.close
+
+	switch cfg.CacheBackend {
+	case config.CacheNone:
+		authStore := cache.NewLRUStore(cfg.CacheMaxEntries)
+		store.sessions = cache.NewSessionRepository(store.sessions, authStore, cfg.SessionCacheTTL)
+		store.roles = cache.NewRoleRepository(store.roles, authStore, cfg.SessionCacheTTL)
+		slog.Info("Caching session and role lookups", "cache", authStore.Name(), "session_ttl", cfg.SessionCacheTTL.String())
+		return nil
+	case config.CacheMemory:
+		cacheStore = cache.NewLRUStore(cfg.CacheMaxEntries)
+	case config.CacheRedis:
+		client, err := cache.ConnectRedis(ctx, cfg.RedisURL)
+		if err != nil {
+			return fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		cacheStore = cache.NewRedisStore(client)
+		store.close = func(ctx context.Context) error {
+			client.Close()
+			return closeBackend(ctx)
+		}
+	default:
+		return fmt.Errorf("unsupported cache backend %q", cfg.CacheBackend)
+	}
+
+	store.services = cache.NewServiceRepository(store.services, cacheStore, cfg.CacheTTL)
+	store.sessions = cache.NewSessionRepository(store.sessions, cacheStore, cfg.SessionCacheTTL)
+	store.roles = cache.NewRoleRepository(store.roles, cacheStore, cfg.SessionCacheTTL)
+
+	slog.Info("Caching service, session and role lookups", "cache", cacheStore.Name(), "ttl", cfg.CacheTTL.String(),
+		"session_ttl", cfg.SessionCacheTTL.String())
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+)
+
+// runVerify implements the verify command. It walks the hash chains of the
+// audit log and the version history of every service, deleted ones included,
+// or only of the services whose IDs are given, and prints the first broken
+// link of each. It returns the exit status: 1 if a chain is broken, 2 if one
+// couldn't be verified.
+func runVerify(ctx context.Context, store *storage, serviceIDs []string) int {
+	services := service.NewServiceService(store.services, store.versions, nil, nil)
+	status := 0
+	report := func(chain string, result *domain.ChainVerification, err error) {
+		switch {
+		case err != nil:
+			fmt.Printf("%s: verification failed: %v\n", chain, err)
+			status = max(status, 2)
+		case !result.Valid:
+			link := result.BrokenLink
+			if link.Revision > 0 {
+				fmt.Printf("%s: BROKEN at revision %d (%s): %s\n", chain, link.Revision, link.ID, link.Reason)
+			} else {
+				fmt.Printf("%s: BROKEN at %s: %s\n", chain, link.ID, link.Reason)
+			}
+			status = max(status, 1)
+		default:
+			fmt.Printf("%s: ok (%d checked, %d unhashed)\n", chain, result.Checked, result.Unhashed)
+		}
+	}
+
+	if len(serviceIDs) == 0 {
+		result, err := service.NewAuditService(store.audit).Verify(ctx)
+		report("audit log", result, err)
+
+		// Listing the services that have versions includes deleted ones,
+		// whose chains end in a tombstone
+		ids, err := store.versions.ListServiceIDs(ctx)
+		if err != nil {
+			fmt.Printf("services: listing failed: %v\n", err)
+			return 2
+		}
+		serviceIDs = ids
+	}
+
+	for _, id := range serviceIDs {
+		result, err := services.VerifyVersions(ctx, id)
+		report("service "+id, result, err)
+	}
+
+	return status
+}
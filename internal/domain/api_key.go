@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// API key errors
+var (
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+	ErrAPIKeyNameRequired  = errors.New("name is required")
+	ErrAPIKeyNameTooLong   = errors.New("name must be 100 characters or less")
+	ErrAPIKeyPrefixExists  = errors.New("api key prefix already exists")
+	ErrScopesRequired      = errors.New("at least one scope is required")
+	ErrInvalidScope        = errors.New("invalid scope")
+	ErrScopeNotAllowed     = errors.New("scope not allowed for the key owner's role or the caller's token")
+	ErrInvalidExpiry       = errors.New("expires_in_days must be between 1 and 3650")
+	ErrInvalidOverlap      = errors.New("overlap_hours must be between 0 and 720")
+	ErrInvalidAPIKey       = errors.New("invalid api key")
+	ErrAPIKeyOwnerInactive = errors.New("api key owner is inactive")
+)
+
+// APIKey is a stored API key. Only a hash of the secret is kept; the prefix,
+// which is part of the key itself, is used to look the key up.
+type APIKey struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name       string             `bson:"name" json:"name"`
+	Prefix     string             `bson:"prefix" json:"prefix"`
+	SecretHash string             `bson:"secret_hash" json:"-"`
+	OwnerID    primitive.ObjectID `bson:"owner_id" json:"owner_id"`
+	Scopes     []string           `bson:"scopes" json:"scopes"`
+	ExpiresAt  *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time         `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// IsExpired reports whether the key has passed its expiry time
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now())
+}
+
+// CreateAPIKeyRequest represents the request body for creating an API key
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" example:"ci-deployments"`
+	Scopes        []string `json:"scopes" example:"services:read,services:write"`
+	ExpiresInDays *int     `json:"expires_in_days,omitempty" example:"90"`
+}
+
+// UpdateAPIKeyRequest represents the request body for partially updating an API key
+type UpdateAPIKeyRequest struct {
+	Name   *string  `json:"name,omitempty" example:"ci-deployments"`
+	Scopes []string `json:"scopes,omitempty" example:"services:read"`
+}
+
+// RotateAPIKeyRequest represents the request body for rotating an API key
+type RotateAPIKeyRequest struct {
+	OverlapHours *int `json:"overlap_hours,omitempty" example:"24"`
+}
+
+// APIKeyResponse represents the API key data returned in API responses
+type APIKeyResponse struct {
+	ID         string     `json:"id" example:"507f1f77bcf86cd799439011"`
+	Name       string     `json:"name" example:"ci-deployments"`
+	Prefix     string     `json:"prefix" example:"sk_3f9a2c81d04b"`
+	OwnerID    string     `json:"owner_id" example:"507f1f77bcf86cd799439012"`
+	Scopes     []string   `json:"scopes" example:"services:read,services:write"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2024-04-15T10:30:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2024-01-16T08:00:00Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt  time.Time  `json:"updated_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// APIKeyCreatedResponse is returned when a key is created or rotated. It is the
+// only response that contains the plaintext key.
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"sk_3f9a2c81d04b_Xq3V0m5y8Hc2pLr7bT1wZ9kD4nF6gJ0sA2eU5iO8qM"`
+}
+
+// ToResponse converts an APIKey to an APIKeyResponse
+func (k *APIKey) ToResponse() APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID.Hex(),
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		OwnerID:    k.OwnerID.Hex(),
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+		UpdatedAt:  k.UpdatedAt,
+	}
+}
@@ -0,0 +1,236 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audit actions
+const (
+	AuditActionLogin                = "auth.login"
+	AuditActionLoginFailed          = "auth.login_failed"
+	AuditActionRefreshTokenReuse    = "auth.refresh_token_reuse"
+	AuditActionLockout              = "auth.lockout"
+	AuditActionUnlock               = "auth.unlock"
+	AuditActionSSOLoginFailed       = "auth.sso_login_failed"
+	AuditActionImpersonate          = "auth.impersonate"
+	AuditActionMFAEnable            = "mfa.enable"
+	AuditActionMFADisable           = "mfa.disable"
+	AuditActionMFAReset             = "mfa.reset"
+	AuditActionMFARecoveryCodeUsed  = "mfa.recovery_code_used"
+	AuditActionUserCreate           = "user.create"
+	AuditActionUserUpdate           = "user.update"
+	AuditActionUserRoleChange       = "user.role_change"
+	AuditActionUserDelete           = "user.delete"
+	AuditActionUserDeletionSchedule = "user.deletion_scheduled"
+	AuditActionUserDeletionCancel   = "user.deletion_cancelled"
+	AuditActionPasswordChange       = "user.password_change"
+	AuditActionPasswordReset        = "user.password_reset"
+	AuditActionEmailVerify          = "user.email_verify"
+	AuditActionSessionRevoke        = "session.revoke"
+	AuditActionSessionRevokeAll     = "session.revoke_all"
+	AuditActionInvitationCreate     = "invitation.create"
+	AuditActionInvitationResend     = "invitation.resend"
+	AuditActionInvitationRevoke     = "invitation.revoke"
+	AuditActionInvitationAccept     = "invitation.accept"
+	AuditActionRoleCreate           = "role.create"
+	AuditActionRoleUpdate           = "role.update"
+	AuditActionRoleDelete           = "role.delete"
+	AuditActionAPIKeyCreate         = "api_key.create"
+	AuditActionAPIKeyUpdate         = "api_key.update"
+	AuditActionAPIKeyRotate         = "api_key.rotate"
+	AuditActionAPIKeyDelete         = "api_key.delete"
+	AuditActionTeamCreate           = "team.create"
+	AuditActionTeamUpdate           = "team.update"
+	AuditActionTeamDelete           = "team.delete"
+	AuditActionTeamMemberSet        = "team.member_set"
+	AuditActionTeamMemberRemove     = "team.member_remove"
+	AuditActionServiceCreate        = "service.create"
+	AuditActionServiceUpdate        = "service.update"
+	AuditActionServiceDelete        = "service.delete"
+)
+
+// Audit target types
+const (
+	AuditTargetUser    = "user"
+	AuditTargetRole    = "role"
+	AuditTargetAPIKey  = "api_key"
+	AuditTargetTeam    = "team"
+	AuditTargetService = "service"
+)
+
+// Maximum number of audit events on a page
+const (
+	DefaultAuditLimit = 50
+	MaxAuditLimit     = 200
+)
+
+// Audit log errors
+var (
+	ErrInvalidAuditCursor = errors.New("invalid cursor")
+	ErrInvalidAuditTime   = errors.New("since and until must be RFC 3339 timestamps or dates")
+)
+
+// AuditEvent is an append-only record of a security-relevant action. Actor
+// fields describe who acted, as far as known: failed logins have no actor.
+type AuditEvent struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Action         string             `bson:"action" json:"action"`
+	ActorID        string             `bson:"actor_id,omitempty" json:"actor_id,omitempty"`               // User who acted, or owner of the API key
+	ImpersonatorID string             `bson:"impersonator_id,omitempty" json:"impersonator_id,omitempty"` // Admin acting as the actor, if any
+	AuthType       string             `bson:"auth_type,omitempty" json:"auth_type,omitempty"`
+	APIKeyID       string             `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"`
+	IPAddress      string             `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
+	UserAgent      string             `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	RequestID      string             `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	TargetType     string             `bson:"target_type,omitempty" json:"target_type,omitempty"`
+	TargetID       string             `bson:"target_id,omitempty" json:"target_id,omitempty"`
+	Before         map[string]string  `bson:"before,omitempty" json:"before,omitempty"` // Summary of the target before the action
+	After          map[string]string  `bson:"after,omitempty" json:"after,omitempty"`   // Summary of the target after the action
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	Hash           string             `bson:"hash,omitempty" json:"hash,omitempty"`           // SHA-256 of the content and PrevHash
+	PrevHash       string             `bson:"prev_hash,omitempty" json:"prev_hash,omitempty"` // Hash of the previous event
+}
+
+// AuditEventResponse is the API response format for an audit event
+type AuditEventResponse struct {
+	ID             string            `json:"id" example:"507f1f77bcf86cd799439011"`
+	Action         string            `json:"action" example:"user.role_change"`
+	ActorID        string            `json:"actor_id,omitempty" example:"507f1f77bcf86cd799439012"`
+	ImpersonatorID string            `json:"impersonator_id,omitempty" example:"507f1f77bcf86cd799439013"`
+	AuthType       string            `json:"auth_type,omitempty" example:"jwt"`
+	APIKeyID       string            `json:"api_key_id,omitempty" example:"507f1f77bcf86cd799439014"`
+	IPAddress      string            `json:"ip_address,omitempty" example:"192.0.2.1"`
+	UserAgent      string            `json:"user_agent,omitempty" example:"Mozilla/5.0"`
+	RequestID      string            `json:"request_id,omitempty" example:"host/abc123-000042"`
+	TargetType     string            `json:"target_type,omitempty" example:"user"`
+	TargetID       string            `json:"target_id,omitempty" example:"507f1f77bcf86cd799439015"`
+	Before         map[string]string `json:"before,omitempty"`
+	After          map[string]string `json:"after,omitempty"`
+	CreatedAt      time.Time         `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	Hash           string            `json:"hash,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	PrevHash       string            `json:"prev_hash,omitempty" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
+}
+
+// ToResponse converts an AuditEvent to an AuditEventResponse
+func (e *AuditEvent) ToResponse() AuditEventResponse {
+	return AuditEventResponse{
+		ID:             e.ID.Hex(),
+		Action:         e.Action,
+		ActorID:        e.ActorID,
+		ImpersonatorID: e.ImpersonatorID,
+		AuthType:       e.AuthType,
+		APIKeyID:       e.APIKeyID,
+		IPAddress:      e.IPAddress,
+		UserAgent:      e.UserAgent,
+		RequestID:      e.RequestID,
+		TargetType:     e.TargetType,
+		TargetID:       e.TargetID,
+		Before:         e.Before,
+		After:          e.After,
+		CreatedAt:      e.CreatedAt,
+		Hash:           e.Hash,
+		PrevHash:       e.PrevHash,
+	}
+}
+
+// ComputeHash hashes the content of the event together with PrevHash
+func (e *AuditEvent) ComputeHash() string {
+	return chainHash(struct {
+		ID             string            `json:"id"`
+		Action         string            `json:"action"`
+		ActorID        string            `json:"actor_id"`
+		ImpersonatorID string            `json:"impersonator_id"`
+		AuthType       string            `json:"auth_type"`
+		APIKeyID       string            `json:"api_key_id"`
+		IPAddress      string            `json:"ip_address"`
+		UserAgent      string            `json:"user_agent"`
+		RequestID      string            `json:"request_id"`
+		TargetType     string            `json:"target_type"`
+		TargetID       string            `json:"target_id"`
+		Before         map[string]string `json:"before"`
+		After          map[string]string `json:"after"`
+		CreatedAt      string            `json:"created_at"`
+		PrevHash       string            `json:"prev_hash"`
+	}{
+		e.ID.Hex(), e.Action, e.ActorID, e.ImpersonatorID, e.AuthType, e.APIKeyID, e.IPAddress, e.UserAgent,
+		e.RequestID, e.TargetType, e.TargetID, chainSummary(e.Before), chainSummary(e.After), chainTime(e.CreatedAt), e.PrevHash,
+	})
+}
+
+// chainSummary normalizes an empty summary, which backends store as none
+func chainSummary(summary map[string]string) map[string]string {
+	if len(summary) == 0 {
+		return nil
+	}
+	return summary
+}
+
+// ChainLink returns the event as a link of the audit log's hash chain
+func (e *AuditEvent) ChainLink() ChainLink {
+	return ChainLink{
+		ID:       e.ID.Hex(),
+		Hash:     e.Hash,
+		PrevHash: e.PrevHash,
+		Computed: e.ComputeHash(),
+	}
+}
+
+// AuditQuery filters the audit log. Events are returned newest first.
+type AuditQuery struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Since      *time.Time
+	Until      *time.Time
+	After      *AuditCursor // Only events older than the cursor
+	Limit      int
+}
+
+// AuditCursor is the position of an event in the audit log
+type AuditCursor struct {
+	CreatedAt time.Time
+	ID        primitive.ObjectID
+}
+
+// NewAuditCursor returns the cursor of an event
+func NewAuditCursor(event *AuditEvent) AuditCursor {
+	return AuditCursor{CreatedAt: event.CreatedAt, ID: event.ID}
+}
+
+// String encodes the cursor as an opaque string
+func (c AuditCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + "_" + c.ID.Hex()))
+}
+
+// ParseAuditCursor decodes a cursor returned by AuditCursor.String
+func ParseAuditCursor(s string) (*AuditCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidAuditCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return nil, ErrInvalidAuditCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidAuditCursor
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidAuditCursor
+	}
+	return &AuditCursor{CreatedAt: t, ID: objectID}, nil
+}
+
+// AuditPage is a page of audit events
+type AuditPage struct {
+	Data       []AuditEventResponse `json:"data"`
+	NextCursor string               `json:"next_cursor,omitempty" example:"MjAyNC0wMS0xNVQxMDozMDowMFpfNTA3ZjFmNzdiY2Y4NmNkNzk5NDM5MDEx"`
+}
@@ -12,6 +12,7 @@ var (
 	ErrDescriptionTooLong  = errors.New("description must be at most 1000 characters")
 	ErrInvalidSortField    = errors.New("invalid sort field")
 	ErrInvalidID           = errors.New("invalid ID format")
+	ErrServiceChanged      = errors.New("service changed concurrently")
 )
 
 // ValidationError wraps validation errors with details
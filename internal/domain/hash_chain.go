@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Hash chain errors
+var (
+	ErrChainConflict = errors.New("previous record was already chained")
+)
+
+// Reasons a hash chain is broken
+const (
+	ChainBreakHashMismatch = "content does not match its hash"
+	ChainBreakPrevMismatch = "previous hash does not match the previous record"
+	ChainBreakMissingHash  = "record has no hash, but older records do"
+	ChainBreakMissingPrev  = "previous record is missing"
+)
+
+// ChainVerification is the result of walking a hash chain
+type ChainVerification struct {
+	Valid      bool        `json:"valid" example:"false"`
+	Checked    int         `json:"checked" example:"12"`  // Records whose hash was checked
+	Unhashed   int         `json:"unhashed" example:"3"`  // Records created before hashing was introduced
+	BrokenLink *ChainBreak `json:"broken_link,omitempty"` // The oldest broken link, if any
+}
+
+// ChainBreak describes a record whose link in a hash chain is broken
+type ChainBreak struct {
+	ID       string `json:"id" example:"507f1f77bcf86cd799439011"`
+	Revision int    `json:"revision,omitempty" example:"4"` // Set for service versions
+	Reason   string `json:"reason" example:"content does not match its hash"`
+}
+
+// ChainLink is a record of a hash chain as seen by a ChainVerifier
+type ChainLink struct {
+	ID       string
+	Revision int
+	Hash     string // Stored hash
+	PrevHash string // Stored hash of the previous record
+	Computed string // Hash of the record's current content
+}
+
+// ChainVerifier walks a hash chain newest first. Records created before
+// hashing was introduced have no hash and are only allowed at the start of
+// the chain. Numbered records, such as service versions, must follow each
+// other without gaps. The break reported is the oldest one found.
+type ChainVerifier struct {
+	result ChainVerification
+	newer  *ChainLink
+}
+
+// Add checks the next older record of the chain
+func (v *ChainVerifier) Add(link ChainLink) {
+	newer := v.newer
+	v.newer = &link
+	gap := newer != nil && newer.Revision > 0 && link.Revision > 0 && newer.Revision != link.Revision+1
+
+	if link.Hash == "" {
+		v.result.Unhashed++
+		if gap {
+			v.broken(newer, ChainBreakMissingPrev)
+		} else if newer != nil && newer.Hash != "" && newer.PrevHash != "" {
+			v.broken(newer, ChainBreakPrevMismatch)
+		}
+		return
+	}
+
+	v.result.Checked++
+	if newer != nil {
+		if gap {
+			v.broken(newer, ChainBreakMissingPrev)
+		} else if newer.Hash == "" {
+			v.broken(newer, ChainBreakMissingHash)
+		} else if newer.PrevHash != link.Hash {
+			v.broken(newer, ChainBreakPrevMismatch)
+		}
+	}
+	if link.Computed != link.Hash {
+		v.broken(&link, ChainBreakHashMismatch)
+	}
+}
+
+// Result returns the verification of the records added so far, treating the
+// last one as the start of the chain
+func (v *ChainVerifier) Result() *ChainVerification {
+	result := v.result
+	oldest := v.newer
+	if oldest != nil && oldest.Hash != "" && oldest.PrevHash != "" && (result.BrokenLink == nil || result.BrokenLink.ID != oldest.ID) {
+		result.BrokenLink = &ChainBreak{ID: oldest.ID, Revision: oldest.Revision, Reason: ChainBreakMissingPrev}
+	}
+	result.Valid = result.BrokenLink == nil
+	return &result
+}
+
+// broken records a break, replacing newer ones
+func (v *ChainVerifier) broken(link *ChainLink, reason string) {
+	v.result.BrokenLink = &ChainBreak{ID: link.ID, Revision: link.Revision, Reason: reason}
+}
+
+// chainHash hashes the canonical JSON encoding of a record's content.
+// Struct fields are encoded in declaration order and map keys sorted, so
+// the encoding of a record is stable.
+func chainHash(content any) string {
+	data, err := json.Marshal(content)
+	if err != nil {
+		// Records only hold strings, numbers and string maps
+		panic(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// chainTime formats a timestamp for hashing. Times are stored with
+// millisecond precision, so hashes survive a round trip through any backend.
+func chainTime(t time.Time) string {
+	return t.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano)
+}
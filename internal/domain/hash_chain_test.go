@@ -0,0 +1,232 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// versionChain returns revisions 1 to n of a service, newest first. The first
+// unhashed revisions have no hash, as if created before hashing.
+func versionChain(n, unhashed int) []*domain.ServiceVersion {
+	service := &domain.Service{ID: primitive.NewObjectID(), Name: "payments", Description: "Handles payments"}
+	versions := make([]*domain.ServiceVersion, n)
+	prevHash := ""
+	for i := 0; i < n; i++ {
+		service.Revision = i + 1
+		version := domain.NewServiceVersion(service, nil)
+		if i >= unhashed {
+			version.PrevHash = prevHash
+			version.Hash = version.ComputeHash()
+		}
+		prevHash = version.Hash
+		versions[n-1-i] = version
+	}
+	return versions
+}
+
+// verifyVersions walks versions, newest first
+func verifyVersions(versions []*domain.ServiceVersion) *domain.ChainVerification {
+	var verifier domain.ChainVerifier
+	for _, version := range versions {
+		verifier.Add(version.ChainLink())
+	}
+	return verifier.Result()
+}
+
+func TestChainVerifier(t *testing.T) {
+	tests := []struct {
+		name             string
+		unhashed         int
+		tamper           func(versions []*domain.ServiceVersion)
+		expectedRevision int
+		expectedReason   string
+		expectedChecked  int
+	}{
+		{
+			name:            "intact chain",
+			expectedChecked: 5,
+		},
+		{
+			name:            "unhashed versions before the chain",
+			unhashed:        2,
+			expectedChecked: 3,
+		},
+		{
+			name: "edited content",
+			tamper: func(versions []*domain.ServiceVersion) {
+				versions[2].Name = "edited"
+			},
+			expectedRevision: 3,
+			expectedReason:   domain.ChainBreakHashMismatch,
+			expectedChecked:  5,
+		},
+		{
+			name: "edited content with recomputed hash",
+			tamper: func(versions []*domain.ServiceVersion) {
+				versions[2].Name = "edited"
+				versions[2].Hash = versions[2].ComputeHash()
+			},
+			expectedRevision: 4,
+			expectedReason:   domain.ChainBreakPrevMismatch,
+			expectedChecked:  5,
+		},
+		{
+			name: "oldest break is reported",
+			tamper: func(versions []*domain.ServiceVersion) {
+				versions[0].Description = "edited"
+				versions[3].Description = "edited"
+			},
+			expectedRevision: 2,
+			expectedReason:   domain.ChainBreakHashMismatch,
+			expectedChecked:  5,
+		},
+		{
+			name: "hash removed",
+			tamper: func(versions []*domain.ServiceVersion) {
+				versions[1].Hash = ""
+			},
+			expectedRevision: 4,
+			expectedReason:   domain.ChainBreakMissingHash,
+			expectedChecked:  4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions := versionChain(5, tt.unhashed)
+			if tt.tamper != nil {
+				tt.tamper(versions)
+			}
+
+			result := verifyVersions(versions)
+
+			assert.Equal(t, tt.expectedChecked, result.Checked)
+			if tt.expectedReason == "" {
+				assert.True(t, result.Valid)
+				assert.Nil(t, result.BrokenLink)
+				assert.Equal(t, tt.unhashed, result.Unhashed)
+				return
+			}
+			assert.False(t, result.Valid)
+			require.NotNil(t, result.BrokenLink)
+			assert.Equal(t, tt.expectedRevision, result.BrokenLink.Revision)
+			assert.Equal(t, tt.expectedReason, result.BrokenLink.Reason)
+		})
+	}
+}
+
+func TestChainVerifier_RemovedRecords(t *testing.T) {
+	// Removing a version from the middle leaves a gap before the next one
+	versions := versionChain(5, 0)
+	result := verifyVersions(append(versions[:2:2], versions[3:]...))
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, 4, result.BrokenLink.Revision)
+	assert.Equal(t, domain.ChainBreakMissingPrev, result.BrokenLink.Reason)
+
+	// So does a new segment started after a lost version
+	versions = versionChain(5, 0)
+	versions[1].PrevHash = ""
+	versions[1].Hash = versions[1].ComputeHash()
+	versions[0].PrevHash = versions[1].Hash
+	versions[0].Hash = versions[0].ComputeHash()
+	result = verifyVersions(append(versions[:2:2], versions[3:]...))
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, 4, result.BrokenLink.Revision)
+	assert.Equal(t, domain.ChainBreakMissingPrev, result.BrokenLink.Reason)
+	assert.Equal(t, 4, result.Checked)
+
+	// And a gap after versions created before hashing
+	versions = versionChain(5, 3)
+	versions[1].PrevHash = ""
+	versions[1].Hash = versions[1].ComputeHash()
+	versions[0].PrevHash = versions[1].Hash
+	versions[0].Hash = versions[0].ComputeHash()
+	result = verifyVersions(append(versions[:2:2], versions[3:]...))
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, 4, result.BrokenLink.Revision)
+	assert.Equal(t, domain.ChainBreakMissingPrev, result.BrokenLink.Reason)
+
+	// Removing the first version leaves the second without a predecessor
+	versions = versionChain(5, 0)
+	result = verifyVersions(versions[:4])
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, 2, result.BrokenLink.Revision)
+	assert.Equal(t, domain.ChainBreakMissingPrev, result.BrokenLink.Reason)
+
+	// An empty chain is intact
+	assert.True(t, verifyVersions(nil).Valid)
+}
+
+func TestServiceVersion_ComputeHashCoversAuthor(t *testing.T) {
+	service := &domain.Service{ID: primitive.NewObjectID(), Name: "payments", Description: "Handles payments", Revision: 1}
+	author := primitive.NewObjectID()
+	version := domain.NewServiceVersion(service, &author)
+	version.Hash = version.ComputeHash()
+	assert.Equal(t, domain.AuthorCommitment(author, version.AuthorSalt), version.AuthorHash)
+
+	// The commitment is salted per version
+	assert.NotEqual(t, version.AuthorHash, domain.NewServiceVersion(service, &author).AuthorHash)
+
+	// Anonymizing the author keeps the hash valid
+	anonymized := *version
+	anonymized.AuthorID = nil
+	anonymized.AuthorSalt = ""
+	anonymized.AuthorDeleted = true
+	assert.Equal(t, version.Hash, anonymized.ComputeHash())
+
+	// Changing the author, or the commitment to them, does not
+	other := primitive.NewObjectID()
+	changed := *version
+	changed.AuthorID = &other
+	assert.NotEqual(t, version.Hash, changed.ComputeHash())
+	changed = anonymized
+	changed.AuthorHash = domain.AuthorCommitment(other, version.AuthorSalt)
+	assert.NotEqual(t, version.Hash, changed.ComputeHash())
+
+	// Nor does adding an author to a version that had none
+	authorless := domain.NewServiceVersion(service, nil)
+	authorless.Hash = authorless.ComputeHash()
+	changed = *authorless
+	changed.AuthorID = &author
+	assert.NotEqual(t, authorless.Hash, changed.ComputeHash())
+
+	// A tombstone is told apart from a version with the same content
+	tombstone := domain.NewServiceTombstone(service, &author)
+	tombstone.ID, tombstone.Revision, tombstone.CreatedAt = version.ID, version.Revision, version.CreatedAt
+	assert.NotEqual(t, version.Hash, tombstone.ComputeHash())
+}
+
+func TestAuditEvent_ComputeHash(t *testing.T) {
+	event := &domain.AuditEvent{
+		ID:        primitive.NewObjectID(),
+		Action:    domain.AuditActionUserRoleChange,
+		ActorID:   "admin-1",
+		Before:    map[string]string{"role": "user", "email": "user@example.com"},
+		After:     map[string]string{},
+		CreatedAt: time.Now(),
+		PrevHash:  "abc",
+	}
+	hash := event.ComputeHash()
+	assert.Len(t, hash, 64)
+
+	// Stable across storage round trips: time zone, precision below
+	// milliseconds and empty summaries don't matter
+	roundTripped := *event
+	roundTripped.CreatedAt = event.CreatedAt.UTC().Truncate(time.Millisecond)
+	roundTripped.Before = map[string]string{"email": "user@example.com", "role": "user"}
+	roundTripped.After = nil
+	assert.Equal(t, hash, roundTripped.ComputeHash())
+
+	// Every field and the previous hash are covered
+	changed := *event
+	changed.ActorID = "admin-2"
+	assert.NotEqual(t, hash, changed.ComputeHash())
+	changed = *event
+	changed.PrevHash = "abd"
+	assert.NotEqual(t, hash, changed.ComputeHash())
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrIdempotencyKeyExists is returned when a record already exists for an idempotency key
+var ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+
+// IdempotencyRecord stores the fingerprint and outcome of a request made with an Idempotency-Key
+type IdempotencyRecord struct {
+	Key         string    `bson:"_id" json:"key"`
+	Fingerprint string    `bson:"fingerprint" json:"fingerprint"`
+	Completed   bool      `bson:"completed" json:"completed"`
+	StatusCode  int       `bson:"status_code" json:"status_code"`
+	ContentType string    `bson:"content_type" json:"content_type"`
+	Body        []byte    `bson:"body" json:"-"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	ExpiresAt   time.Time `bson:"expires_at" json:"expires_at"`
+}
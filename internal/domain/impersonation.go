@@ -0,0 +1,20 @@
+package domain
+
+import "errors"
+
+// Impersonation errors
+var (
+	ErrImpersonateSelf             = errors.New("cannot impersonate yourself")
+	ErrImpersonationTargetInactive = errors.New("only active users can be impersonated")
+)
+
+// ImpersonationResponse is returned when an admin starts impersonating a
+// user. The access token acts as the user but can't be refreshed.
+type ImpersonationResponse struct {
+	AccessToken string       `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType   string       `json:"token_type" example:"Bearer"`
+	ExpiresIn   int64        `json:"expires_in" example:"600"`
+	Scope       string       `json:"scope,omitempty" example:"services:read services:write"`
+	User        UserResponse `json:"user"`
+	ActorID     string       `json:"actor_id" example:"507f1f77bcf86cd799439013"`
+}
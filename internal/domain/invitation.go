@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Invitation errors
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvalidInvitation  = errors.New("invitation is invalid or has expired")
+	ErrInvitationNotSent  = errors.New("invitation email could not be sent")
+)
+
+// Invitation is an emailed invitation for a pending user to set their own
+// password. It shares its ID with the pending user, and only the SHA-256 hash
+// of the token in the invite link is stored.
+type Invitation struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	Email     string             `bson:"email" json:"email"` // Address the invite link was sent to
+	Role      string             `bson:"role" json:"role"`
+	InvitedBy primitive.ObjectID `bson:"invited_by" json:"invited_by"`
+	TokenHash string             `bson:"token_hash" json:"-"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	SentAt    time.Time          `bson:"sent_at" json:"sent_at"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// IsExpired reports whether the invite link has passed its expiry time
+func (i *Invitation) IsExpired() bool {
+	return !i.ExpiresAt.After(time.Now())
+}
+
+// InviteUserRequest represents an admin request to invite a user
+type InviteUserRequest struct {
+	Email     string `json:"email" example:"user@example.com"`
+	FirstName string `json:"first_name" example:"John"`
+	LastName  string `json:"last_name" example:"Doe"`
+	Role      string `json:"role" example:"user"`
+}
+
+// AcceptInvitationRequest sets the password of an invited user with the token from their invite link
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" example:"Zx8rC2kq7VtYw1mP0aLhN3sJ5dF9gB4e6uR8iO2pQ"`
+	Password string `json:"password" example:"securepassword123"`
+}
+
+// InvitationResponse represents the invitation data returned in API responses
+type InvitationResponse struct {
+	ID        string    `json:"id" example:"507f1f77bcf86cd799439011"`
+	Email     string    `json:"email" example:"user@example.com"`
+	Role      string    `json:"role" example:"user"`
+	InvitedBy string    `json:"invited_by" example:"507f1f77bcf86cd799439012"`
+	Expired   bool      `json:"expired" example:"false"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	SentAt    time.Time `json:"sent_at" example:"2024-01-15T10:30:00Z"`
+	ExpiresAt time.Time `json:"expires_at" example:"2024-01-22T10:30:00Z"`
+}
+
+// ToResponse converts an Invitation to an InvitationResponse
+func (i *Invitation) ToResponse() InvitationResponse {
+	return InvitationResponse{
+		ID:        i.ID.Hex(),
+		Email:     i.Email,
+		Role:      i.Role,
+		InvitedBy: i.InvitedBy.Hex(),
+		Expired:   i.IsExpired(),
+		CreatedAt: i.CreatedAt,
+		SentAt:    i.SentAt,
+		ExpiresAt: i.ExpiresAt,
+	}
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidIPAddress is returned for a malformed client IP address
+var ErrInvalidIPAddress = errors.New("invalid IP address")
+
+// LoginAttempts counts the recent failed logins of an account or a client IP.
+// Failures are forgotten once ExpiresAt passes without another one.
+type LoginAttempts struct {
+	Key           string     `bson:"_id" json:"-"`
+	Failures      int        `bson:"failures" json:"failures"`
+	LastFailureAt time.Time  `bson:"last_failure_at" json:"last_failure_at"`
+	LockedUntil   *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	ExpiresAt     time.Time  `bson:"expires_at" json:"-"`
+}
+
+// IsLocked reports whether logins are refused at the given time
+func (a *LoginAttempts) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+// AccountLoginKey is the key that failed logins to an account are counted under.
+// Accounts are keyed by email, so unknown emails are throttled like real ones.
+func AccountLoginKey(email string) string {
+	return "account:" + email
+}
+
+// IPLoginKey is the key that failed logins from a client IP are counted under
+func IPLoginKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Multi-factor authentication errors
+var (
+	ErrMFANotEnrolled    = errors.New("multi-factor authentication is not set up")
+	ErrMFAAlreadyEnabled = errors.New("multi-factor authentication is already enabled")
+	ErrMFACodeRequired   = errors.New("code is required")
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+	ErrMFARequired       = errors.New("multi-factor authentication is required for your role")
+)
+
+// TOTPFactor is the authenticator app of a user. It is enabled once the user
+// confirmed it with a first code.
+type TOTPFactor struct {
+	UserID        primitive.ObjectID `bson:"_id" json:"user_id"`
+	Secret        string             `bson:"secret" json:"-"`
+	ConfirmedAt   *time.Time         `bson:"confirmed_at,omitempty" json:"confirmed_at,omitempty"`
+	LastUsedStep  int64              `bson:"last_used_step" json:"-"`
+	RecoveryCodes []string           `bson:"recovery_codes" json:"-"` // SHA-256 hashes of the unused codes
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// IsEnabled reports whether the factor was confirmed
+func (f *TOTPFactor) IsEnabled() bool {
+	return f.ConfirmedAt != nil
+}
+
+// MFAStatus describes the multi-factor authentication of a user
+type MFAStatus struct {
+	TOTPEnabled            bool       `json:"totp_enabled" example:"true"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty" example:"2024-01-15T10:30:00Z"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining" example:"10"`
+	Required               bool       `json:"required" example:"false"`
+}
+
+// TOTPEnrollment is returned when a user starts setting up an authenticator app
+type TOTPEnrollment struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/Services%20API:user@example.com?issuer=Services+API&secret=JBSWY3DPEHPK3PXP"`
+}
+
+// RecoveryCodesResponse lists newly generated recovery codes. They are only
+// shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k7d2qa-9xmfpz,3hw8nb-c4tzre"`
+}
+
+// MFACodeRequest carries a code from the authenticator app or a recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+// MFAChallenge is returned by login instead of tokens when the user has
+// multi-factor authentication enabled
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfa_required" example:"true"`
+	MFAToken    string `json:"mfa_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresIn   int64  `json:"expires_in" example:"300"`
+}
+
+// MFAVerifyRequest completes a login that returned an MFA challenge
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Code     string `json:"code" example:"123456"`
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // PaginationParams holds pagination parameters
 type PaginationParams struct {
 	Page  int `json:"page"`
@@ -55,6 +57,7 @@ func NewPaginatedResult[T any](data []T, total int64, params PaginationParams) *
 type ListParams struct {
 	Search     string           `json:"search,omitempty"`
 	Name       string           `json:"name,omitempty"`
+	Team       string           `json:"team,omitempty"` // ID of the team that owns the services
 	Sort       string           `json:"sort,omitempty"`
 	Order      string           `json:"order,omitempty"`
 	Pagination PaginationParams `json:"pagination"`
@@ -83,3 +86,39 @@ func IsValidSortField(field string) bool {
 	}
 	return false
 }
+
+// UserListParams holds parameters for listing users
+type UserListParams struct {
+	Query        string           `json:"q,omitempty"` // Every word must appear in the first name, last name or email
+	Role         string           `json:"role,omitempty"`
+	Active       *bool            `json:"active,omitempty"`
+	CreatedAfter *time.Time       `json:"created_after,omitempty"`
+	Sort         string           `json:"sort,omitempty"`
+	Order        string           `json:"order,omitempty"`
+	Pagination   PaginationParams `json:"pagination"`
+}
+
+// DefaultUserListParams returns default user list parameters
+func DefaultUserListParams() UserListParams {
+	return UserListParams{
+		Sort:       "created_at",
+		Order:      "desc",
+		Pagination: DefaultPaginationParams(),
+	}
+}
+
+// ValidUserSortFields returns the valid sort fields for users. Sorting by
+// name orders by last name, then first name.
+func ValidUserSortFields() []string {
+	return []string{"name", "email", "created_at", "last_login"}
+}
+
+// IsValidUserSortField checks if the user sort field is valid
+func IsValidUserSortField(field string) bool {
+	for _, f := range ValidUserSortFields() {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
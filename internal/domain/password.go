@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxPasswordLength is the longest password bcrypt can hash
+const MaxPasswordLength = 72
+
+// Rules of the password policy
+const (
+	PasswordRuleRequired   = "required"
+	PasswordRuleMinLength  = "min_length"
+	PasswordRuleMaxLength  = "max_length"
+	PasswordRuleCharacters = "character_classes"
+	PasswordRuleStrength   = "strength"
+	PasswordRuleReused     = "reused"
+	PasswordRuleBreached   = "breached"
+)
+
+// Password policy errors. A PasswordPolicyError matches the errors of the
+// rules it lists.
+var (
+	ErrPasswordPolicy        = errors.New("password does not meet the password policy")
+	ErrPasswordTooFewClasses = errors.New("password uses too few character classes")
+	ErrPasswordTooWeak       = errors.New("password is too easy to guess")
+	ErrPasswordReused        = errors.New("password was used recently")
+	ErrPasswordBreached      = errors.New("password appears in a data breach")
+)
+
+// PasswordViolation is a rule of the password policy that a password breaks
+type PasswordViolation struct {
+	Rule    string `json:"rule" example:"min_length"`
+	Message string `json:"message" example:"password must be at least 12 characters"`
+	err     error
+}
+
+// NewPasswordViolation creates a violation of a rule, matching err
+func NewPasswordViolation(rule, message string, err error) PasswordViolation {
+	return PasswordViolation{Rule: rule, Message: message, err: err}
+}
+
+// PasswordPolicyError lists the rules of the password policy a password breaks
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns ErrPasswordPolicy and the errors of the violated rules
+func (e *PasswordPolicyError) Unwrap() []error {
+	errs := []error{ErrPasswordPolicy}
+	for _, v := range e.Violations {
+		if v.err != nil {
+			errs = append(errs, v.err)
+		}
+	}
+	return errs
+}
+
+// PasswordHistoryEntry is a previous password hash of a user
+type PasswordHistoryEntry struct {
+	Hash      string             `bson:"_id"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
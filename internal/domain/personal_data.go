@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// PersonalDataExport is the archive of everything stored about a user that
+// they can download with GET /users/me/export
+type PersonalDataExport struct {
+	ExportedAt time.Time          `json:"exported_at" example:"2024-01-15T10:30:00Z"`
+	User       UserResponse       `json:"user"`
+	Teams      []UserTeamResponse `json:"teams"`
+	Sessions   []SessionResponse  `json:"sessions"`
+	APIKeys    []APIKeyResponse   `json:"api_keys"`
+	// Services holds the current state of the services the user created or edited
+	Services []ServiceResponse `json:"services"`
+	// ServiceVersions holds every change the user made to a service
+	ServiceVersions []ServiceVersionResponse `json:"service_versions"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+// RefreshToken is the server-side record of an issued refresh token. Tokens
+// issued from the same login share a FamilyID; each refresh rotates the
+// presented token by recording its successor in ReplacedBy.
+type RefreshToken struct {
+	ID         string             `bson:"_id" json:"id"` // jti claim
+	FamilyID   string             `bson:"family_id" json:"family_id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	ReplacedBy string             `bson:"replaced_by,omitempty" json:"replaced_by,omitempty"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	ExpiresAt  time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// IsRevoked reports whether the token has been revoked
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsRotated reports whether the token has already been exchanged for a successor
+func (t *RefreshToken) IsRotated() bool {
+	return t.ReplacedBy != ""
+}
+
+// LogoutRequest represents a request to end the session of a refresh token
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // ServiceRepository defines the interface for service data access
 type ServiceRepository interface {
@@ -10,9 +13,15 @@ type ServiceRepository interface {
 	// GetByID retrieves a service by its ID
 	GetByID(ctx context.Context, id string) (*Service, error)
 
-	// Update updates an existing service and increments revision
+	// Update updates an existing service and increments revision. The service
+	// is set to the revision written by this call.
 	Update(ctx context.Context, service *Service) error
 
+	// Restore writes back the state of a service before an update, including
+	// its revision, to undo the update. It fails with ErrServiceChanged unless
+	// the service is still at the revision written by that update.
+	Restore(ctx context.Context, previous *Service) error
+
 	// Delete deletes a service by its ID
 	Delete(ctx context.Context, id string) error
 
@@ -22,7 +31,11 @@ type ServiceRepository interface {
 
 // ServiceVersionRepository defines the interface for service version data access
 type ServiceVersionRepository interface {
-	// Create creates a new service version snapshot
+	// Create creates a new service version snapshot. Unless it is the first
+	// revision, it fails with ErrPreviousVersionMissing if the previous
+	// revision isn't stored with the hash the version chains onto. A version
+	// with no PrevHash may follow a missing revision, starting a new segment
+	// of the chain after a gap.
 	Create(ctx context.Context, version *ServiceVersion) error
 
 	// GetByID retrieves a service version by its ID
@@ -36,6 +49,16 @@ type ServiceVersionRepository interface {
 
 	// DeleteByServiceID deletes all versions for a service
 	DeleteByServiceID(ctx context.Context, serviceID string) error
+
+	// ListServiceIDs retrieves the IDs of every service that has versions,
+	// including deleted services whose tombstones remain, oldest first
+	ListServiceIDs(ctx context.Context) ([]string, error)
+
+	// ListByAuthor retrieves every version a user authored, oldest first
+	ListByAuthor(ctx context.Context, authorID string) ([]ServiceVersion, error)
+
+	// AnonymizeAuthor clears the author of every version a user authored and marks the author deleted
+	AnonymizeAuthor(ctx context.Context, authorID string) error
 }
 
 // UserRepository defines the interface for user data access
@@ -55,9 +78,245 @@ type UserRepository interface {
 	// Delete deletes a user by their ID
 	Delete(ctx context.Context, id string) error
 
-	// List retrieves users with pagination
-	List(ctx context.Context, params PaginationParams) (*PaginatedResult[User], error)
+	// List retrieves users with filtering, sorting, and pagination
+	List(ctx context.Context, params UserListParams) (*PaginatedResult[User], error)
+
+	// RecordLogin sets the time a user last logged in
+	RecordLogin(ctx context.Context, id string, at time.Time) error
+
+	// ListDeletionDue retrieves the users whose scheduled deletion is due at the given time
+	ListDeletionDue(ctx context.Context, at time.Time) ([]User, error)
 
 	// ExistsByEmail checks if a user with the given email exists
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+
+	// CountByRole counts the users assigned a role
+	CountByRole(ctx context.Context, role string) (int64, error)
+}
+
+// IdempotencyRepository defines the interface for idempotency record data access
+type IdempotencyRepository interface {
+	// Create stores a new in-progress record, returning ErrIdempotencyKeyExists
+	// if an unexpired record with the same key exists
+	Create(ctx context.Context, record *IdempotencyRecord) error
+
+	// GetByKey retrieves an unexpired record by its key
+	GetByKey(ctx context.Context, key string) (*IdempotencyRecord, error)
+
+	// Complete stores the response of an in-progress record
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+
+	// Delete deletes a record so its key can be used again
+	Delete(ctx context.Context, key string) error
+}
+
+// RefreshTokenRepository defines the interface for refresh token data access
+type RefreshTokenRepository interface {
+	// Create stores a newly issued refresh token
+	Create(ctx context.Context, token *RefreshToken) error
+
+	// GetByID retrieves an unexpired refresh token by its ID (jti)
+	GetByID(ctx context.Context, id string) (*RefreshToken, error)
+
+	// Rotate atomically marks an active token as replaced by replacedBy,
+	// returning ErrRefreshTokenReused if it was already rotated or revoked
+	Rotate(ctx context.Context, id, replacedBy string) error
+
+	// RevokeFamily revokes every token in a token family
+	RevokeFamily(ctx context.Context, familyID string) error
+
+	// RevokeAllForUser revokes every token issued to a user
+	RevokeAllForUser(ctx context.Context, userID string) error
+}
+
+// SessionRepository defines the interface for session data access
+type SessionRepository interface {
+	// Create stores a new session
+	Create(ctx context.Context, session *Session) error
+
+	// GetByID retrieves an unexpired session by its ID, including revoked sessions
+	GetByID(ctx context.Context, id string) (*Session, error)
+
+	// ListByUser retrieves the active sessions of a user, most recently seen first
+	ListByUser(ctx context.Context, userID string) ([]Session, error)
+
+	// Touch records activity on a session from the given client and extends its expiry
+	Touch(ctx context.Context, id string, client ClientInfo, expiresAt time.Time) error
+
+	// Revoke revokes a session
+	Revoke(ctx context.Context, id string) error
+
+	// RevokeAllForUser revokes every session of a user
+	RevokeAllForUser(ctx context.Context, userID string) error
+}
+
+// APIKeyRepository defines the interface for stored API key data access
+type APIKeyRepository interface {
+	// Create stores a new API key, returning ErrAPIKeyPrefixExists on a prefix collision
+	Create(ctx context.Context, key *APIKey) error
+
+	// GetByID retrieves an API key by its ID
+	GetByID(ctx context.Context, id string) (*APIKey, error)
+
+	// GetByPrefix retrieves an API key by its lookup prefix
+	GetByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+
+	// Update updates the name, scopes and expiry of an API key
+	Update(ctx context.Context, key *APIKey) error
+
+	// Delete deletes an API key by its ID
+	Delete(ctx context.Context, id string) error
+
+	// List retrieves API keys with pagination, restricted to one owner unless ownerID is empty
+	List(ctx context.Context, ownerID string, params PaginationParams) (*PaginatedResult[APIKey], error)
+
+	// TouchLastUsed records when an API key was last used
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// RoleRepository defines the interface for role persistence
+type RoleRepository interface {
+	// Create stores a new role, returning ErrRoleExists if the name is taken
+	Create(ctx context.Context, role *Role) error
+
+	// GetByName retrieves a role by its name
+	GetByName(ctx context.Context, name string) (*Role, error)
+
+	// List retrieves all roles ordered by name
+	List(ctx context.Context) ([]Role, error)
+
+	// Update updates the description and permissions of a role
+	Update(ctx context.Context, role *Role) error
+
+	// Delete deletes a role by its name
+	Delete(ctx context.Context, name string) error
+}
+
+// MFARepository defines the interface for multi-factor authentication data access
+type MFARepository interface {
+	// Save creates or replaces the TOTP factor of a user
+	Save(ctx context.Context, factor *TOTPFactor) error
+
+	// GetByUserID retrieves the TOTP factor of a user, returning ErrMFANotEnrolled if there is none
+	GetByUserID(ctx context.Context, userID string) (*TOTPFactor, error)
+
+	// UseStep atomically records a TOTP time step as used, returning
+	// ErrInvalidMFACode unless it is later than the last used step
+	UseStep(ctx context.Context, userID string, step int64) error
+
+	// UseRecoveryCode atomically removes a recovery code by its hash,
+	// returning ErrInvalidMFACode if the user doesn't have it
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) error
+
+	// Delete removes the TOTP factor of a user
+	Delete(ctx context.Context, userID string) error
+}
+
+// LoginAttemptRepository defines the interface for failed login tracking
+type LoginAttemptRepository interface {
+	// Get retrieves the unexpired failed logins of a key, returning ErrNotFound if there are none
+	Get(ctx context.Context, key string) (*LoginAttempts, error)
+
+	// RecordFailure atomically counts a failed login for a key and returns
+	// the updated count. The count restarts once the previous failures expired.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (*LoginAttempts, error)
+
+	// Lock refuses logins for a key until the given time
+	Lock(ctx context.Context, key string, until time.Time) error
+
+	// Reset forgets the failed logins of a key
+	Reset(ctx context.Context, key string) error
+}
+
+// UserTokenRepository defines the interface for emailed token data access
+type UserTokenRepository interface {
+	// Create stores a new token
+	Create(ctx context.Context, token *UserToken) error
+
+	// Consume atomically deletes and returns an unexpired token by its hash
+	// and purpose, returning ErrInvalidUserToken if there is none
+	Consume(ctx context.Context, hash, purpose string) (*UserToken, error)
+
+	// DeleteForUser deletes the outstanding tokens of a user for a purpose
+	DeleteForUser(ctx context.Context, userID, purpose string) error
+}
+
+// PasswordHistoryRepository defines the interface for previous password data access
+type PasswordHistoryRepository interface {
+	// List returns the most recent previous password hashes of a user, newest first
+	List(ctx context.Context, userID string, limit int) ([]string, error)
+
+	// Add records a previous password hash of a user, keeping only the newest keep hashes
+	Add(ctx context.Context, userID, hash string, keep int) error
+}
+
+// InvitationRepository defines the interface for invitation data access
+type InvitationRepository interface {
+	// Create stores a new invitation
+	Create(ctx context.Context, invitation *Invitation) error
+
+	// GetByID retrieves an invitation by the ID of its pending user
+	GetByID(ctx context.Context, id string) (*Invitation, error)
+
+	// GetByTokenHash retrieves an unexpired invitation by the hash of its
+	// token, returning ErrInvalidInvitation if there is none
+	GetByTokenHash(ctx context.Context, hash string) (*Invitation, error)
+
+	// Consume atomically deletes an unexpired invitation by the hash of its
+	// token, returning ErrInvalidInvitation if there is none
+	Consume(ctx context.Context, hash string) error
+
+	// Update replaces the address, token and expiry of an invitation when it is resent
+	Update(ctx context.Context, invitation *Invitation) error
+
+	// Delete deletes an invitation by the ID of its pending user
+	Delete(ctx context.Context, id string) error
+
+	// List retrieves invitations with pagination, newest first
+	List(ctx context.Context, params PaginationParams) (*PaginatedResult[Invitation], error)
+}
+
+// TeamRepository defines the interface for team and team membership persistence
+type TeamRepository interface {
+	// Create stores a new team with its initial members, returning
+	// ErrTeamExists if the name is taken
+	Create(ctx context.Context, team *Team) error
+
+	// GetByID retrieves a team and its members by its ID
+	GetByID(ctx context.Context, id string) (*Team, error)
+
+	// List retrieves teams with pagination, ordered by name
+	List(ctx context.Context, params PaginationParams) (*PaginatedResult[Team], error)
+
+	// ListByMember retrieves the teams a user belongs to, ordered by name
+	ListByMember(ctx context.Context, userID string) ([]Team, error)
+
+	// Update updates the name and description of a team, returning
+	// ErrTeamExists if the new name is taken
+	Update(ctx context.Context, team *Team) error
+
+	// Delete deletes a team and its memberships by its ID
+	Delete(ctx context.Context, id string) error
+
+	// SetMember adds a member to a team or changes the role of an existing
+	// member, keeping the time they were added
+	SetMember(ctx context.Context, teamID string, member TeamMember) error
+
+	// RemoveMember removes a user from a team, returning
+	// ErrTeamMemberNotFound if they aren't a member
+	RemoveMember(ctx context.Context, teamID, userID string) error
+
+	// RemoveUser removes a user from every team they belong to
+	RemoveUser(ctx context.Context, userID string) error
+}
+
+// AuditRepository defines the interface for the append-only audit log. Events
+// can't be changed or deleted.
+type AuditRepository interface {
+	// Create appends an event, setting its ID and creation time unless set.
+	// It fails with ErrChainConflict if another event has the same PrevHash.
+	Create(ctx context.Context, event *AuditEvent) error
+
+	// List retrieves up to query.Limit events matching a query, newest first
+	List(ctx context.Context, query AuditQuery) ([]AuditEvent, error)
 }
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// Role errors
+var (
+	ErrRoleNotFound           = errors.New("role not found")
+	ErrRoleExists             = errors.New("role already exists")
+	ErrRoleNameInvalid        = errors.New("role name must be 2-32 lowercase letters, digits or dashes, starting with a letter")
+	ErrRoleBuiltIn            = errors.New("built-in roles can't be deleted")
+	ErrRoleImmutable          = errors.New("the admin role can't be changed")
+	ErrRoleInUse              = errors.New("role is assigned to users")
+	ErrInvalidPermission      = errors.New("invalid permission")
+	ErrRoleDescriptionTooLong = errors.New("description must be at most 200 characters")
+)
+
+var roleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]{1,31}$`)
+
+// Role is a named set of permissions that can be assigned to users
+type Role struct {
+	Name        string    `bson:"_id" json:"name"`
+	Description string    `bson:"description" json:"description"`
+	Permissions []string  `bson:"permissions" json:"permissions"`
+	BuiltIn     bool      `bson:"built_in" json:"built_in"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// HasPermission checks if the role grants a permission
+func (r *Role) HasPermission(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidRoleName checks if a role name has the allowed format
+func IsValidRoleName(name string) bool {
+	return roleNameRegex.MatchString(name)
+}
+
+// CreateRoleRequest represents the request body for creating a role
+type CreateRoleRequest struct {
+	Name        string   `json:"name" example:"release-manager"`
+	Description string   `json:"description" example:"Creates and updates services during releases"`
+	Permissions []string `json:"permissions" example:"services:read,services:write"`
+}
+
+// UpdateRoleRequest represents the request body for updating a role
+type UpdateRoleRequest struct {
+	Description string   `json:"description" example:"Creates and updates services during releases"`
+	Permissions []string `json:"permissions" example:"services:read,services:write"`
+}
+
+// RoleResponse represents the role data returned in API responses
+type RoleResponse struct {
+	Name        string    `json:"name" example:"editor"`
+	Description string    `json:"description" example:"Create and update services"`
+	Permissions []string  `json:"permissions" example:"services:read,services:write"`
+	BuiltIn     bool      `json:"built_in" example:"true"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// ToResponse converts a Role to a RoleResponse
+func (r *Role) ToResponse() RoleResponse {
+	return RoleResponse{
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: r.Permissions,
+		BuiltIn:     r.BuiltIn,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
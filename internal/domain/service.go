@@ -8,12 +8,13 @@ import (
 
 // Service represents a service in the organization
 type Service struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name        string             `bson:"name" json:"name"`
-	Description string             `bson:"description" json:"description"`
-	Revision    int                `bson:"revision" json:"revision"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name        string              `bson:"name" json:"name"`
+	Description string              `bson:"description" json:"description"`
+	TeamID      *primitive.ObjectID `bson:"team_id,omitempty" json:"team_id,omitempty"` // Team that owns the service, if any
+	Revision    int                 `bson:"revision" json:"revision"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updated_at"`
 }
 
 // ServiceResponse is the API response format for a service
@@ -21,6 +22,7 @@ type ServiceResponse struct {
 	ID          string    `json:"id" example:"507f1f77bcf86cd799439011"`
 	Name        string    `json:"name" example:"payment-service"`
 	Description string    `json:"description" example:"Handles payment processing"`
+	TeamID      string    `json:"team_id,omitempty" example:"507f1f77bcf86cd799439012"`
 	Revision    int       `json:"revision" example:"1"`
 	CreatedAt   time.Time `json:"created_at" example:"2024-01-15T10:30:00Z"`
 	UpdatedAt   time.Time `json:"updated_at" example:"2024-01-15T10:30:00Z"`
@@ -28,7 +30,7 @@ type ServiceResponse struct {
 
 // ToResponse converts a Service to its API response format
 func (s *Service) ToResponse() ServiceResponse {
-	return ServiceResponse{
+	resp := ServiceResponse{
 		ID:          s.ID.Hex(),
 		Name:        s.Name,
 		Description: s.Description,
@@ -36,22 +38,29 @@ func (s *Service) ToResponse() ServiceResponse {
 		CreatedAt:   s.CreatedAt,
 		UpdatedAt:   s.UpdatedAt,
 	}
+	if s.TeamID != nil {
+		resp.TeamID = s.TeamID.Hex()
+	}
+	return resp
 }
 
 // CreateServiceRequest represents the request body for creating a service
 type CreateServiceRequest struct {
 	Name        string `json:"name" example:"payment-service"`
 	Description string `json:"description" example:"Handles payment processing"`
+	TeamID      string `json:"team_id,omitempty" example:"507f1f77bcf86cd799439012"`
 }
 
 // UpdateServiceRequest represents the request body for updating a service
 type UpdateServiceRequest struct {
 	Name        string `json:"name" example:"payment-service-v2"`
 	Description string `json:"description" example:"Updated payment processing service"`
+	TeamID      string `json:"team_id,omitempty" example:"507f1f77bcf86cd799439012"`
 }
 
 // PatchServiceRequest represents the request body for partially updating a service
 type PatchServiceRequest struct {
 	Name        *string `json:"name,omitempty" example:"new-service-name"`
 	Description *string `json:"description,omitempty" example:"Updated description"`
+	TeamID      *string `json:"team_id,omitempty" example:"507f1f77bcf86cd799439012"` // An empty string removes the team
 }
@@ -1,51 +1,147 @@
 package domain
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Service version errors
+var (
+	ErrPreviousVersionMissing = errors.New("previous version of the service is missing")
+)
+
 // ServiceVersion represents a historical snapshot of a service at a specific revision
 type ServiceVersion struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	ServiceID   primitive.ObjectID `bson:"service_id" json:"service_id"`
-	Revision    int                `bson:"revision" json:"revision"`
-	Name        string             `bson:"name" json:"name"`
-	Description string             `bson:"description" json:"description"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"` // When this version was created
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ServiceID     primitive.ObjectID  `bson:"service_id" json:"service_id"`
+	Revision      int                 `bson:"revision" json:"revision"`
+	Name          string              `bson:"name" json:"name"`
+	Description   string              `bson:"description" json:"description"`
+	AuthorID      *primitive.ObjectID `bson:"author_id,omitempty" json:"author_id,omitempty"`           // User who made the change, if known
+	AuthorDeleted bool                `bson:"author_deleted,omitempty" json:"author_deleted,omitempty"` // Set, and AuthorID cleared, once the author's account is deleted
+	AuthorHash    string              `bson:"author_hash,omitempty" json:"author_hash,omitempty"`       // Commitment to AuthorID, kept once the author is anonymized
+	AuthorSalt    string              `bson:"author_salt,omitempty" json:"-"`                           // Random key of AuthorHash, discarded once the author is anonymized
+	Deleted       bool                `bson:"deleted,omitempty" json:"deleted,omitempty"`               // Set on the version recording that the service was deleted
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`                             // When this version was created
+	Hash          string              `bson:"hash,omitempty" json:"hash,omitempty"`                     // SHA-256 of the content and PrevHash
+	PrevHash      string              `bson:"prev_hash,omitempty" json:"prev_hash,omitempty"`           // Hash of the previous revision
 }
 
 // ServiceVersionResponse is the API response format for a service version
 type ServiceVersionResponse struct {
-	ID          string    `json:"id" example:"507f1f77bcf86cd799439011"`
-	ServiceID   string    `json:"service_id" example:"507f1f77bcf86cd799439012"`
-	Revision    int       `json:"revision" example:"2"`
-	Name        string    `json:"name" example:"payment-service"`
-	Description string    `json:"description" example:"Handles payment processing"`
-	CreatedAt   time.Time `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	ID            string    `json:"id" example:"507f1f77bcf86cd799439011"`
+	ServiceID     string    `json:"service_id" example:"507f1f77bcf86cd799439012"`
+	Revision      int       `json:"revision" example:"2"`
+	Name          string    `json:"name" example:"payment-service"`
+	Description   string    `json:"description" example:"Handles payment processing"`
+	AuthorID      string    `json:"author_id,omitempty" example:"507f1f77bcf86cd799439013"`
+	AuthorDeleted bool      `json:"author_deleted,omitempty" example:"false"`
+	AuthorHash    string    `json:"author_hash,omitempty" example:"87619bc7867c12a5dcbe44a6dce6c2348e94b687eeb16a76020cd88d490a0b1b"`
+	Deleted       bool      `json:"deleted,omitempty" example:"false"`
+	CreatedAt     time.Time `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	Hash          string    `json:"hash,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	PrevHash      string    `json:"prev_hash,omitempty" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
 }
 
 // ToResponse converts a ServiceVersion to its API response format
 func (sv *ServiceVersion) ToResponse() ServiceVersionResponse {
-	return ServiceVersionResponse{
-		ID:          sv.ID.Hex(),
-		ServiceID:   sv.ServiceID.Hex(),
-		Revision:    sv.Revision,
-		Name:        sv.Name,
-		Description: sv.Description,
-		CreatedAt:   sv.CreatedAt,
+	resp := ServiceVersionResponse{
+		ID:            sv.ID.Hex(),
+		ServiceID:     sv.ServiceID.Hex(),
+		Revision:      sv.Revision,
+		Name:          sv.Name,
+		Description:   sv.Description,
+		AuthorDeleted: sv.AuthorDeleted,
+		AuthorHash:    sv.AuthorHash,
+		Deleted:       sv.Deleted,
+		CreatedAt:     sv.CreatedAt,
+		Hash:          sv.Hash,
+		PrevHash:      sv.PrevHash,
 	}
+	if sv.AuthorID != nil {
+		resp.AuthorID = sv.AuthorID.Hex()
+	}
+	return resp
 }
 
-// NewServiceVersion creates a new ServiceVersion from a Service
-func NewServiceVersion(service *Service) *ServiceVersion {
-	return &ServiceVersion{
+// NewServiceVersion creates a new ServiceVersion from a Service, authored by
+// the given user if known
+func NewServiceVersion(service *Service, authorID *primitive.ObjectID) *ServiceVersion {
+	version := &ServiceVersion{
 		ID:          primitive.NewObjectID(),
 		ServiceID:   service.ID,
 		Revision:    service.Revision,
 		Name:        service.Name,
 		Description: service.Description,
-		CreatedAt:   time.Now(),
+		AuthorID:    authorID,
+		CreatedAt:   time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if authorID != nil {
+		salt := make([]byte, 16)
+		rand.Read(salt)
+		version.AuthorSalt = hex.EncodeToString(salt)
+		version.AuthorHash = AuthorCommitment(*authorID, version.AuthorSalt)
+	}
+	return version
+}
+
+// NewServiceTombstone creates the version recording that a service was
+// deleted by the given user, if known. It follows the service's last revision
+// and keeps its content, so that the history stays chained after the service
+// is gone.
+func NewServiceTombstone(service *Service, authorID *primitive.ObjectID) *ServiceVersion {
+	version := NewServiceVersion(service, authorID)
+	version.Revision = service.Revision + 1
+	version.Deleted = true
+	return version
+}
+
+// AuthorCommitment returns the HMAC-SHA256 of an author's ID keyed with a
+// random salt. Versions keep it when the author is anonymized, so that the
+// hash still covers who made them, and discard the salt, so that it can't be
+// matched against the IDs of known users.
+func AuthorCommitment(authorID primitive.ObjectID, salt string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(authorID.Hex()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ComputeHash hashes the content of the version together with PrevHash. The
+// author is always covered: by the commitment to AuthorID while it is known,
+// and by the AuthorHash kept once it is anonymized, so an AuthorID that was
+// added, changed or doesn't match AuthorHash changes the hash. Versions
+// without an author or tombstone leave those fields out of the hash.
+func (sv *ServiceVersion) ComputeHash() string {
+	authorHash := sv.AuthorHash
+	if sv.AuthorID != nil {
+		authorHash = AuthorCommitment(*sv.AuthorID, sv.AuthorSalt)
+	}
+	return chainHash(struct {
+		ID          string `json:"id"`
+		ServiceID   string `json:"service_id"`
+		Revision    int    `json:"revision"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		AuthorHash  string `json:"author_hash,omitempty"`
+		Deleted     bool   `json:"deleted,omitempty"`
+		CreatedAt   string `json:"created_at"`
+		PrevHash    string `json:"prev_hash"`
+	}{sv.ID.Hex(), sv.ServiceID.Hex(), sv.Revision, sv.Name, sv.Description, authorHash, sv.Deleted, chainTime(sv.CreatedAt), sv.PrevHash})
+}
+
+// ChainLink returns the version as a link of its service's hash chain
+func (sv *ServiceVersion) ChainLink() ChainLink {
+	return ChainLink{
+		ID:       sv.ID.Hex(),
+		Revision: sv.Revision,
+		Hash:     sv.Hash,
+		PrevHash: sv.PrevHash,
+		Computed: sv.ComputeHash(),
 	}
 }
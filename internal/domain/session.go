@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrSessionNotFound is returned when a session does not exist or has expired
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrSessionRevoked is returned when a token belongs to a revoked session
+	ErrSessionRevoked = errors.New("session has been revoked")
+)
+
+// Session is a login of a user on a device. Its ID is shared by the refresh
+// token family and the access tokens issued from that login.
+type Session struct {
+	ID         string             `bson:"_id" json:"id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UserAgent  string             `bson:"user_agent" json:"user_agent"`
+	IPAddress  string             `bson:"ip_address" json:"ip_address"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	LastSeenAt time.Time          `bson:"last_seen_at" json:"last_seen_at"`
+	ExpiresAt  time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// IsActive reports whether the session is neither revoked nor expired
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(time.Now())
+}
+
+// ClientInfo describes the client a session is started or refreshed from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionResponse represents the session data returned in API responses
+type SessionResponse struct {
+	ID         string    `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)"`
+	IPAddress  string    `json:"ip_address" example:"203.0.113.7"`
+	Current    bool      `json:"current" example:"true"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	LastSeenAt time.Time `json:"last_seen_at" example:"2024-01-15T11:30:00Z"`
+	ExpiresAt  time.Time `json:"expires_at" example:"2024-01-22T10:30:00Z"`
+}
+
+// ToResponse converts a Session to a SessionResponse, flagging it as current
+// when it is the session of the calling token
+func (s *Session) ToResponse(currentSessionID string) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		Current:    s.ID == currentSessionID,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+		ExpiresAt:  s.ExpiresAt,
+	}
+}
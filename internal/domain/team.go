@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Team roles
+const (
+	// TeamRoleMaintainer members manage the team and its membership
+	TeamRoleMaintainer = "maintainer"
+	// TeamRoleMember members work on the services of the team
+	TeamRoleMember = "member"
+)
+
+// Team errors
+var (
+	ErrTeamNotFound           = errors.New("team not found")
+	ErrTeamExists             = errors.New("team already exists")
+	ErrTeamNameInvalid        = errors.New("team name must be 2-64 lowercase letters, digits or dashes, starting with a letter")
+	ErrTeamDescriptionTooLong = errors.New("description must be at most 200 characters")
+	ErrInvalidTeamRole        = errors.New("team role must be maintainer or member")
+	ErrTeamMemberNotFound     = errors.New("user is not a member of the team")
+	ErrLastMaintainer         = errors.New("a team must keep at least one maintainer")
+	ErrTeamHasServices        = errors.New("team owns services")
+	ErrTeamAccessDenied       = errors.New("only members of the team can do this")
+)
+
+var teamNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]{1,63}$`)
+
+// Team is a group of users that owns services. Maintainers manage the team;
+// members and maintainers alike can change the services the team owns.
+type Team struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description" json:"description"`
+	Members     []TeamMember       `bson:"members" json:"members"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// TeamMember is the membership of a user in a team
+type TeamMember struct {
+	UserID  primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Role    string             `bson:"role" json:"role"`
+	AddedAt time.Time          `bson:"added_at" json:"added_at"`
+}
+
+// Member returns the membership of a user, or nil if they aren't a member
+func (t *Team) Member(userID string) *TeamMember {
+	for i := range t.Members {
+		if t.Members[i].UserID.Hex() == userID {
+			return &t.Members[i]
+		}
+	}
+	return nil
+}
+
+// Maintainers counts the maintainers of the team
+func (t *Team) Maintainers() int {
+	count := 0
+	for _, m := range t.Members {
+		if m.Role == TeamRoleMaintainer {
+			count++
+		}
+	}
+	return count
+}
+
+// IsValidTeamName checks if a team name has the allowed format
+func IsValidTeamName(name string) bool {
+	return teamNameRegex.MatchString(name)
+}
+
+// IsValidTeamRole checks if a team role is known
+func IsValidTeamRole(role string) bool {
+	return role == TeamRoleMaintainer || role == TeamRoleMember
+}
+
+// CreateTeamRequest represents the request body for creating a team
+type CreateTeamRequest struct {
+	Name        string `json:"name" example:"payments"`
+	Description string `json:"description" example:"Owns the payment services"`
+}
+
+// UpdateTeamRequest represents the request body for updating a team
+type UpdateTeamRequest struct {
+	Name        string `json:"name" example:"payments"`
+	Description string `json:"description" example:"Owns the payment services"`
+}
+
+// SetTeamMemberRequest represents the request body for adding a member to a
+// team or changing their team role
+type SetTeamMemberRequest struct {
+	Role string `json:"role" example:"member"`
+}
+
+// TeamMemberResponse represents a team membership returned in API responses
+type TeamMemberResponse struct {
+	UserID  string    `json:"user_id" example:"507f1f77bcf86cd799439012"`
+	Role    string    `json:"role" example:"maintainer"`
+	AddedAt time.Time `json:"added_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// TeamResponse represents the team data returned in API responses
+type TeamResponse struct {
+	ID          string               `json:"id" example:"507f1f77bcf86cd799439011"`
+	Name        string               `json:"name" example:"payments"`
+	Description string               `json:"description" example:"Owns the payment services"`
+	Members     []TeamMemberResponse `json:"members"`
+	CreatedAt   time.Time            `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt   time.Time            `json:"updated_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// ToResponse converts a Team to a TeamResponse
+func (t *Team) ToResponse() TeamResponse {
+	members := make([]TeamMemberResponse, len(t.Members))
+	for i, m := range t.Members {
+		members[i] = TeamMemberResponse{
+			UserID:  m.UserID.Hex(),
+			Role:    m.Role,
+			AddedAt: m.AddedAt,
+		}
+	}
+
+	return TeamResponse{
+		ID:          t.ID.Hex(),
+		Name:        t.Name,
+		Description: t.Description,
+		Members:     members,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+// UserTeamResponse represents a team of the current user and their role in it
+type UserTeamResponse struct {
+	ID   string `json:"id" example:"507f1f77bcf86cd799439011"`
+	Name string `json:"name" example:"payments"`
+	Role string `json:"role" example:"maintainer"`
+}
+
+// CurrentUserResponse represents the profile of the current user and the teams they belong to
+type CurrentUserResponse struct {
+	UserResponse
+	Teams []UserTeamResponse `json:"teams"`
+}
+
+// NewCurrentUserResponse builds the profile of a user from the teams they belong to
+func NewCurrentUserResponse(user *User, teams []Team) CurrentUserResponse {
+	memberships := make([]UserTeamResponse, 0, len(teams))
+	for i := range teams {
+		role := ""
+		if m := teams[i].Member(user.ID.Hex()); m != nil {
+			role = m.Role
+		}
+		memberships = append(memberships, UserTeamResponse{
+			ID:   teams[i].ID.Hex(),
+			Name: teams[i].Name,
+			Role: role,
+		})
+	}
+
+	return CurrentUserResponse{
+		UserResponse: user.ToResponse(),
+		Teams:        memberships,
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-// User roles
+// Built-in user roles. Further roles can be created at runtime.
 const (
-	RoleUser  = "user"
-	RoleAdmin = "admin"
+	RoleViewer       = "viewer"
+	RoleEditor       = "editor"
+	RoleServiceOwner = "service-owner"
+	RoleAuditor      = "auditor"
+	RoleUser         = "user"
+	RoleAdmin        = "admin"
 )
 
 // User validation errors
@@ -20,8 +24,8 @@ var (
 	ErrEmailInvalid       = errors.New("email format is invalid")
 	ErrEmailTooLong       = errors.New("email must be at most 255 characters")
 	ErrPasswordRequired   = errors.New("password is required")
-	ErrPasswordTooShort   = errors.New("password must be at least 8 characters")
-	ErrPasswordTooLong    = errors.New("password must be at most 72 characters")
+	ErrPasswordTooShort   = errors.New("password is too short")
+	ErrPasswordTooLong    = errors.New("password is too long")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrEmailAlreadyExists = errors.New("email already exists")
 	ErrUserNotFound       = errors.New("user not found")
@@ -29,44 +33,60 @@ var (
 	ErrFirstNameRequired  = errors.New("first name is required")
 	ErrFirstNameTooLong   = errors.New("first name must be at most 100 characters")
 	ErrLastNameTooLong    = errors.New("last name must be at most 100 characters")
+	ErrSSOFailed          = errors.New("single sign-on failed")
+	ErrSSOEmailRequired   = errors.New("identity provider did not release a verified email address")
+	ErrEmailNotVerified   = errors.New("email address is not verified")
+	ErrDeletionNotPending = errors.New("account deletion is not scheduled")
 )
 
 // User represents a user in the system
 type User struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Email        string             `bson:"email" json:"email"`
-	PasswordHash string             `bson:"password_hash" json:"-"` // Never expose in JSON
-	FirstName    string             `bson:"first_name" json:"first_name"`
-	LastName     string             `bson:"last_name" json:"last_name"`
-	Role         string             `bson:"role" json:"role"`
-	Active       bool               `bson:"active" json:"active"`
-	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+	ID                  primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Email               string             `bson:"email" json:"email"`
+	PasswordHash        string             `bson:"password_hash" json:"-"` // Never expose in JSON
+	FirstName           string             `bson:"first_name" json:"first_name"`
+	LastName            string             `bson:"last_name" json:"last_name"`
+	Role                string             `bson:"role" json:"role"`
+	Active              bool               `bson:"active" json:"active"`
+	EmailVerified       bool               `bson:"email_verified" json:"email_verified"` // Set once the user proved they own the address
+	Pending             bool               `bson:"pending" json:"pending"`               // Invited and yet to set a password
+	LastLoginAt         *time.Time         `bson:"last_login_at,omitempty" json:"last_login_at,omitempty"`
+	DeletionScheduledAt *time.Time         `bson:"deletion_scheduled_at,omitempty" json:"deletion_scheduled_at,omitempty"` // When the account is deleted, if its owner asked for that
+	CreatedAt           time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt           time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 // UserResponse is the API response format for a user (excludes sensitive data)
 type UserResponse struct {
-	ID        string    `json:"id" example:"507f1f77bcf86cd799439011"`
-	Email     string    `json:"email" example:"user@example.com"`
-	FirstName string    `json:"first_name" example:"John"`
-	LastName  string    `json:"last_name" example:"Doe"`
-	Role      string    `json:"role" example:"user"`
-	Active    bool      `json:"active" example:"true"`
-	CreatedAt time.Time `json:"created_at" example:"2024-01-15T10:30:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"2024-01-15T10:30:00Z"`
+	ID                  string     `json:"id" example:"507f1f77bcf86cd799439011"`
+	Email               string     `json:"email" example:"user@example.com"`
+	FirstName           string     `json:"first_name" example:"John"`
+	LastName            string     `json:"last_name" example:"Doe"`
+	Role                string     `json:"role" example:"user"`
+	Active              bool       `json:"active" example:"true"`
+	EmailVerified       bool       `json:"email_verified" example:"true"`
+	Pending             bool       `json:"pending" example:"false"`
+	LastLoginAt         *time.Time `json:"last_login_at,omitempty" example:"2024-01-15T10:30:00Z"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" example:"2024-02-14T10:30:00Z"`
+	CreatedAt           time.Time  `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt           time.Time  `json:"updated_at" example:"2024-01-15T10:30:00Z"`
 }
 
 // ToResponse converts a User to its API response format
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID.Hex(),
-		Email:     u.Email,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		Role:      u.Role,
-		Active:    u.Active,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:                  u.ID.Hex(),
+		Email:               u.Email,
+		FirstName:           u.FirstName,
+		LastName:            u.LastName,
+		Role:                u.Role,
+		Active:              u.Active,
+		EmailVerified:       u.EmailVerified,
+		Pending:             u.Pending,
+		LastLoginAt:         u.LastLoginAt,
+		DeletionScheduledAt: u.DeletionScheduledAt,
+		CreatedAt:           u.CreatedAt,
+		UpdatedAt:           u.UpdatedAt,
 	}
 }
 
@@ -86,11 +106,6 @@ func (u *User) CheckPassword(password string) bool {
 	return err == nil
 }
 
-// IsValidRole checks if the given role is valid
-func IsValidRole(role string) bool {
-	return role == RoleUser || role == RoleAdmin
-}
-
 // RegisterRequest represents a user registration request
 type RegisterRequest struct {
 	Email     string `json:"email" example:"user@example.com"`
@@ -107,11 +122,18 @@ type LoginRequest struct {
 
 // AuthResponse represents the response after successful authentication
 type AuthResponse struct {
-	AccessToken  string       `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	RefreshToken string       `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	TokenType    string       `json:"token_type" example:"Bearer"`
-	ExpiresIn    int64        `json:"expires_in" example:"3600"`
+	AccessToken  string       `json:"access_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string       `json:"refresh_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType    string       `json:"token_type,omitempty" example:"Bearer"`
+	ExpiresIn    int64        `json:"expires_in,omitempty" example:"3600"`
+	Scope        string       `json:"scope,omitempty" example:"services:read services:write"`
 	User         UserResponse `json:"user"`
+	// MFAEnrollmentRequired is set when the user's role requires multi-factor
+	// authentication that they haven't set up. The tokens then only allow setting it up.
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty" example:"false"`
+	// EmailVerificationRequired is set by registration when logins require a
+	// verified email address. No tokens are issued until the address is verified.
+	EmailVerificationRequired bool `json:"email_verification_required,omitempty" example:"false"`
 }
 
 // RefreshTokenRequest represents a token refresh request
@@ -137,6 +159,12 @@ type UpdateUserRequest struct {
 	Active    *bool  `json:"active" example:"true"`
 }
 
+// DeleteAccountRequest represents a request to delete the current user's account
+type DeleteAccountRequest struct {
+	// Password confirms the request. Users who sign in through single sign-on have none.
+	Password string `json:"password" example:"securepassword123"`
+}
+
 // ChangePasswordRequest represents a password change request
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" example:"oldpassword123"`
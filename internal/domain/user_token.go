@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Purposes of the tokens emailed to users
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// ErrInvalidUserToken is returned for an emailed token that is unknown, used or expired
+var ErrInvalidUserToken = errors.New("token is invalid or has expired")
+
+// UserToken is a single-use token emailed to a user to reset their password
+// or verify their email address. Only its SHA-256 hash is stored.
+type UserToken struct {
+	Hash      string             `bson:"_id"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	Purpose   string             `bson:"purpose"`
+	Email     string             `bson:"email"` // Address the token was sent to
+	CreatedAt time.Time          `bson:"created_at"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+}
+
+// ForgotPasswordRequest requests a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+// ResetPasswordRequest sets a new password with a token from a password reset email
+type ResetPasswordRequest struct {
+	Token    string `json:"token" example:"Zx8rC2kq7VtYw1mP0aLhN3sJ5dF9gB4e6uR8iO2pQ"`
+	Password string `json:"password" example:"newpassword123"`
+}
+
+// VerifyEmailRequest verifies an email address with a token from a verification email
+type VerifyEmailRequest struct {
+	Token string `json:"token" example:"Zx8rC2kq7VtYw1mP0aLhN3sJ5dF9gB4e6uR8iO2pQ"`
+}
+
+// ResendVerificationRequest requests a new verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" example:"user@example.com"`
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/response"
+)
+
+// AccountHandler handles password reset and email verification HTTP requests
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+// NewAccountHandler creates a new AccountHandler
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// ForgotPassword handles POST /api/v1/auth/password/forgot
+// @Summary Request a password reset email
+// @Description Email a single-use link to reset the password of an account. The response is the same whether or not the address has an account.
+// @Tags auth
+// @Accept json
+// @Param request body domain.ForgotPasswordRequest true "Forgot password request"
+// @Success 202 "Reset email sent if the address has an account"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/password/forgot [post]
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req domain.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if req.Email == "" {
+		response.BadRequest(w, domain.ErrEmailRequired.Error())
+		return
+	}
+
+	if err := h.accountService.ForgotPassword(r.Context(), req.Email); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, nil)
+}
+
+// ResetPassword handles POST /api/v1/auth/password/reset
+// @Summary Reset a password
+// @Description Set a new password with the token from a password reset email. Every session of the user is revoked.
+// @Tags auth
+// @Accept json
+// @Param request body domain.ResetPasswordRequest true "Reset password request"
+// @Success 204 "Password reset"
+// @Failure 400 {object} handler.PasswordPolicyErrorResponse "Invalid or expired token, or password policy violations"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/password/reset [post]
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req domain.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.accountService.ResetPassword(r.Context(), req); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// VerifyEmail handles POST /api/v1/auth/email/verify
+// @Summary Verify an email address
+// @Description Mark the email address of a user as verified with the token from a verification email
+// @Tags auth
+// @Accept json
+// @Param request body domain.VerifyEmailRequest true "Verify email request"
+// @Success 204 "Email address verified"
+// @Failure 400 {object} response.ErrorResponse "Invalid or expired token"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/email/verify [post]
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req domain.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(r.Context(), req.Token); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ResendVerification handles POST /api/v1/auth/email/verify/resend
+// @Summary Resend the verification email
+// @Description Email a new verification link to an unverified address. The response is the same whether or not the address has an account.
+// @Tags auth
+// @Accept json
+// @Param request body domain.ResendVerificationRequest true "Resend verification request"
+// @Success 202 "Verification email sent if the address has an unverified account"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/email/verify/resend [post]
+func (h *AccountHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req domain.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if req.Email == "" {
+		response.BadRequest(w, domain.ErrEmailRequired.Error())
+		return
+	}
+
+	if err := h.accountService.ResendVerification(r.Context(), req.Email); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, nil)
+}
+
+func (h *AccountHandler) handleError(w http.ResponseWriter, err error) {
+	if passwordPolicyError(w, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidUserToken):
+		response.BadRequest(w, err.Error())
+	default:
+		response.InternalServerError(w, "internal server error")
+	}
+}
@@ -0,0 +1,351 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAccountHandler() (*handler.AccountHandler, *mocks.MockUserRepository, *mailer.MemoryMailer) {
+	userRepo := mocks.NewMockUserRepository()
+	tokenRepo := mocks.NewMockRefreshTokenRepository()
+	sessions := service.NewSessionService(mocks.NewMockSessionRepository(), tokenRepo, nil)
+	throttle := service.NewLoginThrottleService(mocks.NewMockLoginAttemptRepository(), service.LoginPolicy{}, nil)
+	passwords := service.NewPasswordService(mocks.NewMockPasswordHistoryRepository(), nil, service.PasswordPolicy{
+		MinLength:  12,
+		MinClasses: 3,
+	})
+	m := mailer.NewMemory()
+	accounts := service.NewAccountService(userRepo, mocks.NewMockUserTokenRepository(), sessions, throttle, passwords, m, service.AccountOptions{
+		PasswordResetTTL:     time.Hour,
+		EmailVerificationTTL: time.Hour,
+	}, nil)
+	return handler.NewAccountHandler(accounts), userRepo, m
+}
+
+// addTestAccount adds an active user who signs in with a password
+func addTestAccount(t *testing.T, repo *mocks.MockUserRepository) *domain.User {
+	t.Helper()
+	user := addTestUser(repo, domain.RoleUser)
+	require.NoError(t, user.SetPassword("Old-password-123"))
+	return user
+}
+
+// postJSON makes a POST request to an account route with a JSON body; a
+// string body is sent as is
+func postJSON(path string, body interface{}) *http.Request {
+	var raw []byte
+	if str, ok := body.(string); ok {
+		raw = []byte(str)
+	} else {
+		raw, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestAccountHandler_ForgotPassword(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    func(user *domain.User) interface{}
+		setupUser      func(user *domain.User)
+		expectedStatus int
+		expectedEmail  bool
+		expectedError  string
+	}{
+		{
+			name: "account with a password",
+			requestBody: func(user *domain.User) interface{} {
+				return domain.ForgotPasswordRequest{Email: user.Email}
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedEmail:  true,
+		},
+		{
+			name: "unknown address",
+			requestBody: func(user *domain.User) interface{} {
+				return domain.ForgotPasswordRequest{Email: "unknown@example.com"}
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "single sign-on account",
+			requestBody: func(user *domain.User) interface{} {
+				return domain.ForgotPasswordRequest{Email: user.Email}
+			},
+			setupUser:      func(user *domain.User) { user.PasswordHash = "" },
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "inactive account",
+			requestBody: func(user *domain.User) interface{} {
+				return domain.ForgotPasswordRequest{Email: user.Email}
+			},
+			setupUser:      func(user *domain.User) { user.Active = false },
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "missing email",
+			requestBody: func(user *domain.User) interface{} {
+				return domain.ForgotPasswordRequest{}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  domain.ErrEmailRequired.Error(),
+		},
+		{
+			name: "invalid JSON",
+			requestBody: func(user *domain.User) interface{} {
+				return "invalid json"
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, userRepo, m := setupAccountHandler()
+			user := addTestAccount(t, userRepo)
+			if tt.setupUser != nil {
+				tt.setupUser(user)
+			}
+
+			w := httptest.NewRecorder()
+			h.ForgotPassword(w, postJSON("/api/v1/auth/password/forgot", tt.requestBody(user)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			assert.Equal(t, tt.expectedEmail, len(m.Messages()) > 0)
+		})
+	}
+}
+
+func TestAccountHandler_ResetPassword(t *testing.T) {
+	tests := []struct {
+		name               string
+		requestBody        domain.ResetPasswordRequest
+		expectedStatus     int
+		expectedMessage    string
+		expectedViolations []string
+	}{
+		{
+			name:               "password breaks the policy",
+			requestBody:        domain.ResetPasswordRequest{Token: "token", Password: "password"},
+			expectedStatus:     http.StatusBadRequest,
+			expectedMessage:    "password does not meet the password policy",
+			expectedViolations: []string{domain.PasswordRuleMinLength, domain.PasswordRuleCharacters},
+		},
+		{
+			name:            "unknown token",
+			requestBody:     domain.ResetPasswordRequest{Token: "token", Password: "Long-enough-password"},
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "token is invalid or has expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, _ := setupAccountHandler()
+
+			rec := httptest.NewRecorder()
+			h.ResetPassword(rec, postJSON("/api/v1/auth/password/reset", tt.requestBody))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			var resp handler.PasswordPolicyErrorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, "bad_request", resp.Error)
+			assert.Equal(t, tt.expectedMessage, resp.Message)
+
+			var rules []string
+			for _, v := range resp.Violations {
+				assert.NotEmpty(t, v.Message)
+				rules = append(rules, v.Rule)
+			}
+			assert.Equal(t, tt.expectedViolations, rules)
+		})
+	}
+}
+
+func TestAccountHandler_ResetPasswordWithEmailedToken(t *testing.T) {
+	h, userRepo, m := setupAccountHandler()
+	user := addTestAccount(t, userRepo)
+
+	w := httptest.NewRecorder()
+	h.ForgotPassword(w, postJSON("/api/v1/auth/password/forgot", domain.ForgotPasswordRequest{Email: user.Email}))
+	require.Equal(t, http.StatusAccepted, w.Code)
+	reset := domain.ResetPasswordRequest{Token: emailedToken(t, m, user.Email), Password: "New-password-123"}
+
+	w = httptest.NewRecorder()
+	h.ResetPassword(w, postJSON("/api/v1/auth/password/reset", reset))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.True(t, user.CheckPassword(reset.Password))
+	assert.True(t, user.EmailVerified)
+
+	// Tokens are single-use
+	w = httptest.NewRecorder()
+	h.ResetPassword(w, postJSON("/api/v1/auth/password/reset", reset))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), domain.ErrInvalidUserToken.Error())
+
+	w = httptest.NewRecorder()
+	h.ResetPassword(w, postJSON("/api/v1/auth/password/reset", "invalid json"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid request body")
+}
+
+func TestAccountHandler_VerifyEmail(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    func(t *testing.T, token string) interface{}
+		expectedStatus int
+		expectedError  string
+		expectVerified bool
+	}{
+		{
+			name: "token from the verification email",
+			requestBody: func(t *testing.T, token string) interface{} {
+				return domain.VerifyEmailRequest{Token: token}
+			},
+			expectedStatus: http.StatusNoContent,
+			expectVerified: true,
+		},
+		{
+			name: "unknown token",
+			requestBody: func(t *testing.T, token string) interface{} {
+				return domain.VerifyEmailRequest{Token: "unknown"}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  domain.ErrInvalidUserToken.Error(),
+		},
+		{
+			name: "missing token",
+			requestBody: func(t *testing.T, token string) interface{} {
+				return domain.VerifyEmailRequest{}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  domain.ErrInvalidUserToken.Error(),
+		},
+		{
+			name: "invalid JSON",
+			requestBody: func(t *testing.T, token string) interface{} {
+				return "invalid json"
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, userRepo, m := setupAccountHandler()
+			user := addTestAccount(t, userRepo)
+
+			w := httptest.NewRecorder()
+			h.ResendVerification(w, postJSON("/api/v1/auth/email/verify/resend", domain.ResendVerificationRequest{Email: user.Email}))
+			require.Equal(t, http.StatusAccepted, w.Code)
+			token := emailedToken(t, m, user.Email)
+
+			w = httptest.NewRecorder()
+			h.VerifyEmail(w, postJSON("/api/v1/auth/email/verify", tt.requestBody(t, token)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			assert.Equal(t, tt.expectVerified, user.EmailVerified)
+		})
+	}
+}
+
+func TestAccountHandler_ResendVerification(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    func(user *domain.User) interface{}
+		setupUser      func(user *domain.User)
+		expectedStatus int
+		expectedEmail  bool
+		expectedError  string
+	}{
+		{
+			name: "unverified account",
+			requestBody: func(user *domain.User) interface{} {
+				return domain.ResendVerificationRequest{Email: user.Email}
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedEmail:  true,
+		},
+		{
+			name: "verified account",
+			requestBody: func(user *domain.User) interface{} {
+				return domain.ResendVerificationRequest{Email: user.Email}
+			},
+			setupUser:      func(user *domain.User) { user.EmailVerified = true },
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "invited account",
+			requestBody: func(user *domain.User) interface{} {
+				return domain.ResendVerificationRequest{Email: user.Email}
+			},
+			setupUser:      func(user *domain.User) { user.Pending = true },
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "unknown address",
+			requestBody: func(user *domain.User) interface{} {
+				return domain.ResendVerificationRequest{Email: "unknown@example.com"}
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "missing email",
+			requestBody: func(user *domain.User) interface{} {
+				return domain.ResendVerificationRequest{}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  domain.ErrEmailRequired.Error(),
+		},
+		{
+			name: "invalid JSON",
+			requestBody: func(user *domain.User) interface{} {
+				return "invalid json"
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, userRepo, m := setupAccountHandler()
+			user := addTestAccount(t, userRepo)
+			if tt.setupUser != nil {
+				tt.setupUser(user)
+			}
+
+			w := httptest.NewRecorder()
+			h.ResendVerification(w, postJSON("/api/v1/auth/email/verify/resend", tt.requestBody(user)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			assert.Equal(t, tt.expectedEmail, len(m.Messages()) > 0)
+		})
+	}
+}
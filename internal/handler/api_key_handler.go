@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/response"
+)
+
+// APIKeyHandler handles API key management HTTP requests
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+	authorizer    auth.Authorizer
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService, authorizer auth.Authorizer) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		authorizer:    authorizer,
+	}
+}
+
+// APIKeyListResponse represents the response for listing API keys
+type APIKeyListResponse struct {
+	Data       []domain.APIKeyResponse   `json:"data"`
+	Pagination domain.PaginationMetadata `json:"pagination"`
+}
+
+// Create handles POST /api/v1/api-keys
+// @Summary Create an API key
+// @Description Create an API key owned by the current user. The plaintext key is only returned in this response. The services:admin and users:admin scopes can only be granted by admins.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateAPIKeyRequest true "API key creation request"
+// @Success 201 {object} domain.APIKeyCreatedResponse "Created API key including the plaintext key"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - user token required, scope not allowed or impersonating a user"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys [post]
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	key, plaintext, err := h.apiKeyService.Create(r.Context(), userID, req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.Created(w, domain.APIKeyCreatedResponse{APIKeyResponse: key.ToResponse(), Key: plaintext})
+}
+
+// List handles GET /api/v1/api-keys
+// @Summary List API keys
+// @Description Get a paginated list of the current user's API keys. Admins see the keys of all users and can filter by owner.
+// @Tags api-keys
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Param owner_id query string false "Only list keys of this user (admin only)"
+// @Success 200 {object} APIKeyListResponse "List of API keys with pagination"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - user token required or impersonating a user"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys [get]
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	isAdmin, err := h.isAdmin(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	ownerID := userID
+	if isAdmin {
+		ownerID = r.URL.Query().Get("owner_id")
+	}
+
+	result, err := h.apiKeyService.List(r.Context(), ownerID, ParsePaginationParams(r))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	keyResponses := make([]domain.APIKeyResponse, len(result.Data))
+	for i, k := range result.Data {
+		keyResponses[i] = k.ToResponse()
+	}
+
+	response.OK(w, APIKeyListResponse{
+		Data:       keyResponses,
+		Pagination: result.Pagination,
+	})
+}
+
+// Get handles GET /api/v1/api-keys/{id}
+// @Summary Get an API key
+// @Description Get an API key of the current user. Admins can get any key.
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID (MongoDB ObjectID)"
+// @Success 200 {object} domain.APIKeyResponse "API key details"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - user token required or impersonating a user"
+// @Failure 404 {object} response.ErrorResponse "API key not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys/{id} [get]
+func (h *APIKeyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	key, ok := h.accessibleKey(w, r)
+	if !ok {
+		return
+	}
+
+	response.OK(w, key.ToResponse())
+}
+
+// Update handles PATCH /api/v1/api-keys/{id}
+// @Summary Update an API key
+// @Description Rename an API key or replace its scopes. Only provided fields are updated.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID (MongoDB ObjectID)"
+// @Param request body domain.UpdateAPIKeyRequest true "API key update request"
+// @Success 200 {object} domain.APIKeyResponse "Updated API key"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - user token required, scope not allowed or impersonating a user"
+// @Failure 404 {object} response.ErrorResponse "API key not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys/{id} [patch]
+func (h *APIKeyHandler) Update(w http.ResponseWriter, r *http.Request) {
+	key, ok := h.accessibleKey(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	updated, err := h.apiKeyService.Update(r.Context(), key.ID.Hex(), req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, updated.ToResponse())
+}
+
+// Delete handles DELETE /api/v1/api-keys/{id}
+// @Summary Revoke an API key
+// @Description Revoke an API key by deleting it. The key stops working immediately.
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID (MongoDB ObjectID)"
+// @Success 204 "API key revoked"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - user token required or impersonating a user"
+// @Failure 404 {object} response.ErrorResponse "API key not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	key, ok := h.accessibleKey(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.Delete(r.Context(), key.ID.Hex()); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Rotate handles POST /api/v1/api-keys/{id}/rotate
+// @Summary Rotate an API key
+// @Description Issue a replacement key with the same name, owner, scopes and lifetime. The old key keeps working for the overlap period (server default when omitted, 0 revokes it immediately).
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID (MongoDB ObjectID)"
+// @Param request body domain.RotateAPIKeyRequest false "Rotation options"
+// @Success 201 {object} domain.APIKeyCreatedResponse "Replacement API key including the plaintext key"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - user token required or impersonating a user"
+// @Failure 404 {object} response.ErrorResponse "API key not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	key, ok := h.accessibleKey(w, r)
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req domain.RotateAPIKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "invalid request body")
+			return
+		}
+	}
+
+	replacement, plaintext, err := h.apiKeyService.Rotate(r.Context(), key.ID.Hex(), req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.Created(w, domain.APIKeyCreatedResponse{APIKeyResponse: replacement.ToResponse(), Key: plaintext})
+}
+
+// currentUser returns the ID of the user making the request. API keys are
+// managed by users only, so requests authenticated with an API key are rejected.
+func (h *APIKeyHandler) currentUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Forbidden(w, "api keys can only be managed with a user token")
+		return "", false
+	}
+	return userID, true
+}
+
+// accessibleKey loads the key named in the URL if the current user may manage
+// it. Keys of other users are reported as not found unless the user is an admin.
+func (h *APIKeyHandler) accessibleKey(w http.ResponseWriter, r *http.Request) (*domain.APIKey, bool) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	key, err := h.apiKeyService.GetByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleError(w, err)
+		return nil, false
+	}
+
+	if key.OwnerID.Hex() != userID {
+		isAdmin, err := h.isAdmin(r)
+		if err != nil {
+			h.handleError(w, err)
+			return nil, false
+		}
+		if !isAdmin {
+			response.NotFound(w, "api key not found")
+			return nil, false
+		}
+	}
+
+	return key, true
+}
+
+// isAdmin checks if the current user holds the users:admin permission
+func (h *APIKeyHandler) isAdmin(r *http.Request) (bool, error) {
+	err := h.authorizer.Authorize(r.Context(), auth.ScopeUsersAdmin)
+	var permissionErr *auth.PermissionError
+	if errors.As(err, &permissionErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// handleError handles errors from the API key service
+func (h *APIKeyHandler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		response.NotFound(w, "api key not found")
+	case errors.Is(err, domain.ErrUserNotFound):
+		response.NotFound(w, "user not found")
+	case errors.Is(err, domain.ErrInvalidID):
+		response.BadRequest(w, "invalid id format")
+	case errors.Is(err, domain.ErrScopeNotAllowed):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, domain.ErrAPIKeyNameRequired),
+		errors.Is(err, domain.ErrAPIKeyNameTooLong),
+		errors.Is(err, domain.ErrScopesRequired),
+		errors.Is(err, domain.ErrInvalidScope),
+		errors.Is(err, domain.ErrInvalidExpiry),
+		errors.Is(err, domain.ErrInvalidOverlap):
+		response.BadRequest(w, err.Error())
+	default:
+		response.InternalServerError(w, "internal server error")
+	}
+}
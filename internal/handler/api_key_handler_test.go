@@ -0,0 +1,598 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// nonAdmin grants every permission but users:admin
+var nonAdmin = testAuthorizer{deny: []string{auth.ScopeUsersAdmin}}
+
+// failingAuthorizer can't look up the caller's permissions
+type failingAuthorizer struct{}
+
+func (failingAuthorizer) Authorize(ctx context.Context, permissions ...string) error {
+	return errors.New("role lookup failed")
+}
+
+func setupAPIKeyHandler(authorizer auth.Authorizer) (*handler.APIKeyHandler, *mocks.MockAPIKeyRepository, *mocks.MockUserRepository) {
+	apiKeyRepo := mocks.NewMockAPIKeyRepository()
+	userRepo := mocks.NewMockUserRepository()
+	roles := service.NewRoleService(mocks.NewMockRoleRepository(), userRepo, nil)
+	if err := roles.EnsureBuiltInRoles(context.Background()); err != nil {
+		panic(err)
+	}
+	svc := service.NewAPIKeyService(apiKeyRepo, userRepo, roles, time.Hour, nil)
+	return handler.NewAPIKeyHandler(svc, authorizer), apiKeyRepo, userRepo
+}
+
+// addTestAPIKey stores an API key of the owner
+func addTestAPIKey(repo *mocks.MockAPIKeyRepository, ownerID primitive.ObjectID) *domain.APIKey {
+	key := &domain.APIKey{
+		Name:       "ci-deployments",
+		OwnerID:    ownerID,
+		Scopes:     []string{auth.ScopeServicesRead},
+		Prefix:     "sk_" + primitive.NewObjectID().Hex()[12:],
+		SecretHash: "hash",
+	}
+	if err := repo.Create(context.Background(), key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func TestAPIKeyHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		caller         func(owner *domain.User) string
+		tokenScopes    []string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful creation",
+			requestBody: map[string]interface{}{
+				"name":   "ci-deployments",
+				"scopes": []string{auth.ScopeServicesRead},
+			},
+			expectedStatus: http.StatusCreated,
+			expectedError:  `"key":"sk_`,
+		},
+		{
+			name: "missing name",
+			requestBody: map[string]interface{}{
+				"scopes": []string{auth.ScopeServicesRead},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "name is required",
+		},
+		{
+			name: "missing scopes",
+			requestBody: map[string]interface{}{
+				"name": "ci-deployments",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "at least one scope is required",
+		},
+		{
+			name: "unknown scope",
+			requestBody: map[string]interface{}{
+				"name":   "ci-deployments",
+				"scopes": []string{"services:delete"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid scope",
+		},
+		{
+			name: "scope beyond the owner's role",
+			requestBody: map[string]interface{}{
+				"name":   "ci-deployments",
+				"scopes": []string{auth.ScopeUsersAdmin},
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "scope not allowed",
+		},
+		{
+			name: "scope beyond the caller's token",
+			requestBody: map[string]interface{}{
+				"name":   "ci-deployments",
+				"scopes": []string{auth.ScopeServicesWrite},
+			},
+			tokenScopes:    []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "scope not allowed",
+		},
+		{
+			name: "invalid expiry",
+			requestBody: map[string]interface{}{
+				"name":            "ci-deployments",
+				"scopes":          []string{auth.ScopeServicesRead},
+				"expires_in_days": 0,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "expires_in_days",
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+		{
+			name: "unknown owner",
+			requestBody: map[string]interface{}{
+				"name":   "ci-deployments",
+				"scopes": []string{auth.ScopeServicesRead},
+			},
+			caller: func(owner *domain.User) string {
+				return primitive.NewObjectID().Hex()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "user not found",
+		},
+		{
+			name: "API key instead of a user token",
+			requestBody: map[string]interface{}{
+				"name":   "ci-deployments",
+				"scopes": []string{auth.ScopeServicesRead},
+			},
+			caller: func(owner *domain.User) string {
+				return ""
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "api keys can only be managed with a user token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, userRepo := setupAPIKeyHandler(nonAdmin)
+			owner := addTestUser(userRepo, domain.RoleUser)
+			callerID := owner.ID.Hex()
+			if tt.caller != nil {
+				callerID = tt.caller(owner)
+			}
+
+			var body []byte
+			if str, ok := tt.requestBody.(string); ok {
+				body = []byte(str)
+			} else {
+				body, _ = json.Marshal(tt.requestBody)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if callerID != "" {
+				req = asUser(req, callerID, tt.tokenScopes...)
+			}
+			w := httptest.NewRecorder()
+
+			h.Create(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandler_Get(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupRepo      func(repo *mocks.MockAPIKeyRepository, caller, other *domain.User) string
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "own key",
+			setupRepo: func(repo *mocks.MockAPIKeyRepository, caller, other *domain.User) string {
+				return addTestAPIKey(repo, caller.ID).ID.Hex()
+			},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "key of another user",
+			setupRepo: func(repo *mocks.MockAPIKeyRepository, caller, other *domain.User) string {
+				return addTestAPIKey(repo, other.ID).ID.Hex()
+			},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "api key not found",
+		},
+		{
+			name: "key of another user as admin",
+			setupRepo: func(repo *mocks.MockAPIKeyRepository, caller, other *domain.User) string {
+				return addTestAPIKey(repo, other.ID).ID.Hex()
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "not found",
+			setupRepo: func(repo *mocks.MockAPIKeyRepository, caller, other *domain.User) string {
+				return primitive.NewObjectID().Hex()
+			},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "api key not found",
+		},
+		{
+			name: "invalid id",
+			setupRepo: func(repo *mocks.MockAPIKeyRepository, caller, other *domain.User) string {
+				repo.GetByIDFunc = func(ctx context.Context, id string) (*domain.APIKey, error) {
+					return nil, domain.ErrInvalidID
+				}
+				return "not-an-id"
+			},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid id format",
+		},
+		{
+			name: "repository failure",
+			setupRepo: func(repo *mocks.MockAPIKeyRepository, caller, other *domain.User) string {
+				repo.GetByIDFunc = func(ctx context.Context, id string) (*domain.APIKey, error) {
+					return nil, errors.New("connection reset")
+				}
+				return primitive.NewObjectID().Hex()
+			},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "internal server error",
+		},
+		{
+			name: "authorizer failure",
+			setupRepo: func(repo *mocks.MockAPIKeyRepository, caller, other *domain.User) string {
+				return addTestAPIKey(repo, other.ID).ID.Hex()
+			},
+			authorizer:     failingAuthorizer{},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, apiKeyRepo, userRepo := setupAPIKeyHandler(tt.authorizer)
+			caller := addTestUser(userRepo, domain.RoleUser)
+			other := addTestUser(userRepo, domain.RoleUser)
+			id := tt.setupRepo(apiKeyRepo, caller, other)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/api-keys/"+id, nil)
+			req = withURLParams(asUser(req, caller.ID.Hex()), "id", id)
+			w := httptest.NewRecorder()
+
+			h.Get(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandler_List(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorizer    auth.Authorizer
+		filterByOther bool
+		expectedCount int
+	}{
+		{
+			name:          "own keys only",
+			authorizer:    nonAdmin,
+			expectedCount: 1,
+		},
+		{
+			name:          "owner filter is ignored for non-admins",
+			authorizer:    nonAdmin,
+			filterByOther: true,
+			expectedCount: 1,
+		},
+		{
+			name:          "admin sees every key",
+			authorizer:    testAuthorizer{},
+			expectedCount: 3,
+		},
+		{
+			name:          "admin filters by owner",
+			authorizer:    testAuthorizer{},
+			filterByOther: true,
+			expectedCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, apiKeyRepo, userRepo := setupAPIKeyHandler(tt.authorizer)
+			caller := addTestUser(userRepo, domain.RoleUser)
+			other := addTestUser(userRepo, domain.RoleUser)
+			addTestAPIKey(apiKeyRepo, caller.ID)
+			addTestAPIKey(apiKeyRepo, other.ID)
+			addTestAPIKey(apiKeyRepo, other.ID)
+
+			target := "/api/v1/api-keys"
+			if tt.filterByOther {
+				target += "?owner_id=" + other.ID.Hex()
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req = asUser(req, caller.ID.Hex())
+			w := httptest.NewRecorder()
+
+			h.List(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var resp handler.APIKeyListResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Len(t, resp.Data, tt.expectedCount)
+		})
+	}
+}
+
+func TestAPIKeyHandler_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		ownKey         bool
+		requestBody    interface{}
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "rename own key",
+			ownKey:         true,
+			requestBody:    map[string]interface{}{"name": "renamed"},
+			expectedStatus: http.StatusOK,
+			expectedError:  `"name":"renamed"`,
+		},
+		{
+			name:           "key of another user",
+			requestBody:    map[string]interface{}{"name": "renamed"},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "api key not found",
+		},
+		{
+			name:           "scope beyond the owner's role",
+			ownKey:         true,
+			requestBody:    map[string]interface{}{"scopes": []string{auth.ScopeServicesAdmin}},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "scope not allowed",
+		},
+		{
+			name:           "empty name",
+			ownKey:         true,
+			requestBody:    map[string]interface{}{"name": " "},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "name is required",
+		},
+		{
+			name:           "invalid JSON",
+			ownKey:         true,
+			requestBody:    "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, apiKeyRepo, userRepo := setupAPIKeyHandler(nonAdmin)
+			caller := addTestUser(userRepo, domain.RoleUser)
+			owner := addTestUser(userRepo, domain.RoleUser)
+			if tt.ownKey {
+				owner = caller
+			}
+			id := addTestAPIKey(apiKeyRepo, owner.ID).ID.Hex()
+
+			var body []byte
+			if str, ok := tt.requestBody.(string); ok {
+				body = []byte(str)
+			} else {
+				body, _ = json.Marshal(tt.requestBody)
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/api-keys/"+id, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = withURLParams(asUser(req, caller.ID.Hex()), "id", id)
+			w := httptest.NewRecorder()
+
+			h.Update(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name           string
+		ownKey         bool
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectDeleted  bool
+	}{
+		{
+			name:           "own key",
+			ownKey:         true,
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusNoContent,
+			expectDeleted:  true,
+		},
+		{
+			name:           "key of another user",
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "key of another user as admin",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNoContent,
+			expectDeleted:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, apiKeyRepo, userRepo := setupAPIKeyHandler(tt.authorizer)
+			caller := addTestUser(userRepo, domain.RoleUser)
+			owner := addTestUser(userRepo, domain.RoleUser)
+			if tt.ownKey {
+				owner = caller
+			}
+			id := addTestAPIKey(apiKeyRepo, owner.ID).ID.Hex()
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/api-keys/"+id, nil)
+			req = withURLParams(asUser(req, caller.ID.Hex()), "id", id)
+			w := httptest.NewRecorder()
+
+			h.Delete(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			_, err := apiKeyRepo.GetByID(context.Background(), id)
+			if tt.expectDeleted {
+				assert.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandler_Rotate(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "without a body",
+			expectedStatus: http.StatusCreated,
+			expectedError:  `"key":"sk_`,
+		},
+		{
+			name:           "with an overlap",
+			requestBody:    `{"overlap_hours": 0}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "overlap too long",
+			requestBody:    `{"overlap_hours": 721}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "overlap_hours",
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, apiKeyRepo, userRepo := setupAPIKeyHandler(nonAdmin)
+			caller := addTestUser(userRepo, domain.RoleUser)
+			id := addTestAPIKey(apiKeyRepo, caller.ID).ID.Hex()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys/"+id+"/rotate", bytes.NewReader([]byte(tt.requestBody)))
+			req = withURLParams(asUser(req, caller.ID.Hex()), "id", id)
+			w := httptest.NewRecorder()
+
+			h.Rotate(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandler_RouteGuards(t *testing.T) {
+	h, apiKeyRepo, userRepo := setupAPIKeyHandler(nonAdmin)
+	caller := addTestUser(userRepo, domain.RoleUser)
+	id := addTestAPIKey(apiKeyRepo, caller.ID).ID.Hex()
+	router := newTestRouter(routerHandlers{apiKey: h})
+
+	runRouteGuardTests(t, router, caller.ID.Hex(), []routeGuardTest{
+		{
+			name:           "list keys",
+			method:         http.MethodGet,
+			path:           "/api/v1/api-keys",
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "get own key",
+			method:         http.MethodGet,
+			path:           "/api/v1/api-keys/" + id,
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "create a key with a token only allowed to set up MFA",
+			method:         http.MethodPost,
+			path:           "/api/v1/api-keys",
+			scopes:         []string{auth.ScopeMFAEnrollment},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope",
+		},
+		{
+			name:           "rotate a key with a token only allowed to set up MFA",
+			method:         http.MethodPost,
+			path:           "/api/v1/api-keys/" + id + "/rotate",
+			scopes:         []string{auth.ScopeMFAEnrollment},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope",
+		},
+		{
+			name:           "list keys while impersonating",
+			method:         http.MethodGet,
+			path:           "/api/v1/api-keys",
+			scopes:         auth.AllScopes,
+			impersonating:  true,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "not allowed while impersonating",
+		},
+		{
+			name:           "create a key while impersonating",
+			method:         http.MethodPost,
+			path:           "/api/v1/api-keys",
+			scopes:         auth.AllScopes,
+			impersonating:  true,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "not allowed while impersonating",
+		},
+		{
+			name:           "rotate a key while impersonating",
+			method:         http.MethodPost,
+			path:           "/api/v1/api-keys/" + id + "/rotate",
+			scopes:         auth.AllScopes,
+			impersonating:  true,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "not allowed while impersonating",
+		},
+	})
+}
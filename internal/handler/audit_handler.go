@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/logging"
+	"github.com/services-api/pkg/response"
+)
+
+// AuditHandler handles audit log HTTP requests
+type AuditHandler struct {
+	auditService *service.AuditService
+	authorizer   auth.Authorizer
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditService *service.AuditService, authorizer auth.Authorizer) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		authorizer:   authorizer,
+	}
+}
+
+// AuditRequest attributes the audit events recorded while handling a request
+// to its request ID and client. It must run after RequestID and RealIP.
+func AuditRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := service.WithAuditRequest(r.Context(), middleware.GetReqID(r.Context()), ParseClientInfo(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// List handles GET /api/v1/audit
+// @Summary List audit events
+// @Description List security-relevant actions, newest first. Pass the next_cursor of a page as cursor to get the next one; the last page has no next_cursor.
+// @Tags audit
+// @Produce json
+// @Param actor_id query string false "Filter by the user who acted"
+// @Param action query string false "Filter by action, e.g. auth.login_failed or user.role_change"
+// @Param target_type query string false "Filter by target type" Enums(user, service)
+// @Param target_id query string false "Filter by target ID"
+// @Param since query string false "Only events at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param until query string false "Only events before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Events per page (max 200)" default(50)
+// @Success 200 {object} domain.AuditPage "Audit events"
+// @Failure 400 {object} response.ErrorResponse "Invalid filter or cursor"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /audit [get]
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	query, err := ParseAuditQuery(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	page, err := h.auditService.List(r.Context(), query)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, page)
+}
+
+// Export handles GET /api/v1/audit/export
+// @Summary Export audit events
+// @Description Download every audit event matching the filters as newline-delimited JSON, newest first
+// @Tags audit
+// @Produce json
+// @Param actor_id query string false "Filter by the user who acted"
+// @Param action query string false "Filter by action, e.g. auth.login_failed or user.role_change"
+// @Param target_type query string false "Filter by target type" Enums(user, service)
+// @Param target_id query string false "Filter by target ID"
+// @Param since query string false "Only events at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param until query string false "Only events before this time (RFC 3339 or YYYY-MM-DD)"
+// @Success 200 {object} domain.AuditEventResponse "One audit event per line (application/x-ndjson)"
+// @Failure 400 {object} response.ErrorResponse "Invalid filter"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /audit/export [get]
+func (h *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	query, err := ParseAuditQuery(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	query.After = nil
+
+	// Headers are only sent with the first event, so that a failure to read
+	// the first page can still be reported as an error
+	started := false
+	encoder := json.NewEncoder(w)
+	err = h.auditService.Export(r.Context(), query, func(event *domain.AuditEvent) error {
+		if !started {
+			h.startExport(w)
+			started = true
+		}
+		return encoder.Encode(event.ToResponse())
+	})
+	if err != nil {
+		if !started {
+			h.handleError(w, err)
+			return
+		}
+		logging.FromContext(r.Context()).Error("Audit export failed", "error", err)
+		return
+	}
+
+	if !started {
+		h.startExport(w)
+	}
+}
+
+// Verify handles GET /api/v1/audit/verify
+// @Summary Verify the audit log
+// @Description Walk the hash chain of the audit log and report the oldest broken link, if any. Each event stores a SHA-256 hash over its content and the hash of the previous event, so an event edited or removed in the database breaks the chain. Events recorded before hashing was introduced are counted as unhashed.
+// @Tags audit
+// @Produce json
+// @Success 200 {object} domain.ChainVerification "Verification result"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /audit/verify [get]
+func (h *AuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	result, err := h.auditService.Verify(r.Context())
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// startExport writes the headers of an NDJSON export
+func (h *AuditHandler) startExport(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleError maps domain errors to HTTP responses
+func (h *AuditHandler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidAuditCursor), errors.Is(err, domain.ErrInvalidAuditTime):
+		response.BadRequest(w, err.Error())
+	default:
+		response.InternalServerError(w, "internal server error")
+	}
+}
@@ -0,0 +1,318 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// setupAuditHandler returns an audit handler over a log of three events: two
+// logins and a role change
+func setupAuditHandler(authorizer auth.Authorizer) (*handler.AuditHandler, *mocks.MockAuditRepository) {
+	auditRepo := mocks.NewMockAuditRepository()
+	audit := service.NewAuditService(auditRepo)
+
+	userID := primitive.NewObjectID().Hex()
+	for _, action := range []string{domain.AuditActionLogin, domain.AuditActionUserRoleChange, domain.AuditActionLogin} {
+		audit.Record(context.Background(), domain.AuditEvent{
+			Action:     action,
+			ActorID:    userID,
+			TargetType: domain.AuditTargetUser,
+			TargetID:   userID,
+		})
+	}
+
+	return handler.NewAuditHandler(audit, authorizer), auditRepo
+}
+
+func TestAuditHandler_List(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		authorizer     auth.Authorizer
+		setupRepo      func(repo *mocks.MockAuditRepository)
+		expectedStatus int
+		expectedEvents int
+		expectedCursor bool
+		expectedError  string
+	}{
+		{
+			name:           "all events",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+			expectedEvents: 3,
+		},
+		{
+			name:           "filter by action",
+			query:          "?action=" + domain.AuditActionUserRoleChange,
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+			expectedEvents: 1,
+		},
+		{
+			name:           "page with a next cursor",
+			query:          "?limit=2",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+			expectedEvents: 2,
+			expectedCursor: true,
+		},
+		{
+			name:           "invalid time",
+			query:          "?since=yesterday",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  domain.ErrInvalidAuditTime.Error(),
+		},
+		{
+			name:           "invalid cursor",
+			query:          "?cursor=invalid",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid cursor",
+		},
+		{
+			name:       "repository failure",
+			authorizer: testAuthorizer{},
+			setupRepo: func(repo *mocks.MockAuditRepository) {
+				repo.ListFunc = func(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEvent, error) {
+					return nil, errors.New("connection reset")
+				}
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "internal server error",
+		},
+		{
+			name:           "missing permission",
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, auditRepo := setupAuditHandler(tt.authorizer)
+			if tt.setupRepo != nil {
+				tt.setupRepo(auditRepo)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/audit"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			h.List(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var page domain.AuditPage
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+				assert.Len(t, page.Data, tt.expectedEvents)
+				assert.Equal(t, tt.expectedCursor, page.NextCursor != "")
+			}
+		})
+	}
+}
+
+func TestAuditHandler_Export(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		authorizer     auth.Authorizer
+		setupRepo      func(repo *mocks.MockAuditRepository)
+		expectedStatus int
+		expectedEvents int
+		expectedError  string
+	}{
+		{
+			name:           "all events",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+			expectedEvents: 3,
+		},
+		{
+			name:           "filter by action",
+			query:          "?action=" + domain.AuditActionLogin,
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+			expectedEvents: 2,
+		},
+		{
+			name:           "invalid cursor",
+			query:          "?cursor=invalid",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid cursor",
+		},
+		{
+			name:           "no matching events",
+			query:          "?action=" + domain.AuditActionServiceDelete,
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid time",
+			query:          "?until=tomorrow",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  domain.ErrInvalidAuditTime.Error(),
+		},
+		{
+			name:       "repository failure",
+			authorizer: testAuthorizer{},
+			setupRepo: func(repo *mocks.MockAuditRepository) {
+				repo.ListFunc = func(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEvent, error) {
+					return nil, errors.New("connection reset")
+				}
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "internal server error",
+		},
+		{
+			name:           "missing permission",
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, auditRepo := setupAuditHandler(tt.authorizer)
+			if tt.setupRepo != nil {
+				tt.setupRepo(auditRepo)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/audit/export"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			h.Export(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+				events := 0
+				scanner := bufio.NewScanner(strings.NewReader(w.Body.String()))
+				for scanner.Scan() {
+					var event domain.AuditEventResponse
+					require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+					events++
+				}
+				assert.Equal(t, tt.expectedEvents, events)
+			}
+		})
+	}
+}
+
+func TestAuditHandler_Verify(t *testing.T) {
+	tests := []struct {
+		name           string
+		authorizer     auth.Authorizer
+		setupRepo      func(repo *mocks.MockAuditRepository)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "intact log",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+			expectedError:  `"valid":true`,
+		},
+		{
+			name:       "edited event",
+			authorizer: testAuthorizer{},
+			setupRepo: func(repo *mocks.MockAuditRepository) {
+				repo.UpdateEvent(repo.Events()[1].ID, func(event *domain.AuditEvent) {
+					event.Action = domain.AuditActionLogin
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedError:  domain.ChainBreakHashMismatch,
+		},
+		{
+			name:       "repository failure",
+			authorizer: testAuthorizer{},
+			setupRepo: func(repo *mocks.MockAuditRepository) {
+				repo.ListFunc = func(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEvent, error) {
+					return nil, errors.New("connection reset")
+				}
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "internal server error",
+		},
+		{
+			name:           "missing permission",
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, auditRepo := setupAuditHandler(tt.authorizer)
+			if tt.setupRepo != nil {
+				tt.setupRepo(auditRepo)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/audit/verify", nil)
+			w := httptest.NewRecorder()
+
+			h.Verify(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestAuditHandler_RouteGuards(t *testing.T) {
+	h, _ := setupAuditHandler(testAuthorizer{})
+	router := newTestRouter(routerHandlers{audit: h})
+	caller := primitive.NewObjectID().Hex()
+
+	var tests []routeGuardTest
+	for _, path := range []string{"/api/v1/audit", "/api/v1/audit/export", "/api/v1/audit/verify"} {
+		tests = append(tests,
+			routeGuardTest{
+				name:           path + " without users:admin",
+				method:         http.MethodGet,
+				path:           path,
+				scopes:         []string{auth.ScopeUsersRead},
+				expectedStatus: http.StatusForbidden,
+				expectedError:  "missing required scope: " + auth.ScopeUsersAdmin,
+			},
+			routeGuardTest{
+				name:           path,
+				method:         http.MethodGet,
+				path:           path,
+				scopes:         []string{auth.ScopeUsersAdmin},
+				expectedStatus: http.StatusOK,
+			},
+		)
+	}
+
+	runRouteGuardTests(t, router, caller, tests)
+}
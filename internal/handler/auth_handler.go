@@ -7,6 +7,7 @@ import (
 
 	"github.com/services-api/internal/domain"
 	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
 	"github.com/services-api/pkg/response"
 )
 
@@ -29,8 +30,8 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 // @Accept json
 // @Produce json
 // @Param request body domain.RegisterRequest true "Registration request"
-// @Success 201 {object} domain.AuthResponse "Successfully registered"
-// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Success 201 {object} domain.AuthResponse "Successfully registered. Without tokens when the email address must be verified first."
+// @Failure 400 {object} handler.PasswordPolicyErrorResponse "Validation error, or password policy violations"
 // @Failure 409 {object} response.ErrorResponse "Email already exists"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /auth/register [post]
@@ -41,7 +42,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	authResp, err := h.authService.Register(r.Context(), req)
+	authResp, err := h.authService.Register(r.Context(), req, ParseClientInfo(r))
 	if err != nil {
 		h.handleError(w, err)
 		return
@@ -52,14 +53,16 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 // Login handles POST /api/v1/auth/login
 // @Summary Login to the application
-// @Description Authenticate with email and password to get access tokens
+// @Description Authenticate with email and password to get access tokens. Users with multi-factor authentication get an MFA challenge (202) to complete with POST /auth/mfa/verify instead.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body domain.LoginRequest true "Login request"
 // @Success 200 {object} domain.AuthResponse "Successfully authenticated"
+// @Success 202 {object} domain.MFAChallenge "Second factor required"
 // @Failure 400 {object} response.ErrorResponse "Validation error"
 // @Failure 401 {object} response.ErrorResponse "Invalid credentials"
+// @Failure 403 {object} response.ErrorResponse "Email address not verified"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -69,7 +72,44 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	authResp, err := h.authService.Login(r.Context(), req)
+	authResp, challenge, err := h.authService.Login(r.Context(), req, ParseClientInfo(r))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	if challenge != nil {
+		response.JSON(w, http.StatusAccepted, challenge)
+		return
+	}
+
+	response.OK(w, authResp)
+}
+
+// VerifyMFA handles POST /api/v1/auth/mfa/verify
+// @Summary Complete a login with a second factor
+// @Description Exchange the MFA challenge returned by login and a code from the authenticator app, or a recovery code, for access tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body domain.MFAVerifyRequest true "MFA verification request"
+// @Success 200 {object} domain.AuthResponse "Successfully authenticated"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Invalid or expired challenge, or invalid code"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req domain.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if req.MFAToken == "" {
+		response.BadRequest(w, "mfa_token is required")
+		return
+	}
+
+	authResp, err := h.authService.VerifyMFA(r.Context(), req, ParseClientInfo(r))
 	if err != nil {
 		h.handleError(w, err)
 		return
@@ -102,7 +142,7 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	authResp, err := h.authService.RefreshToken(r.Context(), req.RefreshToken)
+	authResp, err := h.authService.RefreshToken(r.Context(), req.RefreshToken, ParseClientInfo(r))
 	if err != nil {
 		h.handleError(w, err)
 		return
@@ -111,23 +151,92 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, authResp)
 }
 
+// Logout handles POST /api/v1/auth/logout
+// @Summary Logout
+// @Description Revoke the refresh token and every token rotated from the same login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body domain.LogoutRequest true "Logout request"
+// @Success 204 "Successfully logged out"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Invalid or expired refresh token"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req domain.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if req.RefreshToken == "" {
+		response.BadRequest(w, "refresh_token is required")
+		return
+	}
+
+	if err := h.authService.Logout(r.Context(), req.RefreshToken); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// LogoutAll handles POST /api/v1/auth/logout-all
+// @Summary Logout from all sessions
+// @Description Revoke every refresh token issued to the authenticated user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 204 "Successfully logged out of all sessions"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Impersonating a user"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "authentication required")
+		return
+	}
+
+	if err := h.authService.LogoutAll(r.Context(), userID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
 // handleError handles errors from the auth service
 func (h *AuthHandler) handleError(w http.ResponseWriter, err error) {
+	if passwordPolicyError(w, err) {
+		return
+	}
+
 	switch {
 	case errors.Is(err, domain.ErrEmailRequired),
 		errors.Is(err, domain.ErrEmailInvalid),
 		errors.Is(err, domain.ErrEmailTooLong),
 		errors.Is(err, domain.ErrPasswordRequired),
-		errors.Is(err, domain.ErrPasswordTooShort),
-		errors.Is(err, domain.ErrPasswordTooLong),
 		errors.Is(err, domain.ErrFirstNameRequired),
 		errors.Is(err, domain.ErrFirstNameTooLong),
-		errors.Is(err, domain.ErrLastNameTooLong):
+		errors.Is(err, domain.ErrLastNameTooLong),
+		errors.Is(err, domain.ErrMFACodeRequired):
 		response.BadRequest(w, err.Error())
 	case errors.Is(err, domain.ErrEmailAlreadyExists):
 		response.Conflict(w, err.Error())
 	case errors.Is(err, domain.ErrInvalidCredentials):
 		response.Unauthorized(w, "invalid credentials")
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		response.Unauthorized(w, err.Error())
+	case errors.Is(err, domain.ErrEmailNotVerified):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, domain.ErrRefreshTokenReused):
+		response.Unauthorized(w, err.Error())
+	case errors.Is(err, domain.ErrInvalidID):
+		response.Unauthorized(w, "invalid credentials")
 	default:
 		response.InternalServerError(w, "internal server error")
 	}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/response"
+)
+
+// serviceValidators uses the revision as ETag since every change increments it
+func serviceValidators(svc *domain.Service) response.Validators {
+	return response.Validators{
+		ETag:         response.ETag(strconv.Itoa(svc.Revision)),
+		LastModified: svc.UpdatedAt,
+		CacheControl: response.CacheRevalidate,
+	}
+}
+
+// serviceListValidators hashes the IDs and revisions on the page together with
+// the total, so any create, update or delete that affects the page changes the
+// ETag. Collections have no Last-Modified: a delete leaves the newest
+// updated_at on the page unchanged, so If-Modified-Since would miss it.
+func serviceListValidators(result *domain.PaginatedResult[domain.Service]) response.Validators {
+	parts := make([]string, 0, len(result.Data)+1)
+	parts = append(parts, strconv.FormatInt(result.Pagination.Total, 10))
+	for _, svc := range result.Data {
+		parts = append(parts, svc.ID.Hex()+":"+strconv.Itoa(svc.Revision))
+	}
+	return response.Validators{ETag: response.HashETag(parts...), CacheControl: response.CacheRevalidate}
+}
+
+// versionValidators hashes the version as it is returned. A version's revision
+// never changes, but anonymizing its author rewrites author_id and author_hash,
+// so neither the revision nor created_at can validate it and clients must
+// revalidate instead of caching it as immutable.
+func versionValidators(version *domain.ServiceVersion) response.Validators {
+	return response.Validators{ETag: response.HashETag(versionDigest(version)), CacheControl: response.CacheRevalidate}
+}
+
+// versionListValidators hashes each version on the page as it is returned
+// together with the total. Like service lists, it has no Last-Modified.
+func versionListValidators(result *domain.PaginatedResult[domain.ServiceVersion]) response.Validators {
+	parts := make([]string, 0, len(result.Data)+1)
+	parts = append(parts, strconv.FormatInt(result.Pagination.Total, 10))
+	for i := range result.Data {
+		parts = append(parts, versionDigest(&result.Data[i]))
+	}
+	return response.Validators{ETag: response.HashETag(parts...), CacheControl: response.CacheRevalidate}
+}
+
+// versionDigest returns the JSON response body of a version
+func versionDigest(version *domain.ServiceVersion) string {
+	body, _ := json.Marshal(version.ToResponse())
+	return string(body)
+}
@@ -6,30 +6,39 @@ import (
 	"time"
 
 	"github.com/services-api/pkg/response"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// HealthChecker reports the status of a storage backend
+type HealthChecker interface {
+	// Backend returns the name of the storage backend
+	Backend() string
+	// CheckHealth verifies the backend is reachable and returns backend-specific details
+	CheckHealth(ctx context.Context) (map[string]string, error)
+}
+
 // HealthHandler handles health check requests
 type HealthHandler struct {
-	mongoClient *mongo.Client
+	checker HealthChecker
 }
 
 // NewHealthHandler creates a new HealthHandler
-func NewHealthHandler(client *mongo.Client) *HealthHandler {
+func NewHealthHandler(checker HealthChecker) *HealthHandler {
 	return &HealthHandler{
-		mongoClient: client,
+		checker: checker,
 	}
 }
 
 // HealthResponse represents the health check response
 type HealthResponse struct {
-	Status   string `json:"status" example:"healthy"`
-	Database string `json:"database" example:"connected"`
+	Status   string            `json:"status" example:"healthy"`
+	Database string            `json:"database" example:"connected"`
+	Backend  string            `json:"backend" example:"mongo"`
+	Details  map[string]string `json:"details,omitempty"`
 }
 
 // Check handles GET /health
 // @Summary Health check
-// @Description Check the health status of the API and its dependencies
+// @Description Check the health status of the API and its storage backend
 // @Tags health
 // @Accept json
 // @Produce json
@@ -43,15 +52,18 @@ func (h *HealthHandler) Check(w http.ResponseWriter, r *http.Request) {
 	healthResp := HealthResponse{
 		Status:   "healthy",
 		Database: "connected",
+		Backend:  h.checker.Backend(),
 	}
 
-	// Check MongoDB connection
-	if err := h.mongoClient.Ping(ctx, nil); err != nil {
+	// Check storage backend
+	details, err := h.checker.CheckHealth(ctx)
+	if err != nil {
 		healthResp.Status = "unhealthy"
 		healthResp.Database = "disconnected"
 		response.JSON(w, http.StatusServiceUnavailable, healthResp)
 		return
 	}
 
+	healthResp.Details = details
 	response.OK(w, healthResp)
 }
@@ -1,10 +1,17 @@
 package handler
 
 import (
+	"errors"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/logging"
+	"github.com/services-api/pkg/response"
 )
 
 const (
@@ -14,6 +21,8 @@ const (
 	DefaultLimit = 20
 	// MaxLimit is the maximum number of items per page
 	MaxLimit = 100
+	// MaxUserAgentLength is the maximum length of a user agent recorded with a session
+	MaxUserAgentLength = 512
 )
 
 // ParsePaginationParams parses pagination parameters from query string
@@ -57,6 +66,11 @@ func ParseListParams(r *http.Request) domain.ListParams {
 		params.Name = name
 	}
 
+	// Parse team filter (services owned by a team)
+	if team := r.URL.Query().Get("team"); team != "" {
+		params.Team = team
+	}
+
 	// Parse sort field
 	if sort := r.URL.Query().Get("sort"); sort != "" {
 		params.Sort = sort
@@ -78,3 +92,155 @@ func ParseListParams(r *http.Request) domain.ListParams {
 
 	return params
 }
+
+// ParseUserListParams parses user list parameters from query string including filtering and sorting
+func ParseUserListParams(r *http.Request) domain.UserListParams {
+	query := r.URL.Query()
+	params := domain.UserListParams{
+		Query:      strings.TrimSpace(query.Get("q")),
+		Role:       query.Get("role"),
+		Pagination: ParsePaginationParams(r),
+	}
+
+	// Parse active filter
+	if activeStr := query.Get("active"); activeStr != "" {
+		if active, err := strconv.ParseBool(activeStr); err == nil {
+			params.Active = &active
+		}
+	}
+
+	// Parse created_after filter (RFC 3339 timestamp or date)
+	if createdAfterStr := query.Get("created_after"); createdAfterStr != "" {
+		for _, layout := range []string{time.RFC3339, time.DateOnly} {
+			if createdAfter, err := time.Parse(layout, createdAfterStr); err == nil {
+				params.CreatedAfter = &createdAfter
+				break
+			}
+		}
+	}
+
+	// Parse sort field
+	if sort := query.Get("sort"); sort != "" {
+		params.Sort = sort
+	} else {
+		params.Sort = "created_at"
+	}
+
+	// Parse sort order
+	if order := query.Get("order"); order == "asc" || order == "ascending" {
+		params.Order = "asc"
+	} else {
+		params.Order = "desc"
+	}
+
+	return params
+}
+
+// ParseAuditQuery parses audit log filters and the page cursor from the query string
+func ParseAuditQuery(r *http.Request) (domain.AuditQuery, error) {
+	query := r.URL.Query()
+	params := domain.AuditQuery{
+		ActorID:    query.Get("actor_id"),
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+		Limit:      domain.DefaultAuditLimit,
+	}
+
+	// Parse time range (RFC 3339 timestamps or dates)
+	var err error
+	if params.Since, err = parseAuditTime(query.Get("since")); err != nil {
+		return params, err
+	}
+	if params.Until, err = parseAuditTime(query.Get("until")); err != nil {
+		return params, err
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		if params.After, err = domain.ParseAuditCursor(cursor); err != nil {
+			return params, err
+		}
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			params.Limit = min(limit, domain.MaxAuditLimit)
+		}
+	}
+
+	return params, nil
+}
+
+// parseAuditTime parses an optional RFC 3339 timestamp or date
+func parseAuditTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, domain.ErrInvalidAuditTime
+}
+
+// ParseClientInfo extracts the client details recorded with a session.
+// RemoteAddr already holds the forwarded client address when RealIP is in use.
+func ParseClientInfo(r *http.Request) domain.ClientInfo {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	userAgent := r.UserAgent()
+	if len(userAgent) > MaxUserAgentLength {
+		userAgent = userAgent[:MaxUserAgentLength]
+	}
+
+	return domain.ClientInfo{
+		UserAgent: userAgent,
+		IPAddress: ip,
+	}
+}
+
+// authorize checks that the request holds every permission, writing a 403
+// response naming the missing ones if it doesn't
+func authorize(w http.ResponseWriter, r *http.Request, authorizer auth.Authorizer, permissions ...string) bool {
+	err := authorizer.Authorize(r.Context(), permissions...)
+	if err == nil {
+		return true
+	}
+
+	var permissionErr *auth.PermissionError
+	if errors.As(err, &permissionErr) {
+		response.Forbidden(w, err.Error())
+		return false
+	}
+
+	logging.FromContext(r.Context()).Error("Error authorizing request", "error", err)
+	response.InternalServerError(w, "internal server error")
+	return false
+}
+
+// PasswordPolicyErrorResponse is the response to a password that breaks the password policy
+type PasswordPolicyErrorResponse struct {
+	Error      string                     `json:"error" example:"bad_request"`
+	Message    string                     `json:"message" example:"password does not meet the password policy"`
+	Violations []domain.PasswordViolation `json:"violations"`
+}
+
+// passwordPolicyError writes a 400 response listing the violated rules if err
+// is a password policy error, and reports whether it did
+func passwordPolicyError(w http.ResponseWriter, err error) bool {
+	var policyErr *domain.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	response.JSON(w, http.StatusBadRequest, PasswordPolicyErrorResponse{
+		Error:      "bad_request",
+		Message:    domain.ErrPasswordPolicy.Error(),
+		Violations: policyErr.Violations,
+	})
+	return true
+}
@@ -2,10 +2,14 @@ package handler_test
 
 import (
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/services-api/internal/domain"
 	"github.com/services-api/internal/handler"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestParsePaginationParams(t *testing.T) {
@@ -94,6 +98,7 @@ func TestParseListParams(t *testing.T) {
 		query          string
 		expectedSearch string
 		expectedName   string
+		expectedTeam   string
 		expectedSort   string
 		expectedOrder  string
 		expectedPage   int
@@ -169,6 +174,17 @@ func TestParseListParams(t *testing.T) {
 			expectedPage:   2,
 			expectedLimit:  10,
 		},
+		{
+			name:           "team filter",
+			query:          "?team=507f1f77bcf86cd799439011",
+			expectedSearch: "",
+			expectedName:   "",
+			expectedTeam:   "507f1f77bcf86cd799439011",
+			expectedSort:   "created_at",
+			expectedOrder:  "desc",
+			expectedPage:   1,
+			expectedLimit:  20,
+		},
 	}
 
 	for _, tt := range tests {
@@ -178,6 +194,7 @@ func TestParseListParams(t *testing.T) {
 
 			assert.Equal(t, tt.expectedSearch, params.Search)
 			assert.Equal(t, tt.expectedName, params.Name)
+			assert.Equal(t, tt.expectedTeam, params.Team)
 			assert.Equal(t, tt.expectedSort, params.Sort)
 			assert.Equal(t, tt.expectedOrder, params.Order)
 			assert.Equal(t, tt.expectedPage, params.Pagination.Page)
@@ -185,3 +202,224 @@ func TestParseListParams(t *testing.T) {
 		})
 	}
 }
+
+func TestParseUserListParams(t *testing.T) {
+	active := true
+	inactive := false
+	createdAfter := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		query                string
+		expectedQuery        string
+		expectedRole         string
+		expectedActive       *bool
+		expectedCreatedAfter *time.Time
+		expectedSort         string
+		expectedOrder        string
+	}{
+		{
+			name:          "default values",
+			query:         "",
+			expectedSort:  "created_at",
+			expectedOrder: "desc",
+		},
+		{
+			name:          "search and role",
+			query:         "?q=+jane+doe+&role=editor",
+			expectedQuery: "jane doe",
+			expectedRole:  "editor",
+			expectedSort:  "created_at",
+			expectedOrder: "desc",
+		},
+		{
+			name:           "active filter",
+			query:          "?active=true",
+			expectedActive: &active,
+			expectedSort:   "created_at",
+			expectedOrder:  "desc",
+		},
+		{
+			name:           "inactive filter",
+			query:          "?active=false",
+			expectedActive: &inactive,
+			expectedSort:   "created_at",
+			expectedOrder:  "desc",
+		},
+		{
+			name:          "invalid active filter is ignored",
+			query:         "?active=maybe",
+			expectedSort:  "created_at",
+			expectedOrder: "desc",
+		},
+		{
+			name:                 "created after date",
+			query:                "?created_after=2024-01-15",
+			expectedCreatedAfter: &createdAfter,
+			expectedSort:         "created_at",
+			expectedOrder:        "desc",
+		},
+		{
+			name:                 "created after timestamp",
+			query:                "?created_after=2024-01-15T00:00:00Z",
+			expectedCreatedAfter: &createdAfter,
+			expectedSort:         "created_at",
+			expectedOrder:        "desc",
+		},
+		{
+			name:          "sort by last login ascending",
+			query:         "?sort=last_login&order=asc",
+			expectedSort:  "last_login",
+			expectedOrder: "asc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/users"+tt.query, nil)
+			params := handler.ParseUserListParams(req)
+
+			assert.Equal(t, tt.expectedQuery, params.Query)
+			assert.Equal(t, tt.expectedRole, params.Role)
+			assert.Equal(t, tt.expectedActive, params.Active)
+			if tt.expectedCreatedAfter == nil {
+				assert.Nil(t, params.CreatedAfter)
+			} else if assert.NotNil(t, params.CreatedAfter) {
+				assert.True(t, tt.expectedCreatedAfter.Equal(*params.CreatedAfter))
+			}
+			assert.Equal(t, tt.expectedSort, params.Sort)
+			assert.Equal(t, tt.expectedOrder, params.Order)
+			assert.Equal(t, 1, params.Pagination.Page)
+			assert.Equal(t, 20, params.Pagination.Limit)
+		})
+	}
+}
+
+func TestParseAuditQuery(t *testing.T) {
+	since := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	cursor := domain.AuditCursor{CreatedAt: since, ID: primitive.NewObjectID()}
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedErr   error
+		expectedSince *time.Time
+		expectedAfter *domain.AuditCursor
+		expectedLimit int
+	}{
+		{
+			name:          "default values",
+			query:         "",
+			expectedLimit: domain.DefaultAuditLimit,
+		},
+		{
+			name:          "since date",
+			query:         "?since=2024-01-15",
+			expectedSince: &since,
+			expectedLimit: domain.DefaultAuditLimit,
+		},
+		{
+			name:          "since timestamp",
+			query:         "?since=2024-01-15T00:00:00Z",
+			expectedSince: &since,
+			expectedLimit: domain.DefaultAuditLimit,
+		},
+		{
+			name:        "invalid since",
+			query:       "?since=yesterday",
+			expectedErr: domain.ErrInvalidAuditTime,
+		},
+		{
+			name:          "cursor",
+			query:         "?cursor=" + cursor.String(),
+			expectedAfter: &cursor,
+			expectedLimit: domain.DefaultAuditLimit,
+		},
+		{
+			name:        "invalid cursor",
+			query:       "?cursor=not-a-cursor",
+			expectedErr: domain.ErrInvalidAuditCursor,
+		},
+		{
+			name:          "limit exceeds max - capped",
+			query:         "?limit=1000",
+			expectedLimit: domain.MaxAuditLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/audit"+tt.query, nil)
+			query, err := handler.ParseAuditQuery(req)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.expectedSince == nil {
+				assert.Nil(t, query.Since)
+			} else if assert.NotNil(t, query.Since) {
+				assert.True(t, tt.expectedSince.Equal(*query.Since))
+			}
+			if tt.expectedAfter == nil {
+				assert.Nil(t, query.After)
+			} else if assert.NotNil(t, query.After) {
+				assert.True(t, tt.expectedAfter.CreatedAt.Equal(query.After.CreatedAt))
+				assert.Equal(t, tt.expectedAfter.ID, query.After.ID)
+			}
+			assert.Equal(t, tt.expectedLimit, query.Limit)
+		})
+	}
+}
+
+func TestParseClientInfo(t *testing.T) {
+	tests := []struct {
+		name              string
+		remoteAddr        string
+		userAgent         string
+		expectedIP        string
+		expectedUserAgent string
+	}{
+		{
+			name:              "address with port",
+			remoteAddr:        "203.0.113.7:54321",
+			userAgent:         "curl/8.0",
+			expectedIP:        "203.0.113.7",
+			expectedUserAgent: "curl/8.0",
+		},
+		{
+			name:              "address without port",
+			remoteAddr:        "203.0.113.7",
+			userAgent:         "curl/8.0",
+			expectedIP:        "203.0.113.7",
+			expectedUserAgent: "curl/8.0",
+		},
+		{
+			name:              "ipv6 address with port",
+			remoteAddr:        "[2001:db8::1]:443",
+			expectedIP:        "2001:db8::1",
+			expectedUserAgent: "",
+		},
+		{
+			name:              "user agent truncated",
+			remoteAddr:        "203.0.113.7:54321",
+			userAgent:         strings.Repeat("a", handler.MaxUserAgentLength+10),
+			expectedIP:        "203.0.113.7",
+			expectedUserAgent: strings.Repeat("a", handler.MaxUserAgentLength),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("User-Agent", tt.userAgent)
+
+			client := handler.ParseClientInfo(req)
+
+			assert.Equal(t, tt.expectedIP, client.IPAddress)
+			assert.Equal(t, tt.expectedUserAgent, client.UserAgent)
+		})
+	}
+}
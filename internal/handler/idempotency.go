@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/logging"
+	"github.com/services-api/pkg/response"
+)
+
+const (
+	// IdempotencyKeyHeader is the header carrying the client-chosen idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses replayed from a stored result
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodySize   = 1 << 20
+	idempotencyWaitTimeout  = 10 * time.Second
+	idempotencyPollInterval = 50 * time.Millisecond
+	idempotencyCreateTries  = 3
+)
+
+// IdempotencyMiddleware makes POST requests carrying an Idempotency-Key safe to retry
+type IdempotencyMiddleware struct {
+	repo domain.IdempotencyRepository
+	ttl  time.Duration
+}
+
+// NewIdempotencyMiddleware creates a new IdempotencyMiddleware keeping results for ttl
+func NewIdempotencyMiddleware(repo domain.IdempotencyRepository, ttl time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+// Handle stores the response of the first request for a key and replays it
+// for retries. A retry with a different body gets 422, and concurrent requests
+// with the same key wait for the first one to finish.
+func (m *IdempotencyMiddleware) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+		if idempotencyKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			response.BadRequest(w, fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		if err != nil {
+			response.BadRequest(w, "invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := scopedIdempotencyKey(r.Context(), idempotencyKey)
+		fingerprint := requestFingerprint(r, body)
+
+		for attempt := 0; attempt < idempotencyCreateTries; attempt++ {
+			now := time.Now().UTC()
+			err := m.repo.Create(r.Context(), &domain.IdempotencyRecord{
+				Key:         key,
+				Fingerprint: fingerprint,
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(m.ttl),
+			})
+			if err == nil {
+				m.execute(w, r, next, key)
+				return
+			}
+			if !errors.Is(err, domain.ErrIdempotencyKeyExists) {
+				logging.FromContext(r.Context()).Error("Error storing idempotency key", "error", err)
+				response.InternalServerError(w, "internal server error")
+				return
+			}
+
+			record, err := m.await(r.Context(), key, fingerprint)
+			if errors.Is(err, domain.ErrNotFound) {
+				// The first request failed or expired and released the key
+				continue
+			}
+			if err != nil {
+				logging.FromContext(r.Context()).Error("Error loading idempotency key", "error", err)
+				response.InternalServerError(w, "internal server error")
+				return
+			}
+
+			switch {
+			case record.Fingerprint != fingerprint:
+				response.Error(w, http.StatusUnprocessableEntity, "unprocessable_entity",
+					"idempotency key was already used with a different request")
+			case !record.Completed:
+				response.Conflict(w, "a request with this idempotency key is still in progress")
+			default:
+				replay(w, record)
+			}
+			return
+		}
+
+		response.Conflict(w, "a request with this idempotency key is still in progress")
+	})
+}
+
+// execute runs the request and stores its response. Server errors release the
+// key instead so that the client can retry.
+func (m *IdempotencyMiddleware) execute(w http.ResponseWriter, r *http.Request, next http.Handler, key string) {
+	rec := &recordingResponseWriter{ResponseWriter: w}
+	completed := false
+
+	// Release the key if the handler panics; the request context may already be canceled
+	defer func() {
+		if !completed {
+			if err := m.repo.Delete(context.Background(), key); err != nil {
+				logging.FromContext(r.Context()).Error("Error releasing idempotency key", "error", err)
+			}
+		}
+	}()
+
+	next.ServeHTTP(rec, r)
+
+	status := rec.statusCode()
+	if status >= http.StatusInternalServerError {
+		return
+	}
+
+	if err := m.repo.Complete(context.Background(), key, status, rec.Header().Get("Content-Type"), rec.body.Bytes()); err != nil {
+		logging.FromContext(r.Context()).Error("Error storing idempotent response", "error", err)
+		return
+	}
+	completed = true
+}
+
+// await returns the record for key, polling while it is in progress with a
+// matching fingerprint until it completes or the wait times out
+func (m *IdempotencyMiddleware) await(ctx context.Context, key, fingerprint string) (*domain.IdempotencyRecord, error) {
+	deadline := time.Now().Add(idempotencyWaitTimeout)
+	for {
+		record, err := m.repo.GetByKey(ctx, key)
+		if err != nil || record.Completed || record.Fingerprint != fingerprint || time.Now().After(deadline) {
+			return record, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(idempotencyPollInterval):
+		}
+	}
+}
+
+// replay writes a stored response
+func replay(w http.ResponseWriter, record *domain.IdempotencyRecord) {
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// scopedIdempotencyKey namespaces the client key by the authenticated principal
+// so different clients can't observe each other's responses
+func scopedIdempotencyKey(ctx context.Context, key string) string {
+	principal := "anonymous"
+	if userID, ok := auth.GetUserID(ctx); ok {
+		principal = "user:" + userID
+	} else if apiKey, ok := auth.GetAPIKeyPrincipal(ctx); ok {
+		principal = "api_key:" + apiKey.KeyID
+	} else if keyID, ok := auth.GetAPIKeyID(ctx); ok {
+		principal = fmt.Sprintf("api_key:%d", keyID)
+	}
+
+	sum := sha256.Sum256([]byte(principal + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestFingerprint identifies the request a key was first used with
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\x00"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingResponseWriter passes a response through while keeping a copy
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingResponseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,113 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingHandler echoes the request count and body so replays can be detected
+func countingHandler(calls *atomic.Int32, status int, delay time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"call":` + strconv.Itoa(int(n)) + `}`))
+	})
+}
+
+func postWithKey(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/services", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(handler.IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	t.Run("replays the stored response", func(t *testing.T) {
+		var calls atomic.Int32
+		h := handler.NewIdempotencyMiddleware(mocks.NewMockIdempotencyRepository(), time.Hour).
+			Handle(countingHandler(&calls, http.StatusCreated, 0))
+
+		first := postWithKey(h, "key-1", `{"name":"a"}`)
+		second := postWithKey(h, "key-1", `{"name":"a"}`)
+
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+		assert.Empty(t, first.Header().Get(handler.IdempotentReplayedHeader))
+		assert.Equal(t, "true", second.Header().Get(handler.IdempotentReplayedHeader))
+	})
+
+	t.Run("rejects a different body under the same key", func(t *testing.T) {
+		var calls atomic.Int32
+		h := handler.NewIdempotencyMiddleware(mocks.NewMockIdempotencyRepository(), time.Hour).
+			Handle(countingHandler(&calls, http.StatusCreated, 0))
+
+		postWithKey(h, "key-1", `{"name":"a"}`)
+		w := postWithKey(h, "key-1", `{"name":"b"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("serializes concurrent requests", func(t *testing.T) {
+		var calls atomic.Int32
+		h := handler.NewIdempotencyMiddleware(mocks.NewMockIdempotencyRepository(), time.Hour).
+			Handle(countingHandler(&calls, http.StatusCreated, 100*time.Millisecond))
+
+		var wg sync.WaitGroup
+		results := make([]*httptest.ResponseRecorder, 5)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = postWithKey(h, "key-1", `{"name":"a"}`)
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+		for _, w := range results {
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Equal(t, results[0].Body.String(), w.Body.String())
+		}
+	})
+
+	t.Run("server errors release the key", func(t *testing.T) {
+		var calls atomic.Int32
+		h := handler.NewIdempotencyMiddleware(mocks.NewMockIdempotencyRepository(), time.Hour).
+			Handle(countingHandler(&calls, http.StatusInternalServerError, 0))
+
+		postWithKey(h, "key-1", `{"name":"a"}`)
+		w := postWithKey(h, "key-1", `{"name":"a"}`)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("requests without a key pass through", func(t *testing.T) {
+		var calls atomic.Int32
+		h := handler.NewIdempotencyMiddleware(mocks.NewMockIdempotencyRepository(), time.Hour).
+			Handle(countingHandler(&calls, http.StatusCreated, 0))
+
+		postWithKey(h, "", `{"name":"a"}`)
+		postWithKey(h, "", `{"name":"a"}`)
+
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
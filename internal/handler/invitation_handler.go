@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/response"
+)
+
+// InvitationHandler handles user invitation HTTP requests
+type InvitationHandler struct {
+	invitationService *service.InvitationService
+	authorizer        auth.Authorizer
+}
+
+// NewInvitationHandler creates a new InvitationHandler
+func NewInvitationHandler(invitationService *service.InvitationService, authorizer auth.Authorizer) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+		authorizer:        authorizer,
+	}
+}
+
+// InvitationListResponse represents the response for listing invitations
+type InvitationListResponse struct {
+	Data       []domain.InvitationResponse `json:"data"`
+	Pagination domain.PaginationMetadata   `json:"pagination"`
+}
+
+// Invite handles POST /api/v1/users/invitations
+// @Summary Invite a user
+// @Description Create a pending user with a role and email them a link to set their own password. Requires the users:admin permission.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body domain.InviteUserRequest true "Invitation request"
+// @Param Idempotency-Key header string false "Client-generated key that makes retries safe"
+// @Success 201 {object} domain.InvitationResponse "Created invitation"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 409 {object} response.ErrorResponse "Email already exists or Idempotency-Key in progress"
+// @Failure 422 {object} response.ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Failure 502 {object} response.ErrorResponse "Invitation email could not be sent"
+// @Security BearerAuth
+// @Router /users/invitations [post]
+func (h *InvitationHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "authentication required")
+		return
+	}
+
+	var req domain.InviteUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	invitation, err := h.invitationService.Invite(r.Context(), userID, req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.Created(w, invitation.ToResponse())
+}
+
+// List handles GET /api/v1/users/invitations
+// @Summary List pending invitations
+// @Description Get a paginated list of invitations that haven't been accepted, newest first. Requires the users:read permission.
+// @Tags users
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Success 200 {object} InvitationListResponse "List of invitations with pagination"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/invitations [get]
+func (h *InvitationHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersRead) {
+		return
+	}
+
+	result, err := h.invitationService.List(r.Context(), ParsePaginationParams(r))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	invitationResponses := make([]domain.InvitationResponse, len(result.Data))
+	for i, inv := range result.Data {
+		invitationResponses[i] = inv.ToResponse()
+	}
+
+	response.OK(w, InvitationListResponse{
+		Data:       invitationResponses,
+		Pagination: result.Pagination,
+	})
+}
+
+// Resend handles POST /api/v1/users/invitations/{id}/resend
+// @Summary Resend an invitation
+// @Description Email a new invite link with a new expiry to the pending user. The previous link stops working. Requires the users:admin permission.
+// @Tags users
+// @Produce json
+// @Param id path string true "Invitation ID, the ID of the pending user"
+// @Success 200 {object} domain.InvitationResponse "Resent invitation"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 404 {object} response.ErrorResponse "Invitation not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Failure 502 {object} response.ErrorResponse "Invitation email could not be sent"
+// @Security BearerAuth
+// @Router /users/invitations/{id}/resend [post]
+func (h *InvitationHandler) Resend(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	invitation, err := h.invitationService.Resend(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, invitation.ToResponse())
+}
+
+// Revoke handles DELETE /api/v1/users/invitations/{id}
+// @Summary Revoke an invitation
+// @Description Withdraw an invitation and delete the pending user. Requires the users:admin permission.
+// @Tags users
+// @Produce json
+// @Param id path string true "Invitation ID, the ID of the pending user"
+// @Success 204 "Invitation revoked"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 404 {object} response.ErrorResponse "Invitation not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/invitations/{id} [delete]
+func (h *InvitationHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	if err := h.invitationService.Revoke(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Accept handles POST /api/v1/auth/invitations/accept
+// @Summary Accept an invitation
+// @Description Set the password of an invited user with the token from their invite link. The user can sign in afterwards.
+// @Tags auth
+// @Accept json
+// @Param request body domain.AcceptInvitationRequest true "Accept invitation request"
+// @Success 204 "Invitation accepted"
+// @Failure 400 {object} handler.PasswordPolicyErrorResponse "Invalid or expired invitation, or password policy violations"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/invitations/accept [post]
+func (h *InvitationHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	var req domain.AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.invitationService.Accept(r.Context(), req); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// handleError handles errors from the invitation service
+func (h *InvitationHandler) handleError(w http.ResponseWriter, err error) {
+	if passwordPolicyError(w, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		response.NotFound(w, "invitation not found")
+	case errors.Is(err, domain.ErrInvalidID):
+		response.BadRequest(w, "invalid invitation id format")
+	case errors.Is(err, domain.ErrEmailAlreadyExists):
+		response.Conflict(w, err.Error())
+	case errors.Is(err, domain.ErrInvalidInvitation),
+		errors.Is(err, domain.ErrEmailRequired),
+		errors.Is(err, domain.ErrEmailInvalid),
+		errors.Is(err, domain.ErrEmailTooLong),
+		errors.Is(err, domain.ErrFirstNameRequired),
+		errors.Is(err, domain.ErrFirstNameTooLong),
+		errors.Is(err, domain.ErrLastNameTooLong),
+		errors.Is(err, domain.ErrInvalidRole):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, domain.ErrInvitationNotSent):
+		response.Error(w, http.StatusBadGateway, "bad_gateway", domain.ErrInvitationNotSent.Error())
+	default:
+		response.InternalServerError(w, "internal server error")
+	}
+}
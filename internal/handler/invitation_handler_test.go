@@ -0,0 +1,502 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// failingMailer fails to send every email
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	return errors.New("smtp server unavailable")
+}
+
+func setupInvitationHandler(authorizer auth.Authorizer, m mailer.Mailer) (*handler.InvitationHandler, *service.InvitationService, *mocks.MockUserRepository) {
+	userRepo := mocks.NewMockUserRepository()
+	roles := service.NewRoleService(mocks.NewMockRoleRepository(), userRepo, nil)
+	if err := roles.EnsureBuiltInRoles(context.Background()); err != nil {
+		panic(err)
+	}
+	sessions := service.NewSessionService(mocks.NewMockSessionRepository(), mocks.NewMockRefreshTokenRepository(), nil)
+	throttle := service.NewLoginThrottleService(mocks.NewMockLoginAttemptRepository(), service.LoginPolicy{}, nil)
+	passwords := service.NewPasswordService(mocks.NewMockPasswordHistoryRepository(), nil, service.PasswordPolicy{
+		MinLength:  12,
+		MinClasses: 3,
+	})
+	accounts := service.NewAccountService(userRepo, mocks.NewMockUserTokenRepository(), sessions, throttle, passwords, m, service.AccountOptions{}, nil)
+	users := service.NewUserService(userRepo, mocks.NewMockTeamRepository(), mocks.NewMockServiceVersionRepository(), sessions, roles, throttle, accounts, passwords, nil)
+	invitations := service.NewInvitationService(mocks.NewMockInvitationRepository(), userRepo, users, passwords, m, service.InvitationOptions{
+		URL: "https://app.example.com/accept-invitation",
+		TTL: 7 * 24 * time.Hour,
+	}, nil)
+	return handler.NewInvitationHandler(invitations, authorizer), invitations, userRepo
+}
+
+// inviteTestUser invites new@example.com and returns the invitation
+func inviteTestUser(invitations *service.InvitationService) *domain.Invitation {
+	invitation, err := invitations.Invite(context.Background(), primitive.NewObjectID().Hex(), domain.InviteUserRequest{
+		Email:     "new@example.com",
+		FirstName: "New",
+	})
+	if err != nil {
+		panic(err)
+	}
+	return invitation
+}
+
+func TestInvitationHandler_Invite(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		authorizer     auth.Authorizer
+		mailer         mailer.Mailer
+		noUser         bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful invitation",
+			requestBody: domain.InviteUserRequest{
+				Email:     "invited@example.com",
+				FirstName: "Invited",
+				Role:      domain.RoleEditor,
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusCreated,
+			expectedError:  `"role":"editor"`,
+		},
+		{
+			name: "existing email",
+			requestBody: domain.InviteUserRequest{
+				Email:     "Taken@Example.com",
+				FirstName: "Again",
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "email already exists",
+		},
+		{
+			name: "invalid email",
+			requestBody: domain.InviteUserRequest{
+				Email:     "not-an-email",
+				FirstName: "Invited",
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "email format is invalid",
+		},
+		{
+			name: "unknown role",
+			requestBody: domain.InviteUserRequest{
+				Email:     "invited@example.com",
+				FirstName: "Invited",
+				Role:      "superuser",
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid role",
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    "invalid json",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+		{
+			name: "email not sent",
+			requestBody: domain.InviteUserRequest{
+				Email:     "invited@example.com",
+				FirstName: "Invited",
+			},
+			authorizer:     testAuthorizer{},
+			mailer:         failingMailer{},
+			expectedStatus: http.StatusBadGateway,
+			expectedError:  "invitation email could not be sent",
+		},
+		{
+			name: "API key instead of a user token",
+			requestBody: domain.InviteUserRequest{
+				Email:     "invited@example.com",
+				FirstName: "Invited",
+			},
+			authorizer:     testAuthorizer{},
+			noUser:         true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "missing permission",
+			requestBody: domain.InviteUserRequest{
+				Email:     "invited@example.com",
+				FirstName: "Invited",
+			},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.mailer
+			if m == nil {
+				m = mailer.NewMemory()
+			}
+			h, _, userRepo := setupInvitationHandler(tt.authorizer, m)
+			userRepo.AddUser(&domain.User{Email: "taken@example.com", FirstName: "Taken", Role: domain.RoleUser, Active: true})
+
+			var body []byte
+			if str, ok := tt.requestBody.(string); ok {
+				body = []byte(str)
+			} else {
+				body, _ = json.Marshal(tt.requestBody)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/invitations", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if !tt.noUser {
+				req = asUser(req, primitive.NewObjectID().Hex())
+			}
+			w := httptest.NewRecorder()
+
+			h.Invite(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestInvitationHandler_List(t *testing.T) {
+	tests := []struct {
+		name           string
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectedCount  int
+	}{
+		{
+			name:           "successful listing",
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:           "missing permission",
+			authorizer:     testAuthorizer{deny: []string{auth.ScopeUsersRead}},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, invitations, _ := setupInvitationHandler(tt.authorizer, mailer.NewMemory())
+			inviteTestUser(invitations)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/invitations", nil)
+			w := httptest.NewRecorder()
+
+			h.List(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp handler.InvitationListResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Len(t, resp.Data, tt.expectedCount)
+			}
+		})
+	}
+}
+
+func TestInvitationHandler_Resend(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          func(invitation *domain.Invitation, userRepo *mocks.MockUserRepository) string
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "pending invitation",
+			setup: func(invitation *domain.Invitation, userRepo *mocks.MockUserRepository) string {
+				return invitation.ID.Hex()
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "user signed in meanwhile",
+			setup: func(invitation *domain.Invitation, userRepo *mocks.MockUserRepository) string {
+				user, err := userRepo.GetByID(context.Background(), invitation.ID.Hex())
+				require.NoError(t, err)
+				user.Pending = false
+				return invitation.ID.Hex()
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "invitation not found",
+		},
+		{
+			name: "unknown invitation",
+			setup: func(invitation *domain.Invitation, userRepo *mocks.MockUserRepository) string {
+				return primitive.NewObjectID().Hex()
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "invitation not found",
+		},
+		{
+			name: "invalid id",
+			setup: func(invitation *domain.Invitation, userRepo *mocks.MockUserRepository) string {
+				return "not-an-id"
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid invitation id format",
+		},
+		{
+			name: "missing permission",
+			setup: func(invitation *domain.Invitation, userRepo *mocks.MockUserRepository) string {
+				return invitation.ID.Hex()
+			},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, invitations, userRepo := setupInvitationHandler(tt.authorizer, mailer.NewMemory())
+			id := tt.setup(inviteTestUser(invitations), userRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/invitations/"+id+"/resend", nil)
+			req = withURLParams(req, "id", id)
+			w := httptest.NewRecorder()
+
+			h.Resend(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestInvitationHandler_Revoke(t *testing.T) {
+	tests := []struct {
+		name           string
+		unknown        bool
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "pending invitation",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "unknown invitation",
+			unknown:        true,
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "invitation not found",
+		},
+		{
+			name:           "missing permission",
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, invitations, userRepo := setupInvitationHandler(tt.authorizer, mailer.NewMemory())
+			id := inviteTestUser(invitations).ID.Hex()
+			requested := id
+			if tt.unknown {
+				requested = primitive.NewObjectID().Hex()
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/invitations/"+requested, nil)
+			req = withURLParams(req, "id", requested)
+			w := httptest.NewRecorder()
+
+			h.Revoke(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+
+			// Revoking deletes the pending user
+			_, err := userRepo.GetByID(context.Background(), id)
+			if tt.expectedStatus == http.StatusNoContent {
+				assert.ErrorIs(t, err, domain.ErrUserNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInvitationHandler_Accept(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    func(token string) interface{}
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "valid token",
+			requestBody: func(token string) interface{} {
+				return domain.AcceptInvitationRequest{Token: token, Password: "Correct-horse-42"}
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "unknown token",
+			requestBody: func(token string) interface{} {
+				return domain.AcceptInvitationRequest{Token: "unknown", Password: "Correct-horse-42"}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invitation is invalid or has expired",
+		},
+		{
+			name: "missing token",
+			requestBody: func(token string) interface{} {
+				return domain.AcceptInvitationRequest{Password: "Correct-horse-42"}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invitation is invalid or has expired",
+		},
+		{
+			name: "weak password",
+			requestBody: func(token string) interface{} {
+				return domain.AcceptInvitationRequest{Token: token, Password: "short"}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "violations",
+		},
+		{
+			name: "invalid JSON",
+			requestBody: func(token string) interface{} {
+				return "invalid json"
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mailer.NewMemory()
+			h, invitations, _ := setupInvitationHandler(testAuthorizer{}, m)
+			inviteTestUser(invitations)
+			token := emailedToken(t, m, "new@example.com")
+
+			var body []byte
+			requestBody := tt.requestBody(token)
+			if str, ok := requestBody.(string); ok {
+				body = []byte(str)
+			} else {
+				body, _ = json.Marshal(requestBody)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/invitations/accept", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			h.Accept(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestInvitationHandler_RouteGuards(t *testing.T) {
+	h, invitations, _ := setupInvitationHandler(testAuthorizer{}, mailer.NewMemory())
+	id := inviteTestUser(invitations).ID.Hex()
+	router := newTestRouter(routerHandlers{invitation: h})
+
+	runRouteGuardTests(t, router, primitive.NewObjectID().Hex(), []routeGuardTest{
+		{
+			name:           "invite without users:admin",
+			method:         http.MethodPost,
+			path:           "/api/v1/users/invitations",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersAdmin,
+		},
+		{
+			name:           "list without users:read",
+			method:         http.MethodGet,
+			path:           "/api/v1/users/invitations",
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersRead,
+		},
+		{
+			name:           "list",
+			method:         http.MethodGet,
+			path:           "/api/v1/users/invitations",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "resend without users:admin",
+			method:         http.MethodPost,
+			path:           "/api/v1/users/invitations/" + id + "/resend",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersAdmin,
+		},
+		{
+			name:           "revoke without users:admin",
+			method:         http.MethodDelete,
+			path:           "/api/v1/users/invitations/" + id,
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersAdmin,
+		},
+		{
+			name:           "revoke",
+			method:         http.MethodDelete,
+			path:           "/api/v1/users/invitations/" + id,
+			scopes:         []string{auth.ScopeUsersAdmin},
+			expectedStatus: http.StatusNoContent,
+		},
+	})
+
+	// Accepting an invitation needs no authentication
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/invitations/accept", strings.NewReader(`{"token":"unknown"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invitation is invalid or has expired")
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/services-api/pkg/jwt"
+	"github.com/services-api/pkg/response"
+)
+
+// JWKSHandler publishes the public keys access tokens are signed with
+type JWKSHandler struct {
+	jwtManager *jwt.Manager
+}
+
+// NewJWKSHandler creates a new JWKSHandler
+func NewJWKSHandler(jwtManager *jwt.Manager) *JWKSHandler {
+	return &JWKSHandler{
+		jwtManager: jwtManager,
+	}
+}
+
+// Get handles GET /.well-known/jwks.json
+// @Summary JSON Web Key Set
+// @Description Public keys that verify access tokens, matched by the kid header of a token. Empty when tokens are signed with HS256.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} jwt.JWKSet "Signing and verification keys"
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) Get(w http.ResponseWriter, r *http.Request) {
+	// Verifiers refetch the set when they see an unknown kid, so a short
+	// cache lifetime is enough
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	response.OK(w, h.jwtManager.JWKS())
+}
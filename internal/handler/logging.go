@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/logging"
+	"github.com/services-api/pkg/response"
+)
+
+// RequestLogger gives every request a logger carrying its request ID and logs
+// the request once it completes, with its route pattern, status and latency.
+// Headers are logged at debug level, with credentials redacted. It must run
+// after RequestID and RealIP.
+func RequestLogger(logger *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx := logging.NewContext(r.Context(), logger.With("request_id", middleware.GetReqID(r.Context())))
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remote_ip", ParseClientInfo(r).IPAddress),
+			}
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				attrs = append(attrs, slog.String("route", rctx.RoutePattern()))
+			}
+
+			requestLogger := logging.FromContext(ctx)
+			if requestLogger.Enabled(ctx, slog.LevelDebug) {
+				headers := make([]any, 0, len(r.Header))
+				for name := range r.Header {
+					headers = append(headers, slog.String(name, r.Header.Get(name)))
+				}
+				attrs = append(attrs, slog.Group("headers", headers...))
+			}
+			requestLogger.LogAttrs(ctx, level, "Request completed", attrs...)
+		})
+	}
+}
+
+// LogPrincipal adds the authenticated user, the auth type and, while
+// impersonating, the admin acting as the user to the request logger. It must
+// run after Authenticate.
+func LogPrincipal(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var args []any
+		if principal, ok := auth.GetAPIKeyPrincipal(ctx); ok {
+			args = append(args, "user_id", principal.OwnerID, "api_key_id", principal.KeyID)
+		} else if userID, ok := auth.GetUserID(ctx); ok {
+			args = append(args, "user_id", userID)
+		}
+		if authType, ok := auth.GetAuthType(ctx); ok {
+			args = append(args, "auth_type", string(authType))
+		}
+		if actorID, ok := auth.GetActorID(ctx); ok {
+			args = append(args, "impersonator_id", actorID)
+		}
+		logging.AddAttrs(ctx, args...)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Recoverer turns panics into 500 responses and logs them with their stack.
+// It must run after RequestLogger.
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// Aborting a response is not an error
+				panic(rec)
+			}
+
+			logging.FromContext(r.Context()).Error("Panic handling request",
+				"panic", fmt.Sprint(rec), "stack", string(debug.Stack()))
+			if r.Header.Get("Connection") != "Upgrade" {
+				response.InternalServerError(w, "internal server error")
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
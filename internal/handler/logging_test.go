@@ -0,0 +1,119 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loggedRouter serves GET /services/{id} behind the logging middleware,
+// authenticating the request as user-1 with a JWT
+func loggedRouter(t *testing.T, level string, h http.HandlerFunc) (http.Handler, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, level, "json")
+	require.NoError(t, err)
+
+	authenticate := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), auth.UserIDContextKey, "user-1")
+			ctx = context.WithValue(ctx, auth.AuthTypeKey, auth.AuthTypeJWT)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(handler.RequestLogger(logger))
+	r.Use(handler.Recoverer)
+	r.With(authenticate, handler.LogPrincipal).Get("/services/{id}", h)
+	return r, &buf
+}
+
+// logEntries decodes one JSON log entry per line
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var entries []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var entry map[string]any
+		require.NoError(t, dec.Decode(&entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRequestLogger(t *testing.T) {
+	router, buf := loggedRouter(t, "info", func(w http.ResponseWriter, r *http.Request) {
+		logging.FromContext(r.Context()).Info("Handling")
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/services/abc", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logEntries(t, buf)
+	require.Len(t, entries, 2)
+
+	// Logs written while handling the request carry its ID and principal
+	assert.Equal(t, "Handling", entries[0]["msg"])
+	assert.NotEmpty(t, entries[0]["request_id"])
+	assert.Equal(t, "user-1", entries[0]["user_id"])
+
+	completed := entries[1]
+	assert.Equal(t, "Request completed", completed["msg"])
+	assert.Equal(t, entries[0]["request_id"], completed["request_id"])
+	assert.Equal(t, "user-1", completed["user_id"])
+	assert.Equal(t, "jwt", completed["auth_type"])
+	assert.Equal(t, "/services/{id}", completed["route"])
+	assert.Equal(t, "/services/abc", completed["path"])
+	assert.Equal(t, float64(http.StatusNotFound), completed["status"])
+	assert.Contains(t, completed, "latency_ms")
+	assert.NotContains(t, completed, "headers")
+}
+
+func TestRequestLogger_DebugHeadersAreRedacted(t *testing.T) {
+	router, buf := loggedRouter(t, "debug", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/services/abc", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("X-API-Key", "sk_secret")
+	req.Header.Set("Accept", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.NotContains(t, buf.String(), "secret")
+	entries := logEntries(t, buf)
+	require.Len(t, entries, 1)
+	headers, ok := entries[0]["headers"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, logging.Redacted, headers["Authorization"])
+	assert.Equal(t, logging.Redacted, headers["X-Api-Key"])
+	assert.Equal(t, "application/json", headers["Accept"])
+}
+
+func TestRecoverer(t *testing.T) {
+	router, buf := loggedRouter(t, "info", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/services/abc", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	entries := logEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Panic handling request", entries[0]["msg"])
+	assert.Equal(t, "boom", entries[0]["panic"])
+	assert.Equal(t, "ERROR", entries[1]["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), entries[1]["status"])
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/metrics"
+	"github.com/services-api/pkg/response"
+)
+
+// unmatchedRoute labels requests that matched no route, so that arbitrary
+// paths don't create new series
+const unmatchedRoute = "unmatched"
+
+// RecordMetrics counts requests and observes their latency by method, route
+// pattern and status. It must run before Recoverer so that panics count as
+// server errors.
+func RecordMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		labels := []string{r.Method, route, strconv.Itoa(status)}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	})
+}
+
+// RequireMetricsToken protects the metrics endpoint with a bearer token of its
+// own, independent of user authentication. An empty token leaves it open.
+func RequireMetricsToken(token string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if token == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided, ok := strings.CutPrefix(r.Header.Get(auth.AuthorizationHeader), auth.BearerPrefix)
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				response.Unauthorized(w, "invalid metrics token")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordMetrics(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(handler.RecordMetrics)
+	r.Use(handler.Recoverer)
+	r.Get("/widgets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "panic" {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	requests := func(route, status string) float64 {
+		return testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, route, status))
+	}
+	ok, failed, unmatched := requests("/widgets/{id}", "204"), requests("/widgets/{id}", "500"), requests("unmatched", "404")
+
+	for _, path := range []string{"/widgets/1", "/widgets/2", "/widgets/panic", "/unknown/path"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Requests are labeled by route pattern, not path
+	assert.Equal(t, ok+2, requests("/widgets/{id}", "204"))
+	assert.Equal(t, failed+1, requests("/widgets/{id}", "500"))
+	assert.Equal(t, unmatched+1, requests("unmatched", "404"))
+	assert.Positive(t, testutil.CollectAndCount(metrics.HTTPRequestDuration, "services_api_http_request_duration_seconds"))
+}
+
+func TestRequireMetricsToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name           string
+		token          string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "no token configured", expectedStatus: http.StatusOK},
+		{name: "valid token", token: "scrape-secret", authorization: "Bearer scrape-secret", expectedStatus: http.StatusOK},
+		{name: "missing token", token: "scrape-secret", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "scrape-secret", authorization: "Bearer other", expectedStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", token: "scrape-secret", authorization: "scrape-secret", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.RequireMetricsToken(tt.token)(ok).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/response"
+)
+
+// MFAHandler handles multi-factor authentication HTTP requests
+type MFAHandler struct {
+	mfaService *service.MFAService
+	authorizer auth.Authorizer
+}
+
+// NewMFAHandler creates a new MFAHandler
+func NewMFAHandler(mfaService *service.MFAService, authorizer auth.Authorizer) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+		authorizer: authorizer,
+	}
+}
+
+// Status handles GET /api/v1/users/me/mfa
+// @Summary Get multi-factor authentication status
+// @Description Get whether the current user has an authenticator app set up, how many recovery codes are left and whether their role requires MFA
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} domain.MFAStatus "MFA status"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Not allowed while impersonating a user"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/me/mfa [get]
+func (h *MFAHandler) Status(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "authentication required")
+		return
+	}
+
+	status, err := h.mfaService.Status(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, status)
+}
+
+// EnrollTOTP handles POST /api/v1/users/me/mfa/totp
+// @Summary Set up an authenticator app
+// @Description Generate a TOTP secret for the current user. Add it to an authenticator app, by hand or from the otpauth URI as a QR code, then confirm it with POST /users/me/mfa/totp/verify.
+// @Tags mfa
+// @Produce json
+// @Success 201 {object} domain.TOTPEnrollment "Secret to add to the authenticator app"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Not allowed while impersonating a user"
+// @Failure 409 {object} response.ErrorResponse "An authenticator app is already enabled"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/me/mfa/totp [post]
+func (h *MFAHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "authentication required")
+		return
+	}
+
+	enrollment, err := h.mfaService.EnrollTOTP(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.Created(w, enrollment)
+}
+
+// ConfirmTOTP handles POST /api/v1/users/me/mfa/totp/verify
+// @Summary Confirm an authenticator app
+// @Description Enable the authenticator app set up with POST /users/me/mfa/totp using a code from it. The response lists recovery codes, which are only shown once. Users whose role requires MFA get their full permissions on the next token refresh.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body domain.MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} domain.RecoveryCodesResponse "Recovery codes"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized or invalid code"
+// @Failure 403 {object} response.ErrorResponse "Not allowed while impersonating a user"
+// @Failure 409 {object} response.ErrorResponse "No authenticator app set up, or already enabled"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/me/mfa/totp/verify [post]
+func (h *MFAHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, req, ok := h.decodeCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTP(r.Context(), userID, req.Code)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, codes)
+}
+
+// DisableTOTP handles DELETE /api/v1/users/me/mfa/totp
+// @Summary Remove the authenticator app
+// @Description Turn off multi-factor authentication for the current user with a code from the authenticator app or a recovery code. Not allowed when the user's role requires MFA.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body domain.MFACodeRequest true "Code from the authenticator app or a recovery code"
+// @Success 204 "Authenticator app removed"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized or invalid code"
+// @Failure 403 {object} response.ErrorResponse "MFA is required for the user's role, or impersonating a user"
+// @Failure 409 {object} response.ErrorResponse "No authenticator app set up"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/me/mfa/totp [delete]
+func (h *MFAHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, req, ok := h.decodeCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.Disable(r.Context(), userID, req.Code); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// RegenerateRecoveryCodes handles POST /api/v1/users/me/mfa/recovery-codes
+// @Summary Regenerate recovery codes
+// @Description Replace the recovery codes of the current user, invalidating the previous ones. Requires a code from the authenticator app or a recovery code.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body domain.MFACodeRequest true "Code from the authenticator app or a recovery code"
+// @Success 200 {object} domain.RecoveryCodesResponse "New recovery codes"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized or invalid code"
+// @Failure 403 {object} response.ErrorResponse "Not allowed while impersonating a user"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/me/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, req, ok := h.decodeCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, codes)
+}
+
+// Reset handles DELETE /api/v1/users/{id}/mfa
+// @Summary Reset a user's multi-factor authentication
+// @Description Remove the authenticator app and recovery codes of a user who lost access to them, so they can log in with their password and set up a new one. Requires the users:admin permission.
+// @Tags mfa
+// @Produce json
+// @Param id path string true "User ID (MongoDB ObjectID)"
+// @Success 204 "MFA reset"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/{id}/mfa [delete]
+func (h *MFAHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	if err := h.mfaService.Reset(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// decodeCodeRequest reads the current user and the code from the request body
+func (h *MFAHandler) decodeCodeRequest(w http.ResponseWriter, r *http.Request) (string, domain.MFACodeRequest, bool) {
+	var req domain.MFACodeRequest
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "authentication required")
+		return "", req, false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return "", req, false
+	}
+
+	return userID, req, true
+}
+
+// handleError handles errors from the MFA service
+func (h *MFAHandler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		response.NotFound(w, "user not found")
+	case errors.Is(err, domain.ErrInvalidID):
+		response.BadRequest(w, "invalid user id format")
+	case errors.Is(err, domain.ErrMFACodeRequired):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		response.Unauthorized(w, err.Error())
+	case errors.Is(err, domain.ErrMFARequired):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, domain.ErrMFANotEnrolled),
+		errors.Is(err, domain.ErrMFAAlreadyEnabled):
+		response.Conflict(w, err.Error())
+	default:
+		response.InternalServerError(w, "internal server error")
+	}
+}
@@ -0,0 +1,407 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func setupMFAHandler(authorizer auth.Authorizer, requiredRoles ...string) (*handler.MFAHandler, *service.MFAService, *mocks.MockUserRepository) {
+	userRepo := mocks.NewMockUserRepository()
+	mfa := service.NewMFAService(mocks.NewMockMFARepository(), userRepo, "Services API", requiredRoles, nil)
+	return handler.NewMFAHandler(mfa, authorizer), mfa, userRepo
+}
+
+// enrollTestTOTP starts setting up an authenticator app for the user and returns its secret
+func enrollTestTOTP(t *testing.T, mfa *service.MFAService, userID string) string {
+	t.Helper()
+	enrollment, err := mfa.EnrollTOTP(context.Background(), userID)
+	require.NoError(t, err)
+	return enrollment.Secret
+}
+
+// enableTestTOTP sets up an authenticator app for the user and returns their recovery codes
+func enableTestTOTP(t *testing.T, mfa *service.MFAService, userID string) []string {
+	t.Helper()
+	code, err := totp.GenerateCode(enrollTestTOTP(t, mfa, userID), time.Now())
+	require.NoError(t, err)
+	codes, err := mfa.ConfirmTOTP(context.Background(), userID, code)
+	require.NoError(t, err)
+	return codes.RecoveryCodes
+}
+
+func TestMFAHandler_Status(t *testing.T) {
+	tests := []struct {
+		name           string
+		caller         func(user *domain.User) string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "current user",
+			expectedStatus: http.StatusOK,
+			expectedError:  `"totp_enabled":false`,
+		},
+		{
+			name: "unknown user",
+			caller: func(user *domain.User) string {
+				return primitive.NewObjectID().Hex()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "user not found",
+		},
+		{
+			name: "no user",
+			caller: func(user *domain.User) string {
+				return ""
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "authentication required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, userRepo := setupMFAHandler(testAuthorizer{})
+			user := addTestUser(userRepo, domain.RoleUser)
+			callerID := user.ID.Hex()
+			if tt.caller != nil {
+				callerID = tt.caller(user)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/mfa", nil)
+			if callerID != "" {
+				req = asUser(req, callerID)
+			}
+			w := httptest.NewRecorder()
+
+			h.Status(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestMFAHandler_EnrollTOTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          func(t *testing.T, mfa *service.MFAService, userID string)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "successful enrollment",
+			expectedStatus: http.StatusCreated,
+			expectedError:  "otpauth://totp/",
+		},
+		{
+			name: "pending enrollment is replaced",
+			setup: func(t *testing.T, mfa *service.MFAService, userID string) {
+				enrollTestTOTP(t, mfa, userID)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "already enabled",
+			setup: func(t *testing.T, mfa *service.MFAService, userID string) {
+				enableTestTOTP(t, mfa, userID)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "already enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mfa, userRepo := setupMFAHandler(testAuthorizer{})
+			userID := addTestUser(userRepo, domain.RoleUser).ID.Hex()
+			if tt.setup != nil {
+				tt.setup(t, mfa, userID)
+			}
+
+			req := asUser(httptest.NewRequest(http.MethodPost, "/api/v1/users/me/mfa/totp", nil), userID)
+			w := httptest.NewRecorder()
+
+			h.EnrollTOTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestMFAHandler_ConfirmTOTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		enroll         bool
+		requestBody    func(secret string) string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:   "valid code",
+			enroll: true,
+			requestBody: func(secret string) string {
+				code, _ := totp.GenerateCode(secret, time.Now())
+				return `{"code":"` + code + `"}`
+			},
+			expectedStatus: http.StatusOK,
+			expectedError:  "recovery_codes",
+		},
+		{
+			name:   "wrong code",
+			enroll: true,
+			requestBody: func(secret string) string {
+				return `{"code":"000000"}`
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "invalid authentication code",
+		},
+		{
+			name:   "missing code",
+			enroll: true,
+			requestBody: func(secret string) string {
+				return `{}`
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "code is required",
+		},
+		{
+			name: "not enrolled",
+			requestBody: func(secret string) string {
+				return `{"code":"000000"}`
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "not set up",
+		},
+		{
+			name:   "invalid JSON",
+			enroll: true,
+			requestBody: func(secret string) string {
+				return "invalid json"
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mfa, userRepo := setupMFAHandler(testAuthorizer{})
+			userID := addTestUser(userRepo, domain.RoleUser).ID.Hex()
+			var secret string
+			if tt.enroll {
+				secret = enrollTestTOTP(t, mfa, userID)
+			}
+
+			body := bytes.NewReader([]byte(tt.requestBody(secret)))
+			req := asUser(httptest.NewRequest(http.MethodPost, "/api/v1/users/me/mfa/totp/verify", body), userID)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			h.ConfirmTOTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestMFAHandler_DisableTOTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		code           func(recoveryCodes []string) string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "with a recovery code",
+			role: domain.RoleUser,
+			code: func(recoveryCodes []string) string {
+				return recoveryCodes[0]
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "wrong code",
+			role: domain.RoleUser,
+			code: func(recoveryCodes []string) string {
+				return "000000"
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "invalid authentication code",
+		},
+		{
+			name: "missing code",
+			role: domain.RoleUser,
+			code: func(recoveryCodes []string) string {
+				return ""
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "code is required",
+		},
+		{
+			name: "required for the role",
+			role: domain.RoleAdmin,
+			code: func(recoveryCodes []string) string {
+				return recoveryCodes[0]
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "required for your role",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mfa, userRepo := setupMFAHandler(testAuthorizer{}, domain.RoleAdmin)
+			userID := addTestUser(userRepo, tt.role).ID.Hex()
+			recoveryCodes := enableTestTOTP(t, mfa, userID)
+
+			body, _ := json.Marshal(domain.MFACodeRequest{Code: tt.code(recoveryCodes)})
+			req := asUser(httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/mfa/totp", bytes.NewReader(body)), userID)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			h.DisableTOTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestMFAHandler_Reset(t *testing.T) {
+	tests := []struct {
+		name           string
+		authorizer     auth.Authorizer
+		unknownUser    bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "successful reset",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "missing permission",
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+		{
+			name:           "unknown user",
+			authorizer:     testAuthorizer{},
+			unknownUser:    true,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "user not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mfa, userRepo := setupMFAHandler(tt.authorizer)
+			userID := addTestUser(userRepo, domain.RoleUser).ID.Hex()
+			enableTestTOTP(t, mfa, userID)
+			if tt.unknownUser {
+				userID = primitive.NewObjectID().Hex()
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/"+userID+"/mfa", nil)
+			req = withURLParams(req, "id", userID)
+			w := httptest.NewRecorder()
+
+			h.Reset(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+
+			enabled, err := mfa.Enabled(context.Background(), userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus != http.StatusNoContent && !tt.unknownUser, enabled)
+		})
+	}
+}
+
+func TestMFAHandler_RouteGuards(t *testing.T) {
+	h, _, userRepo := setupMFAHandler(testAuthorizer{})
+	caller := addTestUser(userRepo, domain.RoleUser).ID.Hex()
+	other := addTestUser(userRepo, domain.RoleUser).ID.Hex()
+	router := newTestRouter(routerHandlers{mfa: h})
+
+	runRouteGuardTests(t, router, caller, []routeGuardTest{
+		{
+			name:           "status",
+			method:         http.MethodGet,
+			path:           "/api/v1/users/me/mfa",
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "status while setting up MFA",
+			method:         http.MethodGet,
+			path:           "/api/v1/users/me/mfa",
+			scopes:         []string{auth.ScopeMFAEnrollment},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "status while impersonating",
+			method:         http.MethodGet,
+			path:           "/api/v1/users/me/mfa",
+			scopes:         []string{auth.ScopeServicesRead},
+			impersonating:  true,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "not allowed while impersonating",
+		},
+		{
+			name:           "enroll while impersonating",
+			method:         http.MethodPost,
+			path:           "/api/v1/users/me/mfa/totp",
+			scopes:         []string{auth.ScopeServicesRead},
+			impersonating:  true,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "not allowed while impersonating",
+		},
+		{
+			name:           "reset without users:admin",
+			method:         http.MethodDelete,
+			path:           "/api/v1/users/" + other + "/mfa",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersAdmin,
+		},
+		{
+			name:           "reset",
+			method:         http.MethodDelete,
+			path:           "/api/v1/users/" + other + "/mfa",
+			scopes:         []string{auth.ScopeUsersAdmin},
+			expectedStatus: http.StatusNoContent,
+		},
+	})
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/oidc"
+	"github.com/services-api/pkg/response"
+)
+
+const (
+	// oidcCookieName is the cookie carrying a login from /auth/oidc/login to the callback
+	oidcCookieName = "oidc_auth"
+	// oidcLoginTimeout is how long a user has to sign in at the identity provider
+	oidcLoginTimeout = 10 * time.Minute
+)
+
+// OIDCHandler handles single sign-on through an OpenID Connect provider
+type OIDCHandler struct {
+	oidcService  *service.OIDCService
+	secureCookie bool
+}
+
+// NewOIDCHandler creates a new OIDCHandler. secureCookie restricts the login
+// cookie to HTTPS and should be set when the API is served over HTTPS.
+func NewOIDCHandler(oidcService *service.OIDCService, secureCookie bool) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:  oidcService,
+		secureCookie: secureCookie,
+	}
+}
+
+// Login handles GET /api/v1/auth/oidc/login
+// @Summary Start single sign-on
+// @Description Redirect the browser to the identity provider to sign in. The provider redirects back to /auth/oidc/callback.
+// @Tags auth
+// @Success 302 "Redirect to the identity provider"
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	authURL, req := h.oidcService.AuthCodeURL()
+
+	// The state, nonce and PKCE verifier only travel in this cookie, so the
+	// callback can't be completed by anyone but the browser that started the login
+	value, _ := json.Marshal(req)
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    base64.RawURLEncoding.EncodeToString(value),
+		Path:     "/api/v1/auth/oidc",
+		MaxAge:   int(oidcLoginTimeout.Seconds()),
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback handles GET /api/v1/auth/oidc/callback
+// @Summary Complete single sign-on
+// @Description Redeem the authorization code returned by the identity provider. Users are created on their first login or linked to an existing account with the same email. Users with multi-factor authentication get an MFA challenge (202) to complete with POST /auth/mfa/verify.
+// @Tags auth
+// @Produce json
+// @Param code query string false "Authorization code"
+// @Param state query string true "State from the login request"
+// @Param error query string false "Error returned by the identity provider"
+// @Success 200 {object} domain.AuthResponse "Successfully authenticated"
+// @Success 202 {object} domain.MFAChallenge "Second factor required"
+// @Failure 400 {object} response.ErrorResponse "Missing or mismatched login state"
+// @Failure 401 {object} response.ErrorResponse "Sign-in failed or was denied"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.readLoginCookie(r)
+	// A login can only be completed once
+	http.SetCookie(w, &http.Cookie{Name: oidcCookieName, Path: "/api/v1/auth/oidc", MaxAge: -1, HttpOnly: true, Secure: h.secureCookie})
+
+	q := r.URL.Query()
+	if !ok || subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(req.State)) != 1 {
+		response.BadRequest(w, "login state is missing or does not match, start the login again")
+		return
+	}
+	if errCode := q.Get("error"); errCode != "" {
+		message := "sign-in was denied: " + errCode
+		if description := q.Get("error_description"); description != "" {
+			message += ": " + description
+		}
+		response.Unauthorized(w, message)
+		return
+	}
+	if q.Get("code") == "" {
+		response.BadRequest(w, "code is required")
+		return
+	}
+
+	authResp, challenge, err := h.oidcService.Callback(r.Context(), q.Get("code"), req, ParseClientInfo(r))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	if challenge != nil {
+		response.JSON(w, http.StatusAccepted, challenge)
+		return
+	}
+
+	response.OK(w, authResp)
+}
+
+// readLoginCookie reads the login started by Login
+func (h *OIDCHandler) readLoginCookie(r *http.Request) (oidc.AuthRequest, bool) {
+	var req oidc.AuthRequest
+	cookie, err := r.Cookie(oidcCookieName)
+	if err != nil {
+		return req, false
+	}
+	value, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return req, false
+	}
+	if err := json.Unmarshal(value, &req); err != nil || req.State == "" {
+		return req, false
+	}
+	return req, true
+}
+
+// handleError handles errors from the OIDC service
+func (h *OIDCHandler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSSOFailed),
+		errors.Is(err, domain.ErrSSOEmailRequired):
+		response.Unauthorized(w, err.Error())
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		response.Unauthorized(w, "invalid credentials")
+	default:
+		response.InternalServerError(w, "internal server error")
+	}
+}
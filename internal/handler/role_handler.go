@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/response"
+)
+
+// RoleHandler handles role and permission HTTP requests
+type RoleHandler struct {
+	roleService *service.RoleService
+	authorizer  auth.Authorizer
+}
+
+// NewRoleHandler creates a new RoleHandler
+func NewRoleHandler(roleService *service.RoleService, authorizer auth.Authorizer) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		authorizer:  authorizer,
+	}
+}
+
+// RoleListResponse represents the response for listing roles
+type RoleListResponse struct {
+	Data []domain.RoleResponse `json:"data"`
+}
+
+// PermissionListResponse represents the response for listing permissions
+type PermissionListResponse struct {
+	Data []auth.Permission `json:"data"`
+}
+
+// ListPermissions handles GET /api/v1/permissions
+// @Summary List permissions
+// @Description List every permission that can be granted to a role. Requires the users:read permission.
+// @Tags roles
+// @Produce json
+// @Success 200 {object} PermissionListResponse "Known permissions"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /permissions [get]
+func (h *RoleHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersRead) {
+		return
+	}
+
+	response.OK(w, PermissionListResponse{Data: auth.Permissions})
+}
+
+// List handles GET /api/v1/roles
+// @Summary List roles
+// @Description List the built-in and custom roles with their permissions. Requires the users:read permission.
+// @Tags roles
+// @Produce json
+// @Success 200 {object} RoleListResponse "Roles"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /roles [get]
+func (h *RoleHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersRead) {
+		return
+	}
+
+	roles, err := h.roleService.List(r.Context())
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	roleResponses := make([]domain.RoleResponse, len(roles))
+	for i, role := range roles {
+		roleResponses[i] = role.ToResponse()
+	}
+
+	response.OK(w, RoleListResponse{Data: roleResponses})
+}
+
+// Get handles GET /api/v1/roles/{name}
+// @Summary Get a role
+// @Description Get a role and its permissions. Requires the users:read permission.
+// @Tags roles
+// @Produce json
+// @Param name path string true "Role name"
+// @Success 200 {object} domain.RoleResponse "Role details"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 404 {object} response.ErrorResponse "Role not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /roles/{name} [get]
+func (h *RoleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersRead) {
+		return
+	}
+
+	role, err := h.roleService.GetByName(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, role.ToResponse())
+}
+
+// Create handles POST /api/v1/roles
+// @Summary Create a role
+// @Description Create a custom role. Requires the users:admin permission.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateRoleRequest true "Role creation request"
+// @Success 201 {object} domain.RoleResponse "Created role"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 409 {object} response.ErrorResponse "Role already exists"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /roles [post]
+func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	var req domain.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	role, err := h.roleService.Create(r.Context(), req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.Created(w, role.ToResponse())
+}
+
+// Update handles PUT /api/v1/roles/{name}
+// @Summary Update a role
+// @Description Replace the description and permissions of a role. Built-in roles can be edited too, except admin. Removed permissions stop working immediately; added ones apply once users refresh their tokens. Requires the users:admin permission.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Param request body domain.UpdateRoleRequest true "Role update request"
+// @Success 200 {object} domain.RoleResponse "Updated role"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 404 {object} response.ErrorResponse "Role not found"
+// @Failure 409 {object} response.ErrorResponse "The admin role can't be changed"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /roles/{name} [put]
+func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	var req domain.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	role, err := h.roleService.Update(r.Context(), chi.URLParam(r, "name"), req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, role.ToResponse())
+}
+
+// Delete handles DELETE /api/v1/roles/{name}
+// @Summary Delete a role
+// @Description Delete a custom role that is not assigned to any user. Requires the users:admin permission.
+// @Tags roles
+// @Produce json
+// @Param name path string true "Role name"
+// @Success 204 "Role deleted"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 404 {object} response.ErrorResponse "Role not found"
+// @Failure 409 {object} response.ErrorResponse "Role is built in or assigned to users"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /roles/{name} [delete]
+func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	if err := h.roleService.Delete(r.Context(), chi.URLParam(r, "name")); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// handleError handles errors from the role service
+func (h *RoleHandler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRoleNotFound):
+		response.NotFound(w, "role not found")
+	case errors.Is(err, domain.ErrRoleExists),
+		errors.Is(err, domain.ErrRoleBuiltIn),
+		errors.Is(err, domain.ErrRoleImmutable),
+		errors.Is(err, domain.ErrRoleInUse):
+		response.Conflict(w, err.Error())
+	case errors.Is(err, domain.ErrRoleNameInvalid),
+		errors.Is(err, domain.ErrRoleDescriptionTooLong),
+		errors.Is(err, domain.ErrInvalidPermission):
+		response.BadRequest(w, err.Error())
+	default:
+		response.InternalServerError(w, "internal server error")
+	}
+}
@@ -0,0 +1,519 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRoleHandler(authorizer auth.Authorizer) (*handler.RoleHandler, *mocks.MockRoleRepository, *mocks.MockUserRepository) {
+	roleRepo := mocks.NewMockRoleRepository()
+	userRepo := mocks.NewMockUserRepository()
+	roles := service.NewRoleService(roleRepo, userRepo, nil)
+	if err := roles.EnsureBuiltInRoles(context.Background()); err != nil {
+		panic(err)
+	}
+	if err := roleRepo.Create(context.Background(), &domain.Role{
+		Name:        "release-manager",
+		Permissions: []string{auth.ScopeServicesRead, auth.ScopeServicesWrite},
+	}); err != nil {
+		panic(err)
+	}
+	return handler.NewRoleHandler(roles, authorizer), roleRepo, userRepo
+}
+
+func TestRoleHandler_ListPermissions(t *testing.T) {
+	tests := []struct {
+		name           string
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "successful listing",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+			expectedError:  auth.ScopeServicesAdmin,
+		},
+		{
+			name:           "missing permission",
+			authorizer:     testAuthorizer{deny: []string{auth.ScopeUsersRead}},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, _ := setupRoleHandler(tt.authorizer)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/permissions", nil)
+			w := httptest.NewRecorder()
+
+			h.ListPermissions(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedError)
+		})
+	}
+}
+
+func TestRoleHandler_List(t *testing.T) {
+	tests := []struct {
+		name           string
+		authorizer     auth.Authorizer
+		setupRepo      func(repo *mocks.MockRoleRepository)
+		expectedStatus int
+		expectedCount  int
+	}{
+		{
+			name:           "successful listing",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+			expectedCount:  len(auth.BuiltInRoles) + 1,
+		},
+		{
+			name:           "missing permission",
+			authorizer:     testAuthorizer{deny: []string{auth.ScopeUsersRead}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:       "repository failure",
+			authorizer: testAuthorizer{},
+			setupRepo: func(repo *mocks.MockRoleRepository) {
+				repo.ListFunc = func(ctx context.Context) ([]domain.Role, error) {
+					return nil, errors.New("connection reset")
+				}
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, roleRepo, _ := setupRoleHandler(tt.authorizer)
+			if tt.setupRepo != nil {
+				tt.setupRepo(roleRepo)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/roles", nil)
+			w := httptest.NewRecorder()
+
+			h.List(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp handler.RoleListResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Len(t, resp.Data, tt.expectedCount)
+			}
+		})
+	}
+}
+
+func TestRoleHandler_Get(t *testing.T) {
+	tests := []struct {
+		name           string
+		roleName       string
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "built-in role",
+			roleName:       domain.RoleAdmin,
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+			expectedError:  `"built_in":true`,
+		},
+		{
+			name:           "custom role",
+			roleName:       "release-manager",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not found",
+			roleName:       "nobody",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "role not found",
+		},
+		{
+			name:           "missing permission",
+			roleName:       domain.RoleAdmin,
+			authorizer:     testAuthorizer{deny: []string{auth.ScopeUsersRead}},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, _ := setupRoleHandler(tt.authorizer)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/roles/"+tt.roleName, nil)
+			req = withURLParams(req, "name", tt.roleName)
+			w := httptest.NewRecorder()
+
+			h.Get(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestRoleHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "successful creation",
+			requestBody: domain.CreateRoleRequest{
+				Name:        "on-call",
+				Description: "Updates services during incidents",
+				Permissions: []string{auth.ScopeServicesWrite},
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "existing name",
+			requestBody: domain.CreateRoleRequest{
+				Name:        domain.RoleEditor,
+				Permissions: []string{auth.ScopeServicesRead},
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "role already exists",
+		},
+		{
+			name: "invalid name",
+			requestBody: domain.CreateRoleRequest{
+				Name:        "On Call",
+				Permissions: []string{auth.ScopeServicesRead},
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "role name must be",
+		},
+		{
+			name: "unknown permission",
+			requestBody: domain.CreateRoleRequest{
+				Name:        "on-call",
+				Permissions: []string{"services:delete"},
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid permission",
+		},
+		{
+			name: "description too long",
+			requestBody: domain.CreateRoleRequest{
+				Name:        "on-call",
+				Description: strings.Repeat("a", 201),
+				Permissions: []string{auth.ScopeServicesRead},
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "description must be at most 200 characters",
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    "invalid json",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+		{
+			name: "missing permission",
+			requestBody: domain.CreateRoleRequest{
+				Name:        "on-call",
+				Permissions: []string{auth.ScopeServicesRead},
+			},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, _ := setupRoleHandler(tt.authorizer)
+
+			var body []byte
+			if str, ok := tt.requestBody.(string); ok {
+				body = []byte(str)
+			} else {
+				body, _ = json.Marshal(tt.requestBody)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/roles", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			h.Create(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestRoleHandler_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		roleName       string
+		requestBody    interface{}
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:     "custom role",
+			roleName: "release-manager",
+			requestBody: domain.UpdateRoleRequest{
+				Description: "Deletes services too",
+				Permissions: []string{auth.ScopeServicesRead, auth.ScopeServicesAdmin},
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+			expectedError:  auth.ScopeServicesAdmin,
+		},
+		{
+			name:     "built-in role",
+			roleName: domain.RoleViewer,
+			requestBody: domain.UpdateRoleRequest{
+				Permissions: []string{auth.ScopeServicesRead, auth.ScopeUsersRead},
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "admin role",
+			roleName: domain.RoleAdmin,
+			requestBody: domain.UpdateRoleRequest{
+				Permissions: []string{auth.ScopeServicesRead},
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "the admin role can't be changed",
+		},
+		{
+			name:     "not found",
+			roleName: "nobody",
+			requestBody: domain.UpdateRoleRequest{
+				Permissions: []string{auth.ScopeServicesRead},
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "role not found",
+		},
+		{
+			name:     "unknown permission",
+			roleName: "release-manager",
+			requestBody: domain.UpdateRoleRequest{
+				Permissions: []string{"services:delete"},
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid permission",
+		},
+		{
+			name:           "invalid JSON",
+			roleName:       "release-manager",
+			requestBody:    "invalid json",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+		{
+			name:     "missing permission",
+			roleName: "release-manager",
+			requestBody: domain.UpdateRoleRequest{
+				Permissions: []string{auth.ScopeServicesRead},
+			},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, _ := setupRoleHandler(tt.authorizer)
+
+			var body []byte
+			if str, ok := tt.requestBody.(string); ok {
+				body = []byte(str)
+			} else {
+				body, _ = json.Marshal(tt.requestBody)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/roles/"+tt.roleName, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = withURLParams(req, "name", tt.roleName)
+			w := httptest.NewRecorder()
+
+			h.Update(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestRoleHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name           string
+		roleName       string
+		setupRepo      func(repo *mocks.MockUserRepository)
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "custom role",
+			roleName:       "release-manager",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:     "role assigned to a user",
+			roleName: "release-manager",
+			setupRepo: func(repo *mocks.MockUserRepository) {
+				addTestUser(repo, "release-manager")
+			},
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "role is assigned to users",
+		},
+		{
+			name:           "built-in role",
+			roleName:       domain.RoleEditor,
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "built-in roles can't be deleted",
+		},
+		{
+			name:           "not found",
+			roleName:       "nobody",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "role not found",
+		},
+		{
+			name:           "missing permission",
+			roleName:       "release-manager",
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, userRepo := setupRoleHandler(tt.authorizer)
+			if tt.setupRepo != nil {
+				tt.setupRepo(userRepo)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/roles/"+tt.roleName, nil)
+			req = withURLParams(req, "name", tt.roleName)
+			w := httptest.NewRecorder()
+
+			h.Delete(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestRoleHandler_RouteGuards(t *testing.T) {
+	h, _, _ := setupRoleHandler(testAuthorizer{})
+	router := newTestRouter(routerHandlers{role: h})
+
+	runRouteGuardTests(t, router, "user-1", []routeGuardTest{
+		{
+			name:           "list permissions without users:read",
+			method:         http.MethodGet,
+			path:           "/api/v1/permissions",
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersRead,
+		},
+		{
+			name:           "list roles",
+			method:         http.MethodGet,
+			path:           "/api/v1/roles",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "get a role without users:read",
+			method:         http.MethodGet,
+			path:           "/api/v1/roles/" + domain.RoleEditor,
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersRead,
+		},
+		{
+			name:           "create a role without users:admin",
+			method:         http.MethodPost,
+			path:           "/api/v1/roles",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersAdmin,
+		},
+		{
+			name:           "update a role without users:admin",
+			method:         http.MethodPut,
+			path:           "/api/v1/roles/release-manager",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersAdmin,
+		},
+		{
+			name:           "delete a role without users:admin",
+			method:         http.MethodDelete,
+			path:           "/api/v1/roles/release-manager",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersAdmin,
+		},
+		{
+			name:           "delete a role",
+			method:         http.MethodDelete,
+			path:           "/api/v1/roles/release-manager",
+			scopes:         []string{auth.ScopeUsersAdmin},
+			expectedStatus: http.StatusNoContent,
+		},
+	})
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -9,6 +10,7 @@ import (
 	"github.com/services-api/pkg/auth"
 	"github.com/services-api/pkg/config"
 	"github.com/services-api/pkg/jwt"
+	"github.com/services-api/pkg/metrics"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
@@ -18,23 +20,38 @@ func NewRouter(
 	jwtManager *jwt.Manager,
 	serviceHandler *ServiceHandler,
 	healthHandler *HealthHandler,
+	jwksHandler *JWKSHandler,
 	authHandler *AuthHandler,
+	accountHandler *AccountHandler,
+	oidcHandler *OIDCHandler,
 	userHandler *UserHandler,
+	invitationHandler *InvitationHandler,
+	mfaHandler *MFAHandler,
+	sessionHandler *SessionHandler,
+	apiKeyHandler *APIKeyHandler,
+	roleHandler *RoleHandler,
+	teamHandler *TeamHandler,
+	auditHandler *AuditHandler,
+	idempotency *IdempotencyMiddleware,
+	sessions auth.SessionValidator,
+	apiKeys auth.APIKeyAuthenticator,
 ) http.Handler {
 	r := chi.NewRouter()
 
-	// Configure Chi router with middleware (logging, recovery, CORS)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	// Configure Chi router with middleware (request IDs, logging, metrics, recovery, CORS)
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	r.Use(RequestLogger(slog.Default()))
+	r.Use(RecordMetrics)
+	r.Use(Recoverer)
+	r.Use(AuditRequest)
 
 	// CORS configuration
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key", "If-None-Match", "If-Modified-Since", IdempotencyKeyHeader},
+		ExposedHeaders:   []string{"Link", "ETag", "Last-Modified", IdempotentReplayedHeader},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -42,6 +59,12 @@ func NewRouter(
 	// Register health endpoint (no auth)
 	r.Get("/health", healthHandler.Check)
 
+	// Prometheus metrics, behind their own token when METRICS_TOKEN is set
+	r.With(RequireMetricsToken(cfg.MetricsToken)).Handle("/metrics", metrics.Handler())
+
+	// Public keys for verifying access tokens (no auth)
+	r.Get("/.well-known/jwks.json", jwksHandler.Get)
+
 	// Swagger documentation endpoint (no auth)
 	r.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
@@ -49,6 +72,20 @@ func NewRouter(
 
 	// Create auth middleware
 	authMiddleware := auth.NewMiddleware(cfg, jwtManager)
+	if sessions != nil {
+		authMiddleware.WithSessionValidator(sessions)
+	}
+	if apiKeys != nil {
+		authMiddleware.WithAPIKeyAuthenticator(apiKeys)
+	}
+
+	// Scope requirements of the protected routes. Handlers additionally check
+	// that the caller's role still grants the permission.
+	requireServicesRead := auth.RequireScopes(auth.ScopeServicesRead)
+	requireServicesWrite := auth.RequireScopes(auth.ScopeServicesWrite)
+	requireServicesAdmin := auth.RequireScopes(auth.ScopeServicesAdmin)
+	requireUsersRead := auth.RequireScopes(auth.ScopeUsersRead)
+	requireUsersAdmin := auth.RequireScopes(auth.ScopeUsersAdmin)
 
 	// Register /api/v1 routes
 	r.Route("/api/v1", func(r chi.Router) {
@@ -57,40 +94,150 @@ func NewRouter(
 			r.Post("/register", authHandler.Register)
 			r.Post("/login", authHandler.Login)
 			r.Post("/refresh", authHandler.Refresh)
+			r.Post("/logout", authHandler.Logout)
+			r.Post("/mfa/verify", authHandler.VerifyMFA)
+			r.Post("/password/forgot", accountHandler.ForgotPassword)
+			r.Post("/password/reset", accountHandler.ResetPassword)
+			r.Post("/email/verify", accountHandler.VerifyEmail)
+			r.Post("/email/verify/resend", accountHandler.ResendVerification)
+			r.Post("/invitations/accept", invitationHandler.Accept)
+			r.With(authMiddleware.Authenticate, LogPrincipal, auth.ForbidImpersonation).Post("/logout-all", authHandler.LogoutAll)
+
+			// Single sign-on, when an OIDC provider is configured
+			if oidcHandler != nil {
+				r.Get("/oidc/login", oidcHandler.Login)
+				r.Get("/oidc/callback", oidcHandler.Callback)
+			}
 		})
 
 		// Protected routes (require authentication)
 		r.Group(func(r chi.Router) {
 			r.Use(authMiddleware.Authenticate)
+			r.Use(LogPrincipal)
 
 			// User routes
 			r.Route("/users", func(r chi.Router) {
 				r.Get("/me", userHandler.GetMe)
-				r.Post("/me/password", userHandler.ChangePassword)
-				r.Post("/", userHandler.Create)
-				r.Get("/", userHandler.List)
+				r.With(auth.ForbidImpersonation).Post("/me/password", userHandler.ChangePassword)
+				r.Get("/me/export", userHandler.ExportMe)
+
+				// Deleting an account only schedules it; signing in again and
+				// restoring it cancels the deletion until the grace period ends.
+				r.With(auth.ForbidImpersonation).Delete("/me", userHandler.DeleteMe)
+				r.With(auth.ForbidImpersonation).Post("/me/restore", userHandler.RestoreMe)
+				r.Get("/me/sessions", sessionHandler.ListMine)
+				r.Delete("/me/sessions/{sessionId}", sessionHandler.RevokeMine)
+
+				// Multi-factor authentication of the current user. These stay
+				// reachable for users who must set up MFA before using their role.
+				r.Route("/me/mfa", func(r chi.Router) {
+					r.Use(auth.ForbidImpersonation)
+					r.Get("/", mfaHandler.Status)
+					r.Post("/totp", mfaHandler.EnrollTOTP)
+					r.Post("/totp/verify", mfaHandler.ConfirmTOTP)
+					r.Delete("/totp", mfaHandler.DisableTOTP)
+					r.Post("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+				})
+
+				r.With(requireUsersAdmin, idempotency.Handle).Post("/", userHandler.Create)
+				r.With(requireUsersRead).Get("/", userHandler.List)
+
+				// Invitations of pending users
+				r.Route("/invitations", func(r chi.Router) {
+					r.With(requireUsersAdmin, idempotency.Handle).Post("/", invitationHandler.Invite)
+					r.With(requireUsersRead).Get("/", invitationHandler.List)
+					r.With(requireUsersAdmin).Delete("/{id}", invitationHandler.Revoke)
+					r.With(requireUsersAdmin).Post("/{id}/resend", invitationHandler.Resend)
+				})
 
 				r.Route("/{id}", func(r chi.Router) {
+					// Users can read their own profile; the handler checks access
 					r.Get("/", userHandler.Get)
-					r.Put("/", userHandler.Update)
-					r.Delete("/", userHandler.Delete)
+					r.With(requireUsersAdmin).Put("/", userHandler.Update)
+					r.With(requireUsersAdmin).Delete("/", userHandler.Delete)
+					r.With(requireUsersAdmin).Delete("/mfa", mfaHandler.Reset)
+					r.With(requireUsersAdmin).Delete("/lockout", userHandler.Unlock)
+					r.With(requireUsersAdmin, auth.ForbidImpersonation).Post("/impersonate", userHandler.Impersonate)
+
+					// Session routes
+					r.Route("/sessions", func(r chi.Router) {
+						r.With(requireUsersRead).Get("/", sessionHandler.List)
+						r.With(requireUsersAdmin).Delete("/", sessionHandler.RevokeAll)
+						r.With(requireUsersAdmin).Delete("/{sessionId}", sessionHandler.Revoke)
+					})
+				})
+			})
+
+			// Role routes
+			r.With(requireUsersRead).Get("/permissions", roleHandler.ListPermissions)
+			r.Route("/roles", func(r chi.Router) {
+				r.With(requireUsersRead).Get("/", roleHandler.List)
+				r.With(requireUsersAdmin).Post("/", roleHandler.Create)
+
+				r.Route("/{name}", func(r chi.Router) {
+					r.With(requireUsersRead).Get("/", roleHandler.Get)
+					r.With(requireUsersAdmin).Put("/", roleHandler.Update)
+					r.With(requireUsersAdmin).Delete("/", roleHandler.Delete)
+				})
+			})
+
+			// Audit log routes
+			r.Route("/audit", func(r chi.Router) {
+				r.Use(requireUsersAdmin)
+				r.Get("/", auditHandler.List)
+				r.Get("/export", auditHandler.Export)
+				r.Get("/verify", auditHandler.Verify)
+			})
+
+			// Team routes. Handlers additionally check that the caller
+			// maintains the team they change.
+			r.Route("/teams", func(r chi.Router) {
+				r.With(requireServicesRead).Get("/", teamHandler.List)
+				r.With(requireServicesWrite, idempotency.Handle).Post("/", teamHandler.Create)
+
+				r.Route("/{id}", func(r chi.Router) {
+					r.With(requireServicesRead).Get("/", teamHandler.Get)
+					r.With(requireServicesWrite).Put("/", teamHandler.Update)
+					r.With(requireServicesWrite).Delete("/", teamHandler.Delete)
+					r.With(requireServicesWrite).Put("/members/{userId}", teamHandler.SetMember)
+					r.With(requireServicesWrite).Delete("/members/{userId}", teamHandler.RemoveMember)
+				})
+			})
+
+			// API key routes
+			r.Route("/api-keys", func(r chi.Router) {
+				// Impersonation can't mint credentials that outlive it, nor
+				// see or change the user's own. Tokens that only allow setting
+				// up MFA can't mint credentials that skip it.
+				r.Use(auth.ForbidImpersonation)
+				r.Use(auth.RequireAnyScope(auth.AllScopes...))
+				r.Post("/", apiKeyHandler.Create)
+				r.Get("/", apiKeyHandler.List)
+
+				r.Route("/{id}", func(r chi.Router) {
+					r.Get("/", apiKeyHandler.Get)
+					r.Patch("/", apiKeyHandler.Update)
+					r.Delete("/", apiKeyHandler.Delete)
+					r.Post("/rotate", apiKeyHandler.Rotate)
 				})
 			})
 
 			// Service routes
 			r.Route("/services", func(r chi.Router) {
-				r.Post("/", serviceHandler.Create)
-				r.Get("/", serviceHandler.List)
+				r.With(requireServicesWrite, idempotency.Handle).Post("/", serviceHandler.Create)
+				r.With(requireServicesRead).Get("/", serviceHandler.List)
 
 				r.Route("/{id}", func(r chi.Router) {
-					r.Get("/", serviceHandler.Get)
-					r.Put("/", serviceHandler.Update)
-					r.Patch("/", serviceHandler.Patch)
-					r.Delete("/", serviceHandler.Delete)
+					r.With(requireServicesRead).Get("/", serviceHandler.Get)
+					r.With(requireServicesWrite).Put("/", serviceHandler.Update)
+					r.With(requireServicesWrite).Patch("/", serviceHandler.Patch)
+					r.With(requireServicesAdmin).Delete("/", serviceHandler.Delete)
 
 					// Version routes
 					r.Route("/versions", func(r chi.Router) {
+						r.Use(requireServicesRead)
 						r.Get("/", serviceHandler.ListVersions)
+						r.Get("/verify", serviceHandler.VerifyVersions)
 						r.Get("/{revision}", serviceHandler.GetVersion)
 					})
 				})
@@ -0,0 +1,143 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/config"
+	"github.com/services-api/pkg/jwt"
+	"github.com/services-api/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testJWTManager = jwt.NewManager("test-secret", 15*time.Minute, 24*time.Hour, "test")
+
+// routerHandlers are the handlers a route guard test mounts on the API
+// router. Routes of handlers left nil must not be requested.
+type routerHandlers struct {
+	invitation *handler.InvitationHandler
+	mfa        *handler.MFAHandler
+	session    *handler.SessionHandler
+	apiKey     *handler.APIKeyHandler
+	role       *handler.RoleHandler
+	team       *handler.TeamHandler
+	audit      *handler.AuditHandler
+}
+
+// newTestRouter returns the API router serving the given handlers
+func newTestRouter(h routerHandlers) http.Handler {
+	return handler.NewRouter(&config.Config{}, testJWTManager, nil, nil, nil, nil, nil, nil, nil, h.invitation,
+		h.mfa, h.session, h.apiKey, h.role, h.team, h.audit, nil, nil, nil)
+}
+
+// routeGuardTest is a request to a protected route made with a token of the
+// given scopes, optionally while impersonating a user
+type routeGuardTest struct {
+	name           string
+	method         string
+	path           string
+	scopes         []string
+	impersonating  bool
+	expectedStatus int
+	expectedError  string
+}
+
+// runRouteGuardTests sends each request through the router as userID
+func runRouteGuardTests(t *testing.T, router http.Handler, userID string, tests []routeGuardTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := accessToken(t, userID, tt.scopes...)
+			if tt.impersonating {
+				token = impersonationToken(t, userID, tt.scopes...)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+// accessToken issues an access token for a user with the given scopes
+func accessToken(t *testing.T, userID string, scopes ...string) string {
+	t.Helper()
+	token, err := testJWTManager.GenerateAccessTokenForSession(userID, "user@example.com", "user", "", scopes)
+	require.NoError(t, err)
+	return token
+}
+
+// impersonationToken issues a token for an admin acting as the user
+func impersonationToken(t *testing.T, userID string, scopes ...string) string {
+	t.Helper()
+	actor := jwt.Actor{Subject: primitive.NewObjectID().Hex(), Email: "admin@example.com"}
+	token, err := testJWTManager.GenerateImpersonationToken(userID, "user@example.com", "user", "", scopes, actor, time.Minute)
+	require.NoError(t, err)
+	return token
+}
+
+// addTestUser stores an active user with the given role
+func addTestUser(repo *mocks.MockUserRepository, role string) *domain.User {
+	user := &domain.User{
+		Email:     primitive.NewObjectID().Hex() + "@example.com",
+		FirstName: "Test",
+		Role:      role,
+		Active:    true,
+	}
+	repo.AddUser(user)
+	return user
+}
+
+// asUser returns the request as made by the user with an access token. The
+// token holds the given scopes, or every scope if none are given, leaving
+// permission checks to the authorizer under test.
+func asUser(req *http.Request, userID string, scopes ...string) *http.Request {
+	if len(scopes) == 0 {
+		scopes = auth.AllScopes
+	}
+	ctx := context.WithValue(req.Context(), auth.UserIDContextKey, userID)
+	ctx = context.WithValue(ctx, auth.AuthTypeKey, auth.AuthTypeJWT)
+	ctx = context.WithValue(ctx, auth.ScopesKey, scopes)
+	return req.WithContext(ctx)
+}
+
+// withURLParams returns the request with chi URL parameters, given as name and value pairs
+func withURLParams(req *http.Request, params ...string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for i := 0; i+1 < len(params); i += 2 {
+		rctx.URLParams.Add(params[i], params[i+1])
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+// emailedToken returns the token of the last link emailed to the address
+func emailedToken(t *testing.T, m *mailer.MemoryMailer, to string) string {
+	t.Helper()
+	msg, ok := m.Last(to)
+	require.True(t, ok, "no email to %s", to)
+	_, link, ok := strings.Cut(msg.Body, "token=")
+	require.True(t, ok, "no link in email to %s", to)
+	token, err := url.QueryUnescape(strings.Fields(link)[0])
+	require.NoError(t, err)
+	return token
+}
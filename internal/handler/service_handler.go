@@ -8,35 +8,46 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/services-api/internal/domain"
 	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
 	"github.com/services-api/pkg/response"
 )
 
 // ServiceHandler handles HTTP requests for services
 type ServiceHandler struct {
-	service *service.ServiceService
+	service    *service.ServiceService
+	authorizer auth.Authorizer
 }
 
 // NewServiceHandler creates a new ServiceHandler
-func NewServiceHandler(svc *service.ServiceService) *ServiceHandler {
+func NewServiceHandler(svc *service.ServiceService, authorizer auth.Authorizer) *ServiceHandler {
 	return &ServiceHandler{
-		service: svc,
+		service:    svc,
+		authorizer: authorizer,
 	}
 }
 
 // Create handles POST /api/v1/services
 // @Summary Create a new service
-// @Description Create a new service with name and description. Revision starts at 1.
+// @Description Create a new service with name and description. Revision starts at 1. A service assigned to a team can only be changed by members of the team and holders of services:admin.
 // @Tags services
 // @Accept json
 // @Produce json
 // @Param request body domain.CreateServiceRequest true "Service creation request"
+// @Param Idempotency-Key header string false "Client-generated key that makes retries safe"
 // @Success 201 {object} domain.ServiceResponse "Created service"
 // @Failure 400 {object} response.ErrorResponse "Validation error"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires services:write permission and membership of the team"
+// @Failure 409 {object} response.ErrorResponse "Request with this Idempotency-Key in progress"
+// @Failure 422 {object} response.ErrorResponse "Idempotency-Key reused with a different request"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
 // @Router /services [post]
 func (h *ServiceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesWrite) {
+		return
+	}
+
 	var req domain.CreateServiceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.BadRequest(w, "invalid request body")
@@ -68,15 +79,24 @@ type ServiceListResponse struct {
 // @Param limit query int false "Items per page (max 100)" default(20)
 // @Param search query string false "Search in name and description"
 // @Param name query string false "Filter by exact name"
+// @Param team query string false "Filter by ID of the owning team"
 // @Param sort query string false "Sort field (name, created_at, updated_at)" default(created_at)
 // @Param order query string false "Sort order (asc, desc)" default(desc)
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} ServiceListResponse "List of services with pagination"
+// @Header 200 {string} ETag "Entity tag of the representation"
+// @Success 304 "Not modified"
 // @Failure 400 {object} response.ErrorResponse "Invalid parameters"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires services:read permission"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
 // @Router /services [get]
 func (h *ServiceHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesRead) {
+		return
+	}
+
 	params := ParseListParams(r)
 
 	result, err := h.service.List(r.Context(), params)
@@ -91,7 +111,7 @@ func (h *ServiceHandler) List(w http.ResponseWriter, r *http.Request) {
 		serviceResponses[i] = svc.ToResponse()
 	}
 
-	response.OK(w, map[string]interface{}{
+	response.Conditional(w, r, serviceListValidators(result), map[string]interface{}{
 		"data":       serviceResponses,
 		"pagination": result.Pagination,
 	})
@@ -104,14 +124,24 @@ func (h *ServiceHandler) List(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Service ID (MongoDB ObjectID)"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
 // @Success 200 {object} domain.ServiceResponse "Service details"
+// @Header 200 {string} ETag "Entity tag of the representation"
+// @Header 200 {string} Last-Modified "Last modification time"
+// @Success 304 "Not modified"
 // @Failure 400 {object} response.ErrorResponse "Invalid ID format"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires services:read permission"
 // @Failure 404 {object} response.ErrorResponse "Service not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
 // @Router /services/{id} [get]
 func (h *ServiceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesRead) {
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		response.BadRequest(w, "service id is required")
@@ -124,12 +154,12 @@ func (h *ServiceHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.OK(w, svc.ToResponse())
+	response.Conditional(w, r, serviceValidators(svc), svc.ToResponse())
 }
 
 // Update handles PUT /api/v1/services/{id}
 // @Summary Update a service
-// @Description Full update of a service. All fields are required. Revision is automatically incremented.
+// @Description Full update of a service. All fields are required except team_id; leaving it out removes the service from its team. Revision is automatically incremented. Services of a team can only be changed by its members and holders of services:admin.
 // @Tags services
 // @Accept json
 // @Produce json
@@ -138,11 +168,16 @@ func (h *ServiceHandler) Get(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} domain.ServiceResponse "Updated service"
 // @Failure 400 {object} response.ErrorResponse "Validation error"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires services:write permission and membership of the team"
 // @Failure 404 {object} response.ErrorResponse "Service not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
 // @Router /services/{id} [put]
 func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesWrite) {
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		response.BadRequest(w, "service id is required")
@@ -166,7 +201,7 @@ func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 // Patch handles PATCH /api/v1/services/{id}
 // @Summary Partially update a service
-// @Description Partial update of a service. Only provided fields are updated. Revision is automatically incremented.
+// @Description Partial update of a service. Only provided fields are updated; an empty team_id removes the service from its team. Revision is automatically incremented. Services of a team can only be changed by its members and holders of services:admin.
 // @Tags services
 // @Accept json
 // @Produce json
@@ -175,11 +210,16 @@ func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} domain.ServiceResponse "Updated service"
 // @Failure 400 {object} response.ErrorResponse "Validation error"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires services:write permission and membership of the team"
 // @Failure 404 {object} response.ErrorResponse "Service not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
 // @Router /services/{id} [patch]
 func (h *ServiceHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesWrite) {
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		response.BadRequest(w, "service id is required")
@@ -211,11 +251,16 @@ func (h *ServiceHandler) Patch(w http.ResponseWriter, r *http.Request) {
 // @Success 204 "Service deleted successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid ID format"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires services:admin permission"
 // @Failure 404 {object} response.ErrorResponse "Service not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
 // @Router /services/{id} [delete]
 func (h *ServiceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesAdmin) {
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		response.BadRequest(w, "service id is required")
@@ -247,6 +292,11 @@ func (h *ServiceHandler) handleError(w http.ResponseWriter, err error) {
 		return
 	}
 
+	if errors.Is(err, domain.ErrTeamAccessDenied) {
+		response.Forbidden(w, err.Error())
+		return
+	}
+
 	response.InternalServerError(w, "internal server error")
 }
 
@@ -265,14 +315,22 @@ type VersionListResponse struct {
 // @Param id path string true "Service ID (MongoDB ObjectID)"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page (max 100)" default(20)
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} VersionListResponse "List of versions with pagination"
+// @Header 200 {string} ETag "Entity tag of the representation"
+// @Success 304 "Not modified"
 // @Failure 400 {object} response.ErrorResponse "Invalid ID format"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires services:read permission"
 // @Failure 404 {object} response.ErrorResponse "Service not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
 // @Router /services/{id}/versions [get]
 func (h *ServiceHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesRead) {
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		response.BadRequest(w, "service id is required")
@@ -293,7 +351,7 @@ func (h *ServiceHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
 		versionResponses[i] = v.ToResponse()
 	}
 
-	response.OK(w, map[string]interface{}{
+	response.Conditional(w, r, versionListValidators(result), map[string]interface{}{
 		"data":       versionResponses,
 		"pagination": result.Pagination,
 	})
@@ -307,14 +365,22 @@ func (h *ServiceHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path string true "Service ID (MongoDB ObjectID)"
 // @Param revision path int true "Revision number"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} domain.ServiceVersionResponse "Version details"
+// @Header 200 {string} ETag "Entity tag of the representation"
+// @Success 304 "Not modified"
 // @Failure 400 {object} response.ErrorResponse "Invalid ID or revision format"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires services:read permission"
 // @Failure 404 {object} response.ErrorResponse "Version not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
 // @Router /services/{id}/versions/{revision} [get]
 func (h *ServiceHandler) GetVersion(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesRead) {
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		response.BadRequest(w, "service id is required")
@@ -339,7 +405,41 @@ func (h *ServiceHandler) GetVersion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.OK(w, version.ToResponse())
+	response.Conditional(w, r, versionValidators(version), version.ToResponse())
+}
+
+// VerifyVersions handles GET /api/v1/services/{id}/versions/verify
+// @Summary Verify the version history of a service
+// @Description Walk the hash chain of a service's versions and report the oldest broken link, if any. Each version stores a SHA-256 hash over its content and the hash of the previous revision, so a version edited or removed in the database breaks the chain. Versions created before hashing was introduced are counted as unhashed.
+// @Tags versions
+// @Produce json
+// @Param id path string true "Service ID (MongoDB ObjectID)"
+// @Success 200 {object} domain.ChainVerification "Verification result"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - requires services:read permission"
+// @Failure 404 {object} response.ErrorResponse "Service not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /services/{id}/versions/verify [get]
+func (h *ServiceHandler) VerifyVersions(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesRead) {
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.BadRequest(w, "service id is required")
+		return
+	}
+
+	result, err := h.service.VerifyVersions(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
 }
 
 // handleVersionError handles errors from the service layer for version endpoints
@@ -354,6 +454,11 @@ func (h *ServiceHandler) handleVersionError(w http.ResponseWriter, err error) {
 		return
 	}
 
+	if errors.Is(err, domain.ErrTeamAccessDenied) {
+		response.Forbidden(w, err.Error())
+		return
+	}
+
 	response.InternalServerError(w, "internal server error")
 }
 
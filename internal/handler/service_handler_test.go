@@ -14,16 +14,38 @@ import (
 	"github.com/services-api/internal/handler"
 	"github.com/services-api/internal/repository/mocks"
 	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// testAuthorizer grants every permission except those listed in deny
+type testAuthorizer struct {
+	deny []string
+}
+
+func (a testAuthorizer) Authorize(ctx context.Context, permissions ...string) error {
+	var missing []string
+	for _, permission := range permissions {
+		for _, denied := range a.deny {
+			if permission == denied {
+				missing = append(missing, permission)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return &auth.PermissionError{Missing: missing}
+	}
+	return nil
+}
+
 func setupServiceHandler() (*handler.ServiceHandler, *mocks.MockServiceRepository, *mocks.MockServiceVersionRepository) {
 	serviceRepo := mocks.NewMockServiceRepository()
 	versionRepo := mocks.NewMockServiceVersionRepository()
-	svc := service.NewServiceService(serviceRepo, versionRepo)
-	h := handler.NewServiceHandler(svc)
+	teams := service.NewTeamService(mocks.NewMockTeamRepository(), mocks.NewMockUserRepository(), serviceRepo, testAuthorizer{}, nil)
+	svc := service.NewServiceService(serviceRepo, versionRepo, teams, nil)
+	h := handler.NewServiceHandler(svc, testAuthorizer{})
 	return h, serviceRepo, versionRepo
 }
 
@@ -337,6 +359,31 @@ func TestServiceHandler_Delete(t *testing.T) {
 	}
 }
 
+func TestServiceHandler_Delete_MissingPermission(t *testing.T) {
+	serviceRepo := mocks.NewMockServiceRepository()
+	svc := &domain.Service{ID: primitive.NewObjectID(), Name: "test-service", Description: "Test description"}
+	serviceRepo.AddService(svc)
+	h := handler.NewServiceHandler(
+		service.NewServiceService(serviceRepo, mocks.NewMockServiceVersionRepository(),
+			service.NewTeamService(mocks.NewMockTeamRepository(), mocks.NewMockUserRepository(), serviceRepo, testAuthorizer{}, nil), nil),
+		testAuthorizer{deny: []string{auth.ScopeServicesAdmin}},
+	)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/services/"+svc.ID.Hex(), nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", svc.ID.Hex())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	h.Delete(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "missing required permission: services:admin")
+
+	_, err := serviceRepo.GetByID(context.Background(), svc.ID.Hex())
+	assert.NoError(t, err)
+}
+
 func TestServiceHandler_List(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -409,3 +456,110 @@ func TestServiceHandler_List(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceHandler_ConditionalGet(t *testing.T) {
+	h, serviceRepo, _ := setupServiceHandler()
+	svc := &domain.Service{
+		ID:          primitive.NewObjectID(),
+		Name:        "test-service",
+		Description: "Test description",
+		Revision:    3,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	serviceRepo.AddService(svc)
+	id := svc.ID.Hex()
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/services/"+id, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		w := httptest.NewRecorder()
+		h.Get(w, req)
+		return w
+	}
+
+	first := get("")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, `"3"`, first.Header().Get("ETag"))
+	assert.NotEmpty(t, first.Header().Get("Last-Modified"))
+
+	notModified := get(`"3"`)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+
+	assert.Equal(t, http.StatusOK, get(`"2"`).Code)
+
+	// List ETags change when a service on the page changes
+	list := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/services", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		h.List(w, req)
+		return w
+	}
+
+	listed := list("")
+	etag := listed.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Empty(t, listed.Header().Get("Last-Modified"))
+	assert.Equal(t, http.StatusNotModified, list(etag).Code)
+
+	svc.Revision = 4
+	assert.Equal(t, http.StatusOK, list(etag).Code)
+}
+
+func TestServiceHandler_ConditionalGetVersion(t *testing.T) {
+	h, serviceRepo, versionRepo := setupServiceHandler()
+	svc := &domain.Service{
+		ID:        primitive.NewObjectID(),
+		Name:      "test-service",
+		Revision:  1,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	serviceRepo.AddService(svc)
+	author := primitive.NewObjectID()
+	versionRepo.AddVersion(domain.NewServiceVersion(svc, &author))
+	id := svc.ID.Hex()
+
+	get := func(path, ifNoneMatch string, handle http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/services/"+id+path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		rctx.URLParams.Add("revision", "1")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		w := httptest.NewRecorder()
+		handle(w, req)
+		return w
+	}
+
+	version := get("/versions/1", "", h.GetVersion)
+	require.Equal(t, http.StatusOK, version.Code)
+	etag := version.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "private, no-cache", version.Header().Get("Cache-Control"))
+	assert.Empty(t, version.Header().Get("Last-Modified"))
+	assert.Equal(t, http.StatusNotModified, get("/versions/1", etag, h.GetVersion).Code)
+
+	list := get("/versions", "", h.ListVersions)
+	require.Equal(t, http.StatusOK, list.Code)
+	listETag := list.Header().Get("ETag")
+	assert.Equal(t, http.StatusNotModified, get("/versions", listETag, h.ListVersions).Code)
+
+	// Anonymizing the author rewrites the version, so cached copies are stale
+	require.NoError(t, versionRepo.AnonymizeAuthor(context.Background(), author.Hex()))
+	assert.Equal(t, http.StatusOK, get("/versions/1", etag, h.GetVersion).Code)
+	assert.Equal(t, http.StatusOK, get("/versions", listETag, h.ListVersions).Code)
+}
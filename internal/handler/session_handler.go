@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/response"
+)
+
+// SessionHandler handles session management HTTP requests
+type SessionHandler struct {
+	sessionService *service.SessionService
+	authorizer     auth.Authorizer
+}
+
+// NewSessionHandler creates a new SessionHandler
+func NewSessionHandler(sessionService *service.SessionService, authorizer auth.Authorizer) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		authorizer:     authorizer,
+	}
+}
+
+// SessionListResponse represents the response for listing sessions
+type SessionListResponse struct {
+	Data []domain.SessionResponse `json:"data"`
+}
+
+// ListMine handles GET /api/v1/users/me/sessions
+// @Summary List current user's sessions
+// @Description List the active sessions (logins) of the currently authenticated user
+// @Tags sessions
+// @Produce json
+// @Success 200 {object} SessionListResponse "Active sessions"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/me/sessions [get]
+func (h *SessionHandler) ListMine(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "authentication required")
+		return
+	}
+
+	h.list(w, r, userID)
+}
+
+// RevokeMine handles DELETE /api/v1/users/me/sessions/{sessionId}
+// @Summary Revoke one of current user's sessions
+// @Description Revoke a session of the currently authenticated user, invalidating its access and refresh tokens
+// @Tags sessions
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 204 "Session revoked"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Session not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/me/sessions/{sessionId} [delete]
+func (h *SessionHandler) RevokeMine(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "authentication required")
+		return
+	}
+
+	if err := h.sessionService.Revoke(r.Context(), userID, chi.URLParam(r, "sessionId")); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// List handles GET /api/v1/users/{id}/sessions
+// @Summary List a user's sessions
+// @Description List the active sessions of any user. Requires the users:read permission.
+// @Tags sessions
+// @Produce json
+// @Param id path string true "User ID (MongoDB ObjectID)"
+// @Success 200 {object} SessionListResponse "Active sessions"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/{id}/sessions [get]
+func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersRead) {
+		return
+	}
+
+	h.list(w, r, chi.URLParam(r, "id"))
+}
+
+// Revoke handles DELETE /api/v1/users/{id}/sessions/{sessionId}
+// @Summary Revoke a user's session
+// @Description Revoke a session of any user, invalidating its access and refresh tokens. Requires the users:admin permission.
+// @Tags sessions
+// @Produce json
+// @Param id path string true "User ID (MongoDB ObjectID)"
+// @Param sessionId path string true "Session ID"
+// @Success 204 "Session revoked"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 404 {object} response.ErrorResponse "Session not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/{id}/sessions/{sessionId} [delete]
+func (h *SessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	if err := h.sessionService.Revoke(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "sessionId")); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// RevokeAll handles DELETE /api/v1/users/{id}/sessions
+// @Summary Revoke all of a user's sessions
+// @Description Revoke every session of any user, invalidating all their access and refresh tokens. Requires the users:admin permission.
+// @Tags sessions
+// @Produce json
+// @Param id path string true "User ID (MongoDB ObjectID)"
+// @Success 204 "Sessions revoked"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/{id}/sessions [delete]
+func (h *SessionHandler) RevokeAll(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	if err := h.sessionService.RevokeAll(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// list writes the active sessions of a user, flagging the caller's own session
+func (h *SessionHandler) list(w http.ResponseWriter, r *http.Request, userID string) {
+	sessions, err := h.sessionService.List(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	currentSessionID, _ := auth.GetSessionID(r.Context())
+	sessionResponses := make([]domain.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		sessionResponses[i] = session.ToResponse(currentSessionID)
+	}
+
+	response.OK(w, SessionListResponse{Data: sessionResponses})
+}
+
+// handleError handles errors from the session service
+func (h *SessionHandler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSessionNotFound):
+		response.NotFound(w, "session not found")
+	case errors.Is(err, domain.ErrInvalidID):
+		response.BadRequest(w, "invalid user id format")
+	default:
+		response.InternalServerError(w, "internal server error")
+	}
+}
@@ -0,0 +1,319 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func setupSessionHandler(authorizer auth.Authorizer) (*handler.SessionHandler, *service.SessionService, *mocks.MockSessionRepository) {
+	sessionRepo := mocks.NewMockSessionRepository()
+	sessions := service.NewSessionService(sessionRepo, mocks.NewMockRefreshTokenRepository(), nil)
+	return handler.NewSessionHandler(sessions, authorizer), sessions, sessionRepo
+}
+
+// startTestSession starts a session of the user
+func startTestSession(sessions *service.SessionService, userID primitive.ObjectID) *domain.Session {
+	client := domain.ClientInfo{UserAgent: "test-agent", IPAddress: "203.0.113.7"}
+	session, err := sessions.Start(context.Background(), userID, client, time.Now().Add(time.Hour))
+	if err != nil {
+		panic(err)
+	}
+	return session
+}
+
+func TestSessionHandler_ListMine(t *testing.T) {
+	h, sessions, _ := setupSessionHandler(testAuthorizer{})
+	userID := primitive.NewObjectID()
+	current := startTestSession(sessions, userID)
+	startTestSession(sessions, userID)
+	startTestSession(sessions, primitive.NewObjectID())
+
+	req := asUser(httptest.NewRequest(http.MethodGet, "/api/v1/users/me/sessions", nil), userID.Hex())
+	req = req.WithContext(context.WithValue(req.Context(), auth.SessionIDKey, current.ID))
+	w := httptest.NewRecorder()
+
+	h.ListMine(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp handler.SessionListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 2)
+	for _, session := range resp.Data {
+		assert.Equal(t, session.ID == current.ID, session.Current)
+	}
+
+	// Requests without a user, like those made with API keys, have no sessions
+	w = httptest.NewRecorder()
+	h.ListMine(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/me/sessions", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSessionHandler_RevokeMine(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupRepo      func(repo *mocks.MockSessionRepository, sessions *service.SessionService, caller primitive.ObjectID) string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "own session",
+			setupRepo: func(repo *mocks.MockSessionRepository, sessions *service.SessionService, caller primitive.ObjectID) string {
+				return startTestSession(sessions, caller).ID
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "session of another user",
+			setupRepo: func(repo *mocks.MockSessionRepository, sessions *service.SessionService, caller primitive.ObjectID) string {
+				return startTestSession(sessions, primitive.NewObjectID()).ID
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "session not found",
+		},
+		{
+			name: "already revoked",
+			setupRepo: func(repo *mocks.MockSessionRepository, sessions *service.SessionService, caller primitive.ObjectID) string {
+				session := startTestSession(sessions, caller)
+				require.NoError(t, repo.Revoke(context.Background(), session.ID))
+				return session.ID
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "session not found",
+		},
+		{
+			name: "unknown session",
+			setupRepo: func(repo *mocks.MockSessionRepository, sessions *service.SessionService, caller primitive.ObjectID) string {
+				return "unknown"
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "session not found",
+		},
+		{
+			name: "repository failure",
+			setupRepo: func(repo *mocks.MockSessionRepository, sessions *service.SessionService, caller primitive.ObjectID) string {
+				repo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Session, error) {
+					return nil, errors.New("connection reset")
+				}
+				return "any"
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, sessions, sessionRepo := setupSessionHandler(testAuthorizer{})
+			caller := primitive.NewObjectID()
+			sessionID := tt.setupRepo(sessionRepo, sessions, caller)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/sessions/"+sessionID, nil)
+			req = withURLParams(asUser(req, caller.Hex()), "sessionId", sessionID)
+			w := httptest.NewRecorder()
+
+			h.RevokeMine(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestSessionHandler_Revoke(t *testing.T) {
+	tests := []struct {
+		name           string
+		authorizer     auth.Authorizer
+		ofPathUser     bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "session of the user",
+			authorizer:     testAuthorizer{},
+			ofPathUser:     true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "session of a different user",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "session not found",
+		},
+		{
+			name:           "missing permission",
+			authorizer:     nonAdmin,
+			ofPathUser:     true,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, sessions, _ := setupSessionHandler(tt.authorizer)
+			userID := primitive.NewObjectID()
+			owner := primitive.NewObjectID()
+			if tt.ofPathUser {
+				owner = userID
+			}
+			sessionID := startTestSession(sessions, owner).ID
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/"+userID.Hex()+"/sessions/"+sessionID, nil)
+			req = withURLParams(req, "id", userID.Hex(), "sessionId", sessionID)
+			w := httptest.NewRecorder()
+
+			h.Revoke(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestSessionHandler_RevokeAll(t *testing.T) {
+	tests := []struct {
+		name           string
+		authorizer     auth.Authorizer
+		setupRepo      func(repo *mocks.MockSessionRepository)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "successful revocation",
+			authorizer:     testAuthorizer{},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "missing permission",
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeUsersAdmin,
+		},
+		{
+			name:       "invalid id",
+			authorizer: testAuthorizer{},
+			setupRepo: func(repo *mocks.MockSessionRepository) {
+				repo.RevokeAllForUserFunc = func(ctx context.Context, userID string) error {
+					return domain.ErrInvalidID
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid user id format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, sessions, sessionRepo := setupSessionHandler(tt.authorizer)
+			if tt.setupRepo != nil {
+				tt.setupRepo(sessionRepo)
+			}
+			userID := primitive.NewObjectID()
+			startTestSession(sessions, userID)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/"+userID.Hex()+"/sessions", nil)
+			req = withURLParams(req, "id", userID.Hex())
+			w := httptest.NewRecorder()
+
+			h.RevokeAll(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+
+			active, err := sessions.List(context.Background(), userID.Hex())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus == http.StatusNoContent, len(active) == 0)
+		})
+	}
+}
+
+func TestSessionHandler_RouteGuards(t *testing.T) {
+	h, sessions, _ := setupSessionHandler(testAuthorizer{})
+	caller := primitive.NewObjectID()
+	own := startTestSession(sessions, caller)
+	other := primitive.NewObjectID().Hex()
+	router := newTestRouter(routerHandlers{session: h})
+
+	runRouteGuardTests(t, router, caller.Hex(), []routeGuardTest{
+		{
+			name:           "own sessions need no scope",
+			method:         http.MethodGet,
+			path:           "/api/v1/users/me/sessions",
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "own sessions while impersonating",
+			method:         http.MethodGet,
+			path:           "/api/v1/users/me/sessions",
+			scopes:         []string{auth.ScopeServicesRead},
+			impersonating:  true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "revoke own session",
+			method:         http.MethodDelete,
+			path:           "/api/v1/users/me/sessions/" + own.ID,
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "list a user's sessions without users:read",
+			method:         http.MethodGet,
+			path:           "/api/v1/users/" + other + "/sessions",
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersRead,
+		},
+		{
+			name:           "list a user's sessions",
+			method:         http.MethodGet,
+			path:           "/api/v1/users/" + other + "/sessions",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "revoke a user's sessions without users:admin",
+			method:         http.MethodDelete,
+			path:           "/api/v1/users/" + other + "/sessions",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersAdmin,
+		},
+		{
+			name:           "revoke a user's session without users:admin",
+			method:         http.MethodDelete,
+			path:           "/api/v1/users/" + other + "/sessions/any",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeUsersAdmin,
+		},
+		{
+			name:           "revoke a user's sessions",
+			method:         http.MethodDelete,
+			path:           "/api/v1/users/" + other + "/sessions",
+			scopes:         []string{auth.ScopeUsersAdmin},
+			expectedStatus: http.StatusNoContent,
+		},
+	})
+}
@@ -0,0 +1,294 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/response"
+)
+
+// TeamHandler handles team and team membership HTTP requests
+type TeamHandler struct {
+	teamService *service.TeamService
+	authorizer  auth.Authorizer
+}
+
+// NewTeamHandler creates a new TeamHandler
+func NewTeamHandler(teamService *service.TeamService, authorizer auth.Authorizer) *TeamHandler {
+	return &TeamHandler{
+		teamService: teamService,
+		authorizer:  authorizer,
+	}
+}
+
+// TeamListResponse represents the response for listing teams
+type TeamListResponse struct {
+	Data       []domain.TeamResponse     `json:"data"`
+	Pagination domain.PaginationMetadata `json:"pagination"`
+}
+
+// Create handles POST /api/v1/teams
+// @Summary Create a team
+// @Description Create a team with the caller as its first maintainer. Requires the services:write permission.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateTeamRequest true "Team creation request"
+// @Param Idempotency-Key header string false "Client-generated key that makes retries safe"
+// @Success 201 {object} domain.TeamResponse "Created team"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 409 {object} response.ErrorResponse "Team name taken or Idempotency-Key in progress"
+// @Failure 422 {object} response.ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams [post]
+func (h *TeamHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesWrite) {
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "authentication required")
+		return
+	}
+
+	var req domain.CreateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	team, err := h.teamService.Create(r.Context(), userID, req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.Created(w, team.ToResponse())
+}
+
+// List handles GET /api/v1/teams
+// @Summary List teams
+// @Description Get a paginated list of teams and their members, ordered by name. Requires the services:read permission.
+// @Tags teams
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Success 200 {object} TeamListResponse "List of teams with pagination"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams [get]
+func (h *TeamHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesRead) {
+		return
+	}
+
+	result, err := h.teamService.List(r.Context(), ParsePaginationParams(r))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	teamResponses := make([]domain.TeamResponse, len(result.Data))
+	for i, team := range result.Data {
+		teamResponses[i] = team.ToResponse()
+	}
+
+	response.OK(w, TeamListResponse{
+		Data:       teamResponses,
+		Pagination: result.Pagination,
+	})
+}
+
+// Get handles GET /api/v1/teams/{id}
+// @Summary Get a team
+// @Description Get a team and its members. Requires the services:read permission.
+// @Tags teams
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} domain.TeamResponse "Team details"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 404 {object} response.ErrorResponse "Team not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams/{id} [get]
+func (h *TeamHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesRead) {
+		return
+	}
+
+	team, err := h.teamService.GetByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, team.ToResponse())
+}
+
+// Update handles PUT /api/v1/teams/{id}
+// @Summary Update a team
+// @Description Rename a team and change its description. Requires the services:write permission, and being a maintainer of the team or holding users:admin.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param request body domain.UpdateTeamRequest true "Team update request"
+// @Success 200 {object} domain.TeamResponse "Updated team"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - not a maintainer of the team"
+// @Failure 404 {object} response.ErrorResponse "Team not found"
+// @Failure 409 {object} response.ErrorResponse "Team name taken"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams/{id} [put]
+func (h *TeamHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesWrite) {
+		return
+	}
+
+	var req domain.UpdateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	team, err := h.teamService.Update(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, team.ToResponse())
+}
+
+// Delete handles DELETE /api/v1/teams/{id}
+// @Summary Delete a team
+// @Description Delete a team that owns no services. Requires the services:write permission, and being a maintainer of the team or holding users:admin.
+// @Tags teams
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 204 "Team deleted"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - not a maintainer of the team"
+// @Failure 404 {object} response.ErrorResponse "Team not found"
+// @Failure 409 {object} response.ErrorResponse "Team owns services"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams/{id} [delete]
+func (h *TeamHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesWrite) {
+		return
+	}
+
+	if err := h.teamService.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// SetMember handles PUT /api/v1/teams/{id}/members/{userId}
+// @Summary Add a team member or change their role
+// @Description Add a user to a team or change their team role (maintainer or member, default member). Requires the services:write permission, and being a maintainer of the team or holding users:admin.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param userId path string true "User ID"
+// @Param request body domain.SetTeamMemberRequest true "Team membership"
+// @Success 200 {object} domain.TeamResponse "Updated team"
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - not a maintainer of the team"
+// @Failure 404 {object} response.ErrorResponse "Team or user not found"
+// @Failure 409 {object} response.ErrorResponse "The team would have no maintainer"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams/{id}/members/{userId} [put]
+func (h *TeamHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesWrite) {
+		return
+	}
+
+	var req domain.SetTeamMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	team, err := h.teamService.SetMember(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "userId"), req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, team.ToResponse())
+}
+
+// RemoveMember handles DELETE /api/v1/teams/{id}/members/{userId}
+// @Summary Remove a team member
+// @Description Remove a user from a team. Requires the services:write permission, and being a maintainer of the team or holding users:admin. Members can leave a team themselves.
+// @Tags teams
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param userId path string true "User ID"
+// @Success 204 "Member removed"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - not a maintainer of the team"
+// @Failure 404 {object} response.ErrorResponse "Team not found or user not a member"
+// @Failure 409 {object} response.ErrorResponse "The team would have no maintainer"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams/{id}/members/{userId} [delete]
+func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeServicesWrite) {
+		return
+	}
+
+	if err := h.teamService.RemoveMember(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "userId")); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// handleError handles errors from the team service
+func (h *TeamHandler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrTeamNotFound),
+		errors.Is(err, domain.ErrTeamMemberNotFound),
+		errors.Is(err, domain.ErrUserNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, domain.ErrTeamAccessDenied):
+		response.Forbidden(w, "only maintainers of the team can do this")
+	case errors.Is(err, domain.ErrTeamExists),
+		errors.Is(err, domain.ErrTeamHasServices),
+		errors.Is(err, domain.ErrLastMaintainer):
+		response.Conflict(w, err.Error())
+	case errors.Is(err, domain.ErrInvalidID),
+		errors.Is(err, domain.ErrTeamNameInvalid),
+		errors.Is(err, domain.ErrTeamDescriptionTooLong),
+		errors.Is(err, domain.ErrInvalidTeamRole):
+		response.BadRequest(w, err.Error())
+	default:
+		response.InternalServerError(w, "internal server error")
+	}
+}
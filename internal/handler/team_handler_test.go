@@ -0,0 +1,613 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/handler"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// teamFixture is a team with one maintainer and one member, and a user outside it
+type teamFixture struct {
+	handler     *handler.TeamHandler
+	teamRepo    *mocks.MockTeamRepository
+	serviceRepo *mocks.MockServiceRepository
+	auditRepo   *mocks.MockAuditRepository
+	team        *domain.Team
+	maintainer  *domain.User
+	member      *domain.User
+	outsider    *domain.User
+}
+
+func setupTeamHandler(authorizer auth.Authorizer) *teamFixture {
+	teamRepo := mocks.NewMockTeamRepository()
+	userRepo := mocks.NewMockUserRepository()
+	serviceRepo := mocks.NewMockServiceRepository()
+	auditRepo := mocks.NewMockAuditRepository()
+	teams := service.NewTeamService(teamRepo, userRepo, serviceRepo, authorizer, service.NewAuditService(auditRepo))
+
+	f := &teamFixture{
+		handler:     handler.NewTeamHandler(teams, authorizer),
+		teamRepo:    teamRepo,
+		serviceRepo: serviceRepo,
+		auditRepo:   auditRepo,
+		maintainer:  addTestUser(userRepo, domain.RoleUser),
+		member:      addTestUser(userRepo, domain.RoleUser),
+		outsider:    addTestUser(userRepo, domain.RoleUser),
+	}
+	f.team = &domain.Team{
+		Name: "payments",
+		Members: []domain.TeamMember{
+			{UserID: f.maintainer.ID, Role: domain.TeamRoleMaintainer},
+			{UserID: f.member.ID, Role: domain.TeamRoleMember},
+		},
+	}
+	if err := teamRepo.Create(context.Background(), f.team); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// caller returns the fixture user with the given team role, or the outsider
+func (f *teamFixture) caller(role string) *domain.User {
+	switch role {
+	case domain.TeamRoleMaintainer:
+		return f.maintainer
+	case domain.TeamRoleMember:
+		return f.member
+	default:
+		return f.outsider
+	}
+}
+
+func TestTeamHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		authorizer     auth.Authorizer
+		noUser         bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "successful creation",
+			requestBody:    domain.CreateTeamRequest{Name: "search", Description: "Owns the search services"},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusCreated,
+			expectedError:  `"role":"maintainer"`,
+		},
+		{
+			name:           "existing name",
+			requestBody:    domain.CreateTeamRequest{Name: "payments"},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusConflict,
+			expectedError:  "team already exists",
+		},
+		{
+			name:           "invalid name",
+			requestBody:    domain.CreateTeamRequest{Name: "Search Team"},
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "team name must be",
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    "invalid json",
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+		{
+			name:           "legacy API key",
+			requestBody:    domain.CreateTeamRequest{Name: "search"},
+			authorizer:     nonAdmin,
+			noUser:         true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing permission",
+			requestBody:    domain.CreateTeamRequest{Name: "search"},
+			authorizer:     testAuthorizer{deny: []string{auth.ScopeServicesWrite}},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeServicesWrite,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupTeamHandler(tt.authorizer)
+
+			var body []byte
+			if str, ok := tt.requestBody.(string); ok {
+				body = []byte(str)
+			} else {
+				body, _ = json.Marshal(tt.requestBody)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/teams", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if !tt.noUser {
+				req = asUser(req, f.outsider.ID.Hex())
+			}
+			w := httptest.NewRecorder()
+
+			f.handler.Create(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestTeamHandler_Get(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             func(f *teamFixture) string
+		authorizer     auth.Authorizer
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "successful retrieval",
+			id:             func(f *teamFixture) string { return f.team.ID.Hex() },
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusOK,
+			expectedError:  `"name":"payments"`,
+		},
+		{
+			name:           "not found",
+			id:             func(f *teamFixture) string { return primitive.NewObjectID().Hex() },
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "team not found",
+		},
+		{
+			name:           "invalid id",
+			id:             func(f *teamFixture) string { return "not-an-id" },
+			authorizer:     nonAdmin,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing permission",
+			id:             func(f *teamFixture) string { return f.team.ID.Hex() },
+			authorizer:     testAuthorizer{deny: []string{auth.ScopeServicesRead}},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeServicesRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupTeamHandler(tt.authorizer)
+			id := tt.id(f)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/teams/"+id, nil)
+			req = withURLParams(asUser(req, f.outsider.ID.Hex()), "id", id)
+			w := httptest.NewRecorder()
+
+			f.handler.Get(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestTeamHandler_List(t *testing.T) {
+	f := setupTeamHandler(nonAdmin)
+
+	req := asUser(httptest.NewRequest(http.MethodGet, "/api/v1/teams", nil), f.outsider.ID.Hex())
+	w := httptest.NewRecorder()
+
+	f.handler.List(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp handler.TeamListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "payments", resp.Data[0].Name)
+}
+
+func TestTeamHandler_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		caller         string
+		authorizer     auth.Authorizer
+		requestBody    interface{}
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "maintainer",
+			caller:         domain.TeamRoleMaintainer,
+			authorizer:     nonAdmin,
+			requestBody:    domain.UpdateTeamRequest{Name: "billing"},
+			expectedStatus: http.StatusOK,
+			expectedError:  `"name":"billing"`,
+		},
+		{
+			name:           "member",
+			caller:         domain.TeamRoleMember,
+			authorizer:     nonAdmin,
+			requestBody:    domain.UpdateTeamRequest{Name: "billing"},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "only maintainers of the team can do this",
+		},
+		{
+			name:           "outsider",
+			authorizer:     nonAdmin,
+			requestBody:    domain.UpdateTeamRequest{Name: "billing"},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "only maintainers of the team can do this",
+		},
+		{
+			name:           "outsider with users:admin",
+			authorizer:     testAuthorizer{},
+			requestBody:    domain.UpdateTeamRequest{Name: "billing"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid name",
+			caller:         domain.TeamRoleMaintainer,
+			authorizer:     nonAdmin,
+			requestBody:    domain.UpdateTeamRequest{Name: "-"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "team name must be",
+		},
+		{
+			name:           "invalid JSON",
+			caller:         domain.TeamRoleMaintainer,
+			authorizer:     nonAdmin,
+			requestBody:    "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+		{
+			name:           "missing permission",
+			caller:         domain.TeamRoleMaintainer,
+			authorizer:     testAuthorizer{deny: []string{auth.ScopeServicesWrite}},
+			requestBody:    domain.UpdateTeamRequest{Name: "billing"},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  auth.ScopeServicesWrite,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupTeamHandler(tt.authorizer)
+			id := f.team.ID.Hex()
+
+			var body []byte
+			if str, ok := tt.requestBody.(string); ok {
+				body = []byte(str)
+			} else {
+				body, _ = json.Marshal(tt.requestBody)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/teams/"+id, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = withURLParams(asUser(req, f.caller(tt.caller).ID.Hex()), "id", id)
+			w := httptest.NewRecorder()
+
+			f.handler.Update(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestTeamHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name           string
+		caller         string
+		ownsService    bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "maintainer",
+			caller:         domain.TeamRoleMaintainer,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "member",
+			caller:         domain.TeamRoleMember,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "only maintainers of the team can do this",
+		},
+		{
+			name:           "team owns services",
+			caller:         domain.TeamRoleMaintainer,
+			ownsService:    true,
+			expectedStatus: http.StatusConflict,
+			expectedError:  "team owns services",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupTeamHandler(nonAdmin)
+			id := f.team.ID.Hex()
+			if tt.ownsService {
+				f.serviceRepo.AddService(&domain.Service{Name: "checkout", TeamID: &f.team.ID})
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/teams/"+id, nil)
+			req = withURLParams(asUser(req, f.caller(tt.caller).ID.Hex()), "id", id)
+			w := httptest.NewRecorder()
+
+			f.handler.Delete(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestTeamHandler_SetMember(t *testing.T) {
+	tests := []struct {
+		name           string
+		caller         string
+		userID         func(f *teamFixture) string
+		requestBody    string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "add a member",
+			caller:         domain.TeamRoleMaintainer,
+			userID:         func(f *teamFixture) string { return f.outsider.ID.Hex() },
+			requestBody:    `{"role":"member"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "promote a member",
+			caller:         domain.TeamRoleMaintainer,
+			userID:         func(f *teamFixture) string { return f.member.ID.Hex() },
+			requestBody:    `{"role":"maintainer"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "demote the last maintainer",
+			caller:         domain.TeamRoleMaintainer,
+			userID:         func(f *teamFixture) string { return f.maintainer.ID.Hex() },
+			requestBody:    `{"role":"member"}`,
+			expectedStatus: http.StatusConflict,
+			expectedError:  "a team must keep at least one maintainer",
+		},
+		{
+			name:           "unknown user",
+			caller:         domain.TeamRoleMaintainer,
+			userID:         func(f *teamFixture) string { return primitive.NewObjectID().Hex() },
+			requestBody:    `{"role":"member"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "user not found",
+		},
+		{
+			name:           "invalid team role",
+			caller:         domain.TeamRoleMaintainer,
+			userID:         func(f *teamFixture) string { return f.outsider.ID.Hex() },
+			requestBody:    `{"role":"owner"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "team role must be maintainer or member",
+		},
+		{
+			name:           "member adds themselves as maintainer",
+			caller:         domain.TeamRoleMember,
+			userID:         func(f *teamFixture) string { return f.member.ID.Hex() },
+			requestBody:    `{"role":"maintainer"}`,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "only maintainers of the team can do this",
+		},
+		{
+			name:           "invalid JSON",
+			caller:         domain.TeamRoleMaintainer,
+			userID:         func(f *teamFixture) string { return f.outsider.ID.Hex() },
+			requestBody:    "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupTeamHandler(nonAdmin)
+			id, userID := f.team.ID.Hex(), tt.userID(f)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/teams/"+id+"/members/"+userID, bytes.NewReader([]byte(tt.requestBody)))
+			req.Header.Set("Content-Type", "application/json")
+			req = withURLParams(asUser(req, f.caller(tt.caller).ID.Hex()), "id", id, "userId", userID)
+			w := httptest.NewRecorder()
+
+			f.handler.SetMember(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestTeamHandler_RemoveMember(t *testing.T) {
+	tests := []struct {
+		name           string
+		caller         string
+		userID         func(f *teamFixture) string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "maintainer removes a member",
+			caller:         domain.TeamRoleMaintainer,
+			userID:         func(f *teamFixture) string { return f.member.ID.Hex() },
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "member leaves",
+			caller:         domain.TeamRoleMember,
+			userID:         func(f *teamFixture) string { return f.member.ID.Hex() },
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "member removes the maintainer",
+			caller:         domain.TeamRoleMember,
+			userID:         func(f *teamFixture) string { return f.maintainer.ID.Hex() },
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "only maintainers of the team can do this",
+		},
+		{
+			name:           "last maintainer leaves",
+			caller:         domain.TeamRoleMaintainer,
+			userID:         func(f *teamFixture) string { return f.maintainer.ID.Hex() },
+			expectedStatus: http.StatusConflict,
+			expectedError:  "a team must keep at least one maintainer",
+		},
+		{
+			name:           "not a member",
+			caller:         domain.TeamRoleMaintainer,
+			userID:         func(f *teamFixture) string { return f.outsider.ID.Hex() },
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "user is not a member of the team",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupTeamHandler(nonAdmin)
+			id, userID := f.team.ID.Hex(), tt.userID(f)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/teams/"+id+"/members/"+userID, nil)
+			req = withURLParams(asUser(req, f.caller(tt.caller).ID.Hex()), "id", id, "userId", userID)
+			w := httptest.NewRecorder()
+
+			f.handler.RemoveMember(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestTeamHandler_RouteGuards(t *testing.T) {
+	f := setupTeamHandler(nonAdmin)
+	id := f.team.ID.Hex()
+	router := newTestRouter(routerHandlers{team: f.handler})
+
+	runRouteGuardTests(t, router, f.member.ID.Hex(), []routeGuardTest{
+		{
+			name:           "list without services:read",
+			method:         http.MethodGet,
+			path:           "/api/v1/teams",
+			scopes:         []string{auth.ScopeUsersRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeServicesRead,
+		},
+		{
+			name:           "get",
+			method:         http.MethodGet,
+			path:           "/api/v1/teams/" + id,
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "create without services:write",
+			method:         http.MethodPost,
+			path:           "/api/v1/teams",
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeServicesWrite,
+		},
+		{
+			name:           "update without services:write",
+			method:         http.MethodPut,
+			path:           "/api/v1/teams/" + id,
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeServicesWrite,
+		},
+		{
+			name:           "delete without services:write",
+			method:         http.MethodDelete,
+			path:           "/api/v1/teams/" + id,
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeServicesWrite,
+		},
+		{
+			name:           "set a member without services:write",
+			method:         http.MethodPut,
+			path:           "/api/v1/teams/" + id + "/members/" + f.outsider.ID.Hex(),
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeServicesWrite,
+		},
+		{
+			name:           "leave without services:write",
+			method:         http.MethodDelete,
+			path:           "/api/v1/teams/" + id + "/members/" + f.member.ID.Hex(),
+			scopes:         []string{auth.ScopeServicesRead},
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "missing required scope: " + auth.ScopeServicesWrite,
+		},
+		{
+			name:           "leave",
+			method:         http.MethodDelete,
+			path:           "/api/v1/teams/" + id + "/members/" + f.member.ID.Hex(),
+			scopes:         []string{auth.ScopeServicesWrite},
+			expectedStatus: http.StatusNoContent,
+		},
+	})
+}
+
+func TestTeamHandler_ImpersonatedChangeRecordsImpersonator(t *testing.T) {
+	f := setupTeamHandler(nonAdmin)
+	router := newTestRouter(routerHandlers{team: f.handler})
+
+	admin := primitive.NewObjectID().Hex()
+	token, err := testJWTManager.GenerateImpersonationToken(f.maintainer.ID.Hex(), f.maintainer.Email, domain.RoleUser, "",
+		[]string{auth.ScopeServicesWrite}, jwt.Actor{Subject: admin, Email: "admin@example.com"}, time.Minute)
+	require.NoError(t, err)
+
+	body, _ := json.Marshal(domain.UpdateTeamRequest{Name: "billing"})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/teams/"+f.team.ID.Hex(), bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// The change is attributed to the impersonated user, made by the admin
+	events := f.auditRepo.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, domain.AuditActionTeamUpdate, events[0].Action)
+	assert.Equal(t, f.maintainer.ID.Hex(), events[0].ActorID)
+	assert.Equal(t, admin, events[0].ImpersonatorID)
+	assert.Equal(t, string(auth.AuthTypeJWT), events[0].AuthType)
+}
@@ -14,13 +14,21 @@ import (
 
 // UserHandler handles user management HTTP requests
 type UserHandler struct {
-	userService *service.UserService
+	userService   *service.UserService
+	teamService   *service.TeamService
+	personalData  *service.PersonalDataService
+	impersonation *service.ImpersonationService
+	authorizer    auth.Authorizer
 }
 
 // NewUserHandler creates a new UserHandler
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, teamService *service.TeamService, personalData *service.PersonalDataService, impersonation *service.ImpersonationService, authorizer auth.Authorizer) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:   userService,
+		teamService:   teamService,
+		personalData:  personalData,
+		impersonation: impersonation,
+		authorizer:    authorizer,
 	}
 }
 
@@ -31,24 +39,24 @@ type UserListResponse struct {
 }
 
 // Create handles POST /api/v1/users
-// @Summary Create a new user (Admin only)
-// @Description Create a new user account. Requires admin role.
+// @Summary Create a new user
+// @Description Create a new user account. Requires the users:admin permission.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param request body domain.CreateUserRequest true "User creation request"
+// @Param Idempotency-Key header string false "Client-generated key that makes retries safe"
 // @Success 201 {object} domain.UserResponse "Created user"
-// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 400 {object} handler.PasswordPolicyErrorResponse "Validation error, or password policy violations"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
-// @Failure 403 {object} response.ErrorResponse "Forbidden - Admin only"
-// @Failure 409 {object} response.ErrorResponse "Email already exists"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 409 {object} response.ErrorResponse "Email already exists or Idempotency-Key in progress"
+// @Failure 422 {object} response.ErrorResponse "Idempotency-Key reused with a different request"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Router /users [post]
 func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
-	// Check admin role
-	if !h.isAdmin(r) {
-		response.Forbidden(w, "admin access required")
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
 		return
 	}
 
@@ -68,27 +76,32 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 // List handles GET /api/v1/users
-// @Summary List all users (Admin only)
-// @Description Get a paginated list of all users. Requires admin role.
+// @Summary List all users
+// @Description Get a paginated list of users with search, filtering and sorting. Requires the users:read permission.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page (max 100)" default(20)
+// @Param q query string false "Search the first name, last name and email (every word must match)"
+// @Param role query string false "Filter by role"
+// @Param active query bool false "Filter by whether the account is active"
+// @Param created_after query string false "Only users created after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param sort query string false "Sort field (name, email, created_at, last_login)" default(created_at)
+// @Param order query string false "Sort order (asc, desc)" default(desc)
 // @Success 200 {object} UserListResponse "List of users with pagination"
+// @Failure 400 {object} response.ErrorResponse "Invalid sort field"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
-// @Failure 403 {object} response.ErrorResponse "Forbidden - Admin only"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Router /users [get]
 func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
-	// Check admin role
-	if !h.isAdmin(r) {
-		response.Forbidden(w, "admin access required")
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersRead) {
 		return
 	}
 
-	params := ParsePaginationParams(r)
+	params := ParseUserListParams(r)
 
 	result, err := h.userService.List(r.Context(), params)
 	if err != nil {
@@ -110,7 +123,7 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 
 // Get handles GET /api/v1/users/{id}
 // @Summary Get a user by ID
-// @Description Get detailed information about a user. Users can view their own profile, admins can view any user.
+// @Description Get detailed information about a user. Users can view their own profile; viewing anyone else requires the users:read permission.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -130,10 +143,11 @@ func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if user can access this resource
-	if !h.canAccessUser(r, id) {
-		response.Forbidden(w, "access denied")
-		return
+	// Users can view their own profile, anyone else needs users:read
+	if currentUserID, _ := auth.GetUserID(r.Context()); currentUserID != id {
+		if !authorize(w, r, h.authorizer, auth.ScopeUsersRead) {
+			return
+		}
 	}
 
 	user, err := h.userService.GetByID(r.Context(), id)
@@ -146,8 +160,8 @@ func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
 }
 
 // Update handles PUT /api/v1/users/{id}
-// @Summary Update a user (Admin only)
-// @Description Update a user's information. Requires admin role.
+// @Summary Update a user
+// @Description Update a user's information. Requires the users:admin permission.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -156,16 +170,14 @@ func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} domain.UserResponse "Updated user"
 // @Failure 400 {object} response.ErrorResponse "Validation error"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
-// @Failure 403 {object} response.ErrorResponse "Forbidden - Admin only"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
 // @Failure 404 {object} response.ErrorResponse "User not found"
 // @Failure 409 {object} response.ErrorResponse "Email already exists"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Router /users/{id} [put]
 func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
-	// Check admin role
-	if !h.isAdmin(r) {
-		response.Forbidden(w, "admin access required")
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
 		return
 	}
 
@@ -191,8 +203,8 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 // Delete handles DELETE /api/v1/users/{id}
-// @Summary Delete a user (Admin only)
-// @Description Delete a user by ID. Requires admin role.
+// @Summary Delete a user
+// @Description Delete a user by ID. Requires the users:admin permission.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -200,15 +212,13 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 // @Success 204 "User deleted successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid ID format"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
-// @Failure 403 {object} response.ErrorResponse "Forbidden - Admin only"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
 // @Failure 404 {object} response.ErrorResponse "User not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Router /users/{id} [delete]
 func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	// Check admin role
-	if !h.isAdmin(r) {
-		response.Forbidden(w, "admin access required")
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
 		return
 	}
 
@@ -226,13 +236,76 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	response.NoContent(w)
 }
 
+// Unlock handles DELETE /api/v1/users/{id}/lockout
+// @Summary Unlock a user's account
+// @Description Lift the lockout of a user's account after too many failed logins and forget the failures. With ip, the lockout of that client IP is lifted too; otherwise IP lockouts only expire. Requires the users:admin permission.
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID (MongoDB ObjectID)"
+// @Param ip query string false "Client IP address to unlock as well"
+// @Success 204 "Account unlocked"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format or IP address"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - missing permission"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/{id}/lockout [delete]
+func (h *UserHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	if err := h.userService.Unlock(r.Context(), chi.URLParam(r, "id"), r.URL.Query().Get("ip")); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Impersonate handles POST /api/v1/users/{id}/impersonate
+// @Summary Impersonate a user
+// @Description Issue a short-lived access token that acts as the user, to see what they see. The token carries the admin in its act claim, grants the user's permissions but never more than the admin's, can't be refreshed and ends with the admin's session. It can't change passwords, multi-factor authentication, API keys or delete the account, and every request made with it is logged. Requires users:admin permission.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} domain.ImpersonationResponse "Impersonation token"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID format or impersonating yourself"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden, or already impersonating a user"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "User is inactive, pending or being deleted"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/{id}/impersonate [post]
+func (h *UserHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, h.authorizer, auth.ScopeUsersAdmin) {
+		return
+	}
+
+	if _, ok := auth.GetUserID(r.Context()); !ok {
+		response.Forbidden(w, "impersonation requires signing in as a user")
+		return
+	}
+
+	resp, err := h.impersonation.Impersonate(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, resp)
+}
+
 // GetMe handles GET /api/v1/users/me
 // @Summary Get current user profile
-// @Description Get the profile of the currently authenticated user
+// @Description Get the profile of the currently authenticated user and the teams they belong to
 // @Tags users
 // @Accept json
 // @Produce json
-// @Success 200 {object} domain.UserResponse "Current user details"
+// @Success 200 {object} domain.CurrentUserResponse "Current user details and teams"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security BearerAuth
@@ -250,7 +323,13 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.OK(w, user.ToResponse())
+	teams, err := h.teamService.ListByMember(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.OK(w, domain.NewCurrentUserResponse(user, teams))
 }
 
 // ChangePassword handles POST /api/v1/users/me/password
@@ -261,8 +340,9 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param request body domain.ChangePasswordRequest true "Password change request"
 // @Success 200 {object} map[string]string "Password changed successfully"
-// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 400 {object} handler.PasswordPolicyErrorResponse "Validation error, or password policy violations"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized or wrong current password"
+// @Failure 403 {object} response.ErrorResponse "Not allowed while impersonating a user"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Router /users/me/password [post]
@@ -287,38 +367,115 @@ func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, map[string]string{"message": "password changed successfully"})
 }
 
-// isAdmin checks if the current user has admin role
-func (h *UserHandler) isAdmin(r *http.Request) bool {
-	role, ok := auth.GetUserRole(r.Context())
+// ExportMe handles GET /api/v1/users/me/export
+// @Summary Export current user's personal data
+// @Description Download everything stored about the currently authenticated user: their profile, teams, sessions, API keys, and the services they created or edited with every change they made
+// @Tags users
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.PersonalDataExport "Personal data archive"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/me/export [get]
+func (h *UserHandler) ExportMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
 	if !ok {
-		return false
+		response.Unauthorized(w, "authentication required")
+		return
 	}
-	return role == domain.RoleAdmin
+
+	export, err := h.personalData.Export(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="personal-data.json"`)
+	response.OK(w, export)
 }
 
-// canAccessUser checks if the current user can access the requested user resource
-func (h *UserHandler) canAccessUser(r *http.Request, targetUserID string) bool {
-	// Admins can access any user
-	if h.isAdmin(r) {
-		return true
+// DeleteMe handles DELETE /api/v1/users/me
+// @Summary Delete current user's account
+// @Description Schedule the currently authenticated user's account for deletion and sign them out everywhere. The account can be restored until the grace period ends; after that it is deleted and the user's changes to services are kept without their name. Users with a password must confirm it.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body domain.DeleteAccountRequest false "Password confirmation"
+// @Success 202 {object} domain.UserResponse "Account scheduled for deletion"
+// @Failure 400 {object} response.ErrorResponse "Password required"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized or wrong password"
+// @Failure 403 {object} response.ErrorResponse "Not allowed while impersonating a user"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/me [delete]
+func (h *UserHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "authentication required")
+		return
+	}
+
+	var req domain.DeleteAccountRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "invalid request body")
+			return
+		}
 	}
 
-	// Users can only access their own profile
-	currentUserID, ok := auth.GetUserID(r.Context())
+	user, err := h.personalData.ScheduleDeletion(r.Context(), userID, req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, user.ToResponse())
+}
+
+// RestoreMe handles POST /api/v1/users/me/restore
+// @Summary Restore current user's account
+// @Description Cancel the scheduled deletion of the currently authenticated user's account
+// @Tags users
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.UserResponse "Account restored"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Not allowed while impersonating a user"
+// @Failure 409 {object} response.ErrorResponse "Account deletion is not scheduled"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /users/me/restore [post]
+func (h *UserHandler) RestoreMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
 	if !ok {
-		return false
+		response.Unauthorized(w, "authentication required")
+		return
+	}
+
+	user, err := h.personalData.CancelDeletion(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
 	}
-	return currentUserID == targetUserID
+
+	response.OK(w, user.ToResponse())
 }
 
 // handleError handles errors from the user service
 func (h *UserHandler) handleError(w http.ResponseWriter, err error) {
+	if passwordPolicyError(w, err) {
+		return
+	}
+
 	switch {
 	case errors.Is(err, domain.ErrUserNotFound):
 		response.NotFound(w, "user not found")
 	case errors.Is(err, domain.ErrInvalidID):
 		response.BadRequest(w, "invalid user id format")
-	case errors.Is(err, domain.ErrEmailAlreadyExists):
+	case errors.Is(err, domain.ErrEmailAlreadyExists),
+		errors.Is(err, domain.ErrDeletionNotPending),
+		errors.Is(err, domain.ErrImpersonationTargetInactive):
 		response.Conflict(w, err.Error())
 	case errors.Is(err, domain.ErrInvalidCredentials):
 		response.Unauthorized(w, "invalid credentials")
@@ -326,12 +483,13 @@ func (h *UserHandler) handleError(w http.ResponseWriter, err error) {
 		errors.Is(err, domain.ErrEmailInvalid),
 		errors.Is(err, domain.ErrEmailTooLong),
 		errors.Is(err, domain.ErrPasswordRequired),
-		errors.Is(err, domain.ErrPasswordTooShort),
-		errors.Is(err, domain.ErrPasswordTooLong),
 		errors.Is(err, domain.ErrFirstNameRequired),
 		errors.Is(err, domain.ErrFirstNameTooLong),
 		errors.Is(err, domain.ErrLastNameTooLong),
-		errors.Is(err, domain.ErrInvalidRole):
+		errors.Is(err, domain.ErrInvalidRole),
+		errors.Is(err, domain.ErrInvalidSortField),
+		errors.Is(err, domain.ErrInvalidIPAddress),
+		errors.Is(err, domain.ErrImpersonateSelf):
 		response.BadRequest(w, err.Error())
 	default:
 		response.InternalServerError(w, "internal server error")
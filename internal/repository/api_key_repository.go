@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoAPIKeyRepository implements domain.APIKeyRepository using MongoDB
+type MongoAPIKeyRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAPIKeyRepository creates a new MongoAPIKeyRepository
+func NewMongoAPIKeyRepository(db *mongo.Database) *MongoAPIKeyRepository {
+	return &MongoAPIKeyRepository{
+		collection: db.Collection("api_keys"),
+	}
+}
+
+// Create stores a new API key
+func (r *MongoAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	now := time.Now()
+	key.CreatedAt = now
+	key.UpdatedAt = now
+
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, key)
+	if err != nil {
+		// Check for duplicate key error (prefix already exists)
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrAPIKeyPrefixExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetByID retrieves an API key by its ID
+func (r *MongoAPIKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	return r.findOne(ctx, bson.M{"_id": objectID})
+}
+
+// GetByPrefix retrieves an API key by its lookup prefix
+func (r *MongoAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	return r.findOne(ctx, bson.M{"prefix": prefix})
+}
+
+// Update updates the name, scopes and expiry of an API key
+func (r *MongoAPIKeyRepository) Update(ctx context.Context, key *domain.APIKey) error {
+	key.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":       key.Name,
+			"scopes":     key.Scopes,
+			"updated_at": key.UpdatedAt,
+		},
+	}
+	if key.ExpiresAt != nil {
+		update["$set"].(bson.M)["expires_at"] = key.ExpiresAt
+	} else {
+		update["$unset"] = bson.M{"expires_at": ""}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": key.ID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes an API key by its ID
+func (r *MongoAPIKeyRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// List retrieves API keys with pagination, optionally restricted to one owner
+func (r *MongoAPIKeyRepository) List(ctx context.Context, ownerID string, params domain.PaginationParams) (*domain.PaginatedResult[domain.APIKey], error) {
+	filter := bson.M{}
+	if ownerID != "" {
+		objectID, err := primitive.ObjectIDFromHex(ownerID)
+		if err != nil {
+			return nil, domain.ErrInvalidID
+		}
+		filter["owner_id"] = objectID
+	}
+
+	// Count total documents
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up pagination
+	findOptions := options.Find()
+	findOptions.SetSkip(int64(params.Offset()))
+	findOptions.SetLimit(int64(params.Limit))
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	// Execute query
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []domain.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return domain.NewPaginatedResult(keys, total, params), nil
+}
+
+// TouchLastUsed records when an API key was last used
+func (r *MongoAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{"last_used_at": usedAt}},
+	)
+	return err
+}
+
+// findOne retrieves a single API key matching filter
+func (r *MongoAPIKeyRepository) findOne(ctx context.Context, filter bson.M) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.collection.FindOne(ctx, filter).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
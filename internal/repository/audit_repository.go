@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoAuditRepository implements domain.AuditRepository using MongoDB
+type MongoAuditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAuditRepository creates a new MongoAuditRepository
+func NewMongoAuditRepository(db *mongo.Database) *MongoAuditRepository {
+	return &MongoAuditRepository{
+		collection: db.Collection("audit_events"),
+	}
+}
+
+// Create appends an event to the audit log. The creation time is truncated to
+// milliseconds, the precision MongoDB stores, so that cursors compare equal.
+func (r *MongoAuditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	}
+
+	_, err := r.collection.InsertOne(ctx, event)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrChainConflict
+	}
+	return err
+}
+
+// List retrieves up to query.Limit events matching a query, newest first
+func (r *MongoAuditRepository) List(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEvent, error) {
+	filter := bson.M{}
+	if query.ActorID != "" {
+		filter["actor_id"] = query.ActorID
+	}
+	if query.Action != "" {
+		filter["action"] = query.Action
+	}
+	if query.TargetType != "" {
+		filter["target_type"] = query.TargetType
+	}
+	if query.TargetID != "" {
+		filter["target_id"] = query.TargetID
+	}
+
+	createdAt := bson.M{}
+	if query.Since != nil {
+		createdAt["$gte"] = *query.Since
+	}
+	if query.Until != nil {
+		createdAt["$lt"] = *query.Until
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	if query.After != nil {
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$lt": query.After.CreatedAt}},
+			bson.M{"created_at": query.After.CreatedAt, "_id": bson.M{"$lt": query.After.ID}},
+		}
+	}
+
+	findOptions := options.Find()
+	findOptions.SetLimit(int64(query.Limit))
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []domain.AuditEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	for i := range events {
+		events[i].CreatedAt = events[i].CreatedAt.UTC()
+	}
+
+	return events, nil
+}
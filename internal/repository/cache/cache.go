@@ -0,0 +1,213 @@
+// Package cache provides read-through caching decorators for domain.ServiceRepository
+// and domain.SessionRepository backed by a pluggable Store (in-process LRU or Redis).
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/logging"
+	"github.com/services-api/pkg/metrics"
+)
+
+const (
+	keyPrefix  = "services:"
+	listGenKey = keyPrefix + "list:gen"
+	defaultTTL = 30 * time.Second
+)
+
+// Store is a byte-oriented key/value store with per-entry expiry
+type Store interface {
+	// Get returns the value for key and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value under key; a zero ttl means the entry does not expire
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the given keys
+	Delete(ctx context.Context, keys ...string) error
+
+	// Name returns the store implementation name
+	Name() string
+}
+
+// ServiceRepository caches GetByID and List results of the wrapped repository
+// and invalidates them on Create, Update and Delete
+type ServiceRepository struct {
+	next  domain.ServiceRepository
+	store Store
+	ttl   time.Duration
+}
+
+// NewServiceRepository creates a caching decorator around next
+func NewServiceRepository(next domain.ServiceRepository, store Store, ttl time.Duration) *ServiceRepository {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &ServiceRepository{
+		next:  next,
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// Create creates a service and invalidates cached listings
+func (r *ServiceRepository) Create(ctx context.Context, service *domain.Service) error {
+	if err := r.next.Create(ctx, service); err != nil {
+		return err
+	}
+	r.invalidate(ctx)
+	return nil
+}
+
+// GetByID returns the cached service or loads it from the wrapped repository
+func (r *ServiceRepository) GetByID(ctx context.Context, id string) (*domain.Service, error) {
+	key := idKey(id)
+
+	var service domain.Service
+	if r.load(ctx, key, &service) {
+		return &service, nil
+	}
+
+	result, err := r.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.save(ctx, key, result)
+	return result, nil
+}
+
+// Update updates a service and invalidates its cached entry and listings
+func (r *ServiceRepository) Update(ctx context.Context, service *domain.Service) error {
+	err := r.next.Update(ctx, service)
+	r.invalidate(ctx, idKey(service.ID.Hex()))
+	return err
+}
+
+// Restore restores a service and invalidates its cached entry and listings
+func (r *ServiceRepository) Restore(ctx context.Context, previous *domain.Service) error {
+	err := r.next.Restore(ctx, previous)
+	r.invalidate(ctx, idKey(previous.ID.Hex()))
+	return err
+}
+
+// Delete deletes a service and invalidates its cached entry and listings
+func (r *ServiceRepository) Delete(ctx context.Context, id string) error {
+	err := r.next.Delete(ctx, id)
+	r.invalidate(ctx, idKey(id))
+	return err
+}
+
+// List returns the cached page for params or loads it from the wrapped repository
+func (r *ServiceRepository) List(ctx context.Context, params domain.ListParams) (*domain.PaginatedResult[domain.Service], error) {
+	key, err := r.listKey(ctx, params)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Cache error building list key", "error", err)
+		return r.next.List(ctx, params)
+	}
+
+	var page domain.PaginatedResult[domain.Service]
+	if r.load(ctx, key, &page) {
+		return &page, nil
+	}
+
+	result, err := r.next.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	r.save(ctx, key, result)
+	return result, nil
+}
+
+// load decodes the cached value for key into dst and records a hit or miss.
+// Values are stored serialized so callers never share mutable state with the cache.
+func (r *ServiceRepository) load(ctx context.Context, key string, dst any) bool {
+	data, ok, err := r.store.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Cache error reading", "key", key, "error", err)
+	}
+	if ok && err == nil {
+		if err := json.Unmarshal(data, dst); err == nil {
+			recordLookup("services", r.store, true)
+			return true
+		}
+		logging.FromContext(ctx).Warn("Cache error decoding", "key", key, "error", err)
+	}
+
+	recordLookup("services", r.store, false)
+	return false
+}
+
+// recordLookup counts a hit or miss of the named cache in the lookup metrics
+func recordLookup(cache string, store Store, hit bool) {
+	result := metrics.ResultMiss
+	if hit {
+		result = metrics.ResultHit
+	}
+	metrics.CacheLookups.WithLabelValues(cache, store.Name(), result).Inc()
+}
+
+// save stores value under key, logging failures since the cache is best effort
+func (r *ServiceRepository) save(ctx context.Context, key string, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Cache error encoding", "key", key, "error", err)
+		return
+	}
+	if err := r.store.Set(ctx, key, data, r.ttl); err != nil {
+		logging.FromContext(ctx).Warn("Cache error writing", "key", key, "error", err)
+	}
+}
+
+// invalidate removes the given keys and starts a new listing generation so
+// every cached page is orphaned and left to expire
+func (r *ServiceRepository) invalidate(ctx context.Context, keys ...string) {
+	keys = append(keys, listGenKey)
+	if err := r.store.Delete(ctx, keys...); err != nil {
+		logging.FromContext(ctx).Warn("Cache error invalidating", "keys", keys, "error", err)
+	}
+}
+
+// listKey derives the cache key for params within the current listing generation
+func (r *ServiceRepository) listKey(ctx context.Context, params domain.ListParams) (string, error) {
+	gen, ok, err := r.store.Get(ctx, listGenKey)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		// A fresh random generation (rather than a counter) guarantees pages
+		// cached before an eviction or restart can never be served again
+		gen = []byte(newGeneration())
+		if err := r.store.Set(ctx, listGenKey, gen, 0); err != nil {
+			return "", err
+		}
+	}
+
+	return listKeyFor(string(gen), params), nil
+}
+
+// listKeyFor hashes the list parameters into a fixed-size key
+func listKeyFor(gen string, params domain.ListParams) string {
+	data, _ := json.Marshal(params)
+	sum := sha256.Sum256(data)
+	return keyPrefix + "list:" + gen + ":" + hex.EncodeToString(sum[:16])
+}
+
+// idKey returns the cache key for a single service
+func idKey(id string) string {
+	return keyPrefix + "id:" + id
+}
+
+// newGeneration returns a random listing generation token
+func newGeneration() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
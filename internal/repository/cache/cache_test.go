@@ -0,0 +1,258 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/repository/cache"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// countingRepository counts the reads that reach the underlying repository
+type countingRepository struct {
+	*mocks.MockServiceRepository
+	gets  int
+	lists int
+}
+
+func (r *countingRepository) GetByID(ctx context.Context, id string) (*domain.Service, error) {
+	r.gets++
+	return r.MockServiceRepository.GetByID(ctx, id)
+}
+
+func (r *countingRepository) List(ctx context.Context, params domain.ListParams) (*domain.PaginatedResult[domain.Service], error) {
+	r.lists++
+	return r.MockServiceRepository.List(ctx, params)
+}
+
+// countingSessionRepository counts the session reads that reach the underlying repository
+type countingSessionRepository struct {
+	*mocks.MockSessionRepository
+	gets int
+}
+
+func (r *countingSessionRepository) GetByID(ctx context.Context, id string) (*domain.Session, error) {
+	r.gets++
+	return r.MockSessionRepository.GetByID(ctx, id)
+}
+
+// countingRoleRepository counts the role reads that reach the underlying repository
+type countingRoleRepository struct {
+	*mocks.MockRoleRepository
+	gets int
+}
+
+func (r *countingRoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	r.gets++
+	return r.MockRoleRepository.GetByName(ctx, name)
+}
+
+func stores(t *testing.T) map[string]cache.Store {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]cache.Store{
+		"memory": cache.NewLRUStore(100),
+		"redis":  cache.NewRedisStore(client),
+	}
+}
+
+func TestServiceRepository_GetByID(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			next := &countingRepository{MockServiceRepository: mocks.NewMockServiceRepository()}
+			repo := cache.NewServiceRepository(next, store, time.Minute)
+			lookups := func(result string) float64 {
+				return testutil.ToFloat64(metrics.CacheLookups.WithLabelValues("services", name, result))
+			}
+			hits, misses := lookups(metrics.ResultHit), lookups(metrics.ResultMiss)
+
+			svc := &domain.Service{Name: "payment-service", Description: "Payments"}
+			require.NoError(t, repo.Create(ctx, svc))
+			id := svc.ID.Hex()
+
+			first, err := repo.GetByID(ctx, id)
+			require.NoError(t, err)
+			second, err := repo.GetByID(ctx, id)
+			require.NoError(t, err)
+
+			assert.Equal(t, 1, next.gets)
+			assert.Equal(t, first.Name, second.Name)
+			assert.Equal(t, svc.ID, second.ID)
+
+			// Mutating a returned value must not leak into the cache
+			second.Name = "mutated"
+			third, err := repo.GetByID(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, "payment-service", third.Name)
+
+			// Update invalidates the cached entry
+			svc.Name = "payment-service-v2"
+			require.NoError(t, repo.Update(ctx, svc))
+			updated, err := repo.GetByID(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, "payment-service-v2", updated.Name)
+			assert.Equal(t, 2, next.gets)
+
+			// Delete invalidates the cached entry and errors are not cached
+			require.NoError(t, repo.Delete(ctx, id))
+			_, err = repo.GetByID(ctx, id)
+			assert.True(t, errors.Is(err, domain.ErrNotFound))
+			_, err = repo.GetByID(ctx, id)
+			assert.True(t, errors.Is(err, domain.ErrNotFound))
+			assert.Equal(t, 4, next.gets)
+
+			assert.Equal(t, hits+2, lookups(metrics.ResultHit))
+			assert.Equal(t, misses+4, lookups(metrics.ResultMiss))
+		})
+	}
+}
+
+func TestServiceRepository_List(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			next := &countingRepository{MockServiceRepository: mocks.NewMockServiceRepository()}
+			repo := cache.NewServiceRepository(next, store, time.Minute)
+
+			require.NoError(t, repo.Create(ctx, &domain.Service{Name: "alpha", Description: "First"}))
+
+			params := domain.DefaultListParams()
+			result, err := repo.List(ctx, params)
+			require.NoError(t, err)
+			assert.Len(t, result.Data, 1)
+
+			_, err = repo.List(ctx, params)
+			require.NoError(t, err)
+			assert.Equal(t, 1, next.lists)
+
+			// Different parameters use a different key
+			other := params
+			other.Pagination.Page = 2
+			_, err = repo.List(ctx, other)
+			require.NoError(t, err)
+			assert.Equal(t, 2, next.lists)
+
+			// Create invalidates every cached page
+			require.NoError(t, repo.Create(ctx, &domain.Service{Name: "beta", Description: "Second"}))
+			result, err = repo.List(ctx, params)
+			require.NoError(t, err)
+			assert.Len(t, result.Data, 2)
+			assert.Equal(t, 3, next.lists)
+		})
+	}
+}
+
+func TestSessionRepository_Revocation(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			next := &countingSessionRepository{MockSessionRepository: mocks.NewMockSessionRepository()}
+			repo := cache.NewSessionRepository(next, store, time.Minute)
+
+			userID := primitive.NewObjectID()
+			now := time.Now()
+			for _, id := range []string{"session-1", "session-2"} {
+				require.NoError(t, repo.Create(ctx, &domain.Session{
+					ID:         id,
+					UserID:     userID,
+					CreatedAt:  now,
+					LastSeenAt: now,
+					ExpiresAt:  now.Add(time.Hour),
+				}))
+			}
+
+			// Repeated checks are served from the cache
+			for i := 0; i < 3; i++ {
+				session, err := repo.GetByID(ctx, "session-1")
+				require.NoError(t, err)
+				assert.True(t, session.IsActive())
+			}
+			assert.Equal(t, 1, next.gets)
+
+			// Revoke invalidates the cached entry
+			require.NoError(t, repo.Revoke(ctx, "session-1"))
+			session, err := repo.GetByID(ctx, "session-1")
+			require.NoError(t, err)
+			assert.False(t, session.IsActive())
+			assert.Equal(t, 2, next.gets)
+
+			// RevokeAllForUser invalidates every active session of the user
+			_, err = repo.GetByID(ctx, "session-2")
+			require.NoError(t, err)
+			require.NoError(t, repo.RevokeAllForUser(ctx, userID.Hex()))
+			session, err = repo.GetByID(ctx, "session-2")
+			require.NoError(t, err)
+			assert.False(t, session.IsActive())
+			assert.Equal(t, 4, next.gets)
+		})
+	}
+}
+
+func TestRoleRepository_Invalidation(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			next := &countingRoleRepository{MockRoleRepository: mocks.NewMockRoleRepository()}
+			repo := cache.NewRoleRepository(next, store, time.Minute)
+
+			require.NoError(t, repo.Create(ctx, &domain.Role{Name: "editor", Permissions: []string{"services:read", "services:write"}}))
+
+			// Repeated checks are served from the cache
+			for i := 0; i < 3; i++ {
+				role, err := repo.GetByName(ctx, "editor")
+				require.NoError(t, err)
+				assert.True(t, role.HasPermission("services:write"))
+			}
+			assert.Equal(t, 1, next.gets)
+
+			// Update invalidates the cached entry
+			require.NoError(t, repo.Update(ctx, &domain.Role{Name: "editor", Permissions: []string{"services:read"}}))
+			role, err := repo.GetByName(ctx, "editor")
+			require.NoError(t, err)
+			assert.False(t, role.HasPermission("services:write"))
+			assert.Equal(t, 2, next.gets)
+
+			// Delete invalidates the cached entry
+			require.NoError(t, repo.Delete(ctx, "editor"))
+			_, err = repo.GetByName(ctx, "editor")
+			assert.ErrorIs(t, err, domain.ErrRoleNotFound)
+		})
+	}
+}
+
+func TestLRUStore_EvictionAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewLRUStore(2)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, store.Set(ctx, "b", []byte("2"), 0))
+
+	// Touch "a" so "b" becomes least recently used
+	_, ok, _ := store.Get(ctx, "a")
+	require.True(t, ok)
+	require.NoError(t, store.Set(ctx, "c", []byte("3"), 0))
+
+	_, ok, _ = store.Get(ctx, "b")
+	assert.False(t, ok, "least recently used entry should be evicted")
+	_, ok, _ = store.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, store.Len())
+
+	require.NoError(t, store.Set(ctx, "short", []byte("x"), time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, ok, _ = store.Get(ctx, "short")
+	assert.False(t, ok, "expired entry should not be returned")
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/logging"
+)
+
+const roleKeyPrefix = "roles:name:"
+
+// RoleRepository caches GetByName results of the wrapped repository, which
+// authorization checks call on every request, and invalidates them whenever a
+// role is changed or deleted
+type RoleRepository struct {
+	next  domain.RoleRepository
+	store Store
+	ttl   time.Duration
+}
+
+// NewRoleRepository creates a caching decorator around next
+func NewRoleRepository(next domain.RoleRepository, store Store, ttl time.Duration) *RoleRepository {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &RoleRepository{
+		next:  next,
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// Create stores a new role
+func (r *RoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	return r.next.Create(ctx, role)
+}
+
+// GetByName returns the cached role or loads it from the wrapped repository
+func (r *RoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	key := roleKey(name)
+
+	data, ok, err := r.store.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Cache error reading", "key", key, "error", err)
+	}
+	if ok && err == nil {
+		var role domain.Role
+		if err := json.Unmarshal(data, &role); err == nil {
+			recordLookup("roles", r.store, true)
+			return &role, nil
+		}
+		logging.FromContext(ctx).Warn("Cache error decoding", "key", key, "error", err)
+	}
+	recordLookup("roles", r.store, false)
+
+	role, err := r.next.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(role); err == nil {
+		if err := r.store.Set(ctx, key, data, r.ttl); err != nil {
+			logging.FromContext(ctx).Warn("Cache error writing", "key", key, "error", err)
+		}
+	}
+
+	return role, nil
+}
+
+// List retrieves all roles from the wrapped repository
+func (r *RoleRepository) List(ctx context.Context) ([]domain.Role, error) {
+	return r.next.List(ctx)
+}
+
+// Update updates a role and invalidates its cached entry
+func (r *RoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	err := r.next.Update(ctx, role)
+	r.invalidate(ctx, role.Name)
+	return err
+}
+
+// Delete deletes a role and invalidates its cached entry
+func (r *RoleRepository) Delete(ctx context.Context, name string) error {
+	err := r.next.Delete(ctx, name)
+	r.invalidate(ctx, name)
+	return err
+}
+
+// invalidate removes the cached entry of a role
+func (r *RoleRepository) invalidate(ctx context.Context, name string) {
+	key := roleKey(name)
+	if err := r.store.Delete(ctx, key); err != nil {
+		logging.FromContext(ctx).Warn("Cache error invalidating", "key", key, "error", err)
+	}
+}
+
+// roleKey returns the cache key for a single role
+func roleKey(name string) string {
+	return roleKeyPrefix + name
+}
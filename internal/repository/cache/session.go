@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/logging"
+)
+
+const sessionKeyPrefix = "sessions:id:"
+
+// SessionRepository caches GetByID results of the wrapped repository, which
+// the auth middleware calls on every request, and invalidates them whenever a
+// session is touched or revoked
+type SessionRepository struct {
+	next  domain.SessionRepository
+	store Store
+	ttl   time.Duration
+}
+
+// NewSessionRepository creates a caching decorator around next
+func NewSessionRepository(next domain.SessionRepository, store Store, ttl time.Duration) *SessionRepository {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &SessionRepository{
+		next:  next,
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// Create stores a new session
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	return r.next.Create(ctx, session)
+}
+
+// GetByID returns the cached session or loads it from the wrapped repository
+func (r *SessionRepository) GetByID(ctx context.Context, id string) (*domain.Session, error) {
+	key := sessionKey(id)
+
+	data, ok, err := r.store.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Cache error reading", "key", key, "error", err)
+	}
+	if ok && err == nil {
+		var session domain.Session
+		if err := json.Unmarshal(data, &session); err == nil {
+			recordLookup("sessions", r.store, true)
+			return &session, nil
+		}
+		logging.FromContext(ctx).Warn("Cache error decoding", "key", key, "error", err)
+	}
+	recordLookup("sessions", r.store, false)
+
+	session, err := r.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Never cache a session beyond its own expiry
+	ttl := r.ttl
+	if remaining := time.Until(session.ExpiresAt); remaining < ttl {
+		ttl = remaining
+	}
+	if data, err := json.Marshal(session); err == nil && ttl > 0 {
+		if err := r.store.Set(ctx, key, data, ttl); err != nil {
+			logging.FromContext(ctx).Warn("Cache error writing", "key", key, "error", err)
+		}
+	}
+
+	return session, nil
+}
+
+// ListByUser retrieves the active sessions of a user from the wrapped repository
+func (r *SessionRepository) ListByUser(ctx context.Context, userID string) ([]domain.Session, error) {
+	return r.next.ListByUser(ctx, userID)
+}
+
+// Touch records activity on a session and invalidates its cached entry
+func (r *SessionRepository) Touch(ctx context.Context, id string, client domain.ClientInfo, expiresAt time.Time) error {
+	err := r.next.Touch(ctx, id, client, expiresAt)
+	r.invalidate(ctx, id)
+	return err
+}
+
+// Revoke revokes a session and invalidates its cached entry
+func (r *SessionRepository) Revoke(ctx context.Context, id string) error {
+	err := r.next.Revoke(ctx, id)
+	r.invalidate(ctx, id)
+	return err
+}
+
+// RevokeAllForUser revokes every session of a user and invalidates their cached entries
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	// Only active sessions can be cached as active, so they are the ones to invalidate
+	sessions, err := r.next.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = r.next.RevokeAllForUser(ctx, userID)
+	ids := make([]string, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.ID
+	}
+	r.invalidate(ctx, ids...)
+	return err
+}
+
+// invalidate removes the cached entries of the given sessions
+func (r *SessionRepository) invalidate(ctx context.Context, ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionKey(id)
+	}
+	if err := r.store.Delete(ctx, keys...); err != nil {
+		logging.FromContext(ctx).Warn("Cache error invalidating", "keys", keys, "error", err)
+	}
+}
+
+// sessionKey returns the cache key for a single session
+func sessionKey(id string) string {
+	return sessionKeyPrefix + id
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoHealthChecker reports the health of the MongoDB backend
+type MongoHealthChecker struct {
+	db *mongo.Database
+}
+
+// NewMongoHealthChecker creates a new MongoHealthChecker
+func NewMongoHealthChecker(db *mongo.Database) *MongoHealthChecker {
+	return &MongoHealthChecker{db: db}
+}
+
+// Backend returns the storage backend name
+func (c *MongoHealthChecker) Backend() string {
+	return "mongo"
+}
+
+// CheckHealth pings MongoDB and reports the server version
+func (c *MongoHealthChecker) CheckHealth(ctx context.Context) (map[string]string, error) {
+	if err := c.db.Client().Ping(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	var buildInfo struct {
+		Version string `bson:"version"`
+	}
+	if err := c.db.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&buildInfo); err != nil {
+		return nil, fmt.Errorf("failed to read build info: %w", err)
+	}
+
+	return map[string]string{
+		"database":       c.db.Name(),
+		"server_version": buildInfo.Version,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoIdempotencyRepository implements domain.IdempotencyRepository using MongoDB
+type MongoIdempotencyRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoIdempotencyRepository creates a new MongoIdempotencyRepository
+func NewMongoIdempotencyRepository(db *mongo.Database) *MongoIdempotencyRepository {
+	return &MongoIdempotencyRepository{
+		collection: db.Collection("idempotency_keys"),
+	}
+}
+
+// Create stores a new in-progress record
+func (r *MongoIdempotencyRepository) Create(ctx context.Context, record *domain.IdempotencyRecord) error {
+	_, err := r.collection.InsertOne(ctx, record)
+	if err == nil {
+		return nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	// The TTL monitor only runs periodically, so an expired record may still
+	// hold the key; remove it and try once more
+	result, err := r.collection.DeleteOne(ctx, bson.M{
+		"_id":        record.Key,
+		"expires_at": bson.M{"$lte": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrIdempotencyKeyExists
+	}
+
+	if _, err := r.collection.InsertOne(ctx, record); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrIdempotencyKeyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByKey retrieves an unexpired record by its key
+func (r *MongoIdempotencyRepository) GetByKey(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	var record domain.IdempotencyRecord
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":        key,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Complete stores the response of an in-progress record
+func (r *MongoIdempotencyRepository) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{
+		"$set": bson.M{
+			"completed":    true,
+			"status_code":  statusCode,
+			"content_type": contentType,
+			"body":         body,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a record so its key can be used again
+func (r *MongoIdempotencyRepository) Delete(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
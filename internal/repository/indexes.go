@@ -2,7 +2,7 @@ package repository
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -21,7 +21,7 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 	if err != nil {
 		return err
 	}
-	log.Println("Created index on services.name")
+	slog.Info("Created index on services.name")
 
 	// Text index on name and description for search
 	_, err = servicesCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
@@ -33,7 +33,7 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 	if err != nil {
 		return err
 	}
-	log.Println("Created text index on services.name and services.description")
+	slog.Info("Created text index on services.name and services.description")
 
 	// Service versions collection indexes
 	versionsCollection := db.Collection("service_versions")
@@ -49,7 +49,7 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 	if err != nil {
 		return err
 	}
-	log.Println("Created compound unique index on service_versions(service_id, revision)")
+	slog.Info("Created compound unique index on service_versions(service_id, revision)")
 
 	// Index on service_id for listing all versions of a service
 	_, err = versionsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
@@ -58,7 +58,17 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 	if err != nil {
 		return err
 	}
-	log.Println("Created index on service_versions.service_id")
+	slog.Info("Created index on service_versions.service_id")
+
+	// Sparse index on author_id for exporting and anonymizing a user's changes
+	_, err = versionsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "author_id", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on service_versions.author_id")
 
 	// Users collection indexes
 	usersCollection := db.Collection("users")
@@ -71,7 +81,259 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 	if err != nil {
 		return err
 	}
-	log.Println("Created unique index on users.email")
+	slog.Info("Created unique index on users.email")
+
+	// Index on role for checking whether a role is in use
+	_, err = usersCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "role", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on users.role")
+
+	// Indexes serving the sort orders of the user directory
+	_, err = usersCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "last_login_at", Value: -1}}},
+		{Keys: bson.D{{Key: "last_name", Value: 1}, {Key: "first_name", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created indexes on users.created_at, users.last_login_at and users.last_name")
+
+	// Sparse index on deletion_scheduled_at for finding accounts due for deletion
+	_, err = usersCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "deletion_scheduled_at", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on users.deletion_scheduled_at")
+
+	// Idempotency records expire through a TTL index on expires_at
+	_, err = db.Collection("idempotency_keys").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created TTL index on idempotency_keys.expires_at")
+
+	// Refresh tokens collection indexes
+	refreshTokensCollection := db.Collection("refresh_tokens")
+
+	// Refresh tokens are only needed until they expire
+	_, err = refreshTokensCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created TTL index on refresh_tokens.expires_at")
+
+	// Indexes on family_id and user_id for revocation
+	_, err = refreshTokensCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "family_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created indexes on refresh_tokens.family_id and refresh_tokens.user_id")
+
+	// Sessions collection indexes
+	sessionsCollection := db.Collection("sessions")
+
+	// Sessions are kept until they expire so revoked ones still fail validation
+	_, err = sessionsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created TTL index on sessions.expires_at")
+
+	// Index on user_id for listing and revoking a user's sessions
+	_, err = sessionsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_seen_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on sessions.user_id")
+
+	// API keys collection indexes
+	apiKeysCollection := db.Collection("api_keys")
+
+	// Unique index on prefix, used to look keys up
+	_, err = apiKeysCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "prefix", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created unique index on api_keys.prefix")
+
+	// Index on owner_id for listing a user's keys
+	_, err = apiKeysCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on api_keys.owner_id")
+
+	// Failed logins are only counted until they expire
+	_, err = db.Collection("login_attempts").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created TTL index on login_attempts.expires_at")
+
+	// User tokens collection indexes
+	userTokensCollection := db.Collection("user_tokens")
+
+	// Emailed tokens are only needed until they expire
+	_, err = userTokensCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created TTL index on user_tokens.expires_at")
+
+	// Index on user_id for invalidating a user's outstanding tokens
+	_, err = userTokensCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on user_tokens.user_id")
+
+	// Index on user_id for listing a user's previous passwords
+	_, err = db.Collection("password_history").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on password_history.user_id")
+
+	// Invitations collection indexes
+	invitationsCollection := db.Collection("invitations")
+
+	// Unique index on token_hash for accepting invitations
+	_, err = invitationsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "token_hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created unique index on invitations.token_hash")
+
+	// Index on created_at for listing invitations
+	_, err = invitationsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on invitations.created_at")
+
+	// Teams collection indexes
+	teamsCollection := db.Collection("teams")
+
+	// Unique index on name
+	_, err = teamsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created unique index on teams.name")
+
+	// Index on members.user_id for listing the teams of a user
+	_, err = teamsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "members.user_id", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on teams.members.user_id")
+
+	// Index on team_id for filtering services by team
+	_, err = servicesCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "team_id", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on services.team_id")
+
+	// Audit events collection indexes
+	auditCollection := db.Collection("audit_events")
+
+	// Index on created_at for paging through the audit log
+	_, err = auditCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on audit_events.created_at")
+
+	// Index on actor_id for filtering by actor
+	_, err = auditCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on audit_events.actor_id")
+
+	// Index on target_type and target_id for filtering by target
+	_, err = auditCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on audit_events.target_type and target_id")
+
+	// Index on action for filtering by action
+	_, err = auditCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "action", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created index on audit_events.action")
+
+	// Unique index on prev_hash, so that concurrent writers can't fork the hash
+	// chain. Events recorded before hashing was introduced are left out.
+	_, err = auditCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "prev_hash", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"hash": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created unique index on audit_events.prev_hash")
 
 	return nil
 }
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoInvitationRepository implements domain.InvitationRepository using MongoDB
+type MongoInvitationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoInvitationRepository creates a new MongoInvitationRepository
+func NewMongoInvitationRepository(db *mongo.Database) *MongoInvitationRepository {
+	return &MongoInvitationRepository{
+		collection: db.Collection("invitations"),
+	}
+}
+
+// Create stores a new invitation
+func (r *MongoInvitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	now := time.Now()
+	invitation.CreatedAt = now
+	invitation.SentAt = now
+
+	_, err := r.collection.InsertOne(ctx, invitation)
+	return err
+}
+
+// GetByID retrieves an invitation by the ID of its pending user
+func (r *MongoInvitationRepository) GetByID(ctx context.Context, id string) (*domain.Invitation, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	var invitation domain.Invitation
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&invitation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrInvitationNotFound
+		}
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// GetByTokenHash retrieves an unexpired invitation by the hash of its token
+func (r *MongoInvitationRepository) GetByTokenHash(ctx context.Context, hash string) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	err := r.collection.FindOne(ctx, bson.M{
+		"token_hash": hash,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&invitation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrInvalidInvitation
+		}
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// Consume atomically deletes an unexpired invitation by the hash of its token
+func (r *MongoInvitationRepository) Consume(ctx context.Context, hash string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{
+		"token_hash": hash,
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrInvalidInvitation
+	}
+
+	return nil
+}
+
+// Update replaces the address, token and expiry of an invitation
+func (r *MongoInvitationRepository) Update(ctx context.Context, invitation *domain.Invitation) error {
+	invitation.SentAt = time.Now()
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": invitation.ID},
+		bson.M{"$set": bson.M{
+			"email":      invitation.Email,
+			"token_hash": invitation.TokenHash,
+			"sent_at":    invitation.SentAt,
+			"expires_at": invitation.ExpiresAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes an invitation by the ID of its pending user
+func (r *MongoInvitationRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// List retrieves invitations with pagination, newest first
+func (r *MongoInvitationRepository) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.Invitation], error) {
+	filter := bson.M{}
+
+	// Count total documents
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up pagination
+	findOptions := options.Find()
+	findOptions.SetSkip(int64(params.Offset()))
+	findOptions.SetLimit(int64(params.Limit))
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	// Execute query
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invitations := []domain.Invitation{}
+	if err := cursor.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+
+	return domain.NewPaginatedResult(invitations, total, params), nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoLoginAttemptRepository implements domain.LoginAttemptRepository using MongoDB
+type MongoLoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoLoginAttemptRepository creates a new MongoLoginAttemptRepository
+func NewMongoLoginAttemptRepository(db *mongo.Database) *MongoLoginAttemptRepository {
+	return &MongoLoginAttemptRepository{
+		collection: db.Collection("login_attempts"),
+	}
+}
+
+// Get retrieves the unexpired failed logins of a key
+func (r *MongoLoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	var attempts domain.LoginAttempts
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":        key,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&attempts)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &attempts, nil
+}
+
+// RecordFailure atomically counts a failed login for a key
+func (r *MongoLoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempts, error) {
+	now := time.Now()
+	// The TTL monitor only runs periodically, so check expiry here as well
+	active := bson.M{"$gt": bson.A{"$expires_at", now}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"failures":        bson.M{"$cond": bson.A{active, bson.M{"$add": bson.A{"$failures", 1}}, 1}},
+		"locked_until":    bson.M{"$cond": bson.A{active, "$locked_until", "$$REMOVE"}},
+		"last_failure_at": now,
+		// A lockout outlasting the window keeps the record alive
+		"expires_at": bson.M{"$max": bson.A{now.Add(window), bson.M{"$cond": bson.A{active, "$locked_until", nil}}}},
+	}}}}
+
+	var attempts domain.LoginAttempts
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&attempts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempts, nil
+}
+
+// Lock refuses logins for a key until the given time
+func (r *MongoLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{
+		"$set": bson.M{"locked_until": until},
+		"$max": bson.M{"expires_at": until},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// Reset forgets the failed logins of a key
+func (r *MongoLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoMFARepository implements domain.MFARepository using MongoDB. Each
+// document holds the TOTP factor of one user, keyed by the user ID.
+type MongoMFARepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoMFARepository creates a new MongoMFARepository
+func NewMongoMFARepository(db *mongo.Database) *MongoMFARepository {
+	return &MongoMFARepository{
+		collection: db.Collection("mfa_totp"),
+	}
+}
+
+// Save creates or replaces the TOTP factor of a user
+func (r *MongoMFARepository) Save(ctx context.Context, factor *domain.TOTPFactor) error {
+	now := time.Now()
+	if factor.CreatedAt.IsZero() {
+		factor.CreatedAt = now
+	}
+	factor.UpdatedAt = now
+	if factor.RecoveryCodes == nil {
+		factor.RecoveryCodes = []string{}
+	}
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": factor.UserID}, factor, options.Replace().SetUpsert(true))
+	return err
+}
+
+// GetByUserID retrieves the TOTP factor of a user
+func (r *MongoMFARepository) GetByUserID(ctx context.Context, userID string) (*domain.TOTPFactor, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	var factor domain.TOTPFactor
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&factor); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+
+	return &factor, nil
+}
+
+// UseStep records a time step as used unless it or a later step already was
+func (r *MongoMFARepository) UseStep(ctx context.Context, userID string, step int64) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "last_used_step": bson.M{"$lt": step}},
+		bson.M{"$set": bson.M{"last_used_step": step}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.ModifiedCount == 0 {
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// UseRecoveryCode removes a recovery code by its hash
+func (r *MongoMFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "recovery_codes": codeHash},
+		bson.M{"$pull": bson.M{"recovery_codes": codeHash}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.ModifiedCount == 0 {
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// Delete removes the TOTP factor of a user
+func (r *MongoMFARepository) Delete(ctx context.Context, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrMFANotEnrolled
+	}
+
+	return nil
+}
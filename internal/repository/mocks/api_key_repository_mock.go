@@ -0,0 +1,189 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockAPIKeyRepository is a mock implementation of domain.APIKeyRepository
+type MockAPIKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[string]*domain.APIKey
+
+	// Hooks for customizing behavior
+	CreateFunc        func(ctx context.Context, key *domain.APIKey) error
+	GetByIDFunc       func(ctx context.Context, id string) (*domain.APIKey, error)
+	GetByPrefixFunc   func(ctx context.Context, prefix string) (*domain.APIKey, error)
+	UpdateFunc        func(ctx context.Context, key *domain.APIKey) error
+	DeleteFunc        func(ctx context.Context, id string) error
+	ListFunc          func(ctx context.Context, ownerID string, params domain.PaginationParams) (*domain.PaginatedResult[domain.APIKey], error)
+	TouchLastUsedFunc func(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// NewMockAPIKeyRepository creates a new MockAPIKeyRepository
+func NewMockAPIKeyRepository() *MockAPIKeyRepository {
+	return &MockAPIKeyRepository{
+		keys: make(map[string]*domain.APIKey),
+	}
+}
+
+// Create stores a new API key
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, key)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.keys {
+		if existing.Prefix == key.Prefix {
+			return domain.ErrAPIKeyPrefixExists
+		}
+	}
+
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	now := time.Now()
+	key.CreatedAt = now
+	key.UpdatedAt = now
+
+	stored := *key
+	m.keys[key.ID.Hex()] = &stored
+	return nil
+}
+
+// GetByID retrieves an API key by its ID
+func (m *MockAPIKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key, ok := m.keys[id]
+	if !ok {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+
+	found := *key
+	return &found, nil
+}
+
+// GetByPrefix retrieves an API key by its lookup prefix
+func (m *MockAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	if m.GetByPrefixFunc != nil {
+		return m.GetByPrefixFunc(ctx, prefix)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.keys {
+		if key.Prefix == prefix {
+			found := *key
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+// Update updates the name, scopes and expiry of an API key
+func (m *MockAPIKeyRepository) Update(ctx context.Context, key *domain.APIKey) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, key)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.keys[key.ID.Hex()]
+	if !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	key.UpdatedAt = time.Now()
+	existing.Name = key.Name
+	existing.Scopes = key.Scopes
+	existing.ExpiresAt = key.ExpiresAt
+	existing.UpdatedAt = key.UpdatedAt
+	return nil
+}
+
+// Delete deletes an API key by its ID
+func (m *MockAPIKeyRepository) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.keys[id]; !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	delete(m.keys, id)
+	return nil
+}
+
+// List retrieves API keys with pagination, optionally restricted to one owner
+func (m *MockAPIKeyRepository) List(ctx context.Context, ownerID string, params domain.PaginationParams) (*domain.PaginatedResult[domain.APIKey], error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, ownerID, params)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []domain.APIKey{}
+	for _, key := range m.keys {
+		if ownerID == "" || key.OwnerID.Hex() == ownerID {
+			keys = append(keys, *key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	total := int64(len(keys))
+
+	// Apply pagination
+	start := params.Offset()
+	end := start + params.Limit
+	if start >= len(keys) {
+		keys = []domain.APIKey{}
+	} else {
+		if end > len(keys) {
+			end = len(keys)
+		}
+		keys = keys[start:end]
+	}
+
+	return domain.NewPaginatedResult(keys, total, params), nil
+}
+
+// TouchLastUsed records when an API key was last used
+func (m *MockAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	if m.TouchLastUsedFunc != nil {
+		return m.TouchLastUsedFunc(ctx, id, usedAt)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.keys[id]
+	if !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	key.LastUsedAt = &usedAt
+	return nil
+}
@@ -0,0 +1,127 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockAuditRepository is a mock implementation of domain.AuditRepository
+type MockAuditRepository struct {
+	mu     sync.RWMutex
+	events []domain.AuditEvent
+
+	// Hooks for customizing behavior
+	CreateFunc func(ctx context.Context, event *domain.AuditEvent) error
+	ListFunc   func(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEvent, error)
+}
+
+// NewMockAuditRepository creates a new MockAuditRepository
+func NewMockAuditRepository() *MockAuditRepository {
+	return &MockAuditRepository{}
+}
+
+// Create appends an event to the audit log
+func (m *MockAuditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, event)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if event.Hash != "" {
+		for _, existing := range m.events {
+			if existing.Hash != "" && existing.PrevHash == event.PrevHash {
+				return domain.ErrChainConflict
+			}
+		}
+	}
+
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	}
+	m.events = append(m.events, *event)
+	return nil
+}
+
+// List retrieves up to query.Limit events matching a query, newest first
+func (m *MockAuditRepository) List(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEvent, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, query)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := []domain.AuditEvent{}
+	for _, event := range m.events {
+		if query.ActorID != "" && event.ActorID != query.ActorID {
+			continue
+		}
+		if query.Action != "" && event.Action != query.Action {
+			continue
+		}
+		if query.TargetType != "" && event.TargetType != query.TargetType {
+			continue
+		}
+		if query.TargetID != "" && event.TargetID != query.TargetID {
+			continue
+		}
+		if query.Since != nil && event.CreatedAt.Before(*query.Since) {
+			continue
+		}
+		if query.Until != nil && !event.CreatedAt.Before(*query.Until) {
+			continue
+		}
+		if query.After != nil && !auditEventBefore(event, *query.After) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return auditEventBefore(events[j], domain.NewAuditCursor(&events[i]))
+	})
+	if query.Limit > 0 && len(events) > query.Limit {
+		events = events[:query.Limit]
+	}
+
+	return events, nil
+}
+
+// Events returns every recorded event, oldest first
+func (m *MockAuditRepository) Events() []domain.AuditEvent {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]domain.AuditEvent(nil), m.events...)
+}
+
+// UpdateEvent changes a stored event in place (for simulating tampering)
+func (m *MockAuditRepository) UpdateEvent(id primitive.ObjectID, fn func(event *domain.AuditEvent)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.events {
+		if m.events[i].ID == id {
+			fn(&m.events[i])
+		}
+	}
+}
+
+// auditEventBefore reports whether an event comes before the cursor in the
+// audit log's newest-first order, i.e. is older than it
+func auditEventBefore(event domain.AuditEvent, cursor domain.AuditCursor) bool {
+	if !event.CreatedAt.Equal(cursor.CreatedAt) {
+		return event.CreatedAt.Before(cursor.CreatedAt)
+	}
+	return event.ID.Hex() < cursor.ID.Hex()
+}
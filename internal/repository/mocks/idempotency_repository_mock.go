@@ -0,0 +1,98 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+)
+
+// MockIdempotencyRepository is a mock implementation of domain.IdempotencyRepository
+type MockIdempotencyRepository struct {
+	mu      sync.RWMutex
+	records map[string]*domain.IdempotencyRecord
+
+	// Hooks for customizing behavior
+	CreateFunc   func(ctx context.Context, record *domain.IdempotencyRecord) error
+	GetByKeyFunc func(ctx context.Context, key string) (*domain.IdempotencyRecord, error)
+	CompleteFunc func(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	DeleteFunc   func(ctx context.Context, key string) error
+}
+
+// NewMockIdempotencyRepository creates a new MockIdempotencyRepository
+func NewMockIdempotencyRepository() *MockIdempotencyRepository {
+	return &MockIdempotencyRepository{
+		records: make(map[string]*domain.IdempotencyRecord),
+	}
+}
+
+// Create stores a new in-progress record
+func (m *MockIdempotencyRepository) Create(ctx context.Context, record *domain.IdempotencyRecord) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, record)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.records[record.Key]; ok && existing.ExpiresAt.After(time.Now()) {
+		return domain.ErrIdempotencyKeyExists
+	}
+
+	stored := *record
+	m.records[record.Key] = &stored
+	return nil
+}
+
+// GetByKey retrieves an unexpired record by its key
+func (m *MockIdempotencyRepository) GetByKey(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	if m.GetByKeyFunc != nil {
+		return m.GetByKeyFunc(ctx, key)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	record, ok := m.records[key]
+	if !ok || !record.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrNotFound
+	}
+
+	found := *record
+	return &found, nil
+}
+
+// Complete stores the response of an in-progress record
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	if m.CompleteFunc != nil {
+		return m.CompleteFunc(ctx, key, statusCode, contentType, body)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.records[key]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	record.Completed = true
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.Body = body
+	return nil
+}
+
+// Delete deletes a record so its key can be used again
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, key string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, key)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+	return nil
+}
@@ -0,0 +1,190 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockInvitationRepository is a mock implementation of domain.InvitationRepository
+type MockInvitationRepository struct {
+	mu          sync.RWMutex
+	invitations map[string]*domain.Invitation
+
+	// Hooks for customizing behavior
+	CreateFunc         func(ctx context.Context, invitation *domain.Invitation) error
+	GetByIDFunc        func(ctx context.Context, id string) (*domain.Invitation, error)
+	GetByTokenHashFunc func(ctx context.Context, hash string) (*domain.Invitation, error)
+	ConsumeFunc        func(ctx context.Context, hash string) error
+	UpdateFunc         func(ctx context.Context, invitation *domain.Invitation) error
+	DeleteFunc         func(ctx context.Context, id string) error
+	ListFunc           func(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.Invitation], error)
+}
+
+// NewMockInvitationRepository creates a new MockInvitationRepository
+func NewMockInvitationRepository() *MockInvitationRepository {
+	return &MockInvitationRepository{
+		invitations: make(map[string]*domain.Invitation),
+	}
+}
+
+// Create stores a new invitation
+func (m *MockInvitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, invitation)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	invitation.CreatedAt = now
+	invitation.SentAt = now
+	stored := *invitation
+	m.invitations[invitation.ID.Hex()] = &stored
+	return nil
+}
+
+// GetByID retrieves an invitation by the ID of its pending user
+func (m *MockInvitationRepository) GetByID(ctx context.Context, id string) (*domain.Invitation, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	invitation, ok := m.invitations[id]
+	if !ok {
+		return nil, domain.ErrInvitationNotFound
+	}
+	result := *invitation
+	return &result, nil
+}
+
+// GetByTokenHash retrieves an unexpired invitation by the hash of its token
+func (m *MockInvitationRepository) GetByTokenHash(ctx context.Context, hash string) (*domain.Invitation, error) {
+	if m.GetByTokenHashFunc != nil {
+		return m.GetByTokenHashFunc(ctx, hash)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	invitation := m.findByTokenHash(hash)
+	if invitation == nil {
+		return nil, domain.ErrInvalidInvitation
+	}
+	result := *invitation
+	return &result, nil
+}
+
+// Consume deletes an unexpired invitation by the hash of its token
+func (m *MockInvitationRepository) Consume(ctx context.Context, hash string) error {
+	if m.ConsumeFunc != nil {
+		return m.ConsumeFunc(ctx, hash)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	invitation := m.findByTokenHash(hash)
+	if invitation == nil {
+		return domain.ErrInvalidInvitation
+	}
+	delete(m.invitations, invitation.ID.Hex())
+	return nil
+}
+
+// Update replaces the address, token and expiry of an invitation
+func (m *MockInvitationRepository) Update(ctx context.Context, invitation *domain.Invitation) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, invitation)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.invitations[invitation.ID.Hex()]
+	if !ok {
+		return domain.ErrInvitationNotFound
+	}
+	invitation.SentAt = time.Now()
+	stored.Email = invitation.Email
+	stored.TokenHash = invitation.TokenHash
+	stored.SentAt = invitation.SentAt
+	stored.ExpiresAt = invitation.ExpiresAt
+	return nil
+}
+
+// Delete deletes an invitation by the ID of its pending user
+func (m *MockInvitationRepository) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.invitations[id]; !ok {
+		return domain.ErrInvitationNotFound
+	}
+	delete(m.invitations, id)
+	return nil
+}
+
+// List retrieves invitations with pagination, newest first
+func (m *MockInvitationRepository) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.Invitation], error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, params)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	invitations := []domain.Invitation{}
+	for _, invitation := range m.invitations {
+		invitations = append(invitations, *invitation)
+	}
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.After(invitations[j].CreatedAt)
+	})
+
+	total := int64(len(invitations))
+
+	// Apply pagination
+	start := params.Offset()
+	end := start + params.Limit
+	if start >= len(invitations) {
+		invitations = []domain.Invitation{}
+	} else {
+		if end > len(invitations) {
+			end = len(invitations)
+		}
+		invitations = invitations[start:end]
+	}
+
+	return domain.NewPaginatedResult(invitations, total, params), nil
+}
+
+// findByTokenHash returns the unexpired invitation with a token hash. The caller holds the lock.
+func (m *MockInvitationRepository) findByTokenHash(hash string) *domain.Invitation {
+	for _, invitation := range m.invitations {
+		if invitation.TokenHash == hash && !invitation.IsExpired() {
+			return invitation
+		}
+	}
+	return nil
+}
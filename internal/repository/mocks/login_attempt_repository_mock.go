@@ -0,0 +1,105 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+)
+
+// MockLoginAttemptRepository is a mock implementation of domain.LoginAttemptRepository
+type MockLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*domain.LoginAttempts
+
+	// Hooks for customizing behavior
+	GetFunc           func(ctx context.Context, key string) (*domain.LoginAttempts, error)
+	RecordFailureFunc func(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempts, error)
+	LockFunc          func(ctx context.Context, key string, until time.Time) error
+	ResetFunc         func(ctx context.Context, key string) error
+}
+
+// NewMockLoginAttemptRepository creates a new MockLoginAttemptRepository
+func NewMockLoginAttemptRepository() *MockLoginAttemptRepository {
+	return &MockLoginAttemptRepository{
+		attempts: make(map[string]*domain.LoginAttempts),
+	}
+}
+
+// Get retrieves the unexpired failed logins of a key
+func (m *MockLoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, key)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempts, ok := m.attempts[key]
+	if !ok || !attempts.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrNotFound
+	}
+	result := *attempts
+	return &result, nil
+}
+
+// RecordFailure counts a failed login for a key
+func (m *MockLoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempts, error) {
+	if m.RecordFailureFunc != nil {
+		return m.RecordFailureFunc(ctx, key, window)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	attempts, ok := m.attempts[key]
+	if !ok || !attempts.ExpiresAt.After(now) {
+		attempts = &domain.LoginAttempts{Key: key}
+		m.attempts[key] = attempts
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = now
+	attempts.ExpiresAt = now.Add(window)
+	if attempts.LockedUntil != nil && attempts.LockedUntil.After(attempts.ExpiresAt) {
+		attempts.ExpiresAt = *attempts.LockedUntil
+	}
+
+	result := *attempts
+	return &result, nil
+}
+
+// Lock refuses logins for a key until the given time
+func (m *MockLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	if m.LockFunc != nil {
+		return m.LockFunc(ctx, key, until)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempts, ok := m.attempts[key]
+	if !ok {
+		attempts = &domain.LoginAttempts{Key: key, LastFailureAt: time.Now()}
+		m.attempts[key] = attempts
+	}
+	attempts.LockedUntil = &until
+	if until.After(attempts.ExpiresAt) {
+		attempts.ExpiresAt = until
+	}
+	return nil
+}
+
+// Reset forgets the failed logins of a key
+func (m *MockLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	if m.ResetFunc != nil {
+		return m.ResetFunc(ctx, key)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.attempts, key)
+	return nil
+}
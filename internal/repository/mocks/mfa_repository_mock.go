@@ -0,0 +1,123 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+)
+
+// MockMFARepository is a mock implementation of domain.MFARepository
+type MockMFARepository struct {
+	mu      sync.RWMutex
+	factors map[string]*domain.TOTPFactor
+
+	// Hooks for customizing behavior
+	SaveFunc            func(ctx context.Context, factor *domain.TOTPFactor) error
+	GetByUserIDFunc     func(ctx context.Context, userID string) (*domain.TOTPFactor, error)
+	UseStepFunc         func(ctx context.Context, userID string, step int64) error
+	UseRecoveryCodeFunc func(ctx context.Context, userID, codeHash string) error
+	DeleteFunc          func(ctx context.Context, userID string) error
+}
+
+// NewMockMFARepository creates a new MockMFARepository
+func NewMockMFARepository() *MockMFARepository {
+	return &MockMFARepository{
+		factors: make(map[string]*domain.TOTPFactor),
+	}
+}
+
+// Save creates or replaces the TOTP factor of a user
+func (m *MockMFARepository) Save(ctx context.Context, factor *domain.TOTPFactor) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, factor)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if factor.CreatedAt.IsZero() {
+		factor.CreatedAt = now
+	}
+	factor.UpdatedAt = now
+
+	stored := *factor
+	stored.RecoveryCodes = append([]string{}, factor.RecoveryCodes...)
+	m.factors[factor.UserID.Hex()] = &stored
+	return nil
+}
+
+// GetByUserID retrieves the TOTP factor of a user
+func (m *MockMFARepository) GetByUserID(ctx context.Context, userID string) (*domain.TOTPFactor, error) {
+	if m.GetByUserIDFunc != nil {
+		return m.GetByUserIDFunc(ctx, userID)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	factor, ok := m.factors[userID]
+	if !ok {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	result := *factor
+	result.RecoveryCodes = append([]string{}, factor.RecoveryCodes...)
+	return &result, nil
+}
+
+// UseStep records a time step as used unless it or a later step already was
+func (m *MockMFARepository) UseStep(ctx context.Context, userID string, step int64) error {
+	if m.UseStepFunc != nil {
+		return m.UseStepFunc(ctx, userID, step)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	factor, ok := m.factors[userID]
+	if !ok || factor.LastUsedStep >= step {
+		return domain.ErrInvalidMFACode
+	}
+	factor.LastUsedStep = step
+	return nil
+}
+
+// UseRecoveryCode removes a recovery code by its hash
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	if m.UseRecoveryCodeFunc != nil {
+		return m.UseRecoveryCodeFunc(ctx, userID, codeHash)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	factor, ok := m.factors[userID]
+	if !ok {
+		return domain.ErrInvalidMFACode
+	}
+	for i, stored := range factor.RecoveryCodes {
+		if stored == codeHash {
+			factor.RecoveryCodes = append(factor.RecoveryCodes[:i:i], factor.RecoveryCodes[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrInvalidMFACode
+}
+
+// Delete removes the TOTP factor of a user
+func (m *MockMFARepository) Delete(ctx context.Context, userID string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, userID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.factors[userID]; !ok {
+		return domain.ErrMFANotEnrolled
+	}
+	delete(m.factors, userID)
+	return nil
+}
@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+)
+
+// MockPasswordHistoryRepository is a mock implementation of domain.PasswordHistoryRepository
+type MockPasswordHistoryRepository struct {
+	mu     sync.Mutex
+	hashes map[string][]string // Newest first
+
+	// Hooks for customizing behavior
+	ListFunc func(ctx context.Context, userID string, limit int) ([]string, error)
+	AddFunc  func(ctx context.Context, userID, hash string, keep int) error
+}
+
+// NewMockPasswordHistoryRepository creates a new MockPasswordHistoryRepository
+func NewMockPasswordHistoryRepository() *MockPasswordHistoryRepository {
+	return &MockPasswordHistoryRepository{
+		hashes: make(map[string][]string),
+	}
+}
+
+// List returns the most recent previous password hashes of a user, newest first
+func (m *MockPasswordHistoryRepository) List(ctx context.Context, userID string, limit int) ([]string, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, userID, limit)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hashes := m.hashes[userID]
+	if len(hashes) > limit {
+		hashes = hashes[:limit]
+	}
+	return append([]string(nil), hashes...), nil
+}
+
+// Add records a previous password hash of a user, keeping only the newest keep hashes
+func (m *MockPasswordHistoryRepository) Add(ctx context.Context, userID, hash string, keep int) error {
+	if m.AddFunc != nil {
+		return m.AddFunc(ctx, userID, hash, keep)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hashes := append([]string{hash}, m.hashes[userID]...)
+	if len(hashes) > keep {
+		hashes = hashes[:keep]
+	}
+	m.hashes[userID] = hashes
+	return nil
+}
@@ -0,0 +1,124 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockRefreshTokenRepository is a mock implementation of domain.RefreshTokenRepository
+type MockRefreshTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]*domain.RefreshToken
+
+	// Hooks for customizing behavior
+	CreateFunc           func(ctx context.Context, token *domain.RefreshToken) error
+	GetByIDFunc          func(ctx context.Context, id string) (*domain.RefreshToken, error)
+	RotateFunc           func(ctx context.Context, id, replacedBy string) error
+	RevokeFamilyFunc     func(ctx context.Context, familyID string) error
+	RevokeAllForUserFunc func(ctx context.Context, userID string) error
+}
+
+// NewMockRefreshTokenRepository creates a new MockRefreshTokenRepository
+func NewMockRefreshTokenRepository() *MockRefreshTokenRepository {
+	return &MockRefreshTokenRepository{
+		tokens: make(map[string]*domain.RefreshToken),
+	}
+}
+
+// Create stores a newly issued refresh token
+func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, token)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *token
+	m.tokens[token.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves an unexpired refresh token by its ID
+func (m *MockRefreshTokenRepository) GetByID(ctx context.Context, id string) (*domain.RefreshToken, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	token, ok := m.tokens[id]
+	if !ok || !token.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrNotFound
+	}
+
+	found := *token
+	return &found, nil
+}
+
+// Rotate marks an active token as replaced by its successor
+func (m *MockRefreshTokenRepository) Rotate(ctx context.Context, id, replacedBy string) error {
+	if m.RotateFunc != nil {
+		return m.RotateFunc(ctx, id, replacedBy)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, ok := m.tokens[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if token.IsRotated() || token.IsRevoked() {
+		return domain.ErrRefreshTokenReused
+	}
+
+	token.ReplacedBy = replacedBy
+	return nil
+}
+
+// RevokeFamily revokes every token in a token family
+func (m *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	if m.RevokeFamilyFunc != nil {
+		return m.RevokeFamilyFunc(ctx, familyID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range m.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// RevokeAllForUser revokes every token issued to a user
+func (m *MockRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	if m.RevokeAllForUserFunc != nil {
+		return m.RevokeAllForUserFunc(ctx, userID)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range m.tokens {
+		if token.UserID == objectID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
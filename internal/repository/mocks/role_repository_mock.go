@@ -0,0 +1,125 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+)
+
+// MockRoleRepository is a mock implementation of domain.RoleRepository
+type MockRoleRepository struct {
+	mu    sync.RWMutex
+	roles map[string]*domain.Role
+
+	// Hooks for customizing behavior
+	CreateFunc    func(ctx context.Context, role *domain.Role) error
+	GetByNameFunc func(ctx context.Context, name string) (*domain.Role, error)
+	ListFunc      func(ctx context.Context) ([]domain.Role, error)
+	UpdateFunc    func(ctx context.Context, role *domain.Role) error
+	DeleteFunc    func(ctx context.Context, name string) error
+}
+
+// NewMockRoleRepository creates a new MockRoleRepository
+func NewMockRoleRepository() *MockRoleRepository {
+	return &MockRoleRepository{
+		roles: make(map[string]*domain.Role),
+	}
+}
+
+// Create stores a new role
+func (m *MockRoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, role)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.roles[role.Name]; ok {
+		return domain.ErrRoleExists
+	}
+
+	now := time.Now()
+	role.CreatedAt = now
+	role.UpdatedAt = now
+
+	stored := *role
+	m.roles[role.Name] = &stored
+	return nil
+}
+
+// GetByName retrieves a role by its name
+func (m *MockRoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	if m.GetByNameFunc != nil {
+		return m.GetByNameFunc(ctx, name)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	role, ok := m.roles[name]
+	if !ok {
+		return nil, domain.ErrRoleNotFound
+	}
+
+	found := *role
+	return &found, nil
+}
+
+// List retrieves all roles ordered by name
+func (m *MockRoleRepository) List(ctx context.Context) ([]domain.Role, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	roles := make([]domain.Role, 0, len(m.roles))
+	for _, role := range m.roles {
+		roles = append(roles, *role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+// Update updates the description and permissions of a role
+func (m *MockRoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, role)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.roles[role.Name]
+	if !ok {
+		return domain.ErrRoleNotFound
+	}
+
+	role.UpdatedAt = time.Now()
+	stored.Description = role.Description
+	stored.Permissions = role.Permissions
+	stored.UpdatedAt = role.UpdatedAt
+	return nil
+}
+
+// Delete deletes a role by its name
+func (m *MockRoleRepository) Delete(ctx context.Context, name string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.roles[name]; !ok {
+		return domain.ErrRoleNotFound
+	}
+
+	delete(m.roles, name)
+	return nil
+}
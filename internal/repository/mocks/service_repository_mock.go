@@ -18,6 +18,7 @@ type MockServiceRepository struct {
 	CreateFunc  func(ctx context.Context, service *domain.Service) error
 	GetByIDFunc func(ctx context.Context, id string) (*domain.Service, error)
 	UpdateFunc  func(ctx context.Context, service *domain.Service) error
+	RestoreFunc func(ctx context.Context, previous *domain.Service) error
 	DeleteFunc  func(ctx context.Context, id string) error
 	ListFunc    func(ctx context.Context, params domain.ListParams) (*domain.PaginatedResult[domain.Service], error)
 }
@@ -86,6 +87,26 @@ func (m *MockServiceRepository) Update(ctx context.Context, service *domain.Serv
 	return nil
 }
 
+// Restore writes back the state of a service before an update
+func (m *MockServiceRepository) Restore(ctx context.Context, previous *domain.Service) error {
+	if m.RestoreFunc != nil {
+		return m.RestoreFunc(ctx, previous)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := previous.ID.Hex()
+	current, ok := m.services[id]
+	if !ok || current.Revision != previous.Revision+1 {
+		return domain.ErrServiceChanged
+	}
+
+	restored := *previous
+	m.services[id] = &restored
+	return nil
+}
+
 // Delete deletes a service by its ID
 func (m *MockServiceRepository) Delete(ctx context.Context, id string) error {
 	if m.DeleteFunc != nil {
@@ -114,6 +135,9 @@ func (m *MockServiceRepository) List(ctx context.Context, params domain.ListPara
 
 	var services []domain.Service
 	for _, s := range m.services {
+		if params.Team != "" && (s.TeamID == nil || s.TeamID.Hex() != params.Team) {
+			continue
+		}
 		services = append(services, *s)
 	}
 
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -20,6 +21,9 @@ type MockServiceVersionRepository struct {
 	GetByServiceIDAndRevisionFunc func(ctx context.Context, serviceID string, revision int) (*domain.ServiceVersion, error)
 	ListByServiceIDFunc           func(ctx context.Context, serviceID string, params domain.PaginationParams) (*domain.PaginatedResult[domain.ServiceVersion], error)
 	DeleteByServiceIDFunc         func(ctx context.Context, serviceID string) error
+	ListServiceIDsFunc            func(ctx context.Context) ([]string, error)
+	ListByAuthorFunc              func(ctx context.Context, authorID string) ([]domain.ServiceVersion, error)
+	AnonymizeAuthorFunc           func(ctx context.Context, authorID string) error
 }
 
 // NewMockServiceVersionRepository creates a new MockServiceVersionRepository
@@ -38,6 +42,19 @@ func (m *MockServiceVersionRepository) Create(ctx context.Context, version *doma
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if version.Revision > 1 {
+		// A version with no PrevHash may start a new segment after a gap
+		chained := version.PrevHash == ""
+		for _, v := range m.versions {
+			if v.ServiceID == version.ServiceID && v.Revision == version.Revision-1 {
+				chained = v.Hash == version.PrevHash
+			}
+		}
+		if !chained {
+			return domain.ErrPreviousVersionMissing
+		}
+	}
+
 	if version.ID.IsZero() {
 		version.ID = primitive.NewObjectID()
 	}
@@ -98,6 +115,11 @@ func (m *MockServiceVersionRepository) ListByServiceID(ctx context.Context, serv
 		}
 	}
 
+	// Sort by revision descending (newest first)
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Revision > versions[j].Revision
+	})
+
 	total := int64(len(versions))
 
 	// Apply pagination
@@ -132,6 +154,70 @@ func (m *MockServiceVersionRepository) DeleteByServiceID(ctx context.Context, se
 	return nil
 }
 
+// ListServiceIDs retrieves the IDs of every service that has versions, oldest first
+func (m *MockServiceVersionRepository) ListServiceIDs(ctx context.Context) ([]string, error) {
+	if m.ListServiceIDsFunc != nil {
+		return m.ListServiceIDsFunc(ctx)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	ids := []string{}
+	for _, v := range m.versions {
+		if id := v.ServiceID.Hex(); !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ListByAuthor retrieves every version a user authored, oldest first
+func (m *MockServiceVersionRepository) ListByAuthor(ctx context.Context, authorID string) ([]domain.ServiceVersion, error) {
+	if m.ListByAuthorFunc != nil {
+		return m.ListByAuthorFunc(ctx, authorID)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	versions := []domain.ServiceVersion{}
+	for _, v := range m.versions {
+		if v.AuthorID != nil && v.AuthorID.Hex() == authorID {
+			versions = append(versions, *v)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if !versions[i].CreatedAt.Equal(versions[j].CreatedAt) {
+			return versions[i].CreatedAt.Before(versions[j].CreatedAt)
+		}
+		return versions[i].ID.Hex() < versions[j].ID.Hex()
+	})
+	return versions, nil
+}
+
+// AnonymizeAuthor clears the author of every version a user authored and marks the author deleted
+func (m *MockServiceVersionRepository) AnonymizeAuthor(ctx context.Context, authorID string) error {
+	if m.AnonymizeAuthorFunc != nil {
+		return m.AnonymizeAuthorFunc(ctx, authorID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, v := range m.versions {
+		if v.AuthorID != nil && v.AuthorID.Hex() == authorID {
+			v.AuthorID = nil
+			v.AuthorSalt = ""
+			v.AuthorDeleted = true
+		}
+	}
+	return nil
+}
+
 // AddVersion adds a version directly to the mock (for test setup)
 func (m *MockServiceVersionRepository) AddVersion(version *domain.ServiceVersion) {
 	m.mu.Lock()
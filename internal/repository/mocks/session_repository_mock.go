@@ -0,0 +1,153 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockSessionRepository is a mock implementation of domain.SessionRepository
+type MockSessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]*domain.Session
+
+	// Hooks for customizing behavior
+	CreateFunc           func(ctx context.Context, session *domain.Session) error
+	GetByIDFunc          func(ctx context.Context, id string) (*domain.Session, error)
+	ListByUserFunc       func(ctx context.Context, userID string) ([]domain.Session, error)
+	TouchFunc            func(ctx context.Context, id string, client domain.ClientInfo, expiresAt time.Time) error
+	RevokeFunc           func(ctx context.Context, id string) error
+	RevokeAllForUserFunc func(ctx context.Context, userID string) error
+}
+
+// NewMockSessionRepository creates a new MockSessionRepository
+func NewMockSessionRepository() *MockSessionRepository {
+	return &MockSessionRepository{
+		sessions: make(map[string]*domain.Session),
+	}
+}
+
+// Create stores a new session
+func (m *MockSessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, session)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *session
+	m.sessions[session.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves an unexpired session by its ID
+func (m *MockSessionRepository) GetByID(ctx context.Context, id string) (*domain.Session, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, ok := m.sessions[id]
+	if !ok || !session.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	found := *session
+	return &found, nil
+}
+
+// ListByUser retrieves the active sessions of a user, most recently seen first
+func (m *MockSessionRepository) ListByUser(ctx context.Context, userID string) ([]domain.Session, error) {
+	if m.ListByUserFunc != nil {
+		return m.ListByUserFunc(ctx, userID)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sessions := []domain.Session{}
+	for _, session := range m.sessions {
+		if session.UserID == objectID && session.IsActive() {
+			sessions = append(sessions, *session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// Touch records activity on a session and extends its expiry
+func (m *MockSessionRepository) Touch(ctx context.Context, id string, client domain.ClientInfo, expiresAt time.Time) error {
+	if m.TouchFunc != nil {
+		return m.TouchFunc(ctx, id, client, expiresAt)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return domain.ErrSessionNotFound
+	}
+
+	session.UserAgent = client.UserAgent
+	session.IPAddress = client.IPAddress
+	session.LastSeenAt = time.Now()
+	session.ExpiresAt = expiresAt
+	return nil
+}
+
+// Revoke revokes a session
+func (m *MockSessionRepository) Revoke(ctx context.Context, id string) error {
+	if m.RevokeFunc != nil {
+		return m.RevokeFunc(ctx, id)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return domain.ErrSessionNotFound
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	return nil
+}
+
+// RevokeAllForUser revokes every session of a user
+func (m *MockSessionRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	if m.RevokeAllForUserFunc != nil {
+		return m.RevokeAllForUserFunc(ctx, userID)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, session := range m.sessions {
+		if session.UserID == objectID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+	return nil
+}
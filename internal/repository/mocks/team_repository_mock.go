@@ -0,0 +1,279 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockTeamRepository is a mock implementation of domain.TeamRepository
+type MockTeamRepository struct {
+	mu    sync.RWMutex
+	teams map[string]*domain.Team
+
+	// Hooks for customizing behavior
+	CreateFunc       func(ctx context.Context, team *domain.Team) error
+	GetByIDFunc      func(ctx context.Context, id string) (*domain.Team, error)
+	ListFunc         func(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.Team], error)
+	ListByMemberFunc func(ctx context.Context, userID string) ([]domain.Team, error)
+	UpdateFunc       func(ctx context.Context, team *domain.Team) error
+	DeleteFunc       func(ctx context.Context, id string) error
+	SetMemberFunc    func(ctx context.Context, teamID string, member domain.TeamMember) error
+	RemoveMemberFunc func(ctx context.Context, teamID, userID string) error
+	RemoveUserFunc   func(ctx context.Context, userID string) error
+}
+
+// NewMockTeamRepository creates a new MockTeamRepository
+func NewMockTeamRepository() *MockTeamRepository {
+	return &MockTeamRepository{
+		teams: make(map[string]*domain.Team),
+	}
+}
+
+// Create stores a new team with its initial members
+func (m *MockTeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, team)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.nameTaken(team.Name, "") {
+		return domain.ErrTeamExists
+	}
+
+	now := time.Now()
+	team.ID = primitive.NewObjectID()
+	team.CreatedAt = now
+	team.UpdatedAt = now
+	if team.Members == nil {
+		team.Members = []domain.TeamMember{}
+	}
+	for i := range team.Members {
+		team.Members[i].AddedAt = now
+	}
+	m.teams[team.ID.Hex()] = copyTeam(team)
+	return nil
+}
+
+// GetByID retrieves a team and its members by its ID
+func (m *MockTeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	team, ok := m.teams[id]
+	if !ok {
+		return nil, domain.ErrTeamNotFound
+	}
+	return copyTeam(team), nil
+}
+
+// List retrieves teams with pagination, ordered by name
+func (m *MockTeamRepository) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.Team], error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, params)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	teams := m.sorted(func(*domain.Team) bool { return true })
+	total := int64(len(teams))
+
+	// Apply pagination
+	start := params.Offset()
+	end := start + params.Limit
+	if start >= len(teams) {
+		teams = []domain.Team{}
+	} else {
+		if end > len(teams) {
+			end = len(teams)
+		}
+		teams = teams[start:end]
+	}
+
+	return domain.NewPaginatedResult(teams, total, params), nil
+}
+
+// ListByMember retrieves the teams a user belongs to, ordered by name
+func (m *MockTeamRepository) ListByMember(ctx context.Context, userID string) ([]domain.Team, error) {
+	if m.ListByMemberFunc != nil {
+		return m.ListByMemberFunc(ctx, userID)
+	}
+
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.sorted(func(team *domain.Team) bool { return team.Member(userID) != nil }), nil
+}
+
+// Update updates the name and description of a team
+func (m *MockTeamRepository) Update(ctx context.Context, team *domain.Team) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, team)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.teams[team.ID.Hex()]
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+	if m.nameTaken(team.Name, team.ID.Hex()) {
+		return domain.ErrTeamExists
+	}
+
+	team.UpdatedAt = time.Now()
+	stored.Name = team.Name
+	stored.Description = team.Description
+	stored.UpdatedAt = team.UpdatedAt
+	return nil
+}
+
+// Delete deletes a team and its memberships by its ID
+func (m *MockTeamRepository) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.teams[id]; !ok {
+		return domain.ErrTeamNotFound
+	}
+	delete(m.teams, id)
+	return nil
+}
+
+// SetMember adds a member to a team or changes the role of an existing member
+func (m *MockTeamRepository) SetMember(ctx context.Context, teamID string, member domain.TeamMember) error {
+	if m.SetMemberFunc != nil {
+		return m.SetMemberFunc(ctx, teamID, member)
+	}
+
+	if _, err := primitive.ObjectIDFromHex(teamID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	team, ok := m.teams[teamID]
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+
+	now := time.Now()
+	team.UpdatedAt = now
+	if existing := team.Member(member.UserID.Hex()); existing != nil {
+		existing.Role = member.Role
+		return nil
+	}
+	member.AddedAt = now
+	team.Members = append(team.Members, member)
+	return nil
+}
+
+// RemoveMember removes a user from a team
+func (m *MockTeamRepository) RemoveMember(ctx context.Context, teamID, userID string) error {
+	if m.RemoveMemberFunc != nil {
+		return m.RemoveMemberFunc(ctx, teamID, userID)
+	}
+
+	if _, err := primitive.ObjectIDFromHex(teamID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	team, ok := m.teams[teamID]
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+	if !removeTeamMember(team, userID) {
+		return domain.ErrTeamMemberNotFound
+	}
+	team.UpdatedAt = time.Now()
+	return nil
+}
+
+// RemoveUser removes a user from every team they belong to
+func (m *MockTeamRepository) RemoveUser(ctx context.Context, userID string) error {
+	if m.RemoveUserFunc != nil {
+		return m.RemoveUserFunc(ctx, userID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, team := range m.teams {
+		removeTeamMember(team, userID)
+	}
+	return nil
+}
+
+// nameTaken checks if another team than exceptID has a name. The caller holds the lock.
+func (m *MockTeamRepository) nameTaken(name, exceptID string) bool {
+	for id, team := range m.teams {
+		if team.Name == name && id != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// sorted returns copies of the teams matching keep, ordered by name. The caller holds the lock.
+func (m *MockTeamRepository) sorted(keep func(*domain.Team) bool) []domain.Team {
+	teams := []domain.Team{}
+	for _, team := range m.teams {
+		if keep(team) {
+			teams = append(teams, *copyTeam(team))
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+	return teams
+}
+
+// removeTeamMember removes a user from a team, reporting whether they were a member
+func removeTeamMember(team *domain.Team, userID string) bool {
+	for i, member := range team.Members {
+		if member.UserID.Hex() == userID {
+			team.Members = append(team.Members[:i:i], team.Members[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// copyTeam returns a copy of a team that doesn't share its members
+func copyTeam(team *domain.Team) *domain.Team {
+	result := *team
+	result.Members = append([]domain.TeamMember{}, team.Members...)
+	return &result
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,13 +16,16 @@ type MockUserRepository struct {
 	users map[string]*domain.User
 
 	// Hooks for customizing behavior
-	CreateFunc        func(ctx context.Context, user *domain.User) error
-	GetByIDFunc       func(ctx context.Context, id string) (*domain.User, error)
-	GetByEmailFunc    func(ctx context.Context, email string) (*domain.User, error)
-	UpdateFunc        func(ctx context.Context, user *domain.User) error
-	DeleteFunc        func(ctx context.Context, id string) error
-	ListFunc          func(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.User], error)
-	ExistsByEmailFunc func(ctx context.Context, email string) (bool, error)
+	CreateFunc          func(ctx context.Context, user *domain.User) error
+	GetByIDFunc         func(ctx context.Context, id string) (*domain.User, error)
+	GetByEmailFunc      func(ctx context.Context, email string) (*domain.User, error)
+	UpdateFunc          func(ctx context.Context, user *domain.User) error
+	DeleteFunc          func(ctx context.Context, id string) error
+	ListFunc            func(ctx context.Context, params domain.UserListParams) (*domain.PaginatedResult[domain.User], error)
+	RecordLoginFunc     func(ctx context.Context, id string, at time.Time) error
+	ListDeletionDueFunc func(ctx context.Context, at time.Time) ([]domain.User, error)
+	ExistsByEmailFunc   func(ctx context.Context, email string) (bool, error)
+	CountByRoleFunc     func(ctx context.Context, role string) (int64, error)
 }
 
 // NewMockUserRepository creates a new MockUserRepository
@@ -135,7 +139,7 @@ func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
 }
 
 // List retrieves users with pagination
-func (m *MockUserRepository) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.User], error) {
+func (m *MockUserRepository) List(ctx context.Context, params domain.UserListParams) (*domain.PaginatedResult[domain.User], error) {
 	if m.ListFunc != nil {
 		return m.ListFunc(ctx, params)
 	}
@@ -145,14 +149,16 @@ func (m *MockUserRepository) List(ctx context.Context, params domain.PaginationP
 
 	var users []domain.User
 	for _, u := range m.users {
-		users = append(users, *u)
+		if matchesUserListParams(u, params) {
+			users = append(users, *u)
+		}
 	}
 
 	total := int64(len(users))
 
 	// Apply pagination
-	start := params.Offset()
-	end := start + params.Limit
+	start := params.Pagination.Offset()
+	end := start + params.Pagination.Limit
 	if start >= len(users) {
 		users = []domain.User{}
 	} else {
@@ -162,7 +168,63 @@ func (m *MockUserRepository) List(ctx context.Context, params domain.PaginationP
 		users = users[start:end]
 	}
 
-	return domain.NewPaginatedResult(users, total, params), nil
+	return domain.NewPaginatedResult(users, total, params.Pagination), nil
+}
+
+// RecordLogin sets the time a user last logged in
+func (m *MockUserRepository) RecordLogin(ctx context.Context, id string, at time.Time) error {
+	if m.RecordLoginFunc != nil {
+		return m.RecordLoginFunc(ctx, id, at)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.LastLoginAt = &at
+	return nil
+}
+
+// ListDeletionDue retrieves the users whose scheduled deletion is due at the given time
+func (m *MockUserRepository) ListDeletionDue(ctx context.Context, at time.Time) ([]domain.User, error) {
+	if m.ListDeletionDueFunc != nil {
+		return m.ListDeletionDueFunc(ctx, at)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := []domain.User{}
+	for _, u := range m.users {
+		if u.DeletionScheduledAt != nil && !u.DeletionScheduledAt.After(at) {
+			users = append(users, *u)
+		}
+	}
+	return users, nil
+}
+
+// matchesUserListParams checks if a user passes the filters of a user listing
+func matchesUserListParams(user *domain.User, params domain.UserListParams) bool {
+	if params.Role != "" && user.Role != params.Role {
+		return false
+	}
+	if params.Active != nil && user.Active != *params.Active {
+		return false
+	}
+	if params.CreatedAfter != nil && !user.CreatedAt.After(*params.CreatedAfter) {
+		return false
+	}
+	for _, word := range strings.Fields(strings.ToLower(params.Query)) {
+		if !strings.Contains(strings.ToLower(user.FirstName), word) &&
+			!strings.Contains(strings.ToLower(user.LastName), word) &&
+			!strings.Contains(strings.ToLower(user.Email), word) {
+			return false
+		}
+	}
+	return true
 }
 
 // ExistsByEmail checks if a user with the given email exists
@@ -182,6 +244,24 @@ func (m *MockUserRepository) ExistsByEmail(ctx context.Context, email string) (b
 	return false, nil
 }
 
+// CountByRole counts the users assigned a role
+func (m *MockUserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	if m.CountByRoleFunc != nil {
+		return m.CountByRoleFunc(ctx, role)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var count int64
+	for _, user := range m.users {
+		if user.Role == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
 // AddUser adds a user directly to the mock (for test setup)
 func (m *MockUserRepository) AddUser(user *domain.User) {
 	m.mu.Lock()
@@ -0,0 +1,76 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+)
+
+// MockUserTokenRepository is a mock implementation of domain.UserTokenRepository
+type MockUserTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*domain.UserToken
+
+	// Hooks for customizing behavior
+	CreateFunc        func(ctx context.Context, token *domain.UserToken) error
+	ConsumeFunc       func(ctx context.Context, hash, purpose string) (*domain.UserToken, error)
+	DeleteForUserFunc func(ctx context.Context, userID, purpose string) error
+}
+
+// NewMockUserTokenRepository creates a new MockUserTokenRepository
+func NewMockUserTokenRepository() *MockUserTokenRepository {
+	return &MockUserTokenRepository{
+		tokens: make(map[string]*domain.UserToken),
+	}
+}
+
+// Create stores a new token
+func (m *MockUserTokenRepository) Create(ctx context.Context, token *domain.UserToken) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, token)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token.CreatedAt = time.Now()
+	stored := *token
+	m.tokens[token.Hash] = &stored
+	return nil
+}
+
+// Consume deletes and returns an unexpired token
+func (m *MockUserTokenRepository) Consume(ctx context.Context, hash, purpose string) (*domain.UserToken, error) {
+	if m.ConsumeFunc != nil {
+		return m.ConsumeFunc(ctx, hash, purpose)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, ok := m.tokens[hash]
+	if !ok || token.Purpose != purpose || !token.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrInvalidUserToken
+	}
+	delete(m.tokens, hash)
+	return token, nil
+}
+
+// DeleteForUser deletes the outstanding tokens of a user for a purpose
+func (m *MockUserTokenRepository) DeleteForUser(ctx context.Context, userID, purpose string) error {
+	if m.DeleteForUserFunc != nil {
+		return m.DeleteForUserFunc(ctx, userID, purpose)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for hash, token := range m.tokens {
+		if token.UserID.Hex() == userID && token.Purpose == purpose {
+			delete(m.tokens, hash)
+		}
+	}
+	return nil
+}
@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
+	"github.com/services-api/pkg/metrics"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -30,12 +32,13 @@ func ConnectMongoDB(ctx context.Context, uri string) (*mongo.Client, error) {
 		clientOptions.SetMaxPoolSize(100)
 		clientOptions.SetMinPoolSize(10)
 		clientOptions.SetMaxConnIdleTime(30 * time.Second)
+		clientOptions.SetMonitor(commandMonitor())
 
 		client, err = mongo.Connect(ctx, clientOptions)
 		if err != nil {
-			log.Printf("MongoDB connection attempt %d failed: %v", attempt, err)
+			slog.Warn("MongoDB connection attempt failed", "attempt", attempt, "error", err)
 			if attempt < maxRetries {
-				log.Printf("Retrying in %v...", delay)
+				slog.Info("Retrying MongoDB connection", "delay", delay.String())
 				time.Sleep(delay)
 				delay = min(delay*backoffFactor, maxDelay)
 				continue
@@ -45,9 +48,9 @@ func ConnectMongoDB(ctx context.Context, uri string) (*mongo.Client, error) {
 
 		// Ping to verify connection
 		if err = client.Ping(ctx, nil); err != nil {
-			log.Printf("MongoDB ping attempt %d failed: %v", attempt, err)
+			slog.Warn("MongoDB ping attempt failed", "attempt", attempt, "error", err)
 			if attempt < maxRetries {
-				log.Printf("Retrying in %v...", delay)
+				slog.Info("Retrying MongoDB connection", "delay", delay.String())
 				time.Sleep(delay)
 				delay = min(delay*backoffFactor, maxDelay)
 				continue
@@ -55,7 +58,7 @@ func ConnectMongoDB(ctx context.Context, uri string) (*mongo.Client, error) {
 			return nil, fmt.Errorf("failed to ping MongoDB after %d attempts: %w", maxRetries, err)
 		}
 
-		log.Println("Successfully connected to MongoDB")
+		slog.Info("Successfully connected to MongoDB")
 		return client, nil
 	}
 
@@ -68,3 +71,15 @@ func min(a, b time.Duration) time.Duration {
 	}
 	return b
 }
+
+// commandMonitor records the latency of every MongoDB command
+func commandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			metrics.MongoCommandDuration.WithLabelValues(e.CommandName, metrics.ResultSuccess).Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			metrics.MongoCommandDuration.WithLabelValues(e.CommandName, metrics.ResultFailure).Observe(e.Duration.Seconds())
+		},
+	}
+}
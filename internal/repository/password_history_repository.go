@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoPasswordHistoryRepository implements domain.PasswordHistoryRepository using MongoDB
+type MongoPasswordHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoPasswordHistoryRepository creates a new MongoPasswordHistoryRepository
+func NewMongoPasswordHistoryRepository(db *mongo.Database) *MongoPasswordHistoryRepository {
+	return &MongoPasswordHistoryRepository{
+		collection: db.Collection("password_history"),
+	}
+}
+
+// List returns the most recent previous password hashes of a user, newest first
+func (r *MongoPasswordHistoryRepository) List(ctx context.Context, userID string, limit int) ([]string, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": objectID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []domain.PasswordHistoryEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(entries))
+	for i, entry := range entries {
+		hashes[i] = entry.Hash
+	}
+	return hashes, nil
+}
+
+// Add records a previous password hash of a user, keeping only the newest keep hashes
+func (r *MongoPasswordHistoryRepository) Add(ctx context.Context, userID, hash string, keep int) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	if _, err := r.collection.InsertOne(ctx, domain.PasswordHistoryEntry{
+		Hash:      hash,
+		UserID:    objectID,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	kept, err := r.List(ctx, userID, keep)
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.DeleteMany(ctx, bson.M{"user_id": objectID, "_id": bson.M{"$nin": kept}})
+	return err
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+// HealthChecker reports the health of the PostgreSQL backend
+type HealthChecker struct {
+	db *sql.DB
+}
+
+// NewHealthChecker creates a new HealthChecker
+func NewHealthChecker(db *sql.DB) *HealthChecker {
+	return &HealthChecker{db: db}
+}
+
+// Backend returns the storage backend name
+func (c *HealthChecker) Backend() string {
+	return "postgres"
+}
+
+// CheckHealth queries the server version and reports connection pool usage
+func (c *HealthChecker) CheckHealth(ctx context.Context) (map[string]string, error) {
+	var version string
+	if err := c.db.QueryRowContext(ctx, `SELECT current_setting('server_version')`).Scan(&version); err != nil {
+		return nil, err
+	}
+
+	stats := c.db.Stats()
+	return map[string]string{
+		"server_version":   version,
+		"open_connections": strconv.Itoa(stats.OpenConnections),
+		"in_use":           strconv.Itoa(stats.InUse),
+	}, nil
+}
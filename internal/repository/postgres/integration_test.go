@@ -0,0 +1,89 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/services-api/internal/repository/postgres"
+	"github.com/services-api/internal/repository/repotest"
+	"github.com/testcontainers/testcontainers-go"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+var testDB *sql.DB
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+
+	// Start PostgreSQL container
+	pgContainer, err := tcpostgres.Run(ctx, "postgres:16-alpine",
+		tcpostgres.WithDatabase("test_services_db"),
+		tcpostgres.WithUsername("postgres"),
+		tcpostgres.WithPassword("postgres"),
+		tcpostgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		log.Fatalf("Failed to start PostgreSQL container: %v", err)
+	}
+
+	// Get connection string
+	connStr, err := pgContainer.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		log.Fatalf("Failed to get connection string: %v", err)
+	}
+
+	// Connect and apply migrations
+	testDB, err = postgres.Open(ctx, connStr)
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	if err := postgres.Migrate(ctx, testDB); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	// Run tests
+	code := m.Run()
+
+	// Cleanup
+	if err := testDB.Close(); err != nil {
+		log.Printf("Error closing PostgreSQL connection: %v", err)
+	}
+
+	if err := testcontainers.TerminateContainer(pgContainer); err != nil {
+		log.Printf("Error terminating container: %v", err)
+	}
+
+	os.Exit(code)
+}
+
+func TestPostgresRepositories_Conformance(t *testing.T) {
+	repos := repotest.Repositories{
+		Services:        postgres.NewServiceRepository(testDB),
+		Versions:        postgres.NewServiceVersionRepository(testDB),
+		Users:           postgres.NewUserRepository(testDB),
+		Idempotency:     postgres.NewIdempotencyRepository(testDB),
+		RefreshTokens:   postgres.NewRefreshTokenRepository(testDB),
+		Sessions:        postgres.NewSessionRepository(testDB),
+		APIKeys:         postgres.NewAPIKeyRepository(testDB),
+		Roles:           postgres.NewRoleRepository(testDB),
+		MFA:             postgres.NewMFARepository(testDB),
+		LoginAttempts:   postgres.NewLoginAttemptRepository(testDB),
+		UserTokens:      postgres.NewUserTokenRepository(testDB),
+		PasswordHistory: postgres.NewPasswordHistoryRepository(testDB),
+		Invitations:     postgres.NewInvitationRepository(testDB),
+		Teams:           postgres.NewTeamRepository(testDB),
+		Audit:           postgres.NewAuditRepository(testDB),
+	}
+
+	repotest.Run(t, repos, func(t *testing.T) {
+		if _, err := testDB.Exec(`TRUNCATE services, service_versions, users, idempotency_keys, refresh_tokens, sessions, api_keys, roles, mfa_totp, mfa_recovery_codes, login_attempts, user_tokens, password_history, invitations, teams, team_members, audit_events CASCADE`); err != nil {
+			t.Fatalf("Failed to truncate tables: %v", err)
+		}
+	})
+}
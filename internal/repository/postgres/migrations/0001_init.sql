@@ -0,0 +1,42 @@
+-- Trigram indexes back the case-insensitive substring search on services
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE services (
+    id          CHAR(24) PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    description VARCHAR(1000) NOT NULL,
+    revision    INTEGER NOT NULL DEFAULT 1,
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX services_name_idx ON services (name);
+CREATE INDEX services_lower_name_idx ON services (lower(name));
+CREATE INDEX services_created_at_idx ON services (created_at);
+CREATE INDEX services_updated_at_idx ON services (updated_at);
+CREATE INDEX services_name_trgm_idx ON services USING gin (name gin_trgm_ops);
+CREATE INDEX services_description_trgm_idx ON services USING gin (description gin_trgm_ops);
+
+CREATE TABLE service_versions (
+    id          CHAR(24) PRIMARY KEY,
+    service_id  CHAR(24) NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    revision    INTEGER NOT NULL,
+    name        VARCHAR(255) NOT NULL,
+    description VARCHAR(1000) NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    UNIQUE (service_id, revision)
+);
+
+CREATE TABLE users (
+    id            CHAR(24) PRIMARY KEY,
+    email         VARCHAR(255) NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    first_name    VARCHAR(100) NOT NULL,
+    last_name     VARCHAR(100) NOT NULL DEFAULT '',
+    role          VARCHAR(32) NOT NULL,
+    active        BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX users_created_at_idx ON users (created_at DESC);
@@ -0,0 +1,13 @@
+CREATE TABLE refresh_tokens (
+    id          TEXT PRIMARY KEY,
+    family_id   TEXT NOT NULL,
+    user_id     CHAR(24) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    replaced_by TEXT NOT NULL DEFAULT '',
+    revoked_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens (user_id);
+CREATE INDEX refresh_tokens_expires_at_idx ON refresh_tokens (expires_at);
@@ -0,0 +1,13 @@
+CREATE TABLE sessions (
+    id           TEXT PRIMARY KEY,
+    user_id      CHAR(24) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_agent   TEXT NOT NULL DEFAULT '',
+    ip_address   TEXT NOT NULL DEFAULT '',
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL,
+    last_seen_at TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id, last_seen_at DESC);
+CREATE INDEX sessions_expires_at_idx ON sessions (expires_at);
@@ -0,0 +1,14 @@
+CREATE TABLE api_keys (
+    id           CHAR(24) PRIMARY KEY,
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL UNIQUE,
+    secret_hash  TEXT NOT NULL,
+    owner_id     CHAR(24) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    scopes       TEXT NOT NULL DEFAULT '',
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX api_keys_owner_id_idx ON api_keys (owner_id, created_at DESC);
@@ -0,0 +1,10 @@
+CREATE TABLE roles (
+    name        VARCHAR(32) PRIMARY KEY,
+    description VARCHAR(200) NOT NULL DEFAULT '',
+    permissions TEXT NOT NULL DEFAULT '',
+    built_in    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX users_role_idx ON users (role);
@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE user_tokens (
+    hash       TEXT PRIMARY KEY,
+    user_id    CHAR(24) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose    TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX user_tokens_user_id_idx ON user_tokens (user_id, purpose);
+CREATE INDEX user_tokens_expires_at_idx ON user_tokens (expires_at);
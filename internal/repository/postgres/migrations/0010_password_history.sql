@@ -0,0 +1,7 @@
+CREATE TABLE password_history (
+    hash       TEXT PRIMARY KEY,
+    user_id    CHAR(24) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX password_history_user_id_idx ON password_history (user_id, created_at);
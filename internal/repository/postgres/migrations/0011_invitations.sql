@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN pending BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE invitations (
+    id         CHAR(24) PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    email      TEXT NOT NULL,
+    role       TEXT NOT NULL,
+    invited_by CHAR(24) NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    sent_at    TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX invitations_created_at_idx ON invitations (created_at);
@@ -0,0 +1,21 @@
+CREATE TABLE teams (
+    id          CHAR(24) PRIMARY KEY,
+    name        TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE team_members (
+    team_id  CHAR(24) NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    user_id  CHAR(24) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role     TEXT NOT NULL,
+    added_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX team_members_user_id_idx ON team_members (user_id);
+
+ALTER TABLE services ADD COLUMN team_id CHAR(24) REFERENCES teams (id);
+
+CREATE INDEX services_team_id_idx ON services (team_id);
@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMPTZ;
+
+CREATE INDEX users_last_login_at_idx ON users (last_login_at);
+CREATE INDEX users_name_idx ON users (last_name, first_name);
+CREATE INDEX users_email_trgm_idx ON users USING gin (lower(email) gin_trgm_ops);
+CREATE INDEX users_first_name_trgm_idx ON users USING gin (lower(first_name) gin_trgm_ops);
+CREATE INDEX users_last_name_trgm_idx ON users USING gin (lower(last_name) gin_trgm_ops);
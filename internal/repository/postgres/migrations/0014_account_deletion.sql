@@ -0,0 +1,8 @@
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMPTZ;
+
+CREATE INDEX users_deletion_scheduled_at_idx ON users (deletion_scheduled_at);
+
+ALTER TABLE service_versions ADD COLUMN author_id CHAR(24);
+ALTER TABLE service_versions ADD COLUMN author_deleted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX service_versions_author_id_idx ON service_versions (author_id);
@@ -0,0 +1,21 @@
+CREATE TABLE audit_events (
+    id              CHAR(24) PRIMARY KEY,
+    action          TEXT NOT NULL,
+    actor_id        TEXT NOT NULL DEFAULT '',
+    impersonator_id TEXT NOT NULL DEFAULT '',
+    auth_type       TEXT NOT NULL DEFAULT '',
+    api_key_id      TEXT NOT NULL DEFAULT '',
+    ip_address      TEXT NOT NULL DEFAULT '',
+    user_agent      TEXT NOT NULL DEFAULT '',
+    request_id      TEXT NOT NULL DEFAULT '',
+    target_type     TEXT NOT NULL DEFAULT '',
+    target_id       TEXT NOT NULL DEFAULT '',
+    before_summary  TEXT,
+    after_summary   TEXT,
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at, id);
+CREATE INDEX audit_events_actor_id_idx ON audit_events (actor_id, created_at);
+CREATE INDEX audit_events_target_idx ON audit_events (target_type, target_id, created_at);
+CREATE INDEX audit_events_action_idx ON audit_events (action, created_at);
@@ -0,0 +1,9 @@
+ALTER TABLE service_versions ADD COLUMN hash TEXT;
+ALTER TABLE service_versions ADD COLUMN prev_hash TEXT;
+
+ALTER TABLE audit_events ADD COLUMN hash TEXT;
+ALTER TABLE audit_events ADD COLUMN prev_hash TEXT;
+
+-- Each event chains onto a different predecessor, so concurrent writers can't fork the
+-- chain. Events without a hash have no prev_hash either.
+CREATE UNIQUE INDEX audit_events_prev_hash_idx ON audit_events (prev_hash);
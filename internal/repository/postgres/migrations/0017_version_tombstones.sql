@@ -0,0 +1,5 @@
+-- Deleting a service keeps its versions, ending the history with a tombstone version
+ALTER TABLE service_versions DROP CONSTRAINT service_versions_service_id_fkey;
+
+ALTER TABLE service_versions ADD COLUMN author_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE service_versions ADD COLUMN deleted BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- Author commitments are keyed with a random salt per version, which is
+-- cleared when the author is anonymized
+ALTER TABLE service_versions ADD COLUMN author_salt TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,184 @@
+// Package postgres provides the PostgreSQL storage backend.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the "pgx" database/sql driver
+	"github.com/services-api/internal/repository/sqlstore"
+)
+
+const (
+	maxRetries    = 5
+	initialDelay  = 1 * time.Second
+	maxDelay      = 30 * time.Second
+	backoffFactor = 2
+
+	// uniqueViolation is the SQLSTATE code for unique constraint violations
+	uniqueViolation = "23505"
+	// migrationLockID is the advisory lock key serializing concurrent migrators
+	migrationLockID = 7246871
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Open establishes a connection pool to PostgreSQL with retry logic
+func Open(ctx context.Context, dsn string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PostgreSQL connection: %w", err)
+	}
+
+	// Set connection pool options
+	db.SetMaxOpenConns(100)
+	db.SetMaxIdleConns(10)
+	db.SetConnMaxIdleTime(30 * time.Second)
+
+	delay := initialDelay
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		if err = db.PingContext(ctx); err == nil {
+			slog.Info("Successfully connected to PostgreSQL")
+			return db, nil
+		}
+
+		slog.Warn("PostgreSQL ping attempt failed", "attempt", attempt, "error", err)
+		if attempt < maxRetries {
+			slog.Info("Retrying PostgreSQL connection", "delay", delay.String())
+			time.Sleep(delay)
+			delay = min(delay*backoffFactor, maxDelay)
+		}
+	}
+
+	db.Close()
+	return nil, fmt.Errorf("failed to ping PostgreSQL after %d attempts: %w", maxRetries, err)
+}
+
+// Migrate applies any pending SQL migrations
+func Migrate(ctx context.Context, db *sql.DB) error {
+	return sqlstore.Migrate(ctx, db, Dialect{}, migrationFiles)
+}
+
+// NewServiceRepository creates a domain.ServiceRepository backed by PostgreSQL
+func NewServiceRepository(db *sql.DB) *sqlstore.ServiceRepository {
+	return sqlstore.NewServiceRepository(db, Dialect{})
+}
+
+// NewServiceVersionRepository creates a domain.ServiceVersionRepository backed by PostgreSQL
+func NewServiceVersionRepository(db *sql.DB) *sqlstore.ServiceVersionRepository {
+	return sqlstore.NewServiceVersionRepository(db, Dialect{})
+}
+
+// NewUserRepository creates a domain.UserRepository backed by PostgreSQL
+func NewUserRepository(db *sql.DB) *sqlstore.UserRepository {
+	return sqlstore.NewUserRepository(db, Dialect{})
+}
+
+// NewIdempotencyRepository creates a domain.IdempotencyRepository backed by PostgreSQL
+func NewIdempotencyRepository(db *sql.DB) *sqlstore.IdempotencyRepository {
+	return sqlstore.NewIdempotencyRepository(db, Dialect{})
+}
+
+// NewRefreshTokenRepository creates a domain.RefreshTokenRepository backed by PostgreSQL
+func NewRefreshTokenRepository(db *sql.DB) *sqlstore.RefreshTokenRepository {
+	return sqlstore.NewRefreshTokenRepository(db, Dialect{})
+}
+
+// NewSessionRepository creates a domain.SessionRepository backed by PostgreSQL
+func NewSessionRepository(db *sql.DB) *sqlstore.SessionRepository {
+	return sqlstore.NewSessionRepository(db, Dialect{})
+}
+
+// NewAPIKeyRepository creates a domain.APIKeyRepository backed by PostgreSQL
+func NewAPIKeyRepository(db *sql.DB) *sqlstore.APIKeyRepository {
+	return sqlstore.NewAPIKeyRepository(db, Dialect{})
+}
+
+// NewRoleRepository creates a domain.RoleRepository backed by PostgreSQL
+func NewRoleRepository(db *sql.DB) *sqlstore.RoleRepository {
+	return sqlstore.NewRoleRepository(db, Dialect{})
+}
+
+// Dialect implements sqlstore.Dialect for PostgreSQL
+type Dialect struct{}
+
+// Rebind rewrites '?' placeholders into $1, $2, ...
+func (Dialect) Rebind(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// IsUniqueViolation checks if the error is a unique constraint violation
+func (Dialect) IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// SearchCondition matches name or description case-insensitively, served by the trigram indexes
+func (Dialect) SearchCondition(search string) (string, []any) {
+	pattern := sqlstore.LikePattern(search)
+	return `(name ILIKE ? ESCAPE '\' OR description ILIKE ? ESCAPE '\')`, []any{pattern, pattern}
+}
+
+// LockMigrations takes a transaction-scoped advisory lock
+func (Dialect) LockMigrations(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID)
+	return err
+}
+
+// NewMFARepository creates a domain.MFARepository backed by PostgreSQL
+func NewMFARepository(db *sql.DB) *sqlstore.MFARepository {
+	return sqlstore.NewMFARepository(db, Dialect{})
+}
+
+// NewLoginAttemptRepository creates a domain.LoginAttemptRepository backed by PostgreSQL
+func NewLoginAttemptRepository(db *sql.DB) *sqlstore.LoginAttemptRepository {
+	return sqlstore.NewLoginAttemptRepository(db, Dialect{})
+}
+
+// NewUserTokenRepository creates a domain.UserTokenRepository backed by PostgreSQL
+func NewUserTokenRepository(db *sql.DB) *sqlstore.UserTokenRepository {
+	return sqlstore.NewUserTokenRepository(db, Dialect{})
+}
+
+// NewPasswordHistoryRepository creates a domain.PasswordHistoryRepository backed by PostgreSQL
+func NewPasswordHistoryRepository(db *sql.DB) *sqlstore.PasswordHistoryRepository {
+	return sqlstore.NewPasswordHistoryRepository(db, Dialect{})
+}
+
+// NewInvitationRepository creates a domain.InvitationRepository backed by PostgreSQL
+func NewInvitationRepository(db *sql.DB) *sqlstore.InvitationRepository {
+	return sqlstore.NewInvitationRepository(db, Dialect{})
+}
+
+// NewTeamRepository creates a domain.TeamRepository backed by PostgreSQL
+func NewTeamRepository(db *sql.DB) *sqlstore.TeamRepository {
+	return sqlstore.NewTeamRepository(db, Dialect{})
+}
+
+// NewAuditRepository creates a domain.AuditRepository backed by PostgreSQL
+func NewAuditRepository(db *sql.DB) *sqlstore.AuditRepository {
+	return sqlstore.NewAuditRepository(db, Dialect{})
+}
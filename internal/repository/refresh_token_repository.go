@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoRefreshTokenRepository implements domain.RefreshTokenRepository using MongoDB
+type MongoRefreshTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoRefreshTokenRepository creates a new MongoRefreshTokenRepository
+func NewMongoRefreshTokenRepository(db *mongo.Database) *MongoRefreshTokenRepository {
+	return &MongoRefreshTokenRepository{
+		collection: db.Collection("refresh_tokens"),
+	}
+}
+
+// Create stores a newly issued refresh token
+func (r *MongoRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+// GetByID retrieves an unexpired refresh token by its ID
+func (r *MongoRefreshTokenRepository) GetByID(ctx context.Context, id string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":        id,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Rotate atomically marks an active token as replaced
+func (r *MongoRefreshTokenRepository) Rotate(ctx context.Context, id, replacedBy string) error {
+	// Matching only unrotated, unrevoked tokens makes concurrent refreshes race safely
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{
+			"_id":         id,
+			"replaced_by": bson.M{"$exists": false},
+			"revoked_at":  bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"replaced_by": replacedBy}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrRefreshTokenReused
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every token in a token family
+func (r *MongoRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.revoke(ctx, bson.M{"family_id": familyID})
+}
+
+// RevokeAllForUser revokes every token issued to a user
+func (r *MongoRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	return r.revoke(ctx, bson.M{"user_id": objectID})
+}
+
+// revoke sets revoked_at on all matching tokens that are not yet revoked
+func (r *MongoRefreshTokenRepository) revoke(ctx context.Context, filter bson.M) error {
+	filter["revoked_at"] = bson.M{"$exists": false}
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+	})
+	return err
+}
@@ -0,0 +1,107 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAPIKey(name, prefix string, owner *domain.User) *domain.APIKey {
+	return &domain.APIKey{
+		Name:       name,
+		Prefix:     prefix,
+		SecretHash: "hash-" + prefix,
+		OwnerID:    owner.ID,
+		Scopes:     []string{"services:read", "services:write"},
+	}
+}
+
+func testAPIKeyCRUD(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	owner := createTestUser(t, repos, "keys@example.com")
+
+	key := newAPIKey("ci", "sk_prefix1", owner)
+	require.NoError(t, repos.APIKeys.Create(ctx, key))
+	assert.False(t, key.ID.IsZero())
+	assert.False(t, key.CreatedAt.IsZero())
+
+	// Prefixes are unique
+	assert.ErrorIs(t, repos.APIKeys.Create(ctx, newAPIKey("other", "sk_prefix1", owner)), domain.ErrAPIKeyPrefixExists)
+
+	found, err := repos.APIKeys.GetByPrefix(ctx, "sk_prefix1")
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, found.ID)
+	assert.Equal(t, "ci", found.Name)
+	assert.Equal(t, "hash-sk_prefix1", found.SecretHash)
+	assert.Equal(t, owner.ID, found.OwnerID)
+	assert.Equal(t, []string{"services:read", "services:write"}, found.Scopes)
+	assert.Nil(t, found.ExpiresAt)
+	assert.Nil(t, found.LastUsedAt)
+
+	// Update name, scopes and expiry
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+	found.Name = "ci-renamed"
+	found.Scopes = []string{"services:read"}
+	found.ExpiresAt = &expiresAt
+	require.NoError(t, repos.APIKeys.Update(ctx, found))
+
+	usedAt := time.Now().UTC().Truncate(time.Millisecond)
+	require.NoError(t, repos.APIKeys.TouchLastUsed(ctx, key.ID.Hex(), usedAt))
+
+	found, err = repos.APIKeys.GetByID(ctx, key.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "ci-renamed", found.Name)
+	assert.Equal(t, []string{"services:read"}, found.Scopes)
+	require.NotNil(t, found.ExpiresAt)
+	assert.WithinDuration(t, expiresAt, *found.ExpiresAt, time.Millisecond)
+	require.NotNil(t, found.LastUsedAt)
+	assert.WithinDuration(t, usedAt, *found.LastUsedAt, time.Millisecond)
+
+	// Clearing the expiry
+	found.ExpiresAt = nil
+	require.NoError(t, repos.APIKeys.Update(ctx, found))
+	found, err = repos.APIKeys.GetByID(ctx, key.ID.Hex())
+	require.NoError(t, err)
+	assert.Nil(t, found.ExpiresAt)
+
+	// Delete
+	require.NoError(t, repos.APIKeys.Delete(ctx, key.ID.Hex()))
+	_, err = repos.APIKeys.GetByID(ctx, key.ID.Hex())
+	assert.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+	_, err = repos.APIKeys.GetByPrefix(ctx, "sk_prefix1")
+	assert.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+	assert.ErrorIs(t, repos.APIKeys.Delete(ctx, key.ID.Hex()), domain.ErrAPIKeyNotFound)
+
+	_, err = repos.APIKeys.GetByID(ctx, "invalid-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+}
+
+func testAPIKeyList(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	alice := createTestUser(t, repos, "alice@example.com")
+	bob := createTestUser(t, repos, "bob@example.com")
+
+	for _, prefix := range []string{"sk_alice1", "sk_alice2", "sk_alice3"} {
+		require.NoError(t, repos.APIKeys.Create(ctx, newAPIKey(prefix, prefix, alice)))
+		time.Sleep(2 * time.Millisecond)
+	}
+	require.NoError(t, repos.APIKeys.Create(ctx, newAPIKey("bob", "sk_bob1", bob)))
+
+	page, err := repos.APIKeys.List(ctx, alice.ID.Hex(), domain.PaginationParams{Page: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), page.Pagination.Total)
+	require.Len(t, page.Data, 2)
+	assert.Equal(t, "sk_alice3", page.Data[0].Prefix)
+	assert.Equal(t, "sk_alice2", page.Data[1].Prefix)
+
+	all, err := repos.APIKeys.List(ctx, "", domain.PaginationParams{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), all.Pagination.Total)
+
+	_, err = repos.APIKeys.List(ctx, "invalid-id", domain.PaginationParams{Page: 1, Limit: 10})
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+}
@@ -0,0 +1,123 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testAuditLog(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	start := time.Now().Add(-time.Minute)
+
+	events := []*domain.AuditEvent{
+		{Action: domain.AuditActionLoginFailed, IPAddress: "192.0.2.1", UserAgent: "curl/8.0"},
+		{Action: domain.AuditActionLogin, ActorID: "user-1", AuthType: "jwt", TargetType: domain.AuditTargetUser, TargetID: "user-1"},
+		{
+			Action: domain.AuditActionUserRoleChange, ActorID: "admin-1", ImpersonatorID: "admin-2", RequestID: "req-1",
+			TargetType: domain.AuditTargetUser, TargetID: "user-1",
+			Before: map[string]string{"role": "user"}, After: map[string]string{"role": "admin"},
+		},
+		{Action: domain.AuditActionServiceDelete, ActorID: "user-1", AuthType: "api_key", APIKeyID: "key-1", TargetType: domain.AuditTargetService, TargetID: "service-1"},
+	}
+	for _, event := range events {
+		require.NoError(t, repos.Audit.Create(ctx, event))
+		assert.False(t, event.ID.IsZero())
+		assert.False(t, event.CreatedAt.IsZero())
+	}
+
+	// Newest first, with every field round-tripped
+	all, err := repos.Audit.List(ctx, domain.AuditQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.Equal(t, events[3].ID, all[0].ID)
+	assert.Equal(t, events[0].ID, all[3].ID)
+	assert.Equal(t, "api_key", all[0].AuthType)
+	assert.Equal(t, "key-1", all[0].APIKeyID)
+	assert.Equal(t, "service-1", all[0].TargetID)
+	assert.Equal(t, "admin-2", all[1].ImpersonatorID)
+	assert.Equal(t, "req-1", all[1].RequestID)
+	assert.Equal(t, map[string]string{"role": "user"}, all[1].Before)
+	assert.Equal(t, map[string]string{"role": "admin"}, all[1].After)
+	assert.Nil(t, all[0].Before)
+	assert.Equal(t, "192.0.2.1", all[3].IPAddress)
+	assert.True(t, events[3].CreatedAt.Equal(all[0].CreatedAt))
+
+	// Filters
+	byActor, err := repos.Audit.List(ctx, domain.AuditQuery{ActorID: "user-1", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, byActor, 2)
+
+	byTarget, err := repos.Audit.List(ctx, domain.AuditQuery{TargetType: domain.AuditTargetUser, TargetID: "user-1", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, byTarget, 2)
+
+	byAction, err := repos.Audit.List(ctx, domain.AuditQuery{Action: domain.AuditActionLoginFailed, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, byAction, 1)
+	assert.Equal(t, events[0].ID, byAction[0].ID)
+
+	future := time.Now().Add(time.Minute)
+	inRange, err := repos.Audit.List(ctx, domain.AuditQuery{Since: &start, Until: &future, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, inRange, 4)
+	none, err := repos.Audit.List(ctx, domain.AuditQuery{Since: &future, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	// Paging with a cursor visits every event once
+	var seen []string
+	query := domain.AuditQuery{Limit: 3}
+	for {
+		page, err := repos.Audit.List(ctx, query)
+		require.NoError(t, err)
+		for i := range page {
+			seen = append(seen, page[i].ID.Hex())
+		}
+		if len(page) < query.Limit {
+			break
+		}
+		cursor := domain.NewAuditCursor(&page[len(page)-1])
+		query.After = &cursor
+	}
+	assert.Equal(t, []string{events[3].ID.Hex(), events[2].ID.Hex(), events[1].ID.Hex(), events[0].ID.Hex()}, seen)
+}
+
+func testAuditHashChain(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	prevHash := ""
+	for i := 0; i < 3; i++ {
+		event := &domain.AuditEvent{
+			ID:        primitive.NewObjectID(),
+			Action:    domain.AuditActionUserUpdate,
+			After:     map[string]string{"role": "user"},
+			CreatedAt: time.Now().UTC().Truncate(time.Millisecond).Add(time.Duration(i) * time.Millisecond),
+			PrevHash:  prevHash,
+		}
+		event.Hash = event.ComputeHash()
+		require.NoError(t, repos.Audit.Create(ctx, event))
+		prevHash = event.Hash
+	}
+
+	// Hashes survive the round trip and still match the content
+	events, err := repos.Audit.List(ctx, domain.AuditQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	var verifier domain.ChainVerifier
+	for i := range events {
+		assert.Equal(t, events[i].Hash, events[i].ComputeHash())
+		verifier.Add(events[i].ChainLink())
+	}
+	assert.True(t, verifier.Result().Valid)
+
+	// Only one event can be chained onto another
+	fork := &domain.AuditEvent{ID: primitive.NewObjectID(), Action: domain.AuditActionLogin, CreatedAt: time.Now().UTC(), PrevHash: events[1].Hash}
+	fork.Hash = fork.ComputeHash()
+	assert.ErrorIs(t, repos.Audit.Create(ctx, fork), domain.ErrChainConflict)
+}
@@ -0,0 +1,73 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotencyRecord(key string, ttl time.Duration) *domain.IdempotencyRecord {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return &domain.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: "fingerprint-" + key,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+}
+
+func testIdempotencyLifecycle(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	// Create an in-progress record
+	record := newIdempotencyRecord("key-1", time.Hour)
+	require.NoError(t, repos.Idempotency.Create(ctx, record))
+
+	// A second record with the same key is rejected
+	err := repos.Idempotency.Create(ctx, newIdempotencyRecord("key-1", time.Hour))
+	assert.ErrorIs(t, err, domain.ErrIdempotencyKeyExists)
+
+	found, err := repos.Idempotency.GetByKey(ctx, "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, "fingerprint-key-1", found.Fingerprint)
+	assert.False(t, found.Completed)
+
+	// Complete stores the response
+	body := []byte(`{"id":"1"}`)
+	require.NoError(t, repos.Idempotency.Complete(ctx, "key-1", 201, "application/json", body))
+
+	found, err = repos.Idempotency.GetByKey(ctx, "key-1")
+	require.NoError(t, err)
+	assert.True(t, found.Completed)
+	assert.Equal(t, 201, found.StatusCode)
+	assert.Equal(t, "application/json", found.ContentType)
+	assert.Equal(t, body, found.Body)
+	assert.WithinDuration(t, record.ExpiresAt, found.ExpiresAt, time.Millisecond)
+
+	assert.ErrorIs(t, repos.Idempotency.Complete(ctx, "missing", 200, "", nil), domain.ErrNotFound)
+
+	// Delete releases the key
+	require.NoError(t, repos.Idempotency.Delete(ctx, "key-1"))
+	_, err = repos.Idempotency.GetByKey(ctx, "key-1")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	require.NoError(t, repos.Idempotency.Create(ctx, newIdempotencyRecord("key-1", time.Hour)))
+}
+
+func testIdempotencyExpiry(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	require.NoError(t, repos.Idempotency.Create(ctx, newIdempotencyRecord("expired", -time.Second)))
+
+	// Expired records are invisible and do not hold their key
+	_, err := repos.Idempotency.GetByKey(ctx, "expired")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.NoError(t, repos.Idempotency.Create(ctx, newIdempotencyRecord("expired", time.Hour)))
+	found, err := repos.Idempotency.GetByKey(ctx, "expired")
+	require.NoError(t, err)
+	assert.False(t, found.Completed)
+}
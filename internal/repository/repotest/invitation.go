@@ -0,0 +1,95 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newInvitation(hash string, user, inviter *domain.User, ttl time.Duration) *domain.Invitation {
+	return &domain.Invitation{
+		ID:        user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		InvitedBy: inviter.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+}
+
+func testInvitationLifecycle(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	inviter := createTestUser(t, repos, "admin@example.com")
+	invitee := createTestUser(t, repos, "invitee@example.com")
+
+	require.NoError(t, repos.Invitations.Create(ctx, newInvitation("hash-1", invitee, inviter, time.Hour)))
+
+	fetched, err := repos.Invitations.GetByID(ctx, invitee.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "invitee@example.com", fetched.Email)
+	assert.Equal(t, inviter.ID, fetched.InvitedBy)
+	assert.False(t, fetched.CreatedAt.IsZero())
+	assert.False(t, fetched.IsExpired())
+
+	fetched, err = repos.Invitations.GetByTokenHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, invitee.ID, fetched.ID)
+
+	// Resending replaces the token
+	fetched.TokenHash = "hash-2"
+	fetched.Email = "renamed@example.com"
+	require.NoError(t, repos.Invitations.Update(ctx, fetched))
+	_, err = repos.Invitations.GetByTokenHash(ctx, "hash-1")
+	assert.ErrorIs(t, err, domain.ErrInvalidInvitation)
+	assert.ErrorIs(t, repos.Invitations.Consume(ctx, "hash-1"), domain.ErrInvalidInvitation)
+
+	fetched, err = repos.Invitations.GetByTokenHash(ctx, "hash-2")
+	require.NoError(t, err)
+	assert.Equal(t, "renamed@example.com", fetched.Email)
+
+	// Invitations are single use
+	require.NoError(t, repos.Invitations.Consume(ctx, "hash-2"))
+	assert.ErrorIs(t, repos.Invitations.Consume(ctx, "hash-2"), domain.ErrInvalidInvitation)
+	_, err = repos.Invitations.GetByID(ctx, invitee.ID.Hex())
+	assert.ErrorIs(t, err, domain.ErrInvitationNotFound)
+
+	// Expired invitations can be found by ID but not accepted
+	require.NoError(t, repos.Invitations.Create(ctx, newInvitation("hash-expired", invitee, inviter, -time.Second)))
+	fetched, err = repos.Invitations.GetByID(ctx, invitee.ID.Hex())
+	require.NoError(t, err)
+	assert.True(t, fetched.IsExpired())
+	_, err = repos.Invitations.GetByTokenHash(ctx, "hash-expired")
+	assert.ErrorIs(t, err, domain.ErrInvalidInvitation)
+	assert.ErrorIs(t, repos.Invitations.Consume(ctx, "hash-expired"), domain.ErrInvalidInvitation)
+
+	// Delete
+	require.NoError(t, repos.Invitations.Delete(ctx, invitee.ID.Hex()))
+	assert.ErrorIs(t, repos.Invitations.Delete(ctx, invitee.ID.Hex()), domain.ErrInvitationNotFound)
+	assert.ErrorIs(t, repos.Invitations.Update(ctx, fetched), domain.ErrInvitationNotFound)
+
+	_, err = repos.Invitations.GetByID(ctx, "invalid-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+}
+
+func testInvitationList(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	inviter := createTestUser(t, repos, "admin@example.com")
+
+	for i := 0; i < 3; i++ {
+		invitee := createTestUser(t, repos, fmt.Sprintf("invitee%d@example.com", i))
+		require.NoError(t, repos.Invitations.Create(ctx, newInvitation(fmt.Sprintf("hash-%d", i), invitee, inviter, time.Hour)))
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	result, err := repos.Invitations.List(ctx, domain.PaginationParams{Page: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Pagination.Total)
+	require.Len(t, result.Data, 2)
+	assert.Equal(t, "invitee2@example.com", result.Data[0].Email)
+	assert.Equal(t, "invitee1@example.com", result.Data[1].Email)
+}
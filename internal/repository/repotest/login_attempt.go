@@ -0,0 +1,69 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLoginAttemptLifecycle(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	key := domain.AccountLoginKey("user@example.com")
+
+	_, err := repos.LoginAttempts.Get(ctx, key)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	for i := 1; i <= 3; i++ {
+		attempts, err := repos.LoginAttempts.RecordFailure(ctx, key, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, i, attempts.Failures)
+		assert.False(t, attempts.IsLocked(time.Now()))
+	}
+
+	// Failures are counted per key
+	other, err := repos.LoginAttempts.RecordFailure(ctx, domain.IPLoginKey("192.0.2.1"), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, other.Failures)
+
+	// A lockout outlasting the window keeps the failures
+	lockedUntil := time.Now().Add(2 * time.Hour)
+	require.NoError(t, repos.LoginAttempts.Lock(ctx, key, lockedUntil))
+	attempts, err := repos.LoginAttempts.RecordFailure(ctx, key, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 4, attempts.Failures)
+	assert.True(t, attempts.IsLocked(time.Now()))
+	assert.WithinDuration(t, lockedUntil, *attempts.LockedUntil, time.Second)
+	assert.WithinDuration(t, lockedUntil, attempts.ExpiresAt, time.Second)
+
+	found, err := repos.LoginAttempts.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 4, found.Failures)
+	assert.True(t, found.IsLocked(time.Now()))
+
+	require.NoError(t, repos.LoginAttempts.Reset(ctx, key))
+	require.NoError(t, repos.LoginAttempts.Reset(ctx, key))
+	_, err = repos.LoginAttempts.Get(ctx, key)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testLoginAttemptExpiry(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	key := domain.IPLoginKey("192.0.2.1")
+
+	_, err := repos.LoginAttempts.RecordFailure(ctx, key, -time.Second)
+	require.NoError(t, err)
+	require.NoError(t, repos.LoginAttempts.Lock(ctx, key, time.Now().Add(-time.Second)))
+
+	// Expired failures are invisible and the count restarts
+	_, err = repos.LoginAttempts.Get(ctx, key)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	attempts, err := repos.LoginAttempts.RecordFailure(ctx, key, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.Failures)
+	assert.Nil(t, attempts.LockedUntil)
+}
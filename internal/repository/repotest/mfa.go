@@ -0,0 +1,71 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMFALifecycle(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	user := createTestUser(t, repos, "mfa@example.com")
+	userID := user.ID.Hex()
+
+	_, err := repos.MFA.GetByUserID(ctx, userID)
+	assert.ErrorIs(t, err, domain.ErrMFANotEnrolled)
+
+	// A pending enrollment has no confirmation and no recovery codes
+	factor := &domain.TOTPFactor{UserID: user.ID, Secret: "JBSWY3DPEHPK3PXP"}
+	require.NoError(t, repos.MFA.Save(ctx, factor))
+
+	found, err := repos.MFA.GetByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", found.Secret)
+	assert.False(t, found.IsEnabled())
+	assert.Empty(t, found.RecoveryCodes)
+
+	// Saving again replaces the factor
+	confirmedAt := time.Now().UTC().Truncate(time.Millisecond)
+	found.ConfirmedAt = &confirmedAt
+	found.RecoveryCodes = []string{"hash-1", "hash-2"}
+	require.NoError(t, repos.MFA.Save(ctx, found))
+
+	found, err = repos.MFA.GetByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.True(t, found.IsEnabled())
+	assert.WithinDuration(t, confirmedAt, *found.ConfirmedAt, time.Second)
+	assert.ElementsMatch(t, []string{"hash-1", "hash-2"}, found.RecoveryCodes)
+
+	// Time steps can only move forward
+	require.NoError(t, repos.MFA.UseStep(ctx, userID, 100))
+	assert.ErrorIs(t, repos.MFA.UseStep(ctx, userID, 100), domain.ErrInvalidMFACode)
+	assert.ErrorIs(t, repos.MFA.UseStep(ctx, userID, 99), domain.ErrInvalidMFACode)
+	require.NoError(t, repos.MFA.UseStep(ctx, userID, 101))
+
+	found, err = repos.MFA.GetByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(101), found.LastUsedStep)
+
+	// Recovery codes are single-use
+	require.NoError(t, repos.MFA.UseRecoveryCode(ctx, userID, "hash-1"))
+	assert.ErrorIs(t, repos.MFA.UseRecoveryCode(ctx, userID, "hash-1"), domain.ErrInvalidMFACode)
+	assert.ErrorIs(t, repos.MFA.UseRecoveryCode(ctx, userID, "hash-3"), domain.ErrInvalidMFACode)
+
+	found, err = repos.MFA.GetByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hash-2"}, found.RecoveryCodes)
+
+	// Delete
+	require.NoError(t, repos.MFA.Delete(ctx, userID))
+	_, err = repos.MFA.GetByUserID(ctx, userID)
+	assert.ErrorIs(t, err, domain.ErrMFANotEnrolled)
+	assert.ErrorIs(t, repos.MFA.Delete(ctx, userID), domain.ErrMFANotEnrolled)
+	assert.ErrorIs(t, repos.MFA.UseStep(ctx, userID, 200), domain.ErrInvalidMFACode)
+
+	_, err = repos.MFA.GetByUserID(ctx, "invalid-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+}
@@ -0,0 +1,37 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPasswordHistory(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	user := createTestUser(t, repos, "history@example.com")
+	other := createTestUser(t, repos, "other-history@example.com")
+	userID := user.ID.Hex()
+
+	hashes, err := repos.PasswordHistory.List(ctx, userID, 5)
+	require.NoError(t, err)
+	assert.Empty(t, hashes)
+
+	for _, hash := range []string{"hash-1", "hash-2", "hash-3", "hash-4"} {
+		require.NoError(t, repos.PasswordHistory.Add(ctx, userID, hash, 3))
+		// Keep the timestamps of the entries apart
+		time.Sleep(2 * time.Millisecond)
+	}
+	require.NoError(t, repos.PasswordHistory.Add(ctx, other.ID.Hex(), "other-hash", 3))
+
+	// Only the newest hashes are kept
+	hashes, err = repos.PasswordHistory.List(ctx, userID, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hash-4", "hash-3", "hash-2"}, hashes)
+
+	hashes, err = repos.PasswordHistory.List(ctx, userID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hash-4", "hash-3"}, hashes)
+}
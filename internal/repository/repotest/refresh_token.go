@@ -0,0 +1,99 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestUser stores a user for tests of records that reference users
+func createTestUser(t *testing.T, repos Repositories, email string) *domain.User {
+	user := &domain.User{
+		Email:     email,
+		FirstName: "Test",
+		Role:      domain.RoleUser,
+		Active:    true,
+	}
+	require.NoError(t, user.SetPassword("password123"))
+	require.NoError(t, repos.Users.Create(context.Background(), user))
+	return user
+}
+
+func newRefreshToken(id, familyID string, user *domain.User, ttl time.Duration) *domain.RefreshToken {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return &domain.RefreshToken{
+		ID:        id,
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
+
+func testRefreshTokenRotation(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	user := createTestUser(t, repos, "rotation@example.com")
+
+	require.NoError(t, repos.RefreshTokens.Create(ctx, newRefreshToken("jti-1", "family-1", user, time.Hour)))
+
+	found, err := repos.RefreshTokens.GetByID(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.Equal(t, "family-1", found.FamilyID)
+	assert.Equal(t, user.ID, found.UserID)
+	assert.False(t, found.IsRotated())
+	assert.False(t, found.IsRevoked())
+
+	// First rotation wins, the second is reuse
+	require.NoError(t, repos.RefreshTokens.Rotate(ctx, "jti-1", "jti-2"))
+	assert.ErrorIs(t, repos.RefreshTokens.Rotate(ctx, "jti-1", "jti-3"), domain.ErrRefreshTokenReused)
+
+	found, err = repos.RefreshTokens.GetByID(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.Equal(t, "jti-2", found.ReplacedBy)
+
+	// Expired tokens are not returned
+	require.NoError(t, repos.RefreshTokens.Create(ctx, newRefreshToken("jti-expired", "family-1", user, -time.Second)))
+	_, err = repos.RefreshTokens.GetByID(ctx, "jti-expired")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = repos.RefreshTokens.GetByID(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testRefreshTokenRevocation(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	alice := createTestUser(t, repos, "alice@example.com")
+	bob := createTestUser(t, repos, "bob@example.com")
+
+	require.NoError(t, repos.RefreshTokens.Create(ctx, newRefreshToken("a-1", "family-a", alice, time.Hour)))
+	require.NoError(t, repos.RefreshTokens.Create(ctx, newRefreshToken("a-2", "family-a", alice, time.Hour)))
+	require.NoError(t, repos.RefreshTokens.Create(ctx, newRefreshToken("a-3", "family-b", alice, time.Hour)))
+	require.NoError(t, repos.RefreshTokens.Create(ctx, newRefreshToken("b-1", "family-c", bob, time.Hour)))
+
+	// Revoking a family leaves other families alone
+	require.NoError(t, repos.RefreshTokens.RevokeFamily(ctx, "family-a"))
+	for id, revoked := range map[string]bool{"a-1": true, "a-2": true, "a-3": false, "b-1": false} {
+		found, err := repos.RefreshTokens.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, revoked, found.IsRevoked(), id)
+	}
+
+	// Revoked tokens can no longer be rotated
+	assert.ErrorIs(t, repos.RefreshTokens.Rotate(ctx, "a-2", "a-4"), domain.ErrRefreshTokenReused)
+
+	// Revoking a user covers all of their families
+	require.NoError(t, repos.RefreshTokens.RevokeAllForUser(ctx, alice.ID.Hex()))
+	found, err := repos.RefreshTokens.GetByID(ctx, "a-3")
+	require.NoError(t, err)
+	assert.True(t, found.IsRevoked())
+
+	found, err = repos.RefreshTokens.GetByID(ctx, "b-1")
+	require.NoError(t, err)
+	assert.False(t, found.IsRevoked())
+
+	assert.ErrorIs(t, repos.RefreshTokens.RevokeAllForUser(ctx, "invalid"), domain.ErrInvalidID)
+}
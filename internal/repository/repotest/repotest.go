@@ -0,0 +1,695 @@
+// Package repotest provides a conformance suite that every storage backend's
+// repository implementations must pass.
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Repositories holds the repository implementations under test
+type Repositories struct {
+	Services        domain.ServiceRepository
+	Versions        domain.ServiceVersionRepository
+	Users           domain.UserRepository
+	Idempotency     domain.IdempotencyRepository
+	RefreshTokens   domain.RefreshTokenRepository
+	Sessions        domain.SessionRepository
+	APIKeys         domain.APIKeyRepository
+	Roles           domain.RoleRepository
+	MFA             domain.MFARepository
+	LoginAttempts   domain.LoginAttemptRepository
+	UserTokens      domain.UserTokenRepository
+	PasswordHistory domain.PasswordHistoryRepository
+	Invitations     domain.InvitationRepository
+	Teams           domain.TeamRepository
+	Audit           domain.AuditRepository
+}
+
+// Run executes the conformance suite. reset is called before each test and
+// must leave the backend empty.
+func Run(t *testing.T, repos Repositories, reset func(t *testing.T)) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repos Repositories)
+	}{
+		{"ServiceRepository_CRUD", testServiceCRUD},
+		{"ServiceRepository_List", testServiceList},
+		{"ServiceRepository_InvalidID", testServiceInvalidID},
+		{"ServiceVersionRepository_CRUD", testServiceVersionCRUD},
+		{"ServiceVersionRepository_DuplicateRevision", testServiceVersionDuplicateRevision},
+		{"ServiceVersionRepository_Author", testServiceVersionAuthor},
+		{"UserRepository_CRUD", testUserCRUD},
+		{"UserRepository_DuplicateEmail", testUserDuplicateEmail},
+		{"UserRepository_List", testUserList},
+		{"UserRepository_RecordLogin", testUserRecordLogin},
+		{"UserRepository_DeletionSchedule", testUserDeletionSchedule},
+		{"IdempotencyRepository_Lifecycle", testIdempotencyLifecycle},
+		{"IdempotencyRepository_Expiry", testIdempotencyExpiry},
+		{"RefreshTokenRepository_Rotation", testRefreshTokenRotation},
+		{"RefreshTokenRepository_Revocation", testRefreshTokenRevocation},
+		{"SessionRepository_Lifecycle", testSessionLifecycle},
+		{"SessionRepository_Revocation", testSessionRevocation},
+		{"APIKeyRepository_CRUD", testAPIKeyCRUD},
+		{"APIKeyRepository_List", testAPIKeyList},
+		{"RoleRepository_CRUD", testRoleCRUD},
+		{"UserRepository_CountByRole", testUserCountByRole},
+		{"MFARepository_Lifecycle", testMFALifecycle},
+		{"LoginAttemptRepository_Lifecycle", testLoginAttemptLifecycle},
+		{"LoginAttemptRepository_Expiry", testLoginAttemptExpiry},
+		{"UserTokenRepository_Lifecycle", testUserTokenLifecycle},
+		{"PasswordHistoryRepository", testPasswordHistory},
+		{"InvitationRepository_Lifecycle", testInvitationLifecycle},
+		{"InvitationRepository_List", testInvitationList},
+		{"TeamRepository_CRUD", testTeamCRUD},
+		{"TeamRepository_Membership", testTeamMembership},
+		{"ServiceRepository_Team", testServiceTeam},
+		{"AuditRepository_List", testAuditLog},
+		{"AuditRepository_HashChain", testAuditHashChain},
+		{"ServiceVersionRepository_HashChain", testServiceVersionHashChain},
+		{"ServiceVersionRepository_ChainedCreate", testServiceVersionChainedCreate},
+		{"ServiceVersionRepository_KeptAfterServiceDelete", testServiceVersionKeptAfterServiceDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset(t)
+			tt.fn(t, repos)
+		})
+	}
+}
+
+func testServiceCRUD(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	// Create
+	service := &domain.Service{
+		Name:        "test-service",
+		Description: "Test description",
+	}
+	require.NoError(t, repos.Services.Create(ctx, service))
+	assert.False(t, service.ID.IsZero())
+	assert.Equal(t, 1, service.Revision)
+	assert.False(t, service.CreatedAt.IsZero())
+	assert.False(t, service.UpdatedAt.IsZero())
+
+	// GetByID
+	fetched, err := repos.Services.GetByID(ctx, service.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, service.Name, fetched.Name)
+	assert.Equal(t, service.Description, fetched.Description)
+	assert.Equal(t, 1, fetched.Revision)
+
+	// Update increments the revision
+	time.Sleep(10 * time.Millisecond)
+	previous := *fetched
+	fetched.Name = "updated-service"
+	fetched.Description = "Updated description"
+	require.NoError(t, repos.Services.Update(ctx, fetched))
+	assert.Equal(t, 2, fetched.Revision)
+
+	updated, err := repos.Services.GetByID(ctx, service.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "updated-service", updated.Name)
+	assert.Equal(t, 2, updated.Revision)
+	assert.True(t, updated.UpdatedAt.After(updated.CreatedAt))
+
+	// Update of a missing service
+	missing := &domain.Service{ID: primitive.NewObjectID(), Name: "missing", Description: "missing"}
+	assert.ErrorIs(t, repos.Services.Update(ctx, missing), domain.ErrNotFound)
+
+	// Restore undoes the update, but only while the service is at its revision
+	require.NoError(t, repos.Services.Restore(ctx, &previous))
+	restored, err := repos.Services.GetByID(ctx, service.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "test-service", restored.Name)
+	assert.Equal(t, 1, restored.Revision)
+	assert.ErrorIs(t, repos.Services.Restore(ctx, &previous), domain.ErrServiceChanged)
+
+	// Delete
+	require.NoError(t, repos.Services.Delete(ctx, service.ID.Hex()))
+	_, err = repos.Services.GetByID(ctx, service.ID.Hex())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, repos.Services.Delete(ctx, service.ID.Hex()), domain.ErrNotFound)
+}
+
+func testServiceList(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	services := []struct {
+		name string
+		desc string
+	}{
+		{"api-gateway", "API Gateway service"},
+		{"auth-service", "Authentication service"},
+		{"payment-service", "Payment processing"},
+		{"notification-service", "Notification sender"},
+		{"user-service", "User management"},
+	}
+	for _, s := range services {
+		require.NoError(t, repos.Services.Create(ctx, &domain.Service{Name: s.name, Description: s.desc}))
+		// Small delay to ensure different timestamps
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Default listing (newest first)
+	result, err := repos.Services.List(ctx, domain.DefaultListParams())
+	require.NoError(t, err)
+	require.Len(t, result.Data, 5)
+	assert.Equal(t, "user-service", result.Data[0].Name)
+
+	// Pagination
+	params := domain.DefaultListParams()
+	params.Pagination = domain.PaginationParams{Page: 2, Limit: 2}
+	result, err = repos.Services.List(ctx, params)
+	require.NoError(t, err)
+	assert.Len(t, result.Data, 2)
+	assert.Equal(t, int64(5), result.Pagination.Total)
+	assert.Equal(t, 3, result.Pagination.TotalPages)
+
+	// Sorting by name ascending
+	result, err = repos.Services.List(ctx, domain.ListParams{
+		Sort:       "name",
+		Order:      "asc",
+		Pagination: domain.PaginationParams{Page: 1, Limit: 10},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Data, 5)
+	assert.Equal(t, "api-gateway", result.Data[0].Name)
+	assert.Equal(t, "user-service", result.Data[4].Name)
+
+	// Search matches name or description, case-insensitively
+	result, err = repos.Services.List(ctx, domain.ListParams{
+		Search:     "payment",
+		Pagination: domain.PaginationParams{Page: 1, Limit: 10},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "payment-service", result.Data[0].Name)
+
+	result, err = repos.Services.List(ctx, domain.ListParams{
+		Search:     "MANAGEMENT",
+		Pagination: domain.PaginationParams{Page: 1, Limit: 10},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "user-service", result.Data[0].Name)
+
+	// Name filter is an exact, case-insensitive match
+	result, err = repos.Services.List(ctx, domain.ListParams{
+		Name:       "Auth-Service",
+		Pagination: domain.PaginationParams{Page: 1, Limit: 10},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "auth-service", result.Data[0].Name)
+
+	result, err = repos.Services.List(ctx, domain.ListParams{
+		Name:       "auth",
+		Pagination: domain.PaginationParams{Page: 1, Limit: 10},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Data)
+}
+
+func testServiceInvalidID(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	_, err := repos.Services.GetByID(ctx, "invalid-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+	assert.ErrorIs(t, repos.Services.Delete(ctx, "invalid-id"), domain.ErrInvalidID)
+
+	_, err = repos.Versions.GetByID(ctx, "invalid-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+	_, err = repos.Users.GetByID(ctx, "invalid-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+}
+
+func testServiceVersionCRUD(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	service := &domain.Service{Name: "test-service", Description: "Test description"}
+	require.NoError(t, repos.Services.Create(ctx, service))
+
+	// Create a snapshot for every revision
+	for revision := 1; revision <= 3; revision++ {
+		service.Revision = revision
+		require.NoError(t, repos.Versions.Create(ctx, domain.NewServiceVersion(service, nil)))
+	}
+
+	// GetByServiceIDAndRevision
+	version, err := repos.Versions.GetByServiceIDAndRevision(ctx, service.ID.Hex(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, service.ID, version.ServiceID)
+	assert.Equal(t, 2, version.Revision)
+	assert.Equal(t, service.Name, version.Name)
+
+	_, err = repos.Versions.GetByServiceIDAndRevision(ctx, service.ID.Hex(), 4)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// GetByID
+	fetched, err := repos.Versions.GetByID(ctx, version.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, version.ID, fetched.ID)
+
+	// ListByServiceID returns newest first
+	result, err := repos.Versions.ListByServiceID(ctx, service.ID.Hex(), domain.PaginationParams{Page: 1, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, result.Data, 2)
+	assert.Equal(t, 3, result.Data[0].Revision)
+	assert.Equal(t, 2, result.Data[1].Revision)
+	assert.Equal(t, int64(3), result.Pagination.Total)
+
+	// ListServiceIDs lists every service with versions once, even after the service is gone
+	other := &domain.Service{Name: "other-service", Description: "Test description"}
+	require.NoError(t, repos.Services.Create(ctx, other))
+	other.Revision = 1
+	require.NoError(t, repos.Versions.Create(ctx, domain.NewServiceVersion(other, nil)))
+	require.NoError(t, repos.Services.Delete(ctx, other.ID.Hex()))
+	ids, err := repos.Versions.ListServiceIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{service.ID.Hex(), other.ID.Hex()}, ids)
+
+	// DeleteByServiceID
+	require.NoError(t, repos.Versions.DeleteByServiceID(ctx, service.ID.Hex()))
+	result, err = repos.Versions.ListByServiceID(ctx, service.ID.Hex(), domain.DefaultPaginationParams())
+	require.NoError(t, err)
+	assert.Empty(t, result.Data)
+	assert.Equal(t, int64(0), result.Pagination.Total)
+	ids, err = repos.Versions.ListServiceIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{other.ID.Hex()}, ids)
+}
+
+func testServiceVersionDuplicateRevision(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	service := &domain.Service{Name: "test-service", Description: "Test description"}
+	require.NoError(t, repos.Services.Create(ctx, service))
+
+	require.NoError(t, repos.Versions.Create(ctx, domain.NewServiceVersion(service, nil)))
+	assert.Error(t, repos.Versions.Create(ctx, domain.NewServiceVersion(service, nil)))
+
+	// The same revision of a different service does not conflict
+	other := &domain.Service{Name: "another-service", Description: "Another description"}
+	require.NoError(t, repos.Services.Create(ctx, other))
+	assert.NoError(t, repos.Versions.Create(ctx, domain.NewServiceVersion(other, nil)))
+}
+
+func testServiceVersionHashChain(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	service := &domain.Service{Name: "test-service", Description: "Test description"}
+	require.NoError(t, repos.Services.Create(ctx, service))
+
+	prevHash := ""
+	for revision := 1; revision <= 3; revision++ {
+		service.Revision = revision
+		version := domain.NewServiceVersion(service, nil)
+		version.PrevHash = prevHash
+		version.Hash = version.ComputeHash()
+		require.NoError(t, repos.Versions.Create(ctx, version))
+		prevHash = version.Hash
+	}
+
+	// Hashes survive the round trip and still match the content
+	result, err := repos.Versions.ListByServiceID(ctx, service.ID.Hex(), domain.DefaultPaginationParams())
+	require.NoError(t, err)
+	require.Len(t, result.Data, 3)
+	var verifier domain.ChainVerifier
+	for i := range result.Data {
+		assert.Equal(t, result.Data[i].Hash, result.Data[i].ComputeHash())
+		verifier.Add(result.Data[i].ChainLink())
+	}
+	assert.True(t, verifier.Result().Valid)
+	assert.Empty(t, result.Data[2].PrevHash)
+	assert.Equal(t, result.Data[1].Hash, result.Data[0].PrevHash)
+}
+
+func testServiceVersionAuthor(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	author := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+
+	service := &domain.Service{Name: "test-service", Description: "Test description"}
+	require.NoError(t, repos.Services.Create(ctx, service))
+	require.NoError(t, repos.Versions.Create(ctx, domain.NewServiceVersion(service, &author)))
+	service.Revision = 2
+	require.NoError(t, repos.Versions.Create(ctx, domain.NewServiceVersion(service, &other)))
+	service.Revision = 3
+	authored := domain.NewServiceVersion(service, &author)
+	require.NoError(t, repos.Versions.Create(ctx, authored))
+	service.Revision = 4
+	require.NoError(t, repos.Versions.Create(ctx, domain.NewServiceVersion(service, nil)))
+
+	// ListByAuthor returns oldest first
+	versions, err := repos.Versions.ListByAuthor(ctx, author.Hex())
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 1, versions[0].Revision)
+	assert.Equal(t, 3, versions[1].Revision)
+	require.NotNil(t, versions[0].AuthorID)
+	assert.Equal(t, author, *versions[0].AuthorID)
+	assert.False(t, versions[0].AuthorDeleted)
+	assert.Equal(t, authored.AuthorSalt, versions[1].AuthorSalt)
+	assert.Equal(t, domain.AuthorCommitment(author, versions[1].AuthorSalt), versions[1].AuthorHash)
+
+	// AnonymizeAuthor keeps the versions but forgets who made them
+	require.NoError(t, repos.Versions.AnonymizeAuthor(ctx, author.Hex()))
+	versions, err = repos.Versions.ListByAuthor(ctx, author.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	version, err := repos.Versions.GetByServiceIDAndRevision(ctx, service.ID.Hex(), 3)
+	require.NoError(t, err)
+	assert.Nil(t, version.AuthorID)
+	assert.True(t, version.AuthorDeleted)
+	assert.Equal(t, authored.AuthorHash, version.AuthorHash)
+	assert.Empty(t, version.AuthorSalt)
+
+	version, err = repos.Versions.GetByServiceIDAndRevision(ctx, service.ID.Hex(), 2)
+	require.NoError(t, err)
+	require.NotNil(t, version.AuthorID)
+	assert.Equal(t, other, *version.AuthorID)
+	assert.False(t, version.AuthorDeleted)
+
+	version, err = repos.Versions.GetByServiceIDAndRevision(ctx, service.ID.Hex(), 4)
+	require.NoError(t, err)
+	assert.Nil(t, version.AuthorID)
+	assert.False(t, version.AuthorDeleted)
+
+	_, err = repos.Versions.ListByAuthor(ctx, "invalid-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+	assert.ErrorIs(t, repos.Versions.AnonymizeAuthor(ctx, "invalid-id"), domain.ErrInvalidID)
+}
+
+func testServiceVersionChainedCreate(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	service := &domain.Service{Name: "test-service", Description: "Test description"}
+	require.NoError(t, repos.Services.Create(ctx, service))
+	first := domain.NewServiceVersion(service, nil)
+	first.Hash = first.ComputeHash()
+	require.NoError(t, repos.Versions.Create(ctx, first))
+
+	// A version must chain onto the stored hash of the previous revision
+	service.Revision = 2
+	forged := domain.NewServiceVersion(service, nil)
+	forged.PrevHash = "not-the-previous-hash"
+	forged.Hash = forged.ComputeHash()
+	assert.ErrorIs(t, repos.Versions.Create(ctx, forged), domain.ErrPreviousVersionMissing)
+
+	service.Revision = 3
+	skipped := domain.NewServiceVersion(service, nil)
+	skipped.PrevHash = first.Hash
+	skipped.Hash = skipped.ComputeHash()
+	assert.ErrorIs(t, repos.Versions.Create(ctx, skipped), domain.ErrPreviousVersionMissing)
+
+	// Following a stored revision, a version can't leave out the hash it chains onto
+	service.Revision = 2
+	unchained := domain.NewServiceVersion(service, nil)
+	unchained.Hash = unchained.ComputeHash()
+	assert.ErrorIs(t, repos.Versions.Create(ctx, unchained), domain.ErrPreviousVersionMissing)
+
+	second := domain.NewServiceVersion(service, nil)
+	second.PrevHash = first.Hash
+	second.Hash = second.ComputeHash()
+	require.NoError(t, repos.Versions.Create(ctx, second))
+
+	_, err := repos.Versions.GetByServiceIDAndRevision(ctx, service.ID.Hex(), 3)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// After a missing revision, a version with no PrevHash starts a new segment
+	service.Revision = 4
+	segment := domain.NewServiceVersion(service, nil)
+	segment.Hash = segment.ComputeHash()
+	require.NoError(t, repos.Versions.Create(ctx, segment))
+}
+
+func testServiceVersionKeptAfterServiceDelete(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	service := &domain.Service{Name: "test-service", Description: "Test description"}
+	require.NoError(t, repos.Services.Create(ctx, service))
+	author := primitive.NewObjectID()
+	version := domain.NewServiceVersion(service, &author)
+	version.Hash = version.ComputeHash()
+	require.NoError(t, repos.Versions.Create(ctx, version))
+	tombstone := domain.NewServiceTombstone(service, &author)
+	tombstone.PrevHash = version.Hash
+	tombstone.Hash = tombstone.ComputeHash()
+	require.NoError(t, repos.Versions.Create(ctx, tombstone))
+
+	require.NoError(t, repos.Services.Delete(ctx, service.ID.Hex()))
+
+	result, err := repos.Versions.ListByServiceID(ctx, service.ID.Hex(), domain.DefaultPaginationParams())
+	require.NoError(t, err)
+	require.Len(t, result.Data, 2)
+	assert.True(t, result.Data[0].Deleted)
+	assert.False(t, result.Data[1].Deleted)
+	assert.Equal(t, domain.AuthorCommitment(author, tombstone.AuthorSalt), result.Data[0].AuthorHash)
+
+	var verifier domain.ChainVerifier
+	for i := range result.Data {
+		verifier.Add(result.Data[i].ChainLink())
+	}
+	assert.True(t, verifier.Result().Valid)
+}
+
+func testUserCRUD(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	user := &domain.User{
+		Email:     "user@example.com",
+		FirstName: "John",
+		LastName:  "Doe",
+		Role:      domain.RoleUser,
+		Active:    true,
+	}
+	require.NoError(t, user.SetPassword("securepassword123"))
+	require.NoError(t, repos.Users.Create(ctx, user))
+	assert.False(t, user.ID.IsZero())
+
+	// GetByID and GetByEmail
+	fetched, err := repos.Users.GetByID(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, fetched.Email)
+	assert.True(t, fetched.CheckPassword("securepassword123"))
+	assert.False(t, fetched.EmailVerified)
+	assert.False(t, fetched.Pending)
+
+	fetched, err = repos.Users.GetByEmail(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, fetched.ID)
+
+	_, err = repos.Users.GetByEmail(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	// ExistsByEmail
+	exists, err := repos.Users.ExistsByEmail(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Update
+	fetched.Role = domain.RoleAdmin
+	fetched.Active = false
+	fetched.EmailVerified = true
+	fetched.Pending = true
+	require.NoError(t, repos.Users.Update(ctx, fetched))
+
+	updated, err := repos.Users.GetByID(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, updated.Role)
+	assert.False(t, updated.Active)
+	assert.True(t, updated.EmailVerified)
+	assert.True(t, updated.Pending)
+
+	// List
+	second := &domain.User{Email: "second@example.com", FirstName: "Jane", Role: domain.RoleUser, Active: true}
+	require.NoError(t, second.SetPassword("securepassword123"))
+	require.NoError(t, repos.Users.Create(ctx, second))
+
+	result, err := repos.Users.List(ctx, domain.DefaultUserListParams())
+	require.NoError(t, err)
+	assert.Len(t, result.Data, 2)
+	assert.Equal(t, int64(2), result.Pagination.Total)
+
+	// Delete
+	require.NoError(t, repos.Users.Delete(ctx, user.ID.Hex()))
+	_, err = repos.Users.GetByID(ctx, user.ID.Hex())
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	assert.ErrorIs(t, repos.Users.Delete(ctx, user.ID.Hex()), domain.ErrUserNotFound)
+}
+
+func testUserList(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	newUser := func(email, first, last, role string, active bool) *domain.User {
+		user := &domain.User{Email: email, FirstName: first, LastName: last, Role: role, Active: active}
+		require.NoError(t, repos.Users.Create(ctx, user))
+		time.Sleep(10 * time.Millisecond)
+		return user
+	}
+	jane := newUser("jane.doe@example.com", "Jane", "Doe", domain.RoleEditor, true)
+	john := newUser("john@acme.test", "John", "Doe", domain.RoleViewer, false)
+	ann := newUser("ann@example.com", "Ann", "Smith_Jones", domain.RoleEditor, true)
+
+	listed := func(params domain.UserListParams) []string {
+		t.Helper()
+		result, err := repos.Users.List(ctx, params)
+		require.NoError(t, err)
+		var emails []string
+		for _, user := range result.Data {
+			emails = append(emails, user.Email)
+		}
+		assert.Equal(t, int64(len(emails)), result.Pagination.Total)
+		return emails
+	}
+
+	// Newest first by default
+	params := domain.DefaultUserListParams()
+	assert.Equal(t, []string{ann.Email, john.Email, jane.Email}, listed(params))
+
+	// Every word of the search must match the name or email, case-insensitively
+	params.Query = "DOE"
+	assert.Equal(t, []string{john.Email, jane.Email}, listed(params))
+	params.Query = "doe acme"
+	assert.Equal(t, []string{john.Email}, listed(params))
+	params.Query = "smith_"
+	assert.Equal(t, []string{ann.Email}, listed(params))
+	params.Query = "h%"
+	assert.Empty(t, listed(params))
+
+	// Filters
+	params = domain.DefaultUserListParams()
+	params.Role = domain.RoleEditor
+	assert.Equal(t, []string{ann.Email, jane.Email}, listed(params))
+	inactive := false
+	params = domain.DefaultUserListParams()
+	params.Active = &inactive
+	assert.Equal(t, []string{john.Email}, listed(params))
+	params = domain.DefaultUserListParams()
+	params.CreatedAfter = &jane.CreatedAt
+	assert.Equal(t, []string{ann.Email, john.Email}, listed(params))
+
+	// Sorting
+	params = domain.DefaultUserListParams()
+	params.Sort, params.Order = "name", "asc"
+	assert.Equal(t, []string{jane.Email, john.Email, ann.Email}, listed(params))
+	params.Sort, params.Order = "email", "desc"
+	assert.Equal(t, []string{john.Email, jane.Email, ann.Email}, listed(params))
+
+	// Users who never logged in sort as the earliest logins
+	require.NoError(t, repos.Users.RecordLogin(ctx, john.ID.Hex(), time.Now().Add(-time.Hour)))
+	require.NoError(t, repos.Users.RecordLogin(ctx, jane.ID.Hex(), time.Now()))
+	params.Sort, params.Order = "last_login", "desc"
+	assert.Equal(t, []string{jane.Email, john.Email, ann.Email}, listed(params))
+	params.Order = "asc"
+	assert.Equal(t, []string{ann.Email, john.Email, jane.Email}, listed(params))
+
+	// Pagination
+	params = domain.DefaultUserListParams()
+	params.Pagination = domain.PaginationParams{Page: 2, Limit: 2}
+	result, err := repos.Users.List(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, jane.Email, result.Data[0].Email)
+	assert.Equal(t, int64(3), result.Pagination.Total)
+}
+
+func testUserRecordLogin(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	user := &domain.User{Email: "user@example.com", FirstName: "Test", Role: domain.RoleUser, Active: true}
+	require.NoError(t, repos.Users.Create(ctx, user))
+
+	fetched, err := repos.Users.GetByID(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	assert.Nil(t, fetched.LastLoginAt)
+
+	at := time.Now().Truncate(time.Millisecond)
+	require.NoError(t, repos.Users.RecordLogin(ctx, user.ID.Hex(), at))
+
+	fetched, err = repos.Users.GetByID(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	require.NotNil(t, fetched.LastLoginAt)
+	assert.WithinDuration(t, at, *fetched.LastLoginAt, time.Millisecond)
+	assert.WithinDuration(t, user.UpdatedAt, fetched.UpdatedAt, time.Millisecond)
+
+	// Updates keep the last login
+	require.NoError(t, repos.Users.Update(ctx, fetched))
+	fetched, err = repos.Users.GetByID(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	assert.NotNil(t, fetched.LastLoginAt)
+
+	assert.ErrorIs(t, repos.Users.RecordLogin(ctx, primitive.NewObjectID().Hex(), at), domain.ErrUserNotFound)
+	assert.ErrorIs(t, repos.Users.RecordLogin(ctx, "invalid-id", at), domain.ErrInvalidID)
+}
+
+func testUserDeletionSchedule(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Millisecond)
+	due := &domain.User{Email: "due@example.com", FirstName: "Due", Role: domain.RoleUser, Active: true}
+	later := &domain.User{Email: "later@example.com", FirstName: "Later", Role: domain.RoleUser, Active: true}
+	kept := &domain.User{Email: "kept@example.com", FirstName: "Kept", Role: domain.RoleUser, Active: true}
+	for _, user := range []*domain.User{due, later, kept} {
+		require.NoError(t, repos.Users.Create(ctx, user))
+	}
+
+	dueAt := now.Add(-time.Hour)
+	due.DeletionScheduledAt = &dueAt
+	require.NoError(t, repos.Users.Update(ctx, due))
+	laterAt := now.Add(time.Hour)
+	later.DeletionScheduledAt = &laterAt
+	require.NoError(t, repos.Users.Update(ctx, later))
+
+	fetched, err := repos.Users.GetByID(ctx, due.ID.Hex())
+	require.NoError(t, err)
+	require.NotNil(t, fetched.DeletionScheduledAt)
+	assert.WithinDuration(t, dueAt, *fetched.DeletionScheduledAt, time.Millisecond)
+
+	// ListDeletionDue
+	users, err := repos.Users.ListDeletionDue(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, due.ID, users[0].ID)
+
+	users, err = repos.Users.ListDeletionDue(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	// Clearing the schedule cancels the deletion
+	fetched.DeletionScheduledAt = nil
+	require.NoError(t, repos.Users.Update(ctx, fetched))
+	fetched, err = repos.Users.GetByID(ctx, due.ID.Hex())
+	require.NoError(t, err)
+	assert.Nil(t, fetched.DeletionScheduledAt)
+
+	users, err = repos.Users.ListDeletionDue(ctx, now)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func testUserDuplicateEmail(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	first := &domain.User{Email: "dup@example.com", FirstName: "First", Role: domain.RoleUser, Active: true}
+	require.NoError(t, repos.Users.Create(ctx, first))
+
+	second := &domain.User{Email: "dup@example.com", FirstName: "Second", Role: domain.RoleUser, Active: true}
+	assert.ErrorIs(t, repos.Users.Create(ctx, second), domain.ErrEmailAlreadyExists)
+
+	// Changing an email to one that is taken also conflicts
+	third := &domain.User{Email: "third@example.com", FirstName: "Third", Role: domain.RoleUser, Active: true}
+	require.NoError(t, repos.Users.Create(ctx, third))
+	third.Email = "dup@example.com"
+	assert.ErrorIs(t, repos.Users.Update(ctx, third), domain.ErrEmailAlreadyExists)
+}
@@ -0,0 +1,68 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRoleCRUD(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	role := &domain.Role{
+		Name:        "editor",
+		Description: "Create and update services",
+		Permissions: []string{"services:read", "services:write"},
+		BuiltIn:     true,
+	}
+	require.NoError(t, repos.Roles.Create(ctx, role))
+	assert.False(t, role.CreatedAt.IsZero())
+
+	// Names are unique
+	assert.ErrorIs(t, repos.Roles.Create(ctx, &domain.Role{Name: "editor"}), domain.ErrRoleExists)
+
+	found, err := repos.Roles.GetByName(ctx, "editor")
+	require.NoError(t, err)
+	assert.Equal(t, "Create and update services", found.Description)
+	assert.Equal(t, []string{"services:read", "services:write"}, found.Permissions)
+	assert.True(t, found.BuiltIn)
+
+	found.Description = "Edit services"
+	found.Permissions = []string{"services:read"}
+	require.NoError(t, repos.Roles.Update(ctx, found))
+
+	updated, err := repos.Roles.GetByName(ctx, "editor")
+	require.NoError(t, err)
+	assert.Equal(t, "Edit services", updated.Description)
+	assert.Equal(t, []string{"services:read"}, updated.Permissions)
+
+	require.NoError(t, repos.Roles.Create(ctx, &domain.Role{Name: "auditor", Permissions: []string{"users:read"}}))
+	roles, err := repos.Roles.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, roles, 2)
+	assert.Equal(t, "auditor", roles[0].Name)
+	assert.Equal(t, "editor", roles[1].Name)
+
+	require.NoError(t, repos.Roles.Delete(ctx, "auditor"))
+	_, err = repos.Roles.GetByName(ctx, "auditor")
+	assert.ErrorIs(t, err, domain.ErrRoleNotFound)
+	assert.ErrorIs(t, repos.Roles.Delete(ctx, "auditor"), domain.ErrRoleNotFound)
+	assert.ErrorIs(t, repos.Roles.Update(ctx, &domain.Role{Name: "auditor"}), domain.ErrRoleNotFound)
+}
+
+func testUserCountByRole(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	createTestUser(t, repos, "first@example.com")
+	createTestUser(t, repos, "second@example.com")
+
+	count, err := repos.Users.CountByRole(ctx, domain.RoleUser)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = repos.Users.CountByRole(ctx, "auditor")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
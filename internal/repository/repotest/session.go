@@ -0,0 +1,108 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSession(id string, user *domain.User, lastSeen time.Time, ttl time.Duration) *domain.Session {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return &domain.Session{
+		ID:         id,
+		UserID:     user.ID,
+		UserAgent:  "test-agent",
+		IPAddress:  "192.0.2.1",
+		CreatedAt:  now,
+		LastSeenAt: lastSeen.UTC().Truncate(time.Millisecond),
+		ExpiresAt:  now.Add(ttl),
+	}
+}
+
+func testSessionLifecycle(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	user := createTestUser(t, repos, "sessions@example.com")
+	now := time.Now()
+
+	require.NoError(t, repos.Sessions.Create(ctx, newSession("session-old", user, now.Add(-time.Hour), time.Hour)))
+	require.NoError(t, repos.Sessions.Create(ctx, newSession("session-new", user, now, time.Hour)))
+	require.NoError(t, repos.Sessions.Create(ctx, newSession("session-expired", user, now, -time.Second)))
+
+	found, err := repos.Sessions.GetByID(ctx, "session-new")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.UserID)
+	assert.Equal(t, "test-agent", found.UserAgent)
+	assert.Equal(t, "192.0.2.1", found.IPAddress)
+	assert.True(t, found.IsActive())
+
+	// Expired sessions are neither returned nor listed
+	_, err = repos.Sessions.GetByID(ctx, "session-expired")
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+
+	sessions, err := repos.Sessions.ListByUser(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "session-new", sessions[0].ID)
+	assert.Equal(t, "session-old", sessions[1].ID)
+
+	// Touch records the client and moves the session to the front
+	expiresAt := now.Add(2 * time.Hour)
+	require.NoError(t, repos.Sessions.Touch(ctx, "session-old", domain.ClientInfo{UserAgent: "curl/8.0", IPAddress: "198.51.100.2"}, expiresAt))
+
+	found, err = repos.Sessions.GetByID(ctx, "session-old")
+	require.NoError(t, err)
+	assert.Equal(t, "curl/8.0", found.UserAgent)
+	assert.Equal(t, "198.51.100.2", found.IPAddress)
+	assert.WithinDuration(t, expiresAt, found.ExpiresAt, time.Second)
+
+	sessions, err = repos.Sessions.ListByUser(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "session-old", sessions[0].ID)
+
+	assert.ErrorIs(t, repos.Sessions.Touch(ctx, "missing", domain.ClientInfo{}, expiresAt), domain.ErrSessionNotFound)
+
+	_, err = repos.Sessions.ListByUser(ctx, "invalid-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+}
+
+func testSessionRevocation(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	alice := createTestUser(t, repos, "alice@example.com")
+	bob := createTestUser(t, repos, "bob@example.com")
+	now := time.Now()
+
+	require.NoError(t, repos.Sessions.Create(ctx, newSession("alice-1", alice, now, time.Hour)))
+	require.NoError(t, repos.Sessions.Create(ctx, newSession("alice-2", alice, now, time.Hour)))
+	require.NoError(t, repos.Sessions.Create(ctx, newSession("bob-1", bob, now, time.Hour)))
+
+	// Revoked sessions are still returned by ID so they fail validation
+	require.NoError(t, repos.Sessions.Revoke(ctx, "alice-1"))
+	found, err := repos.Sessions.GetByID(ctx, "alice-1")
+	require.NoError(t, err)
+	assert.False(t, found.IsActive())
+
+	assert.ErrorIs(t, repos.Sessions.Revoke(ctx, "alice-1"), domain.ErrSessionNotFound)
+	assert.ErrorIs(t, repos.Sessions.Touch(ctx, "alice-1", domain.ClientInfo{}, now.Add(time.Hour)), domain.ErrSessionNotFound)
+
+	sessions, err := repos.Sessions.ListByUser(ctx, alice.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "alice-2", sessions[0].ID)
+
+	// Revoking all sessions of a user leaves other users alone
+	require.NoError(t, repos.Sessions.RevokeAllForUser(ctx, alice.ID.Hex()))
+	sessions, err = repos.Sessions.ListByUser(ctx, alice.ID.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	found, err = repos.Sessions.GetByID(ctx, "bob-1")
+	require.NoError(t, err)
+	assert.True(t, found.IsActive())
+
+	assert.ErrorIs(t, repos.Sessions.RevokeAllForUser(ctx, "invalid-id"), domain.ErrInvalidID)
+}
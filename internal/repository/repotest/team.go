@@ -0,0 +1,187 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testTeamCRUD(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	maintainer := createTestUser(t, repos, "maintainer@example.com")
+
+	team := &domain.Team{
+		Name:        "payments",
+		Description: "Owns the payment services",
+		Members:     []domain.TeamMember{{UserID: maintainer.ID, Role: domain.TeamRoleMaintainer}},
+	}
+	require.NoError(t, repos.Teams.Create(ctx, team))
+	assert.False(t, team.ID.IsZero())
+	assert.False(t, team.CreatedAt.IsZero())
+
+	// Names are unique
+	assert.ErrorIs(t, repos.Teams.Create(ctx, &domain.Team{Name: "payments"}), domain.ErrTeamExists)
+
+	fetched, err := repos.Teams.GetByID(ctx, team.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "payments", fetched.Name)
+	require.Len(t, fetched.Members, 1)
+	assert.Equal(t, maintainer.ID, fetched.Members[0].UserID)
+	assert.Equal(t, domain.TeamRoleMaintainer, fetched.Members[0].Role)
+	assert.False(t, fetched.Members[0].AddedAt.IsZero())
+
+	// Update
+	time.Sleep(10 * time.Millisecond)
+	fetched.Name = "billing"
+	fetched.Description = "Owns the billing services"
+	require.NoError(t, repos.Teams.Update(ctx, fetched))
+
+	updated, err := repos.Teams.GetByID(ctx, team.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "billing", updated.Name)
+	assert.Equal(t, "Owns the billing services", updated.Description)
+	assert.True(t, updated.UpdatedAt.After(updated.CreatedAt))
+
+	other := &domain.Team{Name: "platform", Description: "Runs the platform"}
+	require.NoError(t, repos.Teams.Create(ctx, other))
+	assert.Empty(t, other.Members)
+	other.Name = "billing"
+	assert.ErrorIs(t, repos.Teams.Update(ctx, other), domain.ErrTeamExists)
+
+	// List is ordered by name
+	result, err := repos.Teams.List(ctx, domain.DefaultPaginationParams())
+	require.NoError(t, err)
+	require.Len(t, result.Data, 2)
+	assert.Equal(t, int64(2), result.Pagination.Total)
+	assert.Equal(t, "billing", result.Data[0].Name)
+	assert.Len(t, result.Data[0].Members, 1)
+	assert.Equal(t, "platform", result.Data[1].Name)
+	assert.Empty(t, result.Data[1].Members)
+
+	// Delete
+	require.NoError(t, repos.Teams.Delete(ctx, team.ID.Hex()))
+	_, err = repos.Teams.GetByID(ctx, team.ID.Hex())
+	assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+	assert.ErrorIs(t, repos.Teams.Delete(ctx, team.ID.Hex()), domain.ErrTeamNotFound)
+	assert.ErrorIs(t, repos.Teams.Update(ctx, team), domain.ErrTeamNotFound)
+
+	_, err = repos.Teams.GetByID(ctx, "invalid-id")
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+}
+
+func testTeamMembership(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	alice := createTestUser(t, repos, "alice@example.com")
+	bob := createTestUser(t, repos, "bob@example.com")
+
+	payments := &domain.Team{Name: "payments", Members: []domain.TeamMember{{UserID: alice.ID, Role: domain.TeamRoleMaintainer}}}
+	require.NoError(t, repos.Teams.Create(ctx, payments))
+	platform := &domain.Team{Name: "platform"}
+	require.NoError(t, repos.Teams.Create(ctx, platform))
+
+	// Add members
+	require.NoError(t, repos.Teams.SetMember(ctx, payments.ID.Hex(), domain.TeamMember{UserID: bob.ID, Role: domain.TeamRoleMember}))
+	require.NoError(t, repos.Teams.SetMember(ctx, platform.ID.Hex(), domain.TeamMember{UserID: bob.ID, Role: domain.TeamRoleMaintainer}))
+
+	team, err := repos.Teams.GetByID(ctx, payments.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, team.Members, 2)
+	added := team.Member(bob.ID.Hex())
+	require.NotNil(t, added)
+	assert.Equal(t, domain.TeamRoleMember, added.Role)
+
+	// Changing a role keeps the membership
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, repos.Teams.SetMember(ctx, payments.ID.Hex(), domain.TeamMember{UserID: bob.ID, Role: domain.TeamRoleMaintainer}))
+	team, err = repos.Teams.GetByID(ctx, payments.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, team.Members, 2)
+	changed := team.Member(bob.ID.Hex())
+	require.NotNil(t, changed)
+	assert.Equal(t, domain.TeamRoleMaintainer, changed.Role)
+	assert.WithinDuration(t, added.AddedAt, changed.AddedAt, time.Millisecond)
+
+	missing := primitive.NewObjectID().Hex()
+	assert.ErrorIs(t, repos.Teams.SetMember(ctx, missing, domain.TeamMember{UserID: bob.ID, Role: domain.TeamRoleMember}), domain.ErrTeamNotFound)
+
+	// ListByMember
+	teams, err := repos.Teams.ListByMember(ctx, bob.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, teams, 2)
+	assert.Equal(t, "payments", teams[0].Name)
+	assert.Equal(t, "platform", teams[1].Name)
+
+	teams, err = repos.Teams.ListByMember(ctx, alice.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, teams, 1)
+	assert.Len(t, teams[0].Members, 2)
+
+	// RemoveMember
+	require.NoError(t, repos.Teams.RemoveMember(ctx, payments.ID.Hex(), alice.ID.Hex()))
+	assert.ErrorIs(t, repos.Teams.RemoveMember(ctx, payments.ID.Hex(), alice.ID.Hex()), domain.ErrTeamMemberNotFound)
+	assert.ErrorIs(t, repos.Teams.RemoveMember(ctx, missing, alice.ID.Hex()), domain.ErrTeamNotFound)
+	teams, err = repos.Teams.ListByMember(ctx, alice.ID.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, teams)
+
+	// RemoveUser leaves every team
+	require.NoError(t, repos.Teams.RemoveUser(ctx, bob.ID.Hex()))
+	teams, err = repos.Teams.ListByMember(ctx, bob.ID.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, teams)
+	team, err = repos.Teams.GetByID(ctx, payments.ID.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, team.Members)
+}
+
+func testServiceTeam(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	team := &domain.Team{Name: "payments"}
+	require.NoError(t, repos.Teams.Create(ctx, team))
+
+	owned := &domain.Service{Name: "payment-service", Description: "Payment processing", TeamID: &team.ID}
+	require.NoError(t, repos.Services.Create(ctx, owned))
+	unowned := &domain.Service{Name: "user-service", Description: "User management"}
+	require.NoError(t, repos.Services.Create(ctx, unowned))
+
+	fetched, err := repos.Services.GetByID(ctx, owned.ID.Hex())
+	require.NoError(t, err)
+	require.NotNil(t, fetched.TeamID)
+	assert.Equal(t, team.ID, *fetched.TeamID)
+
+	fetched, err = repos.Services.GetByID(ctx, unowned.ID.Hex())
+	require.NoError(t, err)
+	assert.Nil(t, fetched.TeamID)
+
+	// Team filter
+	params := domain.DefaultListParams()
+	params.Team = team.ID.Hex()
+	result, err := repos.Services.List(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "payment-service", result.Data[0].Name)
+
+	params.Team = "invalid-id"
+	_, err = repos.Services.List(ctx, params)
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+
+	// Update can move a service between teams
+	fetched.TeamID = &team.ID
+	require.NoError(t, repos.Services.Update(ctx, fetched))
+	owned, err = repos.Services.GetByID(ctx, owned.ID.Hex())
+	require.NoError(t, err)
+	owned.TeamID = nil
+	require.NoError(t, repos.Services.Update(ctx, owned))
+
+	params.Team = team.ID.Hex()
+	result, err = repos.Services.List(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "user-service", result.Data[0].Name)
+}
@@ -0,0 +1,55 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUserToken(hash, purpose string, user *domain.User, ttl time.Duration) *domain.UserToken {
+	return &domain.UserToken{
+		Hash:      hash,
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+}
+
+func testUserTokenLifecycle(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	user := createTestUser(t, repos, "tokens@example.com")
+
+	require.NoError(t, repos.UserTokens.Create(ctx, newUserToken("hash-1", domain.TokenPurposePasswordReset, user, time.Hour)))
+
+	// The purpose must match
+	_, err := repos.UserTokens.Consume(ctx, "hash-1", domain.TokenPurposeEmailVerification)
+	assert.ErrorIs(t, err, domain.ErrInvalidUserToken)
+
+	// Tokens are single use
+	token, err := repos.UserTokens.Consume(ctx, "hash-1", domain.TokenPurposePasswordReset)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, token.UserID)
+	assert.Equal(t, "tokens@example.com", token.Email)
+	_, err = repos.UserTokens.Consume(ctx, "hash-1", domain.TokenPurposePasswordReset)
+	assert.ErrorIs(t, err, domain.ErrInvalidUserToken)
+
+	// Expired tokens can't be consumed
+	require.NoError(t, repos.UserTokens.Create(ctx, newUserToken("hash-expired", domain.TokenPurposePasswordReset, user, -time.Second)))
+	_, err = repos.UserTokens.Consume(ctx, "hash-expired", domain.TokenPurposePasswordReset)
+	assert.ErrorIs(t, err, domain.ErrInvalidUserToken)
+
+	// Deleting a user's tokens only affects the given purpose
+	require.NoError(t, repos.UserTokens.Create(ctx, newUserToken("hash-reset", domain.TokenPurposePasswordReset, user, time.Hour)))
+	require.NoError(t, repos.UserTokens.Create(ctx, newUserToken("hash-verify", domain.TokenPurposeEmailVerification, user, time.Hour)))
+	require.NoError(t, repos.UserTokens.DeleteForUser(ctx, user.ID.Hex(), domain.TokenPurposePasswordReset))
+
+	_, err = repos.UserTokens.Consume(ctx, "hash-reset", domain.TokenPurposePasswordReset)
+	assert.ErrorIs(t, err, domain.ErrInvalidUserToken)
+	_, err = repos.UserTokens.Consume(ctx, "hash-verify", domain.TokenPurposeEmailVerification)
+	require.NoError(t, err)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoRoleRepository implements domain.RoleRepository using MongoDB
+type MongoRoleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoRoleRepository creates a new MongoRoleRepository
+func NewMongoRoleRepository(db *mongo.Database) *MongoRoleRepository {
+	return &MongoRoleRepository{
+		collection: db.Collection("roles"),
+	}
+}
+
+// Create stores a new role
+func (r *MongoRoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	now := time.Now()
+	role.CreatedAt = now
+	role.UpdatedAt = now
+
+	_, err := r.collection.InsertOne(ctx, role)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrRoleExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetByName retrieves a role by its name
+func (r *MongoRoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	var role domain.Role
+	err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&role)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// List retrieves all roles ordered by name
+func (r *MongoRoleRepository) List(ctx context.Context) ([]domain.Role, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	roles := []domain.Role{}
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// Update updates the description and permissions of a role
+func (r *MongoRoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	role.UpdatedAt = time.Now()
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": role.Name},
+		bson.M{"$set": bson.M{
+			"description": role.Description,
+			"permissions": role.Permissions,
+			"updated_at":  role.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a role by its name
+func (r *MongoRoleRepository) Delete(ctx context.Context, name string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	return nil
+}
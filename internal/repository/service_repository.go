@@ -58,35 +58,65 @@ func (r *MongoServiceRepository) GetByID(ctx context.Context, id string) (*domai
 	return &service, nil
 }
 
-// Update updates an existing service and increments revision
+// Update updates an existing service and increments revision. The service is
+// set to the revision written by this call, which concurrent updates can't
+// change in between.
 func (r *MongoServiceRepository) Update(ctx context.Context, service *domain.Service) error {
 	service.UpdatedAt = time.Now()
 
-	result, err := r.collection.UpdateOne(
+	var updated domain.Service
+	err := r.collection.FindOneAndUpdate(
 		ctx,
 		bson.M{"_id": service.ID},
 		bson.M{
 			"$set": bson.M{
 				"name":        service.Name,
 				"description": service.Description,
+				"team_id":     service.TeamID,
 				"updated_at":  service.UpdatedAt,
 			},
 			"$inc": bson.M{
 				"revision": 1,
 			},
 		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"revision": 1}),
+	).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+
+	service.Revision = updated.Revision
+
+	return nil
+}
+
+// Restore writes back the state of a service before an update, if the service
+// is still at the revision written by that update
+func (r *MongoServiceRepository) Restore(ctx context.Context, previous *domain.Service) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": previous.ID, "revision": previous.Revision + 1},
+		bson.M{
+			"$set": bson.M{
+				"name":        previous.Name,
+				"description": previous.Description,
+				"team_id":     previous.TeamID,
+				"updated_at":  previous.UpdatedAt,
+				"revision":    previous.Revision,
+			},
+		},
 	)
 	if err != nil {
 		return err
 	}
 
 	if result.MatchedCount == 0 {
-		return domain.ErrNotFound
+		return domain.ErrServiceChanged
 	}
 
-	// Increment the revision in the struct to reflect the new value
-	service.Revision++
-
 	return nil
 }
 
@@ -118,6 +148,15 @@ func (r *MongoServiceRepository) List(ctx context.Context, params domain.ListPar
 		filter["name"] = bson.M{"$regex": "^" + params.Name + "$", "$options": "i"}
 	}
 
+	// Apply team filter (services owned by a team)
+	if params.Team != "" {
+		teamID, err := primitive.ObjectIDFromHex(params.Team)
+		if err != nil {
+			return nil, domain.ErrInvalidID
+		}
+		filter["team_id"] = teamID
+	}
+
 	// Apply search filter (partial match on name or description)
 	if params.Search != "" {
 		filter["$or"] = []bson.M{
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"sort"
 
 	"github.com/services-api/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
@@ -26,12 +27,36 @@ func NewMongoServiceVersionRepository(db *mongo.Database) *MongoServiceVersionRe
 	}
 }
 
-// Create creates a new service version snapshot
+// Create creates a new service version snapshot. Unless it is the first
+// revision, it is only stored if the previous revision is stored with the
+// hash it chains onto, or is missing and the version starts a new segment of
+// the chain with no PrevHash, and fails with domain.ErrPreviousVersionMissing
+// otherwise. Stored versions never change their hash and the unique index on
+// (service_id, revision) rejects a second version chaining onto the same one,
+// so checking before inserting can't fork the chain.
 func (r *MongoServiceVersionRepository) Create(ctx context.Context, version *domain.ServiceVersion) error {
 	if version.ID.IsZero() {
 		version.ID = primitive.NewObjectID()
 	}
 
+	if version.Revision > 1 {
+		var prev domain.ServiceVersion
+		err := r.collection.FindOne(ctx, bson.M{
+			"service_id": version.ServiceID,
+			"revision":   version.Revision - 1,
+		}).Decode(&prev)
+		switch {
+		case err == mongo.ErrNoDocuments:
+			if version.PrevHash != "" {
+				return domain.ErrPreviousVersionMissing
+			}
+		case err != nil:
+			return err
+		case prev.Hash != version.PrevHash:
+			return domain.ErrPreviousVersionMissing
+		}
+	}
+
 	_, err := r.collection.InsertOne(ctx, version)
 	return err
 }
@@ -123,3 +148,58 @@ func (r *MongoServiceVersionRepository) DeleteByServiceID(ctx context.Context, s
 	_, err = r.collection.DeleteMany(ctx, bson.M{"service_id": objectID})
 	return err
 }
+
+// ListServiceIDs retrieves the IDs of every service that has versions, oldest first
+func (r *MongoServiceVersionRepository) ListServiceIDs(ctx context.Context) ([]string, error) {
+	values, err := r.collection.Distinct(ctx, "service_id", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	// ObjectIDs start with their creation time, so sorting them by hex
+	// orders the services by age
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id.Hex())
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ListByAuthor retrieves every version a user authored, oldest first
+func (r *MongoServiceVersionRepository) ListByAuthor(ctx context.Context, authorID string) ([]domain.ServiceVersion, error) {
+	objectID, err := primitive.ObjectIDFromHex(authorID)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"author_id": objectID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := []domain.ServiceVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// AnonymizeAuthor clears the author of every version a user authored and marks the author deleted
+func (r *MongoServiceVersionRepository) AnonymizeAuthor(ctx context.Context, authorID string) error {
+	objectID, err := primitive.ObjectIDFromHex(authorID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"author_id": objectID},
+		bson.M{"$unset": bson.M{"author_id": "", "author_salt": ""}, "$set": bson.M{"author_deleted": true}},
+	)
+	return err
+}
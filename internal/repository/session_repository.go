@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoSessionRepository implements domain.SessionRepository using MongoDB
+type MongoSessionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoSessionRepository creates a new MongoSessionRepository
+func NewMongoSessionRepository(db *mongo.Database) *MongoSessionRepository {
+	return &MongoSessionRepository{
+		collection: db.Collection("sessions"),
+	}
+}
+
+// Create stores a new session
+func (r *MongoSessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+// GetByID retrieves an unexpired session by its ID
+func (r *MongoSessionRepository) GetByID(ctx context.Context, id string) (*domain.Session, error) {
+	var session domain.Session
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":        id,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ListByUser retrieves the active sessions of a user, most recently seen first
+func (r *MongoSessionRepository) ListByUser(ctx context.Context, userID string) ([]domain.Session, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"user_id":    objectID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []domain.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Touch records activity on a session and extends its expiry
+func (r *MongoSessionRepository) Touch(ctx context.Context, id string, client domain.ClientInfo, expiresAt time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"user_agent":   client.UserAgent,
+			"ip_address":   client.IPAddress,
+			"last_seen_at": time.Now(),
+			"expires_at":   expiresAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// Revoke revokes a session
+func (r *MongoSessionRepository) Revoke(ctx context.Context, id string) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeAllForUser revokes every session of a user
+func (r *MongoSessionRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"user_id": objectID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	return err
+}
@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+)
+
+// HealthChecker reports the health of the SQLite backend
+type HealthChecker struct {
+	db   *sql.DB
+	path string
+}
+
+// NewHealthChecker creates a new HealthChecker
+func NewHealthChecker(db *sql.DB, path string) *HealthChecker {
+	return &HealthChecker{db: db, path: path}
+}
+
+// Backend returns the storage backend name
+func (c *HealthChecker) Backend() string {
+	return "sqlite"
+}
+
+// CheckHealth reports the SQLite version, database file and journal mode
+func (c *HealthChecker) CheckHealth(ctx context.Context) (map[string]string, error) {
+	var version, journalMode string
+	if err := c.db.QueryRowContext(ctx, `SELECT sqlite_version()`).Scan(&version); err != nil {
+		return nil, err
+	}
+	if err := c.db.QueryRowContext(ctx, `PRAGMA journal_mode`).Scan(&journalMode); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		"path":           c.path,
+		"sqlite_version": version,
+		"journal_mode":   journalMode,
+	}, nil
+}
@@ -0,0 +1,37 @@
+CREATE TABLE services (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL,
+    revision    INTEGER NOT NULL DEFAULT 1,
+    created_at  TIMESTAMP NOT NULL,
+    updated_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX services_name_idx ON services (name);
+CREATE INDEX services_lower_name_idx ON services (lower(name));
+CREATE INDEX services_created_at_idx ON services (created_at);
+CREATE INDEX services_updated_at_idx ON services (updated_at);
+
+CREATE TABLE service_versions (
+    id          TEXT PRIMARY KEY,
+    service_id  TEXT NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    revision    INTEGER NOT NULL,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL,
+    created_at  TIMESTAMP NOT NULL,
+    UNIQUE (service_id, revision)
+);
+
+CREATE TABLE users (
+    id            TEXT PRIMARY KEY,
+    email         TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    first_name    TEXT NOT NULL,
+    last_name     TEXT NOT NULL DEFAULT '',
+    role          TEXT NOT NULL,
+    active        BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMP NOT NULL,
+    updated_at    TIMESTAMP NOT NULL
+);
+
+CREATE INDEX users_created_at_idx ON users (created_at DESC);
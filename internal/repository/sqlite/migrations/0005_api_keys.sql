@@ -0,0 +1,14 @@
+CREATE TABLE api_keys (
+    id           TEXT PRIMARY KEY,
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL UNIQUE,
+    secret_hash  TEXT NOT NULL,
+    owner_id     TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    scopes       TEXT NOT NULL DEFAULT '',
+    expires_at   TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at   TIMESTAMP NOT NULL,
+    updated_at   TIMESTAMP NOT NULL
+);
+
+CREATE INDEX api_keys_owner_id_idx ON api_keys (owner_id, created_at DESC);
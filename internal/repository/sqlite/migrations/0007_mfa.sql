@@ -0,0 +1,14 @@
+CREATE TABLE mfa_totp (
+    user_id        TEXT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret         TEXT NOT NULL,
+    confirmed_at   TIMESTAMP,
+    last_used_step INTEGER NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP NOT NULL,
+    updated_at     TIMESTAMP NOT NULL
+);
+
+CREATE TABLE mfa_recovery_codes (
+    user_id   TEXT NOT NULL REFERENCES mfa_totp (user_id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    PRIMARY KEY (user_id, code_hash)
+);
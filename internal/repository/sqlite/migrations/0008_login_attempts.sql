@@ -0,0 +1,9 @@
+CREATE TABLE login_attempts (
+    id              TEXT PRIMARY KEY,
+    failures        INTEGER NOT NULL,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until    TIMESTAMP,
+    expires_at      TIMESTAMP NOT NULL
+);
+
+CREATE INDEX login_attempts_expires_at_idx ON login_attempts (expires_at);
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMP;
+
+CREATE INDEX users_last_login_at_idx ON users (last_login_at);
+CREATE INDEX users_name_idx ON users (last_name, first_name);
@@ -0,0 +1,25 @@
+-- Deleting a service keeps its versions, ending the history with a tombstone version.
+-- SQLite can't drop a foreign key, so the table is rebuilt without it.
+CREATE TABLE service_versions_new (
+    id             TEXT PRIMARY KEY,
+    service_id     TEXT NOT NULL,
+    revision       INTEGER NOT NULL,
+    name           TEXT NOT NULL,
+    description    TEXT NOT NULL,
+    created_at     TIMESTAMP NOT NULL,
+    author_id      TEXT,
+    author_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    hash           TEXT,
+    prev_hash      TEXT,
+    author_hash    TEXT NOT NULL DEFAULT '',
+    deleted        BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE (service_id, revision)
+);
+
+INSERT INTO service_versions_new (id, service_id, revision, name, description, created_at, author_id, author_deleted, hash, prev_hash)
+SELECT id, service_id, revision, name, description, created_at, author_id, author_deleted, hash, prev_hash FROM service_versions;
+
+DROP TABLE service_versions;
+ALTER TABLE service_versions_new RENAME TO service_versions;
+
+CREATE INDEX service_versions_author_id_idx ON service_versions (author_id);
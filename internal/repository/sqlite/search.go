@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+)
+
+// searchIndexSchema creates the FTS5 index over services and the triggers keeping it in sync.
+// The trigram tokenizer gives substring matching, equivalent to the Mongo regex search.
+const searchIndexSchema = `
+CREATE VIRTUAL TABLE services_fts USING fts5(
+    id UNINDEXED,
+    name,
+    description,
+    tokenize = 'trigram'
+);
+
+CREATE TRIGGER services_fts_insert AFTER INSERT ON services BEGIN
+    INSERT INTO services_fts (id, name, description) VALUES (new.id, new.name, new.description);
+END;
+
+CREATE TRIGGER services_fts_update AFTER UPDATE OF name, description ON services BEGIN
+    UPDATE services_fts SET name = new.name, description = new.description WHERE id = old.id;
+END;
+
+CREATE TRIGGER services_fts_delete AFTER DELETE ON services BEGIN
+    DELETE FROM services_fts WHERE id = old.id;
+END;
+
+INSERT INTO services_fts (id, name, description) SELECT id, name, description FROM services;
+`
+
+// ensureSearchIndex creates and backfills the FTS5 index if it does not exist yet
+func ensureSearchIndex(ctx context.Context, db *sql.DB) error {
+	var exists bool
+	err := db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'services_fts')`,
+	).Scan(&exists)
+	if err != nil || exists {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, searchIndexSchema); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	slog.Info("Created FTS5 search index on services")
+	return nil
+}
+
+// ftsPhrase quotes a search term as a single FTS5 phrase so user input is never parsed as query syntax
+func ftsPhrase(search string) string {
+	return `"` + strings.ReplaceAll(search, `"`, `""`) + `"`
+}
@@ -0,0 +1,172 @@
+// Package sqlite provides an embedded SQLite storage backend for
+// single-binary deployments that don't want to operate a database server.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/services-api/internal/repository/sqlstore"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// minTrigramSearchLength is the shortest search term the trigram index can serve
+const minTrigramSearchLength = 3
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Open opens the SQLite database at path, creating it if it does not exist
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create SQLite directory: %w", err)
+		}
+	}
+
+	// Immediate transactions take the write lock up front, which avoids
+	// deadlocks when a read transaction later tries to write
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+
+	// SQLite supports a single writer, so serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open SQLite database at %s: %w", path, err)
+	}
+
+	slog.Info("Opened SQLite database", "path", path)
+	return db, nil
+}
+
+// Migrate applies any pending SQL migrations and creates the FTS5 search index
+func Migrate(ctx context.Context, db *sql.DB) error {
+	if err := sqlstore.Migrate(ctx, db, Dialect{}, migrationFiles); err != nil {
+		return err
+	}
+
+	return ensureSearchIndex(ctx, db)
+}
+
+// NewServiceRepository creates a domain.ServiceRepository backed by SQLite
+func NewServiceRepository(db *sql.DB) *sqlstore.ServiceRepository {
+	return sqlstore.NewServiceRepository(db, Dialect{})
+}
+
+// NewServiceVersionRepository creates a domain.ServiceVersionRepository backed by SQLite
+func NewServiceVersionRepository(db *sql.DB) *sqlstore.ServiceVersionRepository {
+	return sqlstore.NewServiceVersionRepository(db, Dialect{})
+}
+
+// NewUserRepository creates a domain.UserRepository backed by SQLite
+func NewUserRepository(db *sql.DB) *sqlstore.UserRepository {
+	return sqlstore.NewUserRepository(db, Dialect{})
+}
+
+// NewIdempotencyRepository creates a domain.IdempotencyRepository backed by SQLite
+func NewIdempotencyRepository(db *sql.DB) *sqlstore.IdempotencyRepository {
+	return sqlstore.NewIdempotencyRepository(db, Dialect{})
+}
+
+// NewRefreshTokenRepository creates a domain.RefreshTokenRepository backed by SQLite
+func NewRefreshTokenRepository(db *sql.DB) *sqlstore.RefreshTokenRepository {
+	return sqlstore.NewRefreshTokenRepository(db, Dialect{})
+}
+
+// NewSessionRepository creates a domain.SessionRepository backed by SQLite
+func NewSessionRepository(db *sql.DB) *sqlstore.SessionRepository {
+	return sqlstore.NewSessionRepository(db, Dialect{})
+}
+
+// NewAPIKeyRepository creates a domain.APIKeyRepository backed by SQLite
+func NewAPIKeyRepository(db *sql.DB) *sqlstore.APIKeyRepository {
+	return sqlstore.NewAPIKeyRepository(db, Dialect{})
+}
+
+// NewRoleRepository creates a domain.RoleRepository backed by SQLite
+func NewRoleRepository(db *sql.DB) *sqlstore.RoleRepository {
+	return sqlstore.NewRoleRepository(db, Dialect{})
+}
+
+// Dialect implements sqlstore.Dialect for SQLite
+type Dialect struct{}
+
+// Rebind returns the query unchanged since SQLite uses '?' placeholders
+func (Dialect) Rebind(query string) string {
+	return query
+}
+
+// IsUniqueViolation checks if the error is a unique or primary key constraint violation
+func (Dialect) IsUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE ||
+		sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// SearchCondition matches name or description case-insensitively using the
+// FTS5 trigram index. Terms shorter than a trigram can't be matched as a
+// phrase, so they are matched with LIKE against the index table instead.
+func (Dialect) SearchCondition(search string) (string, []any) {
+	if utf8.RuneCountInString(search) >= minTrigramSearchLength {
+		return `id IN (SELECT id FROM services_fts WHERE services_fts MATCH ?)`, []any{ftsPhrase(search)}
+	}
+
+	pattern := sqlstore.LikePattern(search)
+	return `id IN (SELECT id FROM services_fts WHERE name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`, []any{pattern, pattern}
+}
+
+// LockMigrations is a no-op because transactions are opened with BEGIN IMMEDIATE
+func (Dialect) LockMigrations(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
+
+// NewMFARepository creates a domain.MFARepository backed by SQLite
+func NewMFARepository(db *sql.DB) *sqlstore.MFARepository {
+	return sqlstore.NewMFARepository(db, Dialect{})
+}
+
+// NewLoginAttemptRepository creates a domain.LoginAttemptRepository backed by SQLite
+func NewLoginAttemptRepository(db *sql.DB) *sqlstore.LoginAttemptRepository {
+	return sqlstore.NewLoginAttemptRepository(db, Dialect{})
+}
+
+// NewUserTokenRepository creates a domain.UserTokenRepository backed by SQLite
+func NewUserTokenRepository(db *sql.DB) *sqlstore.UserTokenRepository {
+	return sqlstore.NewUserTokenRepository(db, Dialect{})
+}
+
+// NewPasswordHistoryRepository creates a domain.PasswordHistoryRepository backed by SQLite
+func NewPasswordHistoryRepository(db *sql.DB) *sqlstore.PasswordHistoryRepository {
+	return sqlstore.NewPasswordHistoryRepository(db, Dialect{})
+}
+
+// NewInvitationRepository creates a domain.InvitationRepository backed by SQLite
+func NewInvitationRepository(db *sql.DB) *sqlstore.InvitationRepository {
+	return sqlstore.NewInvitationRepository(db, Dialect{})
+}
+
+// NewTeamRepository creates a domain.TeamRepository backed by SQLite
+func NewTeamRepository(db *sql.DB) *sqlstore.TeamRepository {
+	return sqlstore.NewTeamRepository(db, Dialect{})
+}
+
+// NewAuditRepository creates a domain.AuditRepository backed by SQLite
+func NewAuditRepository(db *sql.DB) *sqlstore.AuditRepository {
+	return sqlstore.NewAuditRepository(db, Dialect{})
+}
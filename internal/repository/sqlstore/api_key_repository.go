@@ -0,0 +1,205 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const apiKeyColumns = `id, name, prefix, secret_hash, owner_id, scopes, expires_at, last_used_at, created_at, updated_at`
+
+// APIKeyRepository implements domain.APIKeyRepository using database/sql.
+// Scopes are stored space-separated, as in an OAuth scope string.
+type APIKeyRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository
+func NewAPIKeyRepository(db *sql.DB, dialect Dialect) *APIKeyRepository {
+	return &APIKeyRepository{db: db, dialect: dialect}
+}
+
+// Create stores a new API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	now := time.Now().UTC()
+	key.CreatedAt = now
+	key.UpdatedAt = now
+
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`INSERT INTO api_keys (`+apiKeyColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		key.ID.Hex(), key.Name, key.Prefix, key.SecretHash, key.OwnerID.Hex(), strings.Join(key.Scopes, " "),
+		nullTime(key.ExpiresAt), nullTime(key.LastUsedAt), key.CreatedAt, key.UpdatedAt,
+	)
+	if err != nil {
+		// Check for unique violation (prefix already exists)
+		if r.dialect.IsUniqueViolation(err) {
+			return domain.ErrAPIKeyPrefixExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetByID retrieves an API key by its ID
+func (r *APIKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`), id)
+	return r.scanOne(row)
+}
+
+// GetByPrefix retrieves an API key by its lookup prefix
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = ?`), prefix)
+	return r.scanOne(row)
+}
+
+// Update updates the name, scopes and expiry of an API key
+func (r *APIKeyRepository) Update(ctx context.Context, key *domain.APIKey) error {
+	key.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`
+		UPDATE api_keys
+		SET name = ?, scopes = ?, expires_at = ?, updated_at = ?
+		WHERE id = ?`),
+		key.Name, strings.Join(key.Scopes, " "), nullTime(key.ExpiresAt), key.UpdatedAt, key.ID.Hex(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.requireAffected(result)
+}
+
+// Delete deletes an API key by its ID
+func (r *APIKeyRepository) Delete(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM api_keys WHERE id = ?`), id)
+	if err != nil {
+		return err
+	}
+
+	return r.requireAffected(result)
+}
+
+// List retrieves API keys with pagination, optionally restricted to one owner
+func (r *APIKeyRepository) List(ctx context.Context, ownerID string, params domain.PaginationParams) (*domain.PaginatedResult[domain.APIKey], error) {
+	where := ""
+	var args []any
+	if ownerID != "" {
+		if _, err := primitive.ObjectIDFromHex(ownerID); err != nil {
+			return nil, domain.ErrInvalidID
+		}
+		where = ` WHERE owner_id = ?`
+		args = append(args, ownerID)
+	}
+
+	// Count total rows
+	var total int64
+	if err := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT count(*) FROM api_keys`+where), args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		r.dialect.Rebind(`SELECT `+apiKeyColumns+` FROM api_keys`+where+` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`),
+		append(args, params.Limit, params.Offset())...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return domain.NewPaginatedResult(keys, total, params), nil
+}
+
+// TouchLastUsed records when an API key was last used
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`UPDATE api_keys SET last_used_at = ? WHERE id = ?`), usedAt.UTC(), id,
+	)
+	return err
+}
+
+// requireAffected maps a statement that matched no key to domain.ErrAPIKeyNotFound
+func (r *APIKeyRepository) requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// scanOne scans a single API key row, mapping a missing row to domain.ErrAPIKeyNotFound
+func (r *APIKeyRepository) scanOne(row *sql.Row) (*domain.APIKey, error) {
+	key, err := scanAPIKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// scanAPIKey scans an api_keys row into a domain.APIKey
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var id, ownerID, scopes string
+	var expiresAt, lastUsedAt sql.NullTime
+	if err := row.Scan(&id, &key.Name, &key.Prefix, &key.SecretHash, &ownerID, &scopes,
+		&expiresAt, &lastUsedAt, &key.CreatedAt, &key.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if key.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+	if key.OwnerID, err = primitive.ObjectIDFromHex(ownerID); err != nil {
+		return nil, err
+	}
+	key.Scopes = strings.Fields(scopes)
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &key, nil
+}
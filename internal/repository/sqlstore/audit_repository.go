@@ -0,0 +1,178 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const auditColumns = `id, action, actor_id, impersonator_id, auth_type, api_key_id, ip_address, user_agent, request_id,
+	target_type, target_id, before_summary, after_summary, created_at, hash, prev_hash`
+
+// AuditRepository implements domain.AuditRepository using database/sql
+type AuditRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(db *sql.DB, dialect Dialect) *AuditRepository {
+	return &AuditRepository{db: db, dialect: dialect}
+}
+
+// Create appends an event to the audit log. The creation time is truncated to
+// milliseconds so that cursors compare equal in every backend.
+func (r *AuditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	}
+
+	before, err := encodeSummary(event.Before)
+	if err != nil {
+		return err
+	}
+	after, err := encodeSummary(event.After)
+	if err != nil {
+		return err
+	}
+
+	// Events without a hash aren't part of the chain, so they are left out of
+	// the unique index on prev_hash
+	hash := sql.NullString{String: event.Hash, Valid: event.Hash != ""}
+	prevHash := sql.NullString{String: event.PrevHash, Valid: event.Hash != ""}
+
+	_, err = r.db.ExecContext(ctx,
+		r.dialect.Rebind(`INSERT INTO audit_events (`+auditColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		event.ID.Hex(), event.Action, event.ActorID, event.ImpersonatorID, event.AuthType, event.APIKeyID,
+		event.IPAddress, event.UserAgent, event.RequestID, event.TargetType, event.TargetID, before, after, event.CreatedAt.UTC(),
+		hash, prevHash,
+	)
+	if err != nil && r.dialect.IsUniqueViolation(err) {
+		return domain.ErrChainConflict
+	}
+	return err
+}
+
+// List retrieves up to query.Limit events matching a query, newest first
+func (r *AuditRepository) List(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEvent, error) {
+	var conditions []string
+	var args []any
+
+	if query.ActorID != "" {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, query.ActorID)
+	}
+	if query.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, query.Action)
+	}
+	if query.TargetType != "" {
+		conditions = append(conditions, "target_type = ?")
+		args = append(args, query.TargetType)
+	}
+	if query.TargetID != "" {
+		conditions = append(conditions, "target_id = ?")
+		args = append(args, query.TargetID)
+	}
+	if query.Since != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, query.Since.UTC())
+	}
+	if query.Until != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, query.Until.UTC())
+	}
+	if query.After != nil {
+		conditions = append(conditions, "(created_at < ? OR (created_at = ? AND id < ?))")
+		createdAt := query.After.CreatedAt.UTC()
+		args = append(args, createdAt, createdAt, query.After.ID.Hex())
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, query.Limit)
+	rows, err := r.db.QueryContext(ctx,
+		r.dialect.Rebind(`SELECT `+auditColumns+` FROM audit_events`+where+` ORDER BY created_at DESC, id DESC LIMIT ?`),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.AuditEvent{}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// encodeSummary converts a target summary into a nullable JSON column value
+func encodeSummary(summary map[string]string) (sql.NullString, error) {
+	if len(summary) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// decodeSummary converts a nullable JSON column value into a target summary
+func decodeSummary(column sql.NullString) (map[string]string, error) {
+	if !column.Valid {
+		return nil, nil
+	}
+	var summary map[string]string
+	if err := json.Unmarshal([]byte(column.String), &summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// scanAuditEvent scans an audit_events row into a domain.AuditEvent
+func scanAuditEvent(row rowScanner) (*domain.AuditEvent, error) {
+	var event domain.AuditEvent
+	var id string
+	var before, after, hash, prevHash sql.NullString
+	if err := row.Scan(&id, &event.Action, &event.ActorID, &event.ImpersonatorID, &event.AuthType, &event.APIKeyID,
+		&event.IPAddress, &event.UserAgent, &event.RequestID, &event.TargetType, &event.TargetID, &before, &after, &event.CreatedAt,
+		&hash, &prevHash); err != nil {
+		return nil, err
+	}
+	event.Hash, event.PrevHash = hash.String, prevHash.String
+
+	var err error
+	if event.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+	if event.Before, err = decodeSummary(before); err != nil {
+		return nil, err
+	}
+	if event.After, err = decodeSummary(after); err != nil {
+		return nil, err
+	}
+	event.CreatedAt = event.CreatedAt.UTC()
+
+	return &event, nil
+}
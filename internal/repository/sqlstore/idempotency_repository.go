@@ -0,0 +1,95 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/services-api/internal/domain"
+)
+
+const idempotencyColumns = `id, fingerprint, completed, status_code, content_type, body, created_at, expires_at`
+
+// IdempotencyRepository implements domain.IdempotencyRepository using database/sql
+type IdempotencyRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewIdempotencyRepository creates a new IdempotencyRepository
+func NewIdempotencyRepository(db *sql.DB, dialect Dialect) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db, dialect: dialect}
+}
+
+// Create stores a new in-progress record, purging expired records first
+func (r *IdempotencyRepository) Create(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// There is no TTL index, so expired records are removed on write
+		if _, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`DELETE FROM idempotency_keys WHERE expires_at <= ?`), time.Now().UTC(),
+		); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`INSERT INTO idempotency_keys (`+idempotencyColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+			record.Key, record.Fingerprint, record.Completed, record.StatusCode, record.ContentType,
+			record.Body, record.CreatedAt.UTC(), record.ExpiresAt.UTC(),
+		)
+		if err != nil {
+			if r.dialect.IsUniqueViolation(err) {
+				return domain.ErrIdempotencyKeyExists
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// GetByKey retrieves an unexpired record by its key
+func (r *IdempotencyRepository) GetByKey(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	var record domain.IdempotencyRecord
+	err := r.db.QueryRowContext(ctx,
+		r.dialect.Rebind(`SELECT `+idempotencyColumns+` FROM idempotency_keys WHERE id = ? AND expires_at > ?`),
+		key, time.Now().UTC(),
+	).Scan(&record.Key, &record.Fingerprint, &record.Completed, &record.StatusCode, &record.ContentType,
+		&record.Body, &record.CreatedAt, &record.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Complete stores the response of an in-progress record
+func (r *IdempotencyRepository) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`
+		UPDATE idempotency_keys
+		SET completed = ?, status_code = ?, content_type = ?, body = ?
+		WHERE id = ?`),
+		true, statusCode, contentType, body, key,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a record so its key can be used again
+func (r *IdempotencyRepository) Delete(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM idempotency_keys WHERE id = ?`), key)
+	return err
+}
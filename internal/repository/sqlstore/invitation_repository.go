@@ -0,0 +1,189 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const invitationColumns = `id, email, role, invited_by, token_hash, created_at, sent_at, expires_at`
+
+// InvitationRepository implements domain.InvitationRepository using database/sql
+type InvitationRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewInvitationRepository creates a new InvitationRepository
+func NewInvitationRepository(db *sql.DB, dialect Dialect) *InvitationRepository {
+	return &InvitationRepository{db: db, dialect: dialect}
+}
+
+// Create stores a new invitation
+func (r *InvitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	now := time.Now().UTC()
+	invitation.CreatedAt = now
+	invitation.SentAt = now
+
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`INSERT INTO invitations (`+invitationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+		invitation.ID.Hex(), invitation.Email, invitation.Role, invitation.InvitedBy.Hex(), invitation.TokenHash,
+		invitation.CreatedAt, invitation.SentAt, invitation.ExpiresAt.UTC(),
+	)
+	return err
+}
+
+// GetByID retrieves an invitation by the ID of its pending user
+func (r *InvitationRepository) GetByID(ctx context.Context, id string) (*domain.Invitation, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT `+invitationColumns+` FROM invitations WHERE id = ?`), id)
+	invitation, err := scanInvitation(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// GetByTokenHash retrieves an unexpired invitation by the hash of its token
+func (r *InvitationRepository) GetByTokenHash(ctx context.Context, hash string) (*domain.Invitation, error) {
+	row := r.db.QueryRowContext(ctx,
+		r.dialect.Rebind(`SELECT `+invitationColumns+` FROM invitations WHERE token_hash = ? AND expires_at > ?`),
+		hash, time.Now().UTC(),
+	)
+	invitation, err := scanInvitation(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// Consume atomically deletes an unexpired invitation by the hash of its token
+func (r *InvitationRepository) Consume(ctx context.Context, hash string) error {
+	result, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`DELETE FROM invitations WHERE token_hash = ? AND expires_at > ?`),
+		hash, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrInvalidInvitation
+	}
+
+	return nil
+}
+
+// Update replaces the address, token and expiry of an invitation
+func (r *InvitationRepository) Update(ctx context.Context, invitation *domain.Invitation) error {
+	invitation.SentAt = time.Now().UTC()
+
+	result, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`UPDATE invitations SET email = ?, token_hash = ?, sent_at = ?, expires_at = ? WHERE id = ?`),
+		invitation.Email, invitation.TokenHash, invitation.SentAt, invitation.ExpiresAt.UTC(), invitation.ID.Hex(),
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes an invitation by the ID of its pending user
+func (r *InvitationRepository) Delete(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM invitations WHERE id = ?`), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// List retrieves invitations with pagination, newest first
+func (r *InvitationRepository) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.Invitation], error) {
+	// Count total rows
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT count(*) FROM invitations`).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		r.dialect.Rebind(`SELECT `+invitationColumns+` FROM invitations ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`),
+		params.Limit, params.Offset(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []domain.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return domain.NewPaginatedResult(invitations, total, params), nil
+}
+
+// scanInvitation scans an invitations row into a domain.Invitation
+func scanInvitation(row rowScanner) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	var id, invitedBy string
+	if err := row.Scan(&id, &invitation.Email, &invitation.Role, &invitedBy, &invitation.TokenHash,
+		&invitation.CreatedAt, &invitation.SentAt, &invitation.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if invitation.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+	if invitation.InvitedBy, err = primitive.ObjectIDFromHex(invitedBy); err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
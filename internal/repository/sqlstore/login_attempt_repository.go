@@ -0,0 +1,111 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/services-api/internal/domain"
+)
+
+const loginAttemptColumns = `id, failures, last_failure_at, locked_until, expires_at`
+
+// LoginAttemptRepository implements domain.LoginAttemptRepository using database/sql
+type LoginAttemptRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewLoginAttemptRepository creates a new LoginAttemptRepository
+func NewLoginAttemptRepository(db *sql.DB, dialect Dialect) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db, dialect: dialect}
+}
+
+// Get retrieves the unexpired failed logins of a key
+func (r *LoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	attempts, err := scanLoginAttempts(r.db.QueryRowContext(ctx,
+		r.dialect.Rebind(`SELECT `+loginAttemptColumns+` FROM login_attempts WHERE id = ? AND expires_at > ?`),
+		key, time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// RecordFailure atomically counts a failed login for a key, purging expired
+// records first
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempts, error) {
+	now := time.Now().UTC()
+	var attempts *domain.LoginAttempts
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// There is no TTL index, so expired records are removed on write
+		if _, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`DELETE FROM login_attempts WHERE expires_at <= ?`), now,
+		); err != nil {
+			return err
+		}
+
+		// Expired records were just removed, so an existing one is still active.
+		// A lockout outlasting the window keeps the record alive.
+		var err error
+		attempts, err = scanLoginAttempts(tx.QueryRowContext(ctx, r.dialect.Rebind(`
+			INSERT INTO login_attempts (`+loginAttemptColumns+`) VALUES (?, 1, ?, NULL, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				failures = login_attempts.failures + 1,
+				last_failure_at = excluded.last_failure_at,
+				expires_at = CASE
+					WHEN login_attempts.locked_until > excluded.expires_at THEN login_attempts.locked_until
+					ELSE excluded.expires_at
+				END
+			RETURNING `+loginAttemptColumns),
+			key, now, now.Add(window),
+		))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// Lock refuses logins for a key until the given time
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	until = until.UTC()
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(`
+		INSERT INTO login_attempts (`+loginAttemptColumns+`) VALUES (?, 0, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			locked_until = excluded.locked_until,
+			expires_at = CASE
+				WHEN login_attempts.expires_at > excluded.expires_at THEN login_attempts.expires_at
+				ELSE excluded.expires_at
+			END`),
+		key, time.Now().UTC(), until, until,
+	)
+	return err
+}
+
+// Reset forgets the failed logins of a key
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM login_attempts WHERE id = ?`), key)
+	return err
+}
+
+// scanLoginAttempts scans a row selected with loginAttemptColumns
+func scanLoginAttempts(row rowScanner) (*domain.LoginAttempts, error) {
+	var attempts domain.LoginAttempts
+	var lockedUntil sql.NullTime
+	if err := row.Scan(&attempts.Key, &attempts.Failures, &attempts.LastFailureAt, &lockedUntil, &attempts.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if lockedUntil.Valid {
+		attempts.LockedUntil = &lockedUntil.Time
+	}
+	return &attempts, nil
+}
@@ -0,0 +1,167 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MFARepository implements domain.MFARepository using database/sql. Recovery
+// codes are stored one per row so that using one is a single DELETE.
+type MFARepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewMFARepository creates a new MFARepository
+func NewMFARepository(db *sql.DB, dialect Dialect) *MFARepository {
+	return &MFARepository{db: db, dialect: dialect}
+}
+
+// Save creates or replaces the TOTP factor of a user
+func (r *MFARepository) Save(ctx context.Context, factor *domain.TOTPFactor) error {
+	now := time.Now().UTC()
+	if factor.CreatedAt.IsZero() {
+		factor.CreatedAt = now
+	}
+	factor.UpdatedAt = now
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		userID := factor.UserID.Hex()
+		if _, err := tx.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM mfa_recovery_codes WHERE user_id = ?`), userID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM mfa_totp WHERE user_id = ?`), userID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, r.dialect.Rebind(`
+			INSERT INTO mfa_totp (user_id, secret, confirmed_at, last_used_step, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)`),
+			userID, factor.Secret, nullTime(factor.ConfirmedAt), factor.LastUsedStep, factor.CreatedAt.UTC(), factor.UpdatedAt,
+		); err != nil {
+			return err
+		}
+
+		for _, codeHash := range factor.RecoveryCodes {
+			if _, err := tx.ExecContext(ctx,
+				r.dialect.Rebind(`INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES (?, ?)`), userID, codeHash,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetByUserID retrieves the TOTP factor of a user
+func (r *MFARepository) GetByUserID(ctx context.Context, userID string) (*domain.TOTPFactor, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	factor := domain.TOTPFactor{UserID: objectID, RecoveryCodes: []string{}}
+	var confirmedAt sql.NullTime
+	err = r.db.QueryRowContext(ctx, r.dialect.Rebind(`
+		SELECT secret, confirmed_at, last_used_step, created_at, updated_at
+		FROM mfa_totp WHERE user_id = ?`), userID,
+	).Scan(&factor.Secret, &confirmedAt, &factor.LastUsedStep, &factor.CreatedAt, &factor.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	if confirmedAt.Valid {
+		factor.ConfirmedAt = &confirmedAt.Time
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(`SELECT code_hash FROM mfa_recovery_codes WHERE user_id = ? ORDER BY code_hash`), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var codeHash string
+		if err := rows.Scan(&codeHash); err != nil {
+			return nil, err
+		}
+		factor.RecoveryCodes = append(factor.RecoveryCodes, codeHash)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &factor, nil
+}
+
+// UseStep records a time step as used unless it or a later step already was
+func (r *MFARepository) UseStep(ctx context.Context, userID string, step int64) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`UPDATE mfa_totp SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`),
+		step, userID, step,
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.requireAffected(result, domain.ErrInvalidMFACode)
+}
+
+// UseRecoveryCode removes a recovery code by its hash
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`DELETE FROM mfa_recovery_codes WHERE user_id = ? AND code_hash = ?`),
+		userID, codeHash,
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.requireAffected(result, domain.ErrInvalidMFACode)
+}
+
+// Delete removes the TOTP factor of a user along with their recovery codes
+func (r *MFARepository) Delete(ctx context.Context, userID string) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM mfa_recovery_codes WHERE user_id = ?`), userID); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM mfa_totp WHERE user_id = ?`), userID)
+		if err != nil {
+			return err
+		}
+		return r.requireAffected(result, domain.ErrMFANotEnrolled)
+	})
+}
+
+// requireAffected maps a statement that matched no row to notFound
+func (r *MFARepository) requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
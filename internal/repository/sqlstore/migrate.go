@@ -0,0 +1,73 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Migrate applies any pending migrations/*.sql files from fsys in filename order
+func Migrate(ctx context.Context, db *sql.DB, dialect Dialect, fsys fs.FS) error {
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    TEXT PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL
+		)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	names, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := applyMigration(ctx, db, dialect, fsys, name); err != nil {
+			return fmt.Errorf("migration %s failed: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyMigration runs a single migration file unless it has already been applied
+func applyMigration(ctx context.Context, db *sql.DB, dialect Dialect, fsys fs.FS, name string) error {
+	script, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	return withTx(ctx, db, func(tx *sql.Tx) error {
+		// Hold the lock until commit so concurrent instances apply each file once
+		if err := dialect.LockMigrations(ctx, tx); err != nil {
+			return err
+		}
+
+		var applied bool
+		err := tx.QueryRowContext(ctx,
+			dialect.Rebind(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)`), name,
+		).Scan(&applied)
+		if err != nil || applied {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			dialect.Rebind(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`), name, time.Now().UTC(),
+		)
+		if err != nil {
+			return err
+		}
+
+		slog.Info("Applied migration", "name", name)
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PasswordHistoryRepository implements domain.PasswordHistoryRepository using database/sql
+type PasswordHistoryRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewPasswordHistoryRepository creates a new PasswordHistoryRepository
+func NewPasswordHistoryRepository(db *sql.DB, dialect Dialect) *PasswordHistoryRepository {
+	return &PasswordHistoryRepository{db: db, dialect: dialect}
+}
+
+// List returns the most recent previous password hashes of a user, newest first
+func (r *PasswordHistoryRepository) List(ctx context.Context, userID string, limit int) ([]string, error) {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(`
+		SELECT hash FROM password_history
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?`),
+		userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+// Add records a previous password hash of a user, keeping only the newest keep hashes
+func (r *PasswordHistoryRepository) Add(ctx context.Context, userID, hash string, keep int) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`INSERT INTO password_history (hash, user_id, created_at) VALUES (?, ?, ?)`),
+			hash, userID, time.Now().UTC(),
+		); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, r.dialect.Rebind(`
+			DELETE FROM password_history
+			WHERE user_id = ? AND hash NOT IN (
+				SELECT hash FROM password_history
+				WHERE user_id = ?
+				ORDER BY created_at DESC
+				LIMIT ?
+			)`),
+			userID, userID, keep,
+		)
+		return err
+	})
+}
@@ -0,0 +1,115 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const refreshTokenColumns = `id, family_id, user_id, replaced_by, revoked_at, created_at, expires_at`
+
+// RefreshTokenRepository implements domain.RefreshTokenRepository using database/sql
+type RefreshTokenRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewRefreshTokenRepository creates a new RefreshTokenRepository
+func NewRefreshTokenRepository(db *sql.DB, dialect Dialect) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db, dialect: dialect}
+}
+
+// Create stores a newly issued refresh token, purging expired tokens first
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// There is no TTL index, so expired tokens are removed on write
+		if _, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`DELETE FROM refresh_tokens WHERE expires_at <= ?`), time.Now().UTC(),
+		); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`INSERT INTO refresh_tokens (`+refreshTokenColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`),
+			token.ID, token.FamilyID, token.UserID.Hex(), token.ReplacedBy, nullTime(token.RevokedAt),
+			token.CreatedAt.UTC(), token.ExpiresAt.UTC(),
+		)
+		return err
+	})
+}
+
+// GetByID retrieves an unexpired refresh token by its ID
+func (r *RefreshTokenRepository) GetByID(ctx context.Context, id string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	var userID string
+	var revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		r.dialect.Rebind(`SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE id = ? AND expires_at > ?`),
+		id, time.Now().UTC(),
+	).Scan(&token.ID, &token.FamilyID, &userID, &token.ReplacedBy, &revokedAt, &token.CreatedAt, &token.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	token.UserID, err = primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
+
+// Rotate atomically marks an active token as replaced
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, id, replacedBy string) error {
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`
+		UPDATE refresh_tokens
+		SET replaced_by = ?
+		WHERE id = ? AND replaced_by = '' AND revoked_at IS NULL`),
+		replacedBy, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrRefreshTokenReused
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every token in a token family
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`),
+		time.Now().UTC(), familyID,
+	)
+	return err
+}
+
+// RevokeAllForUser revokes every token issued to a user
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`),
+		time.Now().UTC(), userID,
+	)
+	return err
+}
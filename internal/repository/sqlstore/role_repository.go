@@ -0,0 +1,129 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/services-api/internal/domain"
+)
+
+const roleColumns = `name, description, permissions, built_in, created_at, updated_at`
+
+// RoleRepository implements domain.RoleRepository using database/sql.
+// Permissions are stored space-separated, like API key scopes.
+type RoleRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewRoleRepository creates a new RoleRepository
+func NewRoleRepository(db *sql.DB, dialect Dialect) *RoleRepository {
+	return &RoleRepository{db: db, dialect: dialect}
+}
+
+// Create stores a new role
+func (r *RoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	now := time.Now().UTC()
+	role.CreatedAt = now
+	role.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`INSERT INTO roles (`+roleColumns+`) VALUES (?, ?, ?, ?, ?, ?)`),
+		role.Name, role.Description, strings.Join(role.Permissions, " "), role.BuiltIn, role.CreatedAt, role.UpdatedAt,
+	)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return domain.ErrRoleExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetByName retrieves a role by its name
+func (r *RoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT `+roleColumns+` FROM roles WHERE name = ?`), name)
+	role, err := scanRole(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// List retrieves all roles ordered by name
+func (r *RoleRepository) List(ctx context.Context) ([]domain.Role, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+roleColumns+` FROM roles ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []domain.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// Update updates the description and permissions of a role
+func (r *RoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	role.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`UPDATE roles SET description = ?, permissions = ?, updated_at = ? WHERE name = ?`),
+		role.Description, strings.Join(role.Permissions, " "), role.UpdatedAt, role.Name,
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.requireAffected(result)
+}
+
+// Delete deletes a role by its name
+func (r *RoleRepository) Delete(ctx context.Context, name string) error {
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM roles WHERE name = ?`), name)
+	if err != nil {
+		return err
+	}
+
+	return r.requireAffected(result)
+}
+
+// requireAffected maps a statement that matched no role to domain.ErrRoleNotFound
+func (r *RoleRepository) requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrRoleNotFound
+	}
+	return nil
+}
+
+// scanRole scans a roles row into a domain.Role
+func scanRole(row rowScanner) (*domain.Role, error) {
+	var role domain.Role
+	var permissions string
+	if err := row.Scan(&role.Name, &role.Description, &permissions, &role.BuiltIn, &role.CreatedAt, &role.UpdatedAt); err != nil {
+		return nil, err
+	}
+	role.Permissions = strings.Fields(permissions)
+	return &role, nil
+}
@@ -0,0 +1,240 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const serviceColumns = `id, name, description, team_id, revision, created_at, updated_at`
+
+// sortColumns maps API sort fields to their SQL columns
+var sortColumns = map[string]string{
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// ServiceRepository implements domain.ServiceRepository using database/sql
+type ServiceRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewServiceRepository creates a new ServiceRepository
+func NewServiceRepository(db *sql.DB, dialect Dialect) *ServiceRepository {
+	return &ServiceRepository{db: db, dialect: dialect}
+}
+
+// Create creates a new service with revision 1
+func (r *ServiceRepository) Create(ctx context.Context, service *domain.Service) error {
+	service.ID = primitive.NewObjectID()
+	service.Revision = 1
+	service.CreatedAt = time.Now().UTC()
+	service.UpdatedAt = service.CreatedAt
+
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`INSERT INTO services (`+serviceColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`),
+		service.ID.Hex(), service.Name, service.Description, nullObjectID(service.TeamID), service.Revision, service.CreatedAt, service.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a service by its ID
+func (r *ServiceRepository) GetByID(ctx context.Context, id string) (*domain.Service, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT `+serviceColumns+` FROM services WHERE id = ?`), id)
+	service, err := scanService(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// Update updates an existing service and increments revision
+func (r *ServiceRepository) Update(ctx context.Context, service *domain.Service) error {
+	updatedAt := time.Now().UTC()
+
+	// The row lock taken by UPDATE is held until commit, so the returned
+	// revision is exactly the one written by this call
+	var revision int
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, r.dialect.Rebind(`
+			UPDATE services
+			SET name = ?, description = ?, team_id = ?, updated_at = ?, revision = revision + 1
+			WHERE id = ?
+			RETURNING revision`),
+			service.Name, service.Description, nullObjectID(service.TeamID), updatedAt, service.ID.Hex(),
+		).Scan(&revision)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+
+	service.UpdatedAt = updatedAt
+	service.Revision = revision
+
+	return nil
+}
+
+// Restore writes back the state of a service before an update, if the service
+// is still at the revision written by that update
+func (r *ServiceRepository) Restore(ctx context.Context, previous *domain.Service) error {
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`
+		UPDATE services
+		SET name = ?, description = ?, team_id = ?, updated_at = ?, revision = ?
+		WHERE id = ? AND revision = ?`),
+		previous.Name, previous.Description, nullObjectID(previous.TeamID), previous.UpdatedAt.UTC(), previous.Revision,
+		previous.ID.Hex(), previous.Revision+1,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrServiceChanged
+	}
+
+	return nil
+}
+
+// Delete deletes a service by its ID
+func (r *ServiceRepository) Delete(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM services WHERE id = ?`), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// List retrieves services with filtering, sorting, and pagination
+func (r *ServiceRepository) List(ctx context.Context, params domain.ListParams) (*domain.PaginatedResult[domain.Service], error) {
+	var conditions []string
+	var args []any
+
+	// Apply name filter (exact match, case-insensitive)
+	if params.Name != "" {
+		conditions = append(conditions, "lower(name) = lower(?)")
+		args = append(args, params.Name)
+	}
+
+	// Apply team filter (services owned by a team)
+	if params.Team != "" {
+		if _, err := primitive.ObjectIDFromHex(params.Team); err != nil {
+			return nil, domain.ErrInvalidID
+		}
+		conditions = append(conditions, "team_id = ?")
+		args = append(args, params.Team)
+	}
+
+	// Apply search filter (partial match on name or description)
+	if params.Search != "" {
+		condition, searchArgs := r.dialect.SearchCondition(params.Search)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Count total rows
+	var total int64
+	if err := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT count(*) FROM services`+where), args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	// Determine sort order
+	sortColumn, ok := sortColumns[params.Sort]
+	if !ok {
+		sortColumn = "created_at"
+	}
+	sortOrder := "DESC"
+	if strings.ToLower(params.Order) == "asc" {
+		sortOrder = "ASC"
+	}
+
+	args = append(args, params.Pagination.Limit, params.Pagination.Offset())
+	query := fmt.Sprintf(`SELECT %s FROM services%s ORDER BY %s %s, id %s LIMIT ? OFFSET ?`,
+		serviceColumns, where, sortColumn, sortOrder, sortOrder)
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []domain.Service
+	for rows.Next() {
+		service, err := scanService(rows)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, *service)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return domain.NewPaginatedResult(services, total, params.Pagination), nil
+}
+
+// scanService scans a services row into a domain.Service
+func scanService(row rowScanner) (*domain.Service, error) {
+	var service domain.Service
+	var id string
+	var teamID sql.NullString
+	if err := row.Scan(&id, &service.Name, &service.Description, &teamID, &service.Revision, &service.CreatedAt, &service.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	service.ID = objectID
+
+	if teamID.Valid {
+		teamObjectID, err := primitive.ObjectIDFromHex(teamID.String)
+		if err != nil {
+			return nil, err
+		}
+		service.TeamID = &teamObjectID
+	}
+
+	return &service, nil
+}
@@ -0,0 +1,244 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const serviceVersionColumns = `id, service_id, revision, name, description, author_id, author_deleted, author_hash, author_salt,
+	deleted, created_at, hash, prev_hash`
+
+// ServiceVersionRepository implements domain.ServiceVersionRepository using database/sql
+type ServiceVersionRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewServiceVersionRepository creates a new ServiceVersionRepository
+func NewServiceVersionRepository(db *sql.DB, dialect Dialect) *ServiceVersionRepository {
+	return &ServiceVersionRepository{db: db, dialect: dialect}
+}
+
+// Create creates a new service version snapshot. Unless it is the first
+// revision, it is only stored if the previous revision is stored with the
+// hash it chains onto, or is missing and the version starts a new segment of
+// the chain with no PrevHash, and fails with domain.ErrPreviousVersionMissing
+// otherwise.
+func (r *ServiceVersionRepository) Create(ctx context.Context, version *domain.ServiceVersion) error {
+	if version.ID.IsZero() {
+		version.ID = primitive.NewObjectID()
+	}
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if version.Revision > 1 {
+			// Versions predating hashes have none
+			var prevHash sql.NullString
+			err := tx.QueryRowContext(ctx,
+				r.dialect.Rebind(`SELECT hash FROM service_versions WHERE service_id = ? AND revision = ?`),
+				version.ServiceID.Hex(), version.Revision-1,
+			).Scan(&prevHash)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				if version.PrevHash != "" {
+					return domain.ErrPreviousVersionMissing
+				}
+			case err != nil:
+				return err
+			case prevHash.String != version.PrevHash:
+				return domain.ErrPreviousVersionMissing
+			}
+		}
+
+		_, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`INSERT INTO service_versions (`+serviceVersionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+			version.ID.Hex(), version.ServiceID.Hex(), version.Revision, version.Name, version.Description,
+			nullObjectID(version.AuthorID), version.AuthorDeleted, version.AuthorHash, version.AuthorSalt, version.Deleted,
+			version.CreatedAt.UTC(), version.Hash, version.PrevHash,
+		)
+		return err
+	})
+}
+
+// GetByID retrieves a service version by its ID
+func (r *ServiceVersionRepository) GetByID(ctx context.Context, id string) (*domain.ServiceVersion, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT `+serviceVersionColumns+` FROM service_versions WHERE id = ?`), id)
+	return r.scanOne(row)
+}
+
+// GetByServiceIDAndRevision retrieves a specific revision of a service
+func (r *ServiceVersionRepository) GetByServiceIDAndRevision(ctx context.Context, serviceID string, revision int) (*domain.ServiceVersion, error) {
+	if _, err := primitive.ObjectIDFromHex(serviceID); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	row := r.db.QueryRowContext(ctx,
+		r.dialect.Rebind(`SELECT `+serviceVersionColumns+` FROM service_versions WHERE service_id = ? AND revision = ?`),
+		serviceID, revision,
+	)
+	return r.scanOne(row)
+}
+
+// ListByServiceID retrieves all versions for a service with pagination
+func (r *ServiceVersionRepository) ListByServiceID(ctx context.Context, serviceID string, params domain.PaginationParams) (*domain.PaginatedResult[domain.ServiceVersion], error) {
+	if _, err := primitive.ObjectIDFromHex(serviceID); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	// Count total rows
+	var total int64
+	err := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT count(*) FROM service_versions WHERE service_id = ?`), serviceID).Scan(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort by revision descending (newest first)
+	rows, err := r.db.QueryContext(ctx,
+		r.dialect.Rebind(`SELECT `+serviceVersionColumns+` FROM service_versions WHERE service_id = ? ORDER BY revision DESC LIMIT ? OFFSET ?`),
+		serviceID, params.Limit, params.Offset(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []domain.ServiceVersion
+	for rows.Next() {
+		version, err := scanServiceVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return domain.NewPaginatedResult(versions, total, params), nil
+}
+
+// DeleteByServiceID deletes all versions for a service
+func (r *ServiceVersionRepository) DeleteByServiceID(ctx context.Context, serviceID string) error {
+	if _, err := primitive.ObjectIDFromHex(serviceID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM service_versions WHERE service_id = ?`), serviceID)
+	return err
+}
+
+// ListServiceIDs retrieves the IDs of every service that has versions, oldest first
+func (r *ServiceVersionRepository) ListServiceIDs(ctx context.Context) ([]string, error) {
+	// IDs are ObjectID hex strings, which start with their creation time
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT service_id FROM service_versions ORDER BY service_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// ListByAuthor retrieves every version a user authored, oldest first
+func (r *ServiceVersionRepository) ListByAuthor(ctx context.Context, authorID string) ([]domain.ServiceVersion, error) {
+	if _, err := primitive.ObjectIDFromHex(authorID); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		r.dialect.Rebind(`SELECT `+serviceVersionColumns+` FROM service_versions WHERE author_id = ? ORDER BY created_at, id`),
+		authorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []domain.ServiceVersion{}
+	for rows.Next() {
+		version, err := scanServiceVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// AnonymizeAuthor clears the author of every version a user authored and marks the author deleted
+func (r *ServiceVersionRepository) AnonymizeAuthor(ctx context.Context, authorID string) error {
+	if _, err := primitive.ObjectIDFromHex(authorID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`UPDATE service_versions SET author_id = NULL, author_deleted = ?, author_salt = '' WHERE author_id = ?`),
+		true, authorID,
+	)
+	return err
+}
+
+// scanOne scans a single version row, mapping a missing row to domain.ErrNotFound
+func (r *ServiceVersionRepository) scanOne(row *sql.Row) (*domain.ServiceVersion, error) {
+	version, err := scanServiceVersion(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return version, nil
+}
+
+// scanServiceVersion scans a service_versions row into a domain.ServiceVersion
+func scanServiceVersion(row rowScanner) (*domain.ServiceVersion, error) {
+	var version domain.ServiceVersion
+	var id, serviceID string
+	var authorID, hash, prevHash sql.NullString
+	if err := row.Scan(&id, &serviceID, &version.Revision, &version.Name, &version.Description,
+		&authorID, &version.AuthorDeleted, &version.AuthorHash, &version.AuthorSalt, &version.Deleted, &version.CreatedAt,
+		&hash, &prevHash); err != nil {
+		return nil, err
+	}
+	version.Hash, version.PrevHash = hash.String, prevHash.String
+
+	var err error
+	if version.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+	if version.ServiceID, err = primitive.ObjectIDFromHex(serviceID); err != nil {
+		return nil, err
+	}
+	if authorID.Valid {
+		author, err := primitive.ObjectIDFromHex(authorID.String)
+		if err != nil {
+			return nil, err
+		}
+		version.AuthorID = &author
+	}
+
+	return &version, nil
+}
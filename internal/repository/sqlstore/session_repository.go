@@ -0,0 +1,168 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const sessionColumns = `id, user_id, user_agent, ip_address, revoked_at, created_at, last_seen_at, expires_at`
+
+// SessionRepository implements domain.SessionRepository using database/sql
+type SessionRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(db *sql.DB, dialect Dialect) *SessionRepository {
+	return &SessionRepository{db: db, dialect: dialect}
+}
+
+// Create stores a new session, purging expired sessions first
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// There is no TTL index, so expired sessions are removed on write
+		if _, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`DELETE FROM sessions WHERE expires_at <= ?`), time.Now().UTC(),
+		); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`INSERT INTO sessions (`+sessionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+			session.ID, session.UserID.Hex(), session.UserAgent, session.IPAddress, nullTime(session.RevokedAt),
+			session.CreatedAt.UTC(), session.LastSeenAt.UTC(), session.ExpiresAt.UTC(),
+		)
+		return err
+	})
+}
+
+// GetByID retrieves an unexpired session by its ID
+func (r *SessionRepository) GetByID(ctx context.Context, id string) (*domain.Session, error) {
+	row := r.db.QueryRowContext(ctx,
+		r.dialect.Rebind(`SELECT `+sessionColumns+` FROM sessions WHERE id = ? AND expires_at > ?`),
+		id, time.Now().UTC(),
+	)
+
+	session, err := scanSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// ListByUser retrieves the active sessions of a user, most recently seen first
+func (r *SessionRepository) ListByUser(ctx context.Context, userID string) ([]domain.Session, error) {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(`
+		SELECT `+sessionColumns+` FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_seen_at DESC, id`),
+		userID, time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []domain.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Touch records activity on a session and extends its expiry
+func (r *SessionRepository) Touch(ctx context.Context, id string, client domain.ClientInfo, expiresAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`
+		UPDATE sessions
+		SET user_agent = ?, ip_address = ?, last_seen_at = ?, expires_at = ?
+		WHERE id = ? AND revoked_at IS NULL`),
+		client.UserAgent, client.IPAddress, time.Now().UTC(), expiresAt.UTC(), id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.requireAffected(result)
+}
+
+// Revoke revokes a session
+func (r *SessionRepository) Revoke(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`),
+		time.Now().UTC(), id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.requireAffected(result)
+}
+
+// RevokeAllForUser revokes every session of a user
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`),
+		time.Now().UTC(), userID,
+	)
+	return err
+}
+
+// requireAffected maps an update that matched no active session to domain.ErrSessionNotFound
+func (r *SessionRepository) requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+// scanSession scans a sessions row into a domain.Session
+func scanSession(row rowScanner) (*domain.Session, error) {
+	var session domain.Session
+	var userID string
+	var revokedAt sql.NullTime
+	if err := row.Scan(&session.ID, &userID, &session.UserAgent, &session.IPAddress, &revokedAt,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	session.UserID = objectID
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+
+	return &session, nil
+}
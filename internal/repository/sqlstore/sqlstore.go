@@ -0,0 +1,70 @@
+// Package sqlstore implements the domain repositories on top of database/sql.
+// Engine-specific behaviour is supplied by a Dialect (see the postgres and
+// sqlite packages).
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Dialect captures the differences between SQL engines
+type Dialect interface {
+	// Rebind rewrites '?' placeholders into the engine's bind syntax
+	Rebind(query string) string
+	// IsUniqueViolation reports whether err is a unique constraint violation
+	IsUniqueViolation(err error) bool
+	// SearchCondition returns a WHERE fragment and its arguments matching
+	// services whose name or description contains search
+	SearchCondition(search string) (string, []any)
+	// LockMigrations serializes concurrent migrators for the lifetime of tx
+	LockMigrations(ctx context.Context, tx *sql.Tx) error
+}
+
+// withTx runs fn inside a transaction, committing on success and rolling back on error
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LikePattern builds a substring pattern for LIKE ... ESCAPE '\', escaping wildcards in s
+func LikePattern(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	s = strings.ReplaceAll(s, "_", `\_`)
+	return "%" + s + "%"
+}
+
+// rowScanner abstracts *sql.Row and *sql.Rows for shared scan helpers
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// nullTime converts an optional time into a nullable UTC column value
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// nullObjectID converts an optional ObjectID into a nullable column value
+func nullObjectID(id *primitive.ObjectID) sql.NullString {
+	if id == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: id.Hex(), Valid: true}
+}
@@ -0,0 +1,310 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const teamColumns = `id, name, description, created_at, updated_at`
+
+// TeamRepository implements domain.TeamRepository using database/sql.
+// Members are stored in the team_members table.
+type TeamRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewTeamRepository creates a new TeamRepository
+func NewTeamRepository(db *sql.DB, dialect Dialect) *TeamRepository {
+	return &TeamRepository{db: db, dialect: dialect}
+}
+
+// Create stores a new team with its initial members
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	now := time.Now().UTC()
+	team.ID = primitive.NewObjectID()
+	team.CreatedAt = now
+	team.UpdatedAt = now
+	if team.Members == nil {
+		team.Members = []domain.TeamMember{}
+	}
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`INSERT INTO teams (`+teamColumns+`) VALUES (?, ?, ?, ?, ?)`),
+			team.ID.Hex(), team.Name, team.Description, team.CreatedAt, team.UpdatedAt,
+		); err != nil {
+			return err
+		}
+
+		for i := range team.Members {
+			team.Members[i].AddedAt = now
+			if _, err := tx.ExecContext(ctx,
+				r.dialect.Rebind(`INSERT INTO team_members (team_id, user_id, role, added_at) VALUES (?, ?, ?, ?)`),
+				team.ID.Hex(), team.Members[i].UserID.Hex(), team.Members[i].Role, now,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return domain.ErrTeamExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetByID retrieves a team and its members by its ID
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT `+teamColumns+` FROM teams WHERE id = ?`), id)
+	team, err := scanTeam(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTeamNotFound
+		}
+		return nil, err
+	}
+
+	teams := []domain.Team{*team}
+	if err := r.loadMembers(ctx, teams); err != nil {
+		return nil, err
+	}
+	return &teams[0], nil
+}
+
+// List retrieves teams with pagination, ordered by name
+func (r *TeamRepository) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.Team], error) {
+	// Count total rows
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT count(*) FROM teams`).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	teams, err := r.query(ctx, `SELECT `+teamColumns+` FROM teams ORDER BY name LIMIT ? OFFSET ?`, params.Limit, params.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewPaginatedResult(teams, total, params), nil
+}
+
+// ListByMember retrieves the teams a user belongs to, ordered by name
+func (r *TeamRepository) ListByMember(ctx context.Context, userID string) ([]domain.Team, error) {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	return r.query(ctx, `
+		SELECT `+teamColumns+` FROM teams
+		WHERE id IN (SELECT team_id FROM team_members WHERE user_id = ?)
+		ORDER BY name`, userID)
+}
+
+// Update updates the name and description of a team
+func (r *TeamRepository) Update(ctx context.Context, team *domain.Team) error {
+	team.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`UPDATE teams SET name = ?, description = ?, updated_at = ? WHERE id = ?`),
+		team.Name, team.Description, team.UpdatedAt, team.ID.Hex(),
+	)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return domain.ErrTeamExists
+		}
+		return err
+	}
+
+	return requireTeamAffected(result)
+}
+
+// Delete deletes a team and its memberships by its ID
+func (r *TeamRepository) Delete(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM teams WHERE id = ?`), id)
+	if err != nil {
+		return err
+	}
+
+	return requireTeamAffected(result)
+}
+
+// SetMember adds a member to a team or changes the role of an existing member
+func (r *TeamRepository) SetMember(ctx context.Context, teamID string, member domain.TeamMember) error {
+	if _, err := primitive.ObjectIDFromHex(teamID); err != nil {
+		return domain.ErrInvalidID
+	}
+	now := time.Now().UTC()
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, r.dialect.Rebind(`UPDATE teams SET updated_at = ? WHERE id = ?`), now, teamID)
+		if err != nil {
+			return err
+		}
+		if err := requireTeamAffected(result); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, r.dialect.Rebind(`
+			INSERT INTO team_members (team_id, user_id, role, added_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (team_id, user_id) DO UPDATE SET role = excluded.role`),
+			teamID, member.UserID.Hex(), member.Role, now,
+		)
+		return err
+	})
+}
+
+// RemoveMember removes a user from a team
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, userID string) error {
+	if _, err := primitive.ObjectIDFromHex(teamID); err != nil {
+		return domain.ErrInvalidID
+	}
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, r.dialect.Rebind(`UPDATE teams SET updated_at = ? WHERE id = ?`), time.Now().UTC(), teamID)
+		if err != nil {
+			return err
+		}
+		if err := requireTeamAffected(result); err != nil {
+			return err
+		}
+
+		result, err = tx.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM team_members WHERE team_id = ? AND user_id = ?`), teamID, userID)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return domain.ErrTeamMemberNotFound
+		}
+		return nil
+	})
+}
+
+// RemoveUser removes a user from every team they belong to
+func (r *TeamRepository) RemoveUser(ctx context.Context, userID string) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM team_members WHERE user_id = ?`), userID)
+	return err
+}
+
+// query runs a teams query and loads the members of the teams it returns
+func (r *TeamRepository) query(ctx context.Context, query string, args ...any) ([]domain.Team, error) {
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []domain.Team{}
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, *team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadMembers(ctx, teams); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// loadMembers fills in the members of teams, in the order they were added
+func (r *TeamRepository) loadMembers(ctx context.Context, teams []domain.Team) error {
+	if len(teams) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(teams))
+	placeholders := make([]string, len(teams))
+	args := make([]any, len(teams))
+	for i := range teams {
+		teams[i].Members = []domain.TeamMember{}
+		index[teams[i].ID.Hex()] = i
+		placeholders[i] = "?"
+		args[i] = teams[i].ID.Hex()
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(`
+		SELECT team_id, user_id, role, added_at FROM team_members
+		WHERE team_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY added_at, user_id`), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teamID, userID string
+		var member domain.TeamMember
+		if err := rows.Scan(&teamID, &userID, &member.Role, &member.AddedAt); err != nil {
+			return err
+		}
+		if member.UserID, err = primitive.ObjectIDFromHex(userID); err != nil {
+			return err
+		}
+		i := index[teamID]
+		teams[i].Members = append(teams[i].Members, member)
+	}
+	return rows.Err()
+}
+
+// requireTeamAffected maps a statement that matched no team to domain.ErrTeamNotFound
+func requireTeamAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrTeamNotFound
+	}
+	return nil
+}
+
+// scanTeam scans a teams row into a domain.Team without its members
+func scanTeam(row rowScanner) (*domain.Team, error) {
+	var team domain.Team
+	var id string
+	if err := row.Scan(&id, &team.Name, &team.Description, &team.CreatedAt, &team.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	team.ID = objectID
+
+	return &team, nil
+}
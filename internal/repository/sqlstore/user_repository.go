@@ -0,0 +1,303 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const userColumns = `id, email, password_hash, first_name, last_name, role, active, email_verified, pending, last_login_at, deletion_scheduled_at, created_at, updated_at`
+
+// UserRepository implements domain.UserRepository using database/sql
+type UserRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewUserRepository creates a new UserRepository
+func NewUserRepository(db *sql.DB, dialect Dialect) *UserRepository {
+	return &UserRepository{db: db, dialect: dialect}
+}
+
+// Create creates a new user
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	now := time.Now().UTC()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`INSERT INTO users (`+userColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		user.ID.Hex(), user.Email, user.PasswordHash, user.FirstName, user.LastName,
+		user.Role, user.Active, user.EmailVerified, user.Pending, nullTime(user.LastLoginAt), nullTime(user.DeletionScheduledAt),
+		user.CreatedAt, user.UpdatedAt,
+	)
+	if err != nil {
+		// Check for unique violation (email already exists)
+		if r.dialect.IsUniqueViolation(err) {
+			return domain.ErrEmailAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetByID retrieves a user by their ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT `+userColumns+` FROM users WHERE id = ?`), id)
+	return r.scanOne(row)
+}
+
+// GetByEmail retrieves a user by their email
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT `+userColumns+` FROM users WHERE email = ?`), email)
+	return r.scanOne(row)
+}
+
+// Update updates an existing user
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	user.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`
+		UPDATE users
+		SET email = ?, password_hash = ?, first_name = ?, last_name = ?,
+		    role = ?, active = ?, email_verified = ?, pending = ?, deletion_scheduled_at = ?, updated_at = ?
+		WHERE id = ?`),
+		user.Email, user.PasswordHash, user.FirstName, user.LastName,
+		user.Role, user.Active, user.EmailVerified, user.Pending, nullTime(user.DeletionScheduledAt), user.UpdatedAt, user.ID.Hex(),
+	)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return domain.ErrEmailAlreadyExists
+		}
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a user by their ID
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM users WHERE id = ?`), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// userSortColumns maps API sort fields to the SQL columns they order by
+var userSortColumns = map[string][]string{
+	"name":       {"last_name", "first_name"},
+	"email":      {"email"},
+	"created_at": {"created_at"},
+	"last_login": {"last_login_at"},
+}
+
+// List retrieves users with filtering, sorting, and pagination
+func (r *UserRepository) List(ctx context.Context, params domain.UserListParams) (*domain.PaginatedResult[domain.User], error) {
+	var conditions []string
+	var args []any
+
+	// Apply search filter (every word matches the first name, last name or email)
+	for _, word := range strings.Fields(params.Query) {
+		conditions = append(conditions, `(lower(first_name) LIKE lower(?) ESCAPE '\' OR lower(last_name) LIKE lower(?) ESCAPE '\' OR lower(email) LIKE lower(?) ESCAPE '\')`)
+		pattern := LikePattern(word)
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	if params.Role != "" {
+		conditions = append(conditions, "role = ?")
+		args = append(args, params.Role)
+	}
+	if params.Active != nil {
+		conditions = append(conditions, "active = ?")
+		args = append(args, *params.Active)
+	}
+	if params.CreatedAfter != nil {
+		conditions = append(conditions, "created_at > ?")
+		args = append(args, params.CreatedAfter.UTC())
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Count total rows
+	var total int64
+	if err := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT count(*) FROM users`+where), args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	// Determine sort order. Users who never logged in sort as the earliest logins.
+	sortOrder, nulls := "DESC", "NULLS LAST"
+	if strings.ToLower(params.Order) == "asc" {
+		sortOrder, nulls = "ASC", "NULLS FIRST"
+	}
+	columns, ok := userSortColumns[params.Sort]
+	if !ok {
+		columns = userSortColumns["created_at"]
+	}
+	var orderBy []string
+	for _, column := range append(columns, "id") {
+		orderBy = append(orderBy, fmt.Sprintf("%s %s %s", column, sortOrder, nulls))
+	}
+
+	args = append(args, params.Pagination.Limit, params.Pagination.Offset())
+	query := fmt.Sprintf(`SELECT %s FROM users%s ORDER BY %s LIMIT ? OFFSET ?`,
+		userColumns, where, strings.Join(orderBy, ", "))
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return domain.NewPaginatedResult(users, total, params.Pagination), nil
+}
+
+// RecordLogin sets the time a user last logged in
+func (r *UserRepository) RecordLogin(ctx context.Context, id string, at time.Time) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.db.ExecContext(ctx, r.dialect.Rebind(`UPDATE users SET last_login_at = ? WHERE id = ?`), at.UTC(), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ListDeletionDue retrieves the users whose scheduled deletion is due at the given time
+func (r *UserRepository) ListDeletionDue(ctx context.Context, at time.Time) ([]domain.User, error) {
+	rows, err := r.db.QueryContext(ctx,
+		r.dialect.Rebind(`SELECT `+userColumns+` FROM users WHERE deletion_scheduled_at <= ?`), at.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// ExistsByEmail checks if a user with the given email exists
+func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT EXISTS (SELECT 1 FROM users WHERE email = ?)`), email).Scan(&exists)
+	return exists, err
+}
+
+// CountByRole counts the users assigned a role
+func (r *UserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, r.dialect.Rebind(`SELECT COUNT(*) FROM users WHERE role = ?`), role).Scan(&count)
+	return count, err
+}
+
+// scanOne scans a single user row, mapping a missing row to domain.ErrUserNotFound
+func (r *UserRepository) scanOne(row *sql.Row) (*domain.User, error) {
+	user, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// scanUser scans a users row into a domain.User
+func scanUser(row rowScanner) (*domain.User, error) {
+	var user domain.User
+	var id string
+	var lastLoginAt, deletionScheduledAt sql.NullTime
+	if err := row.Scan(&id, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName,
+		&user.Role, &user.Active, &user.EmailVerified, &user.Pending, &lastLoginAt, &deletionScheduledAt,
+		&user.CreatedAt, &user.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if lastLoginAt.Valid {
+		user.LastLoginAt = &lastLoginAt.Time
+	}
+	if deletionScheduledAt.Valid {
+		user.DeletionScheduledAt = &deletionScheduledAt.Time
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	user.ID = objectID
+
+	return &user, nil
+}
@@ -0,0 +1,79 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const userTokenColumns = `hash, user_id, purpose, email, created_at, expires_at`
+
+// UserTokenRepository implements domain.UserTokenRepository using database/sql
+type UserTokenRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewUserTokenRepository creates a new UserTokenRepository
+func NewUserTokenRepository(db *sql.DB, dialect Dialect) *UserTokenRepository {
+	return &UserTokenRepository{db: db, dialect: dialect}
+}
+
+// Create stores a new token, purging expired tokens first
+func (r *UserTokenRepository) Create(ctx context.Context, token *domain.UserToken) error {
+	token.CreatedAt = time.Now().UTC()
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// There is no TTL index, so expired tokens are removed on write
+		if _, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`DELETE FROM user_tokens WHERE expires_at <= ?`), token.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx,
+			r.dialect.Rebind(`INSERT INTO user_tokens (`+userTokenColumns+`) VALUES (?, ?, ?, ?, ?, ?)`),
+			token.Hash, token.UserID.Hex(), token.Purpose, token.Email, token.CreatedAt, token.ExpiresAt.UTC(),
+		)
+		return err
+	})
+}
+
+// Consume atomically deletes and returns an unexpired token
+func (r *UserTokenRepository) Consume(ctx context.Context, hash, purpose string) (*domain.UserToken, error) {
+	var token domain.UserToken
+	var userID string
+	err := r.db.QueryRowContext(ctx, r.dialect.Rebind(`
+		DELETE FROM user_tokens
+		WHERE hash = ? AND purpose = ? AND expires_at > ?
+		RETURNING `+userTokenColumns),
+		hash, purpose, time.Now().UTC(),
+	).Scan(&token.Hash, &userID, &token.Purpose, &token.Email, &token.CreatedAt, &token.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidUserToken
+		}
+		return nil, err
+	}
+
+	if token.UserID, err = primitive.ObjectIDFromHex(userID); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteForUser deletes the outstanding tokens of a user for a purpose
+func (r *UserTokenRepository) DeleteForUser(ctx context.Context, userID, purpose string) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind(`DELETE FROM user_tokens WHERE user_id = ? AND purpose = ?`), userID, purpose,
+	)
+	return err
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoTeamRepository implements domain.TeamRepository using MongoDB. Members
+// are embedded in their team's document.
+type MongoTeamRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoTeamRepository creates a new MongoTeamRepository
+func NewMongoTeamRepository(db *mongo.Database) *MongoTeamRepository {
+	return &MongoTeamRepository{
+		collection: db.Collection("teams"),
+	}
+}
+
+// Create stores a new team with its initial members
+func (r *MongoTeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	now := time.Now()
+	team.ID = primitive.NewObjectID()
+	team.CreatedAt = now
+	team.UpdatedAt = now
+	if team.Members == nil {
+		// Membership updates need an array to operate on
+		team.Members = []domain.TeamMember{}
+	}
+
+	_, err := r.collection.InsertOne(ctx, team)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrTeamExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetByID retrieves a team and its members by its ID
+func (r *MongoTeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	var team domain.Team
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&team)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrTeamNotFound
+		}
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+// List retrieves teams with pagination, ordered by name
+func (r *MongoTeamRepository) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.Team], error) {
+	filter := bson.M{}
+
+	// Count total documents
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up pagination
+	findOptions := options.Find()
+	findOptions.SetSkip(int64(params.Offset()))
+	findOptions.SetLimit(int64(params.Limit))
+	findOptions.SetSort(bson.D{{Key: "name", Value: 1}})
+
+	// Execute query
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	teams := []domain.Team{}
+	if err := cursor.All(ctx, &teams); err != nil {
+		return nil, err
+	}
+
+	return domain.NewPaginatedResult(teams, total, params), nil
+}
+
+// ListByMember retrieves the teams a user belongs to, ordered by name
+func (r *MongoTeamRepository) ListByMember(ctx context.Context, userID string) ([]domain.Team, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"members.user_id": objectID},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	teams := []domain.Team{}
+	if err := cursor.All(ctx, &teams); err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+// Update updates the name and description of a team
+func (r *MongoTeamRepository) Update(ctx context.Context, team *domain.Team) error {
+	team.UpdatedAt = time.Now()
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": team.ID},
+		bson.M{"$set": bson.M{
+			"name":        team.Name,
+			"description": team.Description,
+			"updated_at":  team.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrTeamExists
+		}
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrTeamNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a team and its memberships by its ID
+func (r *MongoTeamRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrTeamNotFound
+	}
+
+	return nil
+}
+
+// SetMember adds a member to a team or changes the role of an existing member
+func (r *MongoTeamRepository) SetMember(ctx context.Context, teamID string, member domain.TeamMember) error {
+	objectID, err := primitive.ObjectIDFromHex(teamID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+	now := time.Now()
+
+	// Change the role of an existing member
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "members.user_id": member.UserID},
+		bson.M{"$set": bson.M{"members.$.role": member.Role, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	// Add a new member. The filter keeps a concurrent add of the same user
+	// from creating a second membership.
+	member.AddedAt = now
+	result, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "members.user_id": bson.M{"$ne": member.UserID}},
+		bson.M{
+			"$push": bson.M{"members": member},
+			"$set":  bson.M{"updated_at": now},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	// Either the team doesn't exist or the user was added meanwhile
+	if _, err := r.GetByID(ctx, teamID); err != nil {
+		return err
+	}
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "members.user_id": member.UserID},
+		bson.M{"$set": bson.M{"members.$.role": member.Role, "updated_at": now}},
+	)
+	return err
+}
+
+// RemoveMember removes a user from a team
+func (r *MongoTeamRepository) RemoveMember(ctx context.Context, teamID, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(teamID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "members.user_id": userObjectID},
+		bson.M{
+			"$pull": bson.M{"members": bson.M{"user_id": userObjectID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		// Tell a missing team apart from a missing member
+		if _, err := r.GetByID(ctx, teamID); err != nil {
+			return err
+		}
+		return domain.ErrTeamMemberNotFound
+	}
+
+	return nil
+}
+
+// RemoveUser removes a user from every team they belong to
+func (r *MongoTeamRepository) RemoveUser(ctx context.Context, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"members.user_id": objectID},
+		bson.M{
+			"$pull": bson.M{"members": bson.M{"user_id": objectID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	return err
+}
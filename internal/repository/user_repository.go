@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/services-api/internal/domain"
@@ -83,16 +85,24 @@ func (r *MongoUserRepository) Update(ctx context.Context, user *domain.User) err
 	user.UpdatedAt = time.Now()
 
 	filter := bson.M{"_id": user.ID}
-	update := bson.M{
-		"$set": bson.M{
-			"email":         user.Email,
-			"password_hash": user.PasswordHash,
-			"first_name":    user.FirstName,
-			"last_name":     user.LastName,
-			"role":          user.Role,
-			"active":        user.Active,
-			"updated_at":    user.UpdatedAt,
-		},
+	set := bson.M{
+		"email":          user.Email,
+		"password_hash":  user.PasswordHash,
+		"first_name":     user.FirstName,
+		"last_name":      user.LastName,
+		"role":           user.Role,
+		"active":         user.Active,
+		"email_verified": user.EmailVerified,
+		"pending":        user.Pending,
+		"updated_at":     user.UpdatedAt,
+	}
+	update := bson.M{"$set": set}
+
+	// A cancelled deletion removes the field so the user drops out of the deletion index
+	if user.DeletionScheduledAt != nil {
+		set["deletion_scheduled_at"] = *user.DeletionScheduledAt
+	} else {
+		update["$unset"] = bson.M{"deletion_scheduled_at": ""}
 	}
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
@@ -129,22 +139,72 @@ func (r *MongoUserRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// List retrieves users with pagination
-func (r *MongoUserRepository) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.User], error) {
+// userSortFields maps API sort fields to the fields they order by
+var userSortFields = map[string][]string{
+	"name":       {"last_name", "first_name"},
+	"email":      {"email"},
+	"created_at": {"created_at"},
+	"last_login": {"last_login_at"},
+}
+
+// List retrieves users with filtering, sorting, and pagination
+func (r *MongoUserRepository) List(ctx context.Context, params domain.UserListParams) (*domain.PaginatedResult[domain.User], error) {
+	filter := bson.M{}
+
+	// Apply search filter (every word matches the first name, last name or email)
+	var terms []bson.M
+	for _, word := range strings.Fields(params.Query) {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(word), "$options": "i"}
+		terms = append(terms, bson.M{"$or": []bson.M{
+			{"first_name": pattern},
+			{"last_name": pattern},
+			{"email": pattern},
+		}})
+	}
+	if len(terms) > 0 {
+		filter["$and"] = terms
+	}
+
+	if params.Role != "" {
+		filter["role"] = params.Role
+	}
+	if params.Active != nil {
+		filter["active"] = *params.Active
+	}
+	if params.CreatedAfter != nil {
+		filter["created_at"] = bson.M{"$gt": *params.CreatedAfter}
+	}
+
 	// Count total documents
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Set up pagination
-	findOptions := options.Find()
-	findOptions.SetSkip(int64(params.Offset()))
-	findOptions.SetLimit(int64(params.Limit))
-	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+	// Determine sort order
+	sortOrder := -1 // descending
+	if strings.ToLower(params.Order) == "asc" {
+		sortOrder = 1
+	}
+
+	fields, ok := userSortFields[params.Sort]
+	if !ok {
+		fields = userSortFields["created_at"]
+	}
+	sort := bson.D{}
+	for _, field := range fields {
+		sort = append(sort, bson.E{Key: field, Value: sortOrder})
+	}
+	sort = append(sort, bson.E{Key: "_id", Value: sortOrder})
+
+	// Set up find options
+	findOptions := options.Find().
+		SetSort(sort).
+		SetSkip(int64(params.Pagination.Offset())).
+		SetLimit(int64(params.Pagination.Limit))
 
 	// Execute query
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +219,42 @@ func (r *MongoUserRepository) List(ctx context.Context, params domain.Pagination
 		users = []domain.User{}
 	}
 
-	return domain.NewPaginatedResult(users, total, params), nil
+	return domain.NewPaginatedResult(users, total, params.Pagination), nil
+}
+
+// RecordLogin sets the time a user last logged in
+func (r *MongoUserRepository) RecordLogin(ctx context.Context, id string, at time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"last_login_at": at}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ListDeletionDue retrieves the users whose scheduled deletion is due at the given time
+func (r *MongoUserRepository) ListDeletionDue(ctx context.Context, at time.Time) ([]domain.User, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"deletion_scheduled_at": bson.M{"$lte": at}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []domain.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
 }
 
 // ExistsByEmail checks if a user with the given email exists
@@ -170,3 +265,8 @@ func (r *MongoUserRepository) ExistsByEmail(ctx context.Context, email string) (
 	}
 	return count > 0, nil
 }
+
+// CountByRole counts the users assigned a role
+func (r *MongoUserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"role": role})
+}
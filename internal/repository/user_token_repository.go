@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoUserTokenRepository implements domain.UserTokenRepository using MongoDB
+type MongoUserTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoUserTokenRepository creates a new MongoUserTokenRepository
+func NewMongoUserTokenRepository(db *mongo.Database) *MongoUserTokenRepository {
+	return &MongoUserTokenRepository{
+		collection: db.Collection("user_tokens"),
+	}
+}
+
+// Create stores a new token
+func (r *MongoUserTokenRepository) Create(ctx context.Context, token *domain.UserToken) error {
+	token.CreatedAt = time.Now()
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+// Consume atomically deletes and returns an unexpired token
+func (r *MongoUserTokenRepository) Consume(ctx context.Context, hash, purpose string) (*domain.UserToken, error) {
+	var token domain.UserToken
+	err := r.collection.FindOneAndDelete(ctx, bson.M{
+		"_id":        hash,
+		"purpose":    purpose,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrInvalidUserToken
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// DeleteForUser deletes the outstanding tokens of a user for a purpose
+func (r *MongoUserTokenRepository) DeleteForUser(ctx context.Context, userID, purpose string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	_, err = r.collection.DeleteMany(ctx, bson.M{"user_id": objectID, "purpose": purpose})
+	return err
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/mailer"
+)
+
+// AccountOptions configures the emails sent by the AccountService
+type AccountOptions struct {
+	// PasswordResetURL is the page that password reset links point to
+	PasswordResetURL string
+	// PasswordResetTTL is how long a password reset link is valid
+	PasswordResetTTL time.Duration
+	// EmailVerificationURL is the page that email verification links point to
+	EmailVerificationURL string
+	// EmailVerificationTTL is how long an email verification link is valid
+	EmailVerificationTTL time.Duration
+	// RequireVerifiedEmail refuses logins until a user verified their email address
+	RequireVerifiedEmail bool
+}
+
+// AccountService handles password resets and email verification through
+// single-use tokens sent by email
+type AccountService struct {
+	userRepo  domain.UserRepository
+	tokenRepo domain.UserTokenRepository
+	sessions  *SessionService
+	throttle  *LoginThrottleService
+	passwords *PasswordService
+	mailer    mailer.Mailer
+	options   AccountOptions
+	audit     *AuditService
+}
+
+// NewAccountService creates a new AccountService
+func NewAccountService(userRepo domain.UserRepository, tokenRepo domain.UserTokenRepository, sessions *SessionService, throttle *LoginThrottleService, passwords *PasswordService, m mailer.Mailer, options AccountOptions, audit *AuditService) *AccountService {
+	return &AccountService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		sessions:  sessions,
+		throttle:  throttle,
+		passwords: passwords,
+		mailer:    m,
+		options:   options,
+		audit:     audit,
+	}
+}
+
+// VerificationRequired reports whether logins require a verified email address
+func (s *AccountService) VerificationRequired() bool {
+	return s.options.RequireVerifiedEmail
+}
+
+// ForgotPassword emails a password reset link to a user. It succeeds whether
+// or not the email address has an account, so accounts can't be enumerated.
+// Users who sign in through single sign-on have no password to reset.
+func (s *AccountService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, strings.ToLower(email))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.Active || user.PasswordHash == "" {
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user, domain.TokenPurposePasswordReset, s.options.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s.\n\n%s\n\n"+
+			"If you didn't ask to reset your password, you can ignore this email.\n",
+			user.FirstName, describeTTL(s.options.PasswordResetTTL), tokenLink(s.options.PasswordResetURL, token)),
+	})
+}
+
+// ResetPassword sets a new password with a token from a password reset email.
+// Every session of the user is revoked, and failed logins are forgotten.
+func (s *AccountService) ResetPassword(ctx context.Context, req domain.ResetPasswordRequest) error {
+	if req.Token == "" {
+		return domain.ErrInvalidUserToken
+	}
+	// Check what doesn't depend on the user before the token is used up
+	if err := s.passwords.Validate(ctx, nil, req.Password); err != nil {
+		return err
+	}
+
+	user, err := s.consumeToken(ctx, req.Token, domain.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+	if !user.Active {
+		return domain.ErrInvalidUserToken
+	}
+
+	if err := s.passwords.Set(ctx, user, req.Password); err != nil {
+		return err
+	}
+	// Following the link proved the user receives email at the address
+	user.EmailVerified = true
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Nobody is signed in, so the user is the actor
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionPasswordReset,
+		ActorID:    user.ID.Hex(),
+		TargetType: domain.AuditTargetUser,
+		TargetID:   user.ID.Hex(),
+	})
+
+	if err := s.sessions.revokeAll(ctx, user.ID.Hex()); err != nil {
+		return err
+	}
+	return s.throttle.Success(ctx, user.Email)
+}
+
+// SendVerification emails an email verification link to a user, replacing
+// any link sent before
+func (s *AccountService) SendVerification(ctx context.Context, user *domain.User) error {
+	token, err := s.issueToken(ctx, user, domain.TokenPurposeEmailVerification, s.options.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to verify your email address. It expires in %s.\n\n%s\n",
+			user.FirstName, describeTTL(s.options.EmailVerificationTTL), tokenLink(s.options.EmailVerificationURL, token)),
+	})
+}
+
+// ResendVerification emails a new verification link to an unverified user.
+// Like ForgotPassword, it succeeds whether or not the address has an account.
+func (s *AccountService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, strings.ToLower(email))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	// Invited users verify their address by accepting the invitation
+	if !user.Active || user.EmailVerified || user.Pending {
+		return nil
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// VerifyEmail marks a user's email address as verified with a token from a
+// verification email. The token must have been sent to the current address.
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return domain.ErrInvalidUserToken
+	}
+
+	user, err := s.consumeToken(ctx, token, domain.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	before := userAuditSummary(user)
+	user.EmailVerified = true
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionEmailVerify,
+		ActorID:    user.ID.Hex(),
+		TargetType: domain.AuditTargetUser,
+		TargetID:   user.ID.Hex(),
+		Before:     before,
+		After:      userAuditSummary(user),
+	})
+	return nil
+}
+
+// issueToken replaces a user's outstanding tokens for a purpose with a new
+// one and returns its plaintext
+func (s *AccountService) issueToken(ctx context.Context, user *domain.User, purpose string, ttl time.Duration) (string, error) {
+	if err := s.tokenRepo.DeleteForUser(ctx, user.ID.Hex(), purpose); err != nil {
+		return "", err
+	}
+
+	plaintext := newEmailedToken()
+	if err := s.tokenRepo.Create(ctx, &domain.UserToken{
+		Hash:      hashUserToken(plaintext),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	return plaintext, nil
+}
+
+// consumeToken uses up a token and returns its user, who must still have the
+// email address the token was sent to
+func (s *AccountService) consumeToken(ctx context.Context, plaintext, purpose string) (*domain.User, error) {
+	token, err := s.tokenRepo.Consume(ctx, hashUserToken(plaintext), purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID.Hex())
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidUserToken
+		}
+		return nil, err
+	}
+	if user.Email != token.Email {
+		return nil, domain.ErrInvalidUserToken
+	}
+	return user, nil
+}
+
+// newEmailedToken returns the plaintext of a new token for a link sent by email
+func newEmailedToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// hashUserToken hashes an emailed token for storage. Tokens carry 256 bits of
+// entropy, so a fast unsalted hash is sufficient.
+func hashUserToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenLink appends a token to the query of a link
+func tokenLink(base, token string) string {
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return base + separator + "token=" + url.QueryEscape(token)
+}
+
+// describeTTL writes out the lifetime of a link in whole hours or minutes
+func describeTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		return pluralize(int(ttl/time.Hour), "hour")
+	}
+	return pluralize(int(ttl.Round(time.Minute)/time.Minute), "minute")
+}
+
+// pluralize formats a count of a unit
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,138 @@
+package service_test
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAccountOptions = service.AccountOptions{
+	PasswordResetURL:     "https://app.example.com/reset-password",
+	PasswordResetTTL:     time.Hour,
+	EmailVerificationURL: "https://app.example.com/verify-email",
+	EmailVerificationTTL: 48 * time.Hour,
+}
+
+var mailedTokenRegex = regexp.MustCompile(`\?token=(\S+)`)
+
+// mailedToken returns the token from the link in the last email to an address
+func mailedToken(t *testing.T, m *mailer.MemoryMailer, to, subject string) string {
+	t.Helper()
+	msg, ok := m.Last(to)
+	require.True(t, ok, "no email to %s", to)
+	require.Equal(t, subject, msg.Subject)
+
+	match := mailedTokenRegex.FindStringSubmatch(msg.Body)
+	require.NotNil(t, match, "no link in email to %s", to)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+func TestAccountService_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, accounts, m := s.auth, s.accounts, s.mailer
+	registered := registerTestUser(t, svc)
+
+	// Unknown addresses are silently ignored
+	require.NoError(t, accounts.ForgotPassword(ctx, "nobody@example.com"))
+	_, ok := m.Last("nobody@example.com")
+	assert.False(t, ok)
+
+	require.NoError(t, accounts.ForgotPassword(ctx, "User@Example.com"))
+	token := mailedToken(t, m, "user@example.com", "Reset your password")
+
+	assert.ErrorIs(t, accounts.ResetPassword(ctx, domain.ResetPasswordRequest{Token: token, Password: "short"}), domain.ErrPasswordTooShort)
+	assert.ErrorIs(t, accounts.ResetPassword(ctx, domain.ResetPasswordRequest{Token: "unknown", Password: "newpassword123"}), domain.ErrInvalidUserToken)
+	require.NoError(t, accounts.ResetPassword(ctx, domain.ResetPasswordRequest{Token: token, Password: "newpassword123"}))
+
+	// Tokens are single use
+	assert.ErrorIs(t, accounts.ResetPassword(ctx, domain.ResetPasswordRequest{Token: token, Password: "otherpassword123"}), domain.ErrInvalidUserToken)
+
+	// Existing sessions end and only the new password works
+	_, err := svc.RefreshToken(ctx, registered.RefreshToken, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	assert.ErrorIs(t, login(svc, "user@example.com", "securepassword123", testClient), domain.ErrInvalidCredentials)
+	require.NoError(t, login(svc, "user@example.com", "newpassword123", testClient))
+}
+
+func TestAccountService_NewResetLinkReplacesOld(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, accounts, m := s.auth, s.accounts, s.mailer
+	registerTestUser(t, svc)
+
+	require.NoError(t, accounts.ForgotPassword(ctx, "user@example.com"))
+	first := mailedToken(t, m, "user@example.com", "Reset your password")
+	require.NoError(t, accounts.ForgotPassword(ctx, "user@example.com"))
+	second := mailedToken(t, m, "user@example.com", "Reset your password")
+
+	assert.ErrorIs(t, accounts.ResetPassword(ctx, domain.ResetPasswordRequest{Token: first, Password: "newpassword123"}), domain.ErrInvalidUserToken)
+	require.NoError(t, accounts.ResetPassword(ctx, domain.ResetPasswordRequest{Token: second, Password: "newpassword123"}))
+}
+
+func TestAccountService_VerificationGatesLogin(t *testing.T) {
+	ctx := context.Background()
+	options := testAccountOptions
+	options.RequireVerifiedEmail = true
+	s := newTestServices(withAccountOptions(options))
+	svc, accounts, m := s.auth, s.accounts, s.mailer
+
+	// Registration issues no tokens until the address is verified
+	registered := registerTestUser(t, svc)
+	assert.True(t, registered.EmailVerificationRequired)
+	assert.Empty(t, registered.AccessToken)
+	assert.False(t, registered.User.EmailVerified)
+
+	assert.ErrorIs(t, login(svc, "user@example.com", "securepassword123", testClient), domain.ErrEmailNotVerified)
+	// Without the right password, the login fails as usual
+	assert.ErrorIs(t, login(svc, "user@example.com", "wrong-password", testClient), domain.ErrInvalidCredentials)
+
+	// Resending replaces the first link
+	first := mailedToken(t, m, "user@example.com", "Verify your email address")
+	require.NoError(t, accounts.ResendVerification(ctx, "user@example.com"))
+	token := mailedToken(t, m, "user@example.com", "Verify your email address")
+	assert.ErrorIs(t, accounts.VerifyEmail(ctx, first), domain.ErrInvalidUserToken)
+
+	require.NoError(t, accounts.VerifyEmail(ctx, token))
+	assert.ErrorIs(t, accounts.VerifyEmail(ctx, token), domain.ErrInvalidUserToken)
+	require.NoError(t, login(svc, "user@example.com", "securepassword123", testClient))
+
+	// Verified users get no further emails
+	sent := len(m.Messages())
+	require.NoError(t, accounts.ResendVerification(ctx, "user@example.com"))
+	assert.Len(t, m.Messages(), sent)
+}
+
+func TestAccountService_EmailChangeRequiresVerification(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, userSvc, accounts, m := s.auth, s.users, s.accounts, s.mailer
+	registered := registerTestUser(t, svc)
+	oldToken := mailedToken(t, m, "user@example.com", "Verify your email address")
+
+	user, err := userSvc.Update(ctx, registered.User.ID, domain.UpdateUserRequest{
+		Email:     "new@example.com",
+		FirstName: "Test",
+		Role:      domain.RoleUser,
+	})
+	require.NoError(t, err)
+	assert.False(t, user.EmailVerified)
+
+	// A link sent to the old address can't verify the new one
+	assert.ErrorIs(t, accounts.VerifyEmail(ctx, oldToken), domain.ErrInvalidUserToken)
+
+	require.NoError(t, accounts.VerifyEmail(ctx, mailedToken(t, m, "new@example.com", "Verify your email address")))
+	user, err = userSvc.GetByID(ctx, registered.User.ID)
+	require.NoError(t, err)
+	assert.True(t, user.EmailVerified)
+}
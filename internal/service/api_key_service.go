@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/logging"
+)
+
+const (
+	// apiKeyPrefix marks stored API keys, which look like sk_<12 hex chars>_<secret>
+	apiKeyPrefix = "sk_"
+	// apiKeyLookupLength is the length of the stored lookup prefix including apiKeyPrefix
+	apiKeyLookupLength = len(apiKeyPrefix) + 12
+	// apiKeyLastUsedInterval limits how often last-used times are written
+	apiKeyLastUsedInterval = time.Minute
+	// maxAPIKeyExpiryDays is the longest lifetime a key can be created with
+	maxAPIKeyExpiryDays = 3650
+	// maxAPIKeyOverlapHours is the longest a rotated key keeps working
+	maxAPIKeyOverlapHours = 720
+)
+
+// APIKeyService manages stored API keys and authenticates requests made with them
+type APIKeyService struct {
+	apiKeyRepo      domain.APIKeyRepository
+	userRepo        domain.UserRepository
+	roles           *RoleService
+	rotationOverlap time.Duration
+	audit           *AuditService
+}
+
+// NewAPIKeyService creates a new APIKeyService. rotationOverlap is how long a
+// rotated key keeps working when the request does not specify an overlap.
+func NewAPIKeyService(apiKeyRepo domain.APIKeyRepository, userRepo domain.UserRepository, roles *RoleService, rotationOverlap time.Duration, audit *AuditService) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:      apiKeyRepo,
+		userRepo:        userRepo,
+		roles:           roles,
+		rotationOverlap: rotationOverlap,
+		audit:           audit,
+	}
+}
+
+// Create issues a new API key for a user and returns it with its plaintext,
+// which is not stored and can't be retrieved again
+func (s *APIKeyService) Create(ctx context.Context, ownerID string, req domain.CreateAPIKeyRequest) (*domain.APIKey, string, error) {
+	owner, err := s.userRepo.GetByID(ctx, ownerID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := validateAPIKeyName(name); err != nil {
+		return nil, "", err
+	}
+
+	scopes, err := s.validateScopes(ctx, req.Scopes, owner.Role)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &domain.APIKey{
+		Name:    name,
+		OwnerID: owner.ID,
+		Scopes:  scopes,
+	}
+
+	if req.ExpiresInDays != nil {
+		if *req.ExpiresInDays < 1 || *req.ExpiresInDays > maxAPIKeyExpiryDays {
+			return nil, "", domain.ErrInvalidExpiry
+		}
+		expiresAt := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	plaintext, err := s.store(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionAPIKeyCreate,
+		TargetType: domain.AuditTargetAPIKey,
+		TargetID:   key.ID.Hex(),
+		After:      apiKeyAuditSummary(key),
+	})
+	return key, plaintext, nil
+}
+
+// GetByID retrieves an API key by its ID
+func (s *APIKeyService) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	return s.apiKeyRepo.GetByID(ctx, id)
+}
+
+// List retrieves API keys with pagination, restricted to one owner unless ownerID is empty
+func (s *APIKeyService) List(ctx context.Context, ownerID string, params domain.PaginationParams) (*domain.PaginatedResult[domain.APIKey], error) {
+	return s.apiKeyRepo.List(ctx, ownerID, params)
+}
+
+// Update renames an API key or replaces its scopes
+func (s *APIKeyService) Update(ctx context.Context, id string, req domain.UpdateAPIKeyRequest) (*domain.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := apiKeyAuditSummary(key)
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := validateAPIKeyName(name); err != nil {
+			return nil, err
+		}
+		key.Name = name
+	}
+
+	if req.Scopes != nil {
+		owner, err := s.userRepo.GetByID(ctx, key.OwnerID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		if key.Scopes, err = s.validateScopes(ctx, req.Scopes, owner.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.apiKeyRepo.Update(ctx, key); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionAPIKeyUpdate,
+		TargetType: domain.AuditTargetAPIKey,
+		TargetID:   id,
+		Before:     before,
+		After:      apiKeyAuditSummary(key),
+	})
+	return key, nil
+}
+
+// Delete revokes an API key by deleting it
+func (s *APIKeyService) Delete(ctx context.Context, id string) error {
+	key, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.apiKeyRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionAPIKeyDelete,
+		TargetType: domain.AuditTargetAPIKey,
+		TargetID:   id,
+		Before:     apiKeyAuditSummary(key),
+	})
+	return nil
+}
+
+// Rotate issues a replacement for an API key with the same name, owner, scopes
+// and lifetime. The old key keeps working for the overlap period so clients can
+// switch over without downtime.
+func (s *APIKeyService) Rotate(ctx context.Context, id string, req domain.RotateAPIKeyRequest) (*domain.APIKey, string, error) {
+	overlap := s.rotationOverlap
+	if req.OverlapHours != nil {
+		if *req.OverlapHours < 0 || *req.OverlapHours > maxAPIKeyOverlapHours {
+			return nil, "", domain.ErrInvalidOverlap
+		}
+		overlap = time.Duration(*req.OverlapHours) * time.Hour
+	}
+
+	old, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	// The replacement is a new credential, so the caller must hold its scopes
+	held, _ := auth.GetScopes(ctx)
+	for _, scope := range old.Scopes {
+		if !containsScope(held, scope) {
+			return nil, "", domain.ErrScopeNotAllowed
+		}
+	}
+
+	now := time.Now()
+	replacement := &domain.APIKey{
+		Name:    old.Name,
+		OwnerID: old.OwnerID,
+		Scopes:  old.Scopes,
+	}
+	if old.ExpiresAt != nil {
+		expiresAt := now.Add(old.ExpiresAt.Sub(old.CreatedAt))
+		replacement.ExpiresAt = &expiresAt
+	}
+
+	plaintext, err := s.store(ctx, replacement)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Shorten the old key's lifetime to the overlap, never extend it
+	before := apiKeyAuditSummary(old)
+	overlapEnd := now.Add(overlap)
+	if old.ExpiresAt == nil || overlapEnd.Before(*old.ExpiresAt) {
+		old.ExpiresAt = &overlapEnd
+		if err := s.apiKeyRepo.Update(ctx, old); err != nil {
+			return nil, "", err
+		}
+	}
+
+	// The event targets the old key; its replacement is named in the summary
+	after := apiKeyAuditSummary(old)
+	after["replacement_id"] = replacement.ID.Hex()
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionAPIKeyRotate,
+		TargetType: domain.AuditTargetAPIKey,
+		TargetID:   id,
+		Before:     before,
+		After:      after,
+	})
+	return replacement, plaintext, nil
+}
+
+// AuthenticateAPIKey resolves a stored API key presented by a client. It
+// implements auth.APIKeyAuthenticator.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, plaintext string) (*auth.APIKeyPrincipal, error) {
+	prefix, ok := parseAPIKey(plaintext)
+	if !ok {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(plaintext)), []byte(key.SecretHash)) != 1 {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if key.IsExpired() {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	// Keys stop working while their owner is deactivated or waiting to be deleted
+	owner, err := s.userRepo.GetByID(ctx, key.OwnerID.Hex())
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if !owner.Active || owner.DeletionScheduledAt != nil {
+		return nil, domain.ErrAPIKeyOwnerInactive
+	}
+
+	// A key never grants more than its owner currently has, even if the owner
+	// lost a role after the key was created
+	granted, err := s.roles.Permissions(ctx, owner.Role)
+	if err != nil {
+		return nil, err
+	}
+	scopes := intersectScopes(key.Scopes, granted)
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedInterval {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID.Hex(), now); err != nil {
+			logging.FromContext(ctx).Warn("Failed to record use of API key", "api_key_id", key.ID.Hex(), "error", err)
+		}
+	}
+
+	return &auth.APIKeyPrincipal{
+		KeyID:   key.ID.Hex(),
+		OwnerID: key.OwnerID.Hex(),
+		Scopes:  scopes,
+	}, nil
+}
+
+// store generates a secret for key and stores it, retrying on the unlikely
+// event of a prefix collision, and returns the plaintext key
+func (s *APIKeyService) store(ctx context.Context, key *domain.APIKey) (string, error) {
+	for attempt := 0; ; attempt++ {
+		plaintext := generateAPIKey()
+		key.Prefix = plaintext[:apiKeyLookupLength]
+		key.SecretHash = hashAPIKey(plaintext)
+
+		err := s.apiKeyRepo.Create(ctx, key)
+		if err == nil {
+			return plaintext, nil
+		}
+		if !errors.Is(err, domain.ErrAPIKeyPrefixExists) || attempt == 2 {
+			return "", err
+		}
+	}
+}
+
+// generateAPIKey returns a new random key of the form sk_<12 hex chars>_<secret>
+func generateAPIKey() string {
+	lookup := make([]byte, 6)
+	rand.Read(lookup)
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return apiKeyPrefix + hex.EncodeToString(lookup) + "_" + base64.RawURLEncoding.EncodeToString(secret)
+}
+
+// parseAPIKey returns the lookup prefix of a stored key, or false if the key
+// is not in the stored key format
+func parseAPIKey(plaintext string) (string, bool) {
+	if !strings.HasPrefix(plaintext, apiKeyPrefix) || len(plaintext) <= apiKeyLookupLength+1 {
+		return "", false
+	}
+	if plaintext[apiKeyLookupLength] != '_' {
+		return "", false
+	}
+	return plaintext[:apiKeyLookupLength], true
+}
+
+// hashAPIKey hashes a key for storage. Keys carry 256 bits of entropy, so a
+// fast unsalted hash is sufficient.
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// validateAPIKeyName validates the name of an API key
+func validateAPIKeyName(name string) error {
+	if name == "" {
+		return domain.ErrAPIKeyNameRequired
+	}
+	if len(name) > 100 {
+		return domain.ErrAPIKeyNameTooLong
+	}
+	return nil
+}
+
+// validateScopes checks that scopes are known and held by both the owner's
+// role and the caller's token, returning them without duplicates. A key never
+// grants more than the request creating it, so tokens only allowed to set up
+// MFA can't mint keys that skip it.
+func (s *APIKeyService) validateScopes(ctx context.Context, scopes []string, role string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, domain.ErrScopesRequired
+	}
+
+	rolePermissions, err := s.roles.Permissions(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	held, _ := auth.GetScopes(ctx)
+	granted := intersectScopes(held, rolePermissions)
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !auth.IsValidScope(scope) {
+			return nil, domain.ErrInvalidScope
+		}
+		if !containsScope(granted, scope) {
+			return nil, domain.ErrScopeNotAllowed
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+
+	return result, nil
+}
+
+// intersectScopes returns the scopes that are also in granted
+func intersectScopes(scopes, granted []string) []string {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if containsScope(granted, scope) {
+			result = append(result, scope)
+		}
+	}
+	return result
+}
+
+// containsScope checks if scopes contains scope
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ownerContext returns the context of a request made with a token of the
+// default user role
+func ownerContext() context.Context {
+	return userContext(domain.RoleUser, auth.ScopeServicesRead, auth.ScopeServicesWrite)
+}
+
+func newTestAPIKeyService(t *testing.T) (*service.APIKeyService, *mocks.MockAPIKeyRepository, *domain.User) {
+	t.Helper()
+	userRepo := mocks.NewMockUserRepository()
+	owner := &domain.User{Email: "owner@example.com", Role: domain.RoleUser, Active: true}
+	require.NoError(t, userRepo.Create(context.Background(), owner))
+
+	apiKeyRepo := mocks.NewMockAPIKeyRepository()
+	return service.NewAPIKeyService(apiKeyRepo, userRepo, newTestRoleService(userRepo), 24*time.Hour, nil), apiKeyRepo, owner
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	ctx := ownerContext()
+	svc, _, owner := newTestAPIKeyService(t)
+
+	days := 30
+	key, plaintext, err := svc.Create(ctx, owner.ID.Hex(), domain.CreateAPIKeyRequest{
+		Name:          " ci ",
+		Scopes:        []string{auth.ScopeServicesRead, auth.ScopeServicesRead},
+		ExpiresInDays: &days,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ci", key.Name)
+	assert.Equal(t, []string{auth.ScopeServicesRead}, key.Scopes)
+	assert.True(t, strings.HasPrefix(plaintext, key.Prefix+"_"))
+	assert.NotContains(t, key.SecretHash, plaintext)
+	require.NotNil(t, key.ExpiresAt)
+
+	principal, err := svc.AuthenticateAPIKey(ctx, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID.Hex(), principal.KeyID)
+	assert.Equal(t, owner.ID.Hex(), principal.OwnerID)
+
+	stored, err := svc.GetByID(ctx, key.ID.Hex())
+	require.NoError(t, err)
+	assert.NotNil(t, stored.LastUsedAt)
+
+	// A key with the right prefix but the wrong secret is rejected
+	_, err = svc.AuthenticateAPIKey(ctx, key.Prefix+"_wrong-secret")
+	assert.ErrorIs(t, err, domain.ErrInvalidAPIKey)
+
+	// Keys that are not in the stored key format are rejected without a lookup
+	_, err = svc.AuthenticateAPIKey(ctx, "legacy-key")
+	assert.ErrorIs(t, err, domain.ErrInvalidAPIKey)
+
+	require.NoError(t, svc.Delete(ctx, key.ID.Hex()))
+	_, err = svc.AuthenticateAPIKey(ctx, plaintext)
+	assert.ErrorIs(t, err, domain.ErrInvalidAPIKey)
+}
+
+func TestAPIKeyService_CreateValidation(t *testing.T) {
+	ctx := ownerContext()
+	svc, _, owner := newTestAPIKeyService(t)
+	tooLong := 3651
+
+	tests := []struct {
+		name    string
+		req     domain.CreateAPIKeyRequest
+		ctx     context.Context
+		wantErr error
+	}{
+		{name: "missing name", req: domain.CreateAPIKeyRequest{Scopes: []string{auth.ScopeServicesRead}}, wantErr: domain.ErrAPIKeyNameRequired},
+		{name: "missing scopes", req: domain.CreateAPIKeyRequest{Name: "ci"}, wantErr: domain.ErrScopesRequired},
+		{name: "unknown scope", req: domain.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"services:delete"}}, wantErr: domain.ErrInvalidScope},
+		{name: "admin scope for user", req: domain.CreateAPIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeUsersAdmin}}, wantErr: domain.ErrScopeNotAllowed},
+		{name: "scope beyond the caller's token", req: domain.CreateAPIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeServicesWrite}}, ctx: userContext(domain.RoleUser, auth.ScopeServicesRead), wantErr: domain.ErrScopeNotAllowed},
+		{name: "token only allowed to set up MFA", req: domain.CreateAPIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeServicesRead}}, ctx: userContext(domain.RoleUser, auth.ScopeMFAEnrollment), wantErr: domain.ErrScopeNotAllowed},
+		{name: "expiry too long", req: domain.CreateAPIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeServicesRead}, ExpiresInDays: &tooLong}, wantErr: domain.ErrInvalidExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ctx
+			if tt.ctx != nil {
+				ctx = tt.ctx
+			}
+			_, _, err := svc.Create(ctx, owner.ID.Hex(), tt.req)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAPIKeyService_Rotate(t *testing.T) {
+	ctx := ownerContext()
+	svc, _, owner := newTestAPIKeyService(t)
+
+	old, oldPlaintext, err := svc.Create(ctx, owner.ID.Hex(), domain.CreateAPIKeyRequest{
+		Name:   "ci",
+		Scopes: []string{auth.ScopeServicesRead, auth.ScopeServicesWrite},
+	})
+	require.NoError(t, err)
+
+	// With an overlap both keys work until the old one expires
+	replacement, newPlaintext, err := svc.Rotate(ctx, old.ID.Hex(), domain.RotateAPIKeyRequest{})
+	require.NoError(t, err)
+	assert.NotEqual(t, old.ID, replacement.ID)
+	assert.Equal(t, old.Scopes, replacement.Scopes)
+	assert.Nil(t, replacement.ExpiresAt)
+
+	_, err = svc.AuthenticateAPIKey(ctx, oldPlaintext)
+	require.NoError(t, err)
+	_, err = svc.AuthenticateAPIKey(ctx, newPlaintext)
+	require.NoError(t, err)
+
+	stored, err := svc.GetByID(ctx, old.ID.Hex())
+	require.NoError(t, err)
+	require.NotNil(t, stored.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *stored.ExpiresAt, time.Minute)
+
+	// Without an overlap the old key stops working immediately
+	none := 0
+	_, _, err = svc.Rotate(ctx, replacement.ID.Hex(), domain.RotateAPIKeyRequest{OverlapHours: &none})
+	require.NoError(t, err)
+	_, err = svc.AuthenticateAPIKey(ctx, newPlaintext)
+	assert.ErrorIs(t, err, domain.ErrInvalidAPIKey)
+
+	tooLong := 721
+	_, _, err = svc.Rotate(ctx, old.ID.Hex(), domain.RotateAPIKeyRequest{OverlapHours: &tooLong})
+	assert.ErrorIs(t, err, domain.ErrInvalidOverlap)
+}
+
+func TestAPIKeyService_InactiveOwner(t *testing.T) {
+	ctx := ownerContext()
+	svc, _, owner := newTestAPIKeyService(t)
+
+	_, plaintext, err := svc.Create(ctx, owner.ID.Hex(), domain.CreateAPIKeyRequest{
+		Name:   "ci",
+		Scopes: []string{auth.ScopeServicesRead},
+	})
+	require.NoError(t, err)
+
+	owner.Active = false
+	_, err = svc.AuthenticateAPIKey(ctx, plaintext)
+	assert.ErrorIs(t, err, domain.ErrAPIKeyOwnerInactive)
+}
+
+func TestAPIKeyService_CallerTokenLimitsScopes(t *testing.T) {
+	ctx := ownerContext()
+	svc, _, owner := newTestAPIKeyService(t)
+
+	key, _, err := svc.Create(ctx, owner.ID.Hex(), domain.CreateAPIKeyRequest{
+		Name:   "ci",
+		Scopes: []string{auth.ScopeServicesRead, auth.ScopeServicesWrite},
+	})
+	require.NoError(t, err)
+
+	// A read-only token can neither widen a key to its owner's role nor
+	// rotate it into a new credential with scopes the token lacks
+	readOnly := userContext(domain.RoleUser, auth.ScopeServicesRead)
+	_, err = svc.Update(readOnly, key.ID.Hex(), domain.UpdateAPIKeyRequest{Scopes: []string{auth.ScopeServicesWrite}})
+	assert.ErrorIs(t, err, domain.ErrScopeNotAllowed)
+	_, _, err = svc.Rotate(readOnly, key.ID.Hex(), domain.RotateAPIKeyRequest{})
+	assert.ErrorIs(t, err, domain.ErrScopeNotAllowed)
+
+	enrollment := userContext(domain.RoleUser, auth.ScopeMFAEnrollment)
+	_, _, err = svc.Rotate(enrollment, key.ID.Hex(), domain.RotateAPIKeyRequest{})
+	assert.ErrorIs(t, err, domain.ErrScopeNotAllowed)
+
+	updated, err := svc.Update(readOnly, key.ID.Hex(), domain.UpdateAPIKeyRequest{Scopes: []string{auth.ScopeServicesRead}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{auth.ScopeServicesRead}, updated.Scopes)
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// auditExportPage is how many events are read at a time when exporting
+const auditExportPage = 500
+
+// auditConflictBackoff is the longest wait before chaining an event again
+// after another process chained its event onto the same predecessor
+const auditConflictBackoff = 10 * time.Millisecond
+
+// auditConflictAttempts is how often an event is chained before it is dropped
+// because other processes keep taking its predecessor
+const auditConflictAttempts = 20
+
+// auditRequestKey is the context key of the request an audit event is recorded for
+type auditRequestKey struct{}
+
+// auditRequest describes the HTTP request an action was taken in
+type auditRequest struct {
+	id     string
+	client domain.ClientInfo
+}
+
+// WithAuditRequest returns a context whose audit events are attributed to the
+// request with the given ID, made by the given client
+func WithAuditRequest(ctx context.Context, requestID string, client domain.ClientInfo) context.Context {
+	return context.WithValue(ctx, auditRequestKey{}, auditRequest{id: requestID, client: client})
+}
+
+// AuditService records security-relevant actions in the append-only audit
+// log and lets admins query it. A nil *AuditService records nothing.
+type AuditService struct {
+	repo domain.AuditRepository
+
+	// appendMu serializes appends, so that events recorded by this process
+	// never compete for the same predecessor
+	appendMu sync.Mutex
+}
+
+// NewAuditService creates a new AuditService
+func NewAuditService(repo domain.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record appends an event to the audit log. The actor, auth type and request
+// are taken from the context unless the event sets them. Failing to record an
+// event doesn't fail the action it describes, so errors are only logged. An
+// event losing its predecessor to another process is chained again, up to
+// auditConflictAttempts times and only while ctx isn't done; an event that
+// can't be stored by then is logged and dropped.
+func (s *AuditService) Record(ctx context.Context, event domain.AuditEvent) {
+	if s == nil {
+		return
+	}
+
+	if event.ActorID == "" {
+		if principal, ok := auth.GetAPIKeyPrincipal(ctx); ok {
+			event.ActorID = principal.OwnerID
+			event.APIKeyID = principal.KeyID
+		} else if userID, ok := auth.GetUserID(ctx); ok {
+			event.ActorID = userID
+		}
+	}
+	if event.AuthType == "" {
+		if authType, ok := auth.GetAuthType(ctx); ok {
+			event.AuthType = string(authType)
+		}
+	}
+	if event.ImpersonatorID == "" {
+		event.ImpersonatorID, _ = auth.GetActorID(ctx)
+	}
+	if req, ok := ctx.Value(auditRequestKey{}).(auditRequest); ok {
+		event.RequestID = req.id
+		if event.IPAddress == "" {
+			event.IPAddress = req.client.IPAddress
+		}
+		if event.UserAgent == "" {
+			event.UserAgent = req.client.UserAgent
+		}
+	}
+
+	// The action already happened, so store the event even if the client
+	// went away in the meantime, but stop chaining it again once ctx is done
+	parent := ctx
+	ctx = context.WithoutCancel(ctx)
+	log := logging.FromContext(ctx)
+	for attempt := 1; ; attempt++ {
+		err := s.append(ctx, &event)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, domain.ErrChainConflict) {
+			log.Error("Failed to record audit event", "action", event.Action, "error", err)
+			return
+		}
+		if attempt == auditConflictAttempts || parent.Err() != nil {
+			log.Error("Dropped audit event after chain conflicts", "action", event.Action, "attempts", attempt, "context_error", parent.Err())
+			return
+		}
+
+		// Back off by a random delay, so that competing processes don't keep colliding
+		timer := time.NewTimer(rand.N(auditConflictBackoff))
+		select {
+		case <-timer.C:
+		case <-parent.Done():
+			timer.Stop()
+		}
+	}
+}
+
+// append chains an event onto the newest one in the log and stores it. It
+// fails with domain.ErrChainConflict if another event was chained onto the
+// newest one first.
+func (s *AuditService) append(ctx context.Context, event *domain.AuditEvent) error {
+	s.appendMu.Lock()
+	defer s.appendMu.Unlock()
+
+	latest, err := s.repo.List(ctx, domain.AuditQuery{Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	event.ID = primitive.NewObjectID()
+	event.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	event.PrevHash = ""
+	if len(latest) > 0 {
+		event.PrevHash = latest[0].Hash
+		// The chain must list in the same order as the log, even if clocks disagree
+		if !event.CreatedAt.After(latest[0].CreatedAt) {
+			event.CreatedAt = latest[0].CreatedAt.Add(time.Millisecond)
+		}
+	}
+	event.Hash = event.ComputeHash()
+
+	return s.repo.Create(ctx, event)
+}
+
+// List returns a page of events matching a query, newest first. The page has
+// a cursor for the next one unless it is the last.
+func (s *AuditService) List(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	if query.Limit <= 0 {
+		query.Limit = domain.DefaultAuditLimit
+	}
+	if query.Limit > domain.MaxAuditLimit {
+		query.Limit = domain.MaxAuditLimit
+	}
+
+	events, err := s.repo.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.AuditPage{Data: make([]domain.AuditEventResponse, 0, len(events))}
+	for i := range events {
+		page.Data = append(page.Data, events[i].ToResponse())
+	}
+	if len(events) == query.Limit {
+		page.NextCursor = domain.NewAuditCursor(&events[len(events)-1]).String()
+	}
+
+	return page, nil
+}
+
+// Export calls fn with every event matching a query, newest first, reading the
+// log a page at a time. query.Limit is ignored.
+func (s *AuditService) Export(ctx context.Context, query domain.AuditQuery, fn func(event *domain.AuditEvent) error) error {
+	query.Limit = auditExportPage
+	for {
+		events, err := s.repo.List(ctx, query)
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			if err := fn(&events[i]); err != nil {
+				return err
+			}
+		}
+		if len(events) < query.Limit {
+			return nil
+		}
+		cursor := domain.NewAuditCursor(&events[len(events)-1])
+		query.After = &cursor
+	}
+}
+
+// Verify walks the hash chain of the audit log, newest first, and reports the
+// oldest broken link
+func (s *AuditService) Verify(ctx context.Context) (*domain.ChainVerification, error) {
+	var verifier domain.ChainVerifier
+	err := s.Export(ctx, domain.AuditQuery{}, func(event *domain.AuditEvent) error {
+		verifier.Add(event.ChainLink())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Result(), nil
+}
+
+// userAuditSummary summarizes the fields of a user that audit events track.
+// Events outlive the users they describe and identify them by ID, so the
+// summary leaves out personal data such as the email address and name.
+func userAuditSummary(user *domain.User) map[string]string {
+	return map[string]string{
+		"role":           user.Role,
+		"active":         strconv.FormatBool(user.Active),
+		"email_verified": strconv.FormatBool(user.EmailVerified),
+	}
+}
+
+// roleAuditSummary summarizes the fields of a role that audit events track
+func roleAuditSummary(role *domain.Role) map[string]string {
+	return map[string]string{
+		"permissions": strings.Join(role.Permissions, " "),
+	}
+}
+
+// apiKeyAuditSummary summarizes the fields of an API key that audit events track
+func apiKeyAuditSummary(key *domain.APIKey) map[string]string {
+	summary := map[string]string{
+		"name":     key.Name,
+		"owner_id": key.OwnerID.Hex(),
+		"scopes":   strings.Join(key.Scopes, " "),
+	}
+	if key.ExpiresAt != nil {
+		summary["expires_at"] = key.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return summary
+}
+
+// teamAuditSummary summarizes the fields of a team that audit events track
+func teamAuditSummary(team *domain.Team) map[string]string {
+	return map[string]string{
+		"name":        team.Name,
+		"maintainers": strconv.Itoa(team.Maintainers()),
+		"members":     strconv.Itoa(len(team.Members)),
+	}
+}
+
+// teamMemberAuditSummary summarizes the membership of a user in a team
+func teamMemberAuditSummary(member *domain.TeamMember) map[string]string {
+	return map[string]string{
+		"user_id": member.UserID.Hex(),
+		"role":    member.Role,
+	}
+}
+
+// serviceAuditSummary summarizes the fields of a service that audit events track
+func serviceAuditSummary(service *domain.Service) map[string]string {
+	summary := map[string]string{
+		"name":     service.Name,
+		"revision": strconv.Itoa(service.Revision),
+	}
+	if service.TeamID != nil {
+		summary["team_id"] = service.TeamID.Hex()
+	}
+	return summary
+}
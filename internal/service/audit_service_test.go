@@ -0,0 +1,467 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditService_RecordFillsActorAndRequest(t *testing.T) {
+	repo := mocks.NewMockAuditRepository()
+	audit := service.NewAuditService(repo)
+
+	ctx := context.WithValue(context.Background(), auth.UserIDContextKey, "user-1")
+	ctx = context.WithValue(ctx, auth.AuthTypeKey, auth.AuthTypeJWT)
+	ctx = context.WithValue(ctx, auth.ActorIDKey, "admin-1")
+	ctx = service.WithAuditRequest(ctx, "req-1", testClient)
+	audit.Record(ctx, domain.AuditEvent{Action: domain.AuditActionUserDelete, TargetType: domain.AuditTargetUser, TargetID: "user-2"})
+
+	// API keys act for their owner
+	keyCtx := context.WithValue(context.Background(), auth.APIKeyPrincipalKey, &auth.APIKeyPrincipal{KeyID: "key-1", OwnerID: "owner-1"})
+	keyCtx = context.WithValue(keyCtx, auth.AuthTypeKey, auth.AuthTypeAPIKey)
+	audit.Record(keyCtx, domain.AuditEvent{Action: domain.AuditActionServiceDelete})
+
+	events := repo.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "user-1", events[0].ActorID)
+	assert.Equal(t, "admin-1", events[0].ImpersonatorID)
+	assert.Equal(t, "jwt", events[0].AuthType)
+	assert.Equal(t, "req-1", events[0].RequestID)
+	assert.Equal(t, testClient.IPAddress, events[0].IPAddress)
+	assert.Equal(t, testClient.UserAgent, events[0].UserAgent)
+	assert.Equal(t, "owner-1", events[1].ActorID)
+	assert.Equal(t, "key-1", events[1].APIKeyID)
+	assert.Equal(t, "api_key", events[1].AuthType)
+
+	// A nil service records nothing
+	var disabled *service.AuditService
+	disabled.Record(ctx, domain.AuditEvent{Action: domain.AuditActionLogin})
+}
+
+func TestAuditService_ListPages(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockAuditRepository()
+	audit := service.NewAuditService(repo)
+	for i := 0; i < 5; i++ {
+		audit.Record(ctx, domain.AuditEvent{Action: domain.AuditActionLoginFailed})
+	}
+
+	page, err := audit.List(ctx, domain.AuditQuery{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Data, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	var ids []string
+	for _, event := range page.Data {
+		ids = append(ids, event.ID)
+	}
+	for page.NextCursor != "" {
+		cursor, err := domain.ParseAuditCursor(page.NextCursor)
+		require.NoError(t, err)
+		page, err = audit.List(ctx, domain.AuditQuery{Limit: 2, After: cursor})
+		require.NoError(t, err)
+		for _, event := range page.Data {
+			ids = append(ids, event.ID)
+		}
+	}
+	assert.Len(t, ids, 5)
+
+	var exported int
+	require.NoError(t, audit.Export(ctx, domain.AuditQuery{}, func(event *domain.AuditEvent) error {
+		exported++
+		return nil
+	}))
+	assert.Equal(t, 5, exported)
+}
+
+func TestAuditService_RecordsSecurityActions(t *testing.T) {
+	s := newTestServices(withAudit())
+	repo, authSvc, users, userRepo := s.auditRepo, s.auth, s.users, s.userRepo
+
+	login := registerTestUser(t, authSvc)
+	_, _, err := authSvc.Login(context.Background(), domain.LoginRequest{Email: "user@example.com", Password: "wrongpassword123"}, testClient)
+	require.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	admin := addTestUser(userRepo, "admin@example.com", domain.RoleAdmin)
+	ctx := callerContext(admin)
+	_, err = users.Update(ctx, login.User.ID, domain.UpdateUserRequest{Email: "user@example.com", FirstName: "Test", Role: domain.RoleEditor})
+	require.NoError(t, err)
+	require.NoError(t, users.Delete(ctx, login.User.ID))
+
+	var actions []string
+	for _, event := range repo.Events() {
+		actions = append(actions, event.Action)
+	}
+	assert.Equal(t, []string{
+		domain.AuditActionUserCreate,
+		domain.AuditActionLogin,
+		domain.AuditActionLoginFailed,
+		domain.AuditActionUserRoleChange,
+		domain.AuditActionUserDelete,
+	}, actions)
+
+	events := repo.Events()
+	assert.Equal(t, login.User.ID, events[1].ActorID)
+	assert.Equal(t, testClient.IPAddress, events[2].IPAddress)
+	assert.Empty(t, events[2].ActorID)
+	assert.Equal(t, login.User.ID, events[2].TargetID)
+
+	roleChange := events[3]
+	assert.Equal(t, admin.ID.Hex(), roleChange.ActorID)
+	assert.Equal(t, domain.RoleUser, roleChange.Before["role"])
+	assert.Equal(t, domain.RoleEditor, roleChange.After["role"])
+	assert.Equal(t, login.User.ID, events[4].TargetID)
+	assert.Equal(t, domain.RoleEditor, events[4].Before["role"])
+}
+
+func TestAuditService_RecordsChanges(t *testing.T) {
+	s := newTestServices(withAudit())
+	admin := addTestUser(s.userRepo, "admin@example.com", domain.RoleAdmin)
+	bob := addTestUser(s.userRepo, "bob@example.com", domain.RoleEditor)
+	ctx := callerContext(admin)
+
+	// lastEvent returns the newest event, checking what it records
+	lastEvent := func(t *testing.T, action, targetType, targetID string) domain.AuditEvent {
+		t.Helper()
+		events := s.auditRepo.Events()
+		require.NotEmpty(t, events)
+		event := events[len(events)-1]
+		assert.Equal(t, action, event.Action)
+		assert.Equal(t, targetType, event.TargetType)
+		assert.Equal(t, targetID, event.TargetID)
+		return event
+	}
+
+	t.Run("roles", func(t *testing.T) {
+		_, err := s.roles.Create(ctx, domain.CreateRoleRequest{Name: "release-manager", Permissions: []string{auth.ScopeServicesRead}})
+		require.NoError(t, err)
+		event := lastEvent(t, domain.AuditActionRoleCreate, domain.AuditTargetRole, "release-manager")
+		assert.Equal(t, admin.ID.Hex(), event.ActorID)
+		assert.Equal(t, auth.ScopeServicesRead, event.After["permissions"])
+
+		_, err = s.roles.Update(ctx, "release-manager", domain.UpdateRoleRequest{Permissions: []string{auth.ScopeServicesRead, auth.ScopeServicesWrite}})
+		require.NoError(t, err)
+		event = lastEvent(t, domain.AuditActionRoleUpdate, domain.AuditTargetRole, "release-manager")
+		assert.Equal(t, auth.ScopeServicesRead, event.Before["permissions"])
+		assert.Equal(t, auth.ScopeServicesRead+" "+auth.ScopeServicesWrite, event.After["permissions"])
+
+		require.NoError(t, s.roles.Delete(ctx, "release-manager"))
+		lastEvent(t, domain.AuditActionRoleDelete, domain.AuditTargetRole, "release-manager")
+	})
+
+	t.Run("API keys", func(t *testing.T) {
+		key, _, err := s.apiKeys.Create(ctx, admin.ID.Hex(), domain.CreateAPIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeServicesRead}})
+		require.NoError(t, err)
+		event := lastEvent(t, domain.AuditActionAPIKeyCreate, domain.AuditTargetAPIKey, key.ID.Hex())
+		assert.Equal(t, "ci", event.After["name"])
+		assert.Equal(t, admin.ID.Hex(), event.After["owner_id"])
+		assert.Equal(t, auth.ScopeServicesRead, event.After["scopes"])
+
+		name := "deploys"
+		_, err = s.apiKeys.Update(ctx, key.ID.Hex(), domain.UpdateAPIKeyRequest{Name: &name})
+		require.NoError(t, err)
+		event = lastEvent(t, domain.AuditActionAPIKeyUpdate, domain.AuditTargetAPIKey, key.ID.Hex())
+		assert.Equal(t, "ci", event.Before["name"])
+		assert.Equal(t, "deploys", event.After["name"])
+
+		replacement, _, err := s.apiKeys.Rotate(ctx, key.ID.Hex(), domain.RotateAPIKeyRequest{})
+		require.NoError(t, err)
+		event = lastEvent(t, domain.AuditActionAPIKeyRotate, domain.AuditTargetAPIKey, key.ID.Hex())
+		assert.Empty(t, event.Before["expires_at"])
+		assert.NotEmpty(t, event.After["expires_at"])
+		assert.Equal(t, replacement.ID.Hex(), event.After["replacement_id"])
+
+		require.NoError(t, s.apiKeys.Delete(ctx, replacement.ID.Hex()))
+		event = lastEvent(t, domain.AuditActionAPIKeyDelete, domain.AuditTargetAPIKey, replacement.ID.Hex())
+		assert.Equal(t, "deploys", event.Before["name"])
+	})
+
+	t.Run("services", func(t *testing.T) {
+		created, err := s.services.Create(ctx, domain.CreateServiceRequest{Name: "payments", Description: "Handles payments"})
+		require.NoError(t, err)
+		id := created.ID.Hex()
+		event := lastEvent(t, domain.AuditActionServiceCreate, domain.AuditTargetService, id)
+		assert.Equal(t, "1", event.After["revision"])
+
+		_, err = s.services.Update(ctx, id, domain.UpdateServiceRequest{Name: "payments", Description: "Handles all payments"})
+		require.NoError(t, err)
+		event = lastEvent(t, domain.AuditActionServiceUpdate, domain.AuditTargetService, id)
+		assert.Equal(t, "1", event.Before["revision"])
+		assert.Equal(t, "2", event.After["revision"])
+
+		name := "billing"
+		_, err = s.services.Patch(ctx, id, domain.PatchServiceRequest{Name: &name})
+		require.NoError(t, err)
+		event = lastEvent(t, domain.AuditActionServiceUpdate, domain.AuditTargetService, id)
+		assert.Equal(t, "payments", event.Before["name"])
+		assert.Equal(t, "billing", event.After["name"])
+	})
+
+	t.Run("teams", func(t *testing.T) {
+		team, err := s.teams.Create(ctx, admin.ID.Hex(), domain.CreateTeamRequest{Name: "payments"})
+		require.NoError(t, err)
+		id := team.ID.Hex()
+		event := lastEvent(t, domain.AuditActionTeamCreate, domain.AuditTargetTeam, id)
+		assert.Equal(t, "payments", event.After["name"])
+
+		_, err = s.teams.Update(ctx, id, domain.UpdateTeamRequest{Name: "billing"})
+		require.NoError(t, err)
+		event = lastEvent(t, domain.AuditActionTeamUpdate, domain.AuditTargetTeam, id)
+		assert.Equal(t, "payments", event.Before["name"])
+		assert.Equal(t, "billing", event.After["name"])
+
+		_, err = s.teams.SetMember(ctx, id, bob.ID.Hex(), domain.SetTeamMemberRequest{})
+		require.NoError(t, err)
+		event = lastEvent(t, domain.AuditActionTeamMemberSet, domain.AuditTargetTeam, id)
+		assert.Nil(t, event.Before)
+		assert.Equal(t, map[string]string{"user_id": bob.ID.Hex(), "role": domain.TeamRoleMember}, event.After)
+
+		_, err = s.teams.SetMember(ctx, id, bob.ID.Hex(), domain.SetTeamMemberRequest{Role: domain.TeamRoleMaintainer})
+		require.NoError(t, err)
+		event = lastEvent(t, domain.AuditActionTeamMemberSet, domain.AuditTargetTeam, id)
+		assert.Equal(t, domain.TeamRoleMember, event.Before["role"])
+		assert.Equal(t, domain.TeamRoleMaintainer, event.After["role"])
+
+		require.NoError(t, s.teams.RemoveMember(ctx, id, bob.ID.Hex()))
+		event = lastEvent(t, domain.AuditActionTeamMemberRemove, domain.AuditTargetTeam, id)
+		assert.Equal(t, bob.ID.Hex(), event.Before["user_id"])
+
+		require.NoError(t, s.teams.Delete(ctx, id))
+		event = lastEvent(t, domain.AuditActionTeamDelete, domain.AuditTargetTeam, id)
+		assert.Equal(t, "billing", event.Before["name"])
+	})
+
+	t.Run("invitations", func(t *testing.T) {
+		invitation, err := s.invitations.Invite(ctx, admin.ID.Hex(), domain.InviteUserRequest{Email: "new@example.com", FirstName: "New"})
+		require.NoError(t, err)
+		id := invitation.ID.Hex()
+		event := lastEvent(t, domain.AuditActionInvitationCreate, domain.AuditTargetUser, id)
+		assert.Equal(t, admin.ID.Hex(), event.ActorID)
+		assert.Equal(t, domain.RoleUser, event.After["role"])
+
+		_, err = s.invitations.Resend(ctx, id)
+		require.NoError(t, err)
+		lastEvent(t, domain.AuditActionInvitationResend, domain.AuditTargetUser, id)
+
+		// Accepting is done by the invited user, who isn't signed in
+		token := mailedToken(t, s.mailer, "new@example.com", "You have been invited")
+		require.NoError(t, s.invitations.Accept(context.Background(), domain.AcceptInvitationRequest{Token: token, Password: "newpassword123"}))
+		event = lastEvent(t, domain.AuditActionInvitationAccept, domain.AuditTargetUser, id)
+		assert.Equal(t, id, event.ActorID)
+		assert.Equal(t, "false", event.Before["email_verified"])
+		assert.Equal(t, "true", event.After["email_verified"])
+
+		revoked, err := s.invitations.Invite(ctx, admin.ID.Hex(), domain.InviteUserRequest{Email: "other@example.com", FirstName: "Other"})
+		require.NoError(t, err)
+		require.NoError(t, s.invitations.Revoke(ctx, revoked.ID.Hex()))
+		lastEvent(t, domain.AuditActionInvitationRevoke, domain.AuditTargetUser, revoked.ID.Hex())
+	})
+
+	t.Run("sessions and passwords", func(t *testing.T) {
+		user := registerTestUser(t, s.auth).User
+		userCtx := context.WithValue(userContext(user.Role), auth.UserIDContextKey, user.ID)
+
+		sessions, err := s.sessions.List(userCtx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.NoError(t, s.sessions.Revoke(userCtx, user.ID, sessions[0].ID))
+		event := lastEvent(t, domain.AuditActionSessionRevoke, domain.AuditTargetUser, user.ID)
+		assert.Equal(t, user.ID, event.ActorID)
+		assert.Equal(t, sessions[0].ID, event.Before["session_id"])
+
+		require.NoError(t, s.sessions.RevokeAll(ctx, user.ID))
+		event = lastEvent(t, domain.AuditActionSessionRevokeAll, domain.AuditTargetUser, user.ID)
+		assert.Equal(t, admin.ID.Hex(), event.ActorID)
+
+		require.NoError(t, s.users.ChangePassword(userCtx, user.ID, domain.ChangePasswordRequest{
+			CurrentPassword: "securepassword123",
+			NewPassword:     "changedpassword123",
+		}))
+		event = lastEvent(t, domain.AuditActionPasswordChange, domain.AuditTargetUser, user.ID)
+		assert.Equal(t, user.ID, event.ActorID)
+
+		// Resetting is done by the user, who isn't signed in
+		require.NoError(t, s.accounts.ForgotPassword(context.Background(), "user@example.com"))
+		token := mailedToken(t, s.mailer, "user@example.com", "Reset your password")
+		require.NoError(t, s.accounts.ResetPassword(context.Background(), domain.ResetPasswordRequest{Token: token, Password: "resetpassword123"}))
+		event = lastEvent(t, domain.AuditActionPasswordReset, domain.AuditTargetUser, user.ID)
+		assert.Equal(t, user.ID, event.ActorID)
+	})
+
+	result, err := s.audit.Verify(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+}
+
+func TestAuditService_RecordsNoPersonalData(t *testing.T) {
+	s := newTestServices(withAudit())
+	authSvc, users := s.auth, s.users
+
+	login := registerTestUser(t, authSvc)
+	ctx := context.Background()
+	client := domain.ClientInfo{UserAgent: "test-agent", IPAddress: "198.51.100.7"}
+
+	// Lock out the account, and fail to log in to an email without one
+	for i := 0; i < testLoginPolicy.MaxFailures; i++ {
+		_, _, err := authSvc.Login(ctx, domain.LoginRequest{Email: "user@example.com", Password: "wrongpassword123"}, client)
+		require.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+	_, _, err := authSvc.Login(ctx, domain.LoginRequest{Email: "nobody@example.com", Password: "wrongpassword123"}, client)
+	require.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	admin := addTestUser(s.userRepo, "admin@example.com", domain.RoleAdmin)
+	adminCtx := callerContext(admin)
+	_, err = users.Update(adminCtx, login.User.ID, domain.UpdateUserRequest{Email: "renamed@example.com", FirstName: "Renamed", LastName: "User", Role: domain.RoleUser})
+	require.NoError(t, err)
+	require.NoError(t, users.Delete(adminCtx, login.User.ID))
+
+	personalData := []string{"user@example.com", "nobody@example.com", "renamed@example.com", "Test", "Renamed"}
+	var lockout *domain.AuditEvent
+	for _, event := range s.auditRepo.Events() {
+		for _, summary := range []map[string]string{event.Before, event.After} {
+			for key, value := range summary {
+				assert.NotContains(t, personalData, value, "%s: %s", event.Action, key)
+			}
+		}
+		if event.Action == domain.AuditActionLockout {
+			lockout = &event
+		}
+	}
+
+	// The lockout names the account by user ID
+	require.NotNil(t, lockout)
+	assert.Equal(t, domain.AuditTargetUser, lockout.TargetType)
+	assert.Equal(t, login.User.ID, lockout.TargetID)
+	assert.Equal(t, "account", lockout.After["scope"])
+}
+
+func TestAuditService_HashChain(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockAuditRepository()
+	audit := service.NewAuditService(repo)
+	for i := 0; i < 4; i++ {
+		audit.Record(ctx, domain.AuditEvent{Action: domain.AuditActionLockout, After: map[string]string{"scope": "account"}})
+	}
+
+	// Each event is chained onto the previous one, later in time
+	events := repo.Events()
+	require.Len(t, events, 4)
+	assert.Empty(t, events[0].PrevHash)
+	for i := 1; i < len(events); i++ {
+		assert.Equal(t, events[i-1].Hash, events[i].PrevHash)
+		assert.True(t, events[i].CreatedAt.After(events[i-1].CreatedAt))
+	}
+
+	result, err := audit.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, 4, result.Checked)
+
+	// Editing an event in the database breaks the chain
+	repo.UpdateEvent(events[1].ID, func(event *domain.AuditEvent) {
+		event.After["scope"] = "ip"
+	})
+	result, err = audit.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, events[1].ID.Hex(), result.BrokenLink.ID)
+	assert.Equal(t, domain.ChainBreakHashMismatch, result.BrokenLink.Reason)
+}
+
+func TestAuditService_RecordRetriesChainConflicts(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockAuditRepository()
+	audit := service.NewAuditService(repo)
+	audit.Record(ctx, domain.AuditEvent{Action: domain.AuditActionLogin})
+
+	// Another writer chains onto the newest event first
+	conflicts := 0
+	repo.CreateFunc = func(ctx context.Context, event *domain.AuditEvent) error {
+		if conflicts < 2 {
+			conflicts++
+			return domain.ErrChainConflict
+		}
+		repo.CreateFunc = nil
+		return repo.Create(ctx, event)
+	}
+	audit.Record(ctx, domain.AuditEvent{Action: domain.AuditActionUserDelete})
+
+	assert.Equal(t, 2, conflicts)
+	events := repo.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, events[0].Hash, events[1].PrevHash)
+}
+
+func TestAuditService_RecordGivesUpOnChainConflicts(t *testing.T) {
+	repo := mocks.NewMockAuditRepository()
+	audit := service.NewAuditService(repo)
+
+	// Another writer always chains onto the newest event first
+	attempts := 0
+	repo.CreateFunc = func(ctx context.Context, event *domain.AuditEvent) error {
+		attempts++
+		return domain.ErrChainConflict
+	}
+
+	// The event is dropped after a bounded number of attempts
+	audit.Record(context.Background(), domain.AuditEvent{Action: domain.AuditActionLogin})
+	assert.Equal(t, 20, attempts)
+	assert.Empty(t, repo.Events())
+
+	// A done context stops the retries after the first attempt
+	attempts = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	audit.Record(ctx, domain.AuditEvent{Action: domain.AuditActionLogin})
+	assert.Equal(t, 1, attempts)
+}
+
+// slowAuditRepository takes a while to store events, like a database, so that
+// concurrent appends read the same newest event
+type slowAuditRepository struct {
+	*mocks.MockAuditRepository
+}
+
+func (r slowAuditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	time.Sleep(time.Millisecond)
+	return r.MockAuditRepository.Create(ctx, event)
+}
+
+func TestAuditService_ConcurrentRecordsKeepEveryEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockAuditRepository()
+	slow := slowAuditRepository{repo}
+
+	// Two services on one log stand in for two API processes
+	processes := []*service.AuditService{service.NewAuditService(slow), service.NewAuditService(slow)}
+	const perProcess = 40
+
+	var wg sync.WaitGroup
+	for _, audit := range processes {
+		for i := 0; i < perProcess; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				audit.Record(ctx, domain.AuditEvent{Action: domain.AuditActionLogin})
+			}()
+		}
+	}
+	wg.Wait()
+
+	events := repo.Events()
+	assert.Len(t, events, len(processes)*perProcess)
+
+	result, err := processes[0].Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, len(processes)*perProcess, result.Checked)
+}
@@ -2,45 +2,65 @@ package service
 
 import (
 	"context"
+	"errors"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/auth"
 	"github.com/services-api/pkg/jwt"
+	"github.com/services-api/pkg/logging"
+	"github.com/services-api/pkg/metrics"
 )
 
+// mfaChallengeExpiry is how long a user has to enter their second factor after their password
+const mfaChallengeExpiry = 5 * time.Minute
+
+// dummyPasswordHash is checked against when logging in to an unknown email, so
+// that it takes as long as a wrong password
+const dummyPasswordHash = "$2a$10$yCc4/i/qXOZ/JPIYmt/jvuGcSszHKb2mVOFhRmFy9qYPbvP37.2Ny"
+
 var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 
 // AuthService handles authentication operations
 type AuthService struct {
-	userRepo   domain.UserRepository
-	jwtManager *jwt.Manager
+	userRepo         domain.UserRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+	sessions         *SessionService
+	roles            *RoleService
+	mfa              *MFAService
+	throttle         *LoginThrottleService
+	accounts         *AccountService
+	passwords        *PasswordService
+	jwtManager       *jwt.Manager
+	audit            *AuditService
 }
 
 // NewAuthService creates a new AuthService
-func NewAuthService(userRepo domain.UserRepository, jwtManager *jwt.Manager) *AuthService {
+func NewAuthService(userRepo domain.UserRepository, refreshTokenRepo domain.RefreshTokenRepository, sessions *SessionService, roles *RoleService, mfa *MFAService, throttle *LoginThrottleService, accounts *AccountService, passwords *PasswordService, jwtManager *jwt.Manager, audit *AuditService) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		jwtManager: jwtManager,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessions:         sessions,
+		roles:            roles,
+		mfa:              mfa,
+		throttle:         throttle,
+		accounts:         accounts,
+		passwords:        passwords,
+		jwtManager:       jwtManager,
+		audit:            audit,
 	}
 }
 
-// Register creates a new user account
-func (s *AuthService) Register(ctx context.Context, req domain.RegisterRequest) (*domain.AuthResponse, error) {
+// Register creates a new user account and emails a link to verify its
+// address. When logins require a verified address, no tokens are issued.
+func (s *AuthService) Register(ctx context.Context, req domain.RegisterRequest, client domain.ClientInfo) (*domain.AuthResponse, error) {
 	// Validate request
 	if err := s.validateRegisterRequest(req); err != nil {
 		return nil, err
 	}
 
-	// Check if email already exists
-	exists, err := s.userRepo.ExistsByEmail(ctx, strings.ToLower(req.Email))
-	if err != nil {
-		return nil, err
-	}
-	if exists {
-		return nil, domain.ErrEmailAlreadyExists
-	}
-
 	// Create user
 	user := &domain.User{
 		Email:     strings.ToLower(req.Email),
@@ -51,97 +71,399 @@ func (s *AuthService) Register(ctx context.Context, req domain.RegisterRequest)
 	}
 
 	// Set password
-	if err := user.SetPassword(req.Password); err != nil {
+	if err := s.passwords.Set(ctx, user, req.Password); err != nil {
 		return nil, err
 	}
 
+	// Check if email already exists
+	exists, err := s.userRepo.ExistsByEmail(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, domain.ErrEmailAlreadyExists
+	}
+
 	// Save user
 	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, err
 	}
 
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionUserCreate,
+		ActorID:    user.ID.Hex(),
+		TargetType: domain.AuditTargetUser,
+		TargetID:   user.ID.Hex(),
+		After:      userAuditSummary(user),
+	})
+
+	if err := s.accounts.SendVerification(ctx, user); err != nil {
+		return nil, err
+	}
+	if s.accounts.VerificationRequired() {
+		return &domain.AuthResponse{
+			User:                      user.ToResponse(),
+			EmailVerificationRequired: true,
+		}, nil
+	}
+
 	// Generate tokens
-	return s.generateAuthResponse(user)
+	return s.generateAuthResponse(ctx, user, client)
 }
 
-// Login authenticates a user and returns tokens
-func (s *AuthService) Login(ctx context.Context, req domain.LoginRequest) (*domain.AuthResponse, error) {
+// Login authenticates a user with their password. Users with multi-factor
+// authentication get an MFA challenge instead of tokens, to be completed with
+// VerifyMFA. Failed logins are throttled per account and client IP, and every
+// failure returns domain.ErrInvalidCredentials so accounts can't be enumerated.
+func (s *AuthService) Login(ctx context.Context, req domain.LoginRequest, client domain.ClientInfo) (*domain.AuthResponse, *domain.MFAChallenge, error) {
 	// Validate request
 	if req.Email == "" {
-		return nil, domain.ErrEmailRequired
+		return nil, nil, domain.ErrEmailRequired
 	}
 	if req.Password == "" {
-		return nil, domain.ErrPasswordRequired
+		return nil, nil, domain.ErrPasswordRequired
+	}
+
+	email := strings.ToLower(req.Email)
+	if err := s.throttle.Check(ctx, email, client.IPAddress); err != nil {
+		return nil, nil, err
 	}
 
 	// Find user by email
-	user, err := s.userRepo.GetByEmail(ctx, strings.ToLower(req.Email))
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		if err == domain.ErrUserNotFound {
+			(&domain.User{PasswordHash: dummyPasswordHash}).CheckPassword(req.Password)
+			return nil, nil, s.loginFailed(ctx, email, "", client)
+		}
+		return nil, nil, err
+	}
+
+	// Verify password, and that the user is active
+	if !user.CheckPassword(req.Password) || !user.Active {
+		return nil, nil, s.loginFailed(ctx, email, user.ID.Hex(), client)
+	}
+	if s.accounts.VerificationRequired() && !user.EmailVerified {
+		return nil, nil, domain.ErrEmailNotVerified
+	}
+
+	resp, challenge, err := s.completeLogin(ctx, user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Failures are only forgotten once the second factor was entered as well
+	if challenge == nil {
+		if err := s.throttle.Success(ctx, email); err != nil {
+			return nil, nil, err
+		}
+	}
+	return resp, challenge, nil
+}
+
+// VerifyMFA completes a login that returned an MFA challenge with a code from
+// the user's authenticator app or a recovery code
+func (s *AuthService) VerifyMFA(ctx context.Context, req domain.MFAVerifyRequest, client domain.ClientInfo) (*domain.AuthResponse, error) {
+	claims, err := s.jwtManager.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) || errors.Is(err, domain.ErrInvalidID) {
 			return nil, domain.ErrInvalidCredentials
 		}
 		return nil, err
 	}
-
-	// Check if user is active
 	if !user.Active {
 		return nil, domain.ErrInvalidCredentials
 	}
 
-	// Verify password
-	if !user.CheckPassword(req.Password) {
-		return nil, domain.ErrInvalidCredentials
+	// Wrong codes count towards the lockout of the account like wrong passwords
+	if err := s.throttle.Check(ctx, user.Email, client.IPAddress); err != nil {
+		return nil, err
+	}
+	if err := s.mfa.Verify(ctx, claims.UserID, req.Code); err != nil {
+		if errors.Is(err, domain.ErrInvalidMFACode) {
+			s.recordLoginFailure(ctx, claims.UserID, client)
+			if err := s.throttle.Failure(ctx, user.Email, claims.UserID, client.IPAddress); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	if err := s.throttle.Success(ctx, user.Email); err != nil {
+		return nil, err
 	}
 
-	// Generate tokens
-	return s.generateAuthResponse(user)
+	return s.generateAuthResponse(ctx, user, client)
 }
 
-// RefreshToken generates new tokens using a refresh token
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*domain.AuthResponse, error) {
-	// Validate refresh token
-	claims, err := s.jwtManager.ValidateRefreshToken(refreshToken)
+// RefreshToken rotates a refresh token, returning new tokens in the same session.
+// Presenting a token that was already rotated revokes the whole session.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string, client domain.ClientInfo) (resp *domain.AuthResponse, err error) {
+	defer func() {
+		metrics.RefreshTokens.WithLabelValues(refreshResult(err)).Inc()
+	}()
+
+	stored, err := s.loadRefreshToken(ctx, refreshToken)
 	if err != nil {
+		return nil, err
+	}
+
+	if stored.IsRevoked() {
 		return nil, domain.ErrInvalidCredentials
 	}
+	if stored.IsRotated() {
+		return nil, s.revokeReusedFamily(ctx, stored)
+	}
 
 	// Get user to ensure they still exist and are active
-	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	user, err := s.userRepo.GetByID(ctx, stored.UserID.Hex())
 	if err != nil {
 		return nil, domain.ErrInvalidCredentials
 	}
 
 	if !user.Active {
+		if err := s.sessions.revokeAll(ctx, user.ID.Hex()); err != nil {
+			return nil, err
+		}
 		return nil, domain.ErrInvalidCredentials
 	}
 
-	// Generate new tokens
-	return s.generateAuthResponse(user)
+	// The token family is the session, which may have been revoked on its own
+	if err := s.sessions.ValidateSession(ctx, user.ID.Hex(), stored.FamilyID); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) || errors.Is(err, domain.ErrSessionRevoked) {
+			return nil, domain.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// Claim the token before issuing its successor so concurrent refreshes can't both succeed
+	nextID := jwt.NewTokenID()
+	if err := s.refreshTokenRepo.Rotate(ctx, stored.ID, nextID); err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			return nil, s.revokeReusedFamily(ctx, stored)
+		}
+		return nil, err
+	}
+
+	if err := s.sessions.Touch(ctx, stored.FamilyID, client, s.refreshTokenExpiresAt()); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, stored.FamilyID, nextID)
 }
 
-// generateAuthResponse creates tokens and builds the auth response
-func (s *AuthService) generateAuthResponse(user *domain.User) (*domain.AuthResponse, error) {
+// Logout revokes the session of a refresh token along with its access tokens
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := s.loadRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	return s.sessions.revokeQuietly(ctx, stored.UserID.Hex(), stored.FamilyID)
+}
+
+// LogoutAll revokes every session of a user
+func (s *AuthService) LogoutAll(ctx context.Context, userID string) error {
+	return s.sessions.RevokeAll(ctx, userID)
+}
+
+// loadRefreshToken validates a refresh token and returns its server-side record
+func (s *AuthService) loadRefreshToken(ctx context.Context, refreshToken string) (*domain.RefreshToken, error) {
+	claims, err := s.jwtManager.ValidateRefreshToken(refreshToken)
+	if err != nil || claims.ID == "" {
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	stored, err := s.refreshTokenRepo.GetByID(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// The record must belong to the user the token was issued to
+	if stored.UserID.Hex() != claims.UserID {
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	return stored, nil
+}
+
+// revokeReusedFamily revokes a session after one of its rotated refresh tokens was replayed
+func (s *AuthService) revokeReusedFamily(ctx context.Context, stored *domain.RefreshToken) error {
+	logging.FromContext(ctx).Warn("Refresh token reuse detected, revoking session", "target_user_id", stored.UserID.Hex(), "session_id", stored.FamilyID)
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionRefreshTokenReuse,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   stored.UserID.Hex(),
+		After:      map[string]string{"session_id": stored.FamilyID},
+	})
+	if err := s.sessions.revokeQuietly(ctx, stored.UserID.Hex(), stored.FamilyID); err != nil {
+		return err
+	}
+	return domain.ErrRefreshTokenReused
+}
+
+// loginFailed records a failed login and returns the error to respond with
+func (s *AuthService) loginFailed(ctx context.Context, email, userID string, client domain.ClientInfo) error {
+	s.recordLoginFailure(ctx, userID, client)
+	if err := s.throttle.Failure(ctx, email, userID, client.IPAddress); err != nil {
+		return err
+	}
+	return domain.ErrInvalidCredentials
+}
+
+// recordLoginFailure adds a failed login to the audit log. userID is empty
+// when no account has the email, which isn't recorded: it may be personal
+// data of someone without an account.
+func (s *AuthService) recordLoginFailure(ctx context.Context, userID string, client domain.ClientInfo) {
+	event := domain.AuditEvent{
+		Action:    domain.AuditActionLoginFailed,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	}
+	if userID != "" {
+		event.TargetType = domain.AuditTargetUser
+		event.TargetID = userID
+	}
+	s.audit.Record(ctx, event)
+	metrics.Logins.WithLabelValues(metrics.ResultFailure).Inc()
+}
+
+// refreshResult classifies the outcome of using a refresh token for metrics
+func refreshResult(err error) string {
+	switch {
+	case err == nil:
+		return metrics.ResultSuccess
+	case errors.Is(err, domain.ErrRefreshTokenReused):
+		return metrics.ResultReused
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		return metrics.ResultRejected
+	default:
+		return metrics.ResultError
+	}
+}
+
+// completeLogin finishes a login whose first factor was checked, returning an
+// MFA challenge instead of tokens when the user has MFA enabled
+func (s *AuthService) completeLogin(ctx context.Context, user *domain.User, client domain.ClientInfo) (*domain.AuthResponse, *domain.MFAChallenge, error) {
+	enabled, err := s.mfa.Enabled(ctx, user.ID.Hex())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if enabled {
+		token, err := s.jwtManager.GenerateMFAToken(user.ID.Hex(), user.Email, user.Role, mfaChallengeExpiry)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, &domain.MFAChallenge{
+			MFARequired: true,
+			MFAToken:    token,
+			ExpiresIn:   int64(mfaChallengeExpiry.Seconds()),
+		}, nil
+	}
+
+	resp, err := s.generateAuthResponse(ctx, user, client)
+	return resp, nil, err
+}
+
+// generateAuthResponse starts a new session for the client, records the
+// login and builds the auth response
+func (s *AuthService) generateAuthResponse(ctx context.Context, user *domain.User, client domain.ClientInfo) (*domain.AuthResponse, error) {
+	session, err := s.sessions.Start(ctx, user.ID, client, s.refreshTokenExpiresAt())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.userRepo.RecordLogin(ctx, user.ID.Hex(), now); err != nil {
+		return nil, err
+	}
+	user.LastLoginAt = &now
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionLogin,
+		ActorID:    user.ID.Hex(),
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   user.ID.Hex(),
+		After:      map[string]string{"session_id": session.ID},
+	})
+	metrics.Logins.WithLabelValues(metrics.ResultSuccess).Inc()
+	return s.issueTokens(ctx, user, session.ID, jwt.NewTokenID())
+}
+
+// refreshTokenExpiresAt returns the expiry of a refresh token issued now
+func (s *AuthService) refreshTokenExpiresAt() time.Time {
+	return time.Now().Add(s.jwtManager.GetRefreshTokenExpiry())
+}
+
+// issueTokens stores a refresh token with the given ID in the session's token
+// family and returns it with a new access token bound to the session
+func (s *AuthService) issueTokens(ctx context.Context, user *domain.User, sessionID, refreshTokenID string) (*domain.AuthResponse, error) {
 	userID := user.ID.Hex()
 
-	accessToken, err := s.jwtManager.GenerateAccessToken(userID, user.Email, user.Role)
+	scopes, err := s.roles.Permissions(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	// Until a user whose role requires MFA sets it up, their tokens only allow setting it up
+	enrollmentRequired, err := s.mfaEnrollmentRequired(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if enrollmentRequired {
+		scopes = []string{auth.ScopeMFAEnrollment}
+	}
+	accessToken, err := s.jwtManager.GenerateAccessTokenForSession(userID, user.Email, user.Role, sessionID, scopes)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(userID, user.Email, user.Role)
+	refreshToken, err := s.jwtManager.GenerateRefreshTokenWithID(userID, user.Email, user.Role, refreshTokenID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.refreshTokenRepo.Create(ctx, &domain.RefreshToken{
+		ID:        refreshTokenID,
+		FamilyID:  sessionID,
+		UserID:    user.ID,
+		CreatedAt: time.Now(),
+		ExpiresAt: s.refreshTokenExpiresAt(),
+	}); err != nil {
+		return nil, err
+	}
+
 	return &domain.AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    s.jwtManager.GetAccessTokenExpiry(),
+		Scope:        strings.Join(scopes, " "),
 		User:         user.ToResponse(),
+
+		MFAEnrollmentRequired: enrollmentRequired,
 	}, nil
 }
 
+// mfaEnrollmentRequired reports whether the role of a user requires MFA that they haven't set up
+func (s *AuthService) mfaEnrollmentRequired(ctx context.Context, user *domain.User) (bool, error) {
+	if !s.mfa.Required(user.Role) {
+		return false, nil
+	}
+	enabled, err := s.mfa.Enabled(ctx, user.ID.Hex())
+	return !enabled, err
+}
+
 // validateRegisterRequest validates the registration request
 func (s *AuthService) validateRegisterRequest(req domain.RegisterRequest) error {
 	// Validate email
@@ -155,17 +477,6 @@ func (s *AuthService) validateRegisterRequest(req domain.RegisterRequest) error
 		return domain.ErrEmailInvalid
 	}
 
-	// Validate password
-	if req.Password == "" {
-		return domain.ErrPasswordRequired
-	}
-	if len(req.Password) < 8 {
-		return domain.ErrPasswordTooShort
-	}
-	if len(req.Password) > 72 {
-		return domain.ErrPasswordTooLong
-	}
-
 	// Validate first name
 	if strings.TrimSpace(req.FirstName) == "" {
 		return domain.ErrFirstNameRequired
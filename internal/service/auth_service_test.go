@@ -0,0 +1,217 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/jwt"
+	"github.com/services-api/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_RefreshToken_Rotation(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestServices().auth
+	login := registerTestUser(t, svc)
+
+	// First refresh rotates the token
+	refreshed, err := svc.RefreshToken(ctx, login.RefreshToken, testClient)
+	require.NoError(t, err)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+
+	// The rotated token can be used again
+	again, err := svc.RefreshToken(ctx, refreshed.RefreshToken, testClient)
+	require.NoError(t, err)
+	assert.NotEmpty(t, again.AccessToken)
+}
+
+func TestAuthService_RefreshToken_ReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestServices().auth
+	login := registerTestUser(t, svc)
+
+	refreshed, err := svc.RefreshToken(ctx, login.RefreshToken, testClient)
+	require.NoError(t, err)
+
+	// Replaying the original token is detected as reuse
+	_, err = svc.RefreshToken(ctx, login.RefreshToken, testClient)
+	assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+
+	// The legitimate successor is revoked along with the rest of the family
+	_, err = svc.RefreshToken(ctx, refreshed.RefreshToken, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+}
+
+func TestAuthService_RefreshToken_Untracked(t *testing.T) {
+	svc := newTestServices().auth
+	jwtManager := jwt.NewManager("test-secret", 15*time.Minute, 24*time.Hour, "test")
+
+	// A validly signed token that was never issued by the service is rejected
+	token, err := jwtManager.GenerateRefreshToken("507f1f77bcf86cd799439011", "user@example.com", domain.RoleUser)
+	require.NoError(t, err)
+
+	_, err = svc.RefreshToken(context.Background(), token, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+}
+
+func TestAuthService_Metrics(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestServices().auth
+	logins := func(result string) float64 { return testutil.ToFloat64(metrics.Logins.WithLabelValues(result)) }
+	refreshes := func(result string) float64 { return testutil.ToFloat64(metrics.RefreshTokens.WithLabelValues(result)) }
+	successes, failures := logins(metrics.ResultSuccess), logins(metrics.ResultFailure)
+	refreshed, reused, rejected := refreshes(metrics.ResultSuccess), refreshes(metrics.ResultReused), refreshes(metrics.ResultRejected)
+
+	// Registering signs the user in
+	login := registerTestUser(t, svc)
+	_, _, err := svc.Login(ctx, domain.LoginRequest{Email: "user@example.com", Password: "wrong-password"}, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	assert.Equal(t, successes+1, logins(metrics.ResultSuccess))
+	assert.Equal(t, failures+1, logins(metrics.ResultFailure))
+
+	next, err := svc.RefreshToken(ctx, login.RefreshToken, testClient)
+	require.NoError(t, err)
+	_, err = svc.RefreshToken(ctx, login.RefreshToken, testClient)
+	assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+	_, err = svc.RefreshToken(ctx, next.RefreshToken, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	assert.Equal(t, refreshed+1, refreshes(metrics.ResultSuccess))
+	assert.Equal(t, reused+1, refreshes(metrics.ResultReused))
+	assert.Equal(t, rejected+1, refreshes(metrics.ResultRejected))
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestServices().auth
+	login := registerTestUser(t, svc)
+
+	refreshed, err := svc.RefreshToken(ctx, login.RefreshToken, testClient)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Logout(ctx, refreshed.RefreshToken))
+
+	_, err = svc.RefreshToken(ctx, refreshed.RefreshToken, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+}
+
+func TestAuthService_LogoutAll(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestServices().auth
+	first := registerTestUser(t, svc)
+
+	second, challenge, err := svc.Login(ctx, domain.LoginRequest{Email: "user@example.com", Password: "securepassword123"}, testClient)
+	require.NoError(t, err)
+	require.Nil(t, challenge)
+
+	require.NoError(t, svc.LogoutAll(ctx, first.User.ID))
+
+	for _, token := range []string{first.RefreshToken, second.RefreshToken} {
+		_, err := svc.RefreshToken(ctx, token, testClient)
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, sessions := s.auth, s.sessions
+	login := registerTestUser(t, svc)
+	userID := login.User.ID
+
+	list, err := sessions.List(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "test-agent", list[0].UserAgent)
+	assert.Equal(t, "192.0.2.1", list[0].IPAddress)
+	sessionID := list[0].ID
+	require.NoError(t, sessions.ValidateSession(ctx, userID, sessionID))
+
+	// Refreshing keeps the session and records the new client
+	refreshed, err := svc.RefreshToken(ctx, login.RefreshToken, domain.ClientInfo{UserAgent: "curl/8.0", IPAddress: "198.51.100.2"})
+	require.NoError(t, err)
+
+	list, err = sessions.List(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, sessionID, list[0].ID)
+	assert.Equal(t, "curl/8.0", list[0].UserAgent)
+
+	// Sessions of other users can't be revoked or validated
+	otherUserID := "507f1f77bcf86cd799439011"
+	assert.ErrorIs(t, sessions.Revoke(ctx, otherUserID, sessionID), domain.ErrSessionNotFound)
+	assert.ErrorIs(t, sessions.ValidateSession(ctx, otherUserID, sessionID), domain.ErrSessionNotFound)
+
+	// Revoking the session invalidates its access and refresh tokens
+	require.NoError(t, sessions.Revoke(ctx, userID, sessionID))
+	assert.ErrorIs(t, sessions.ValidateSession(ctx, userID, sessionID), domain.ErrSessionRevoked)
+
+	_, err = svc.RefreshToken(ctx, refreshed.RefreshToken, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	list, err = sessions.List(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestUserService_DeactivationRevokesSessions(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, sessions, userSvc := s.auth, s.sessions, s.users
+	login := registerTestUser(t, svc)
+
+	list, err := sessions.List(ctx, login.User.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	active := false
+	_, err = userSvc.Update(ctx, login.User.ID, domain.UpdateUserRequest{
+		Email:     login.User.Email,
+		FirstName: login.User.FirstName,
+		Role:      domain.RoleUser,
+		Active:    &active,
+	})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, sessions.ValidateSession(ctx, login.User.ID, list[0].ID), domain.ErrSessionRevoked)
+	_, err = svc.RefreshToken(ctx, login.RefreshToken, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+}
+
+func TestAuthService_RecordsLastLogin(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, userRepo := s.auth, s.userRepo
+	registered := registerTestUser(t, svc)
+	require.NotNil(t, registered.User.LastLoginAt)
+
+	time.Sleep(10 * time.Millisecond)
+	login, _, err := svc.Login(ctx, domain.LoginRequest{Email: "user@example.com", Password: "securepassword123"}, testClient)
+	require.NoError(t, err)
+	require.NotNil(t, login.User.LastLoginAt)
+	assert.True(t, login.User.LastLoginAt.After(*registered.User.LastLoginAt))
+
+	user, err := userRepo.GetByID(ctx, login.User.ID)
+	require.NoError(t, err)
+	assert.Equal(t, login.User.LastLoginAt, user.LastLoginAt)
+}
+
+func TestUserService_List(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, userSvc, userRepo := s.auth, s.users, s.userRepo
+	registerTestUser(t, svc)
+	addTestUser(userRepo, "admin@example.com", domain.RoleAdmin)
+
+	result, err := userSvc.List(ctx, domain.UserListParams{Role: domain.RoleAdmin})
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "admin@example.com", result.Data[0].Email)
+	assert.Equal(t, 20, result.Pagination.Limit)
+
+	_, err = userSvc.List(ctx, domain.UserListParams{Sort: "password"})
+	assert.ErrorIs(t, err, domain.ErrInvalidSortField)
+}
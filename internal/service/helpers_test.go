@@ -0,0 +1,139 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/jwt"
+	"github.com/services-api/pkg/mailer"
+	"github.com/stretchr/testify/require"
+)
+
+var testClient = domain.ClientInfo{UserAgent: "test-agent", IPAddress: "192.0.2.1"}
+
+// testServices is the graph of services the tests run against, built on mock
+// repositories by newTestServices. Tests use the parts they need.
+type testServices struct {
+	userRepo    *mocks.MockUserRepository
+	teamRepo    *mocks.MockTeamRepository
+	serviceRepo *mocks.MockServiceRepository
+	versionRepo *mocks.MockServiceVersionRepository
+	apiKeyRepo  *mocks.MockAPIKeyRepository
+	auditRepo   *mocks.MockAuditRepository
+	mailer      *mailer.MemoryMailer
+	jwtManager  *jwt.Manager
+
+	audit        *service.AuditService
+	sessions     *service.SessionService
+	roles        *service.RoleService
+	mfa          *service.MFAService
+	throttle     *service.LoginThrottleService
+	passwords    *service.PasswordService
+	accounts     *service.AccountService
+	auth         *service.AuthService
+	users        *service.UserService
+	teams        *service.TeamService
+	services     *service.ServiceService
+	apiKeys      *service.APIKeyService
+	invitations  *service.InvitationService
+	personalData *service.PersonalDataService
+}
+
+// testConfig holds the settings newTestServices builds the services with
+type testConfig struct {
+	accountOptions   service.AccountOptions
+	mfaRequiredRoles []string
+	gracePeriod      time.Duration
+	invitationMailer mailer.Mailer
+	audited          bool
+}
+
+// testOption overrides a default of newTestServices
+type testOption func(*testConfig)
+
+// withAccountOptions sets the options of the account service
+func withAccountOptions(options service.AccountOptions) testOption {
+	return func(c *testConfig) { c.accountOptions = options }
+}
+
+// withMFARequiredRoles requires roles to set up multi-factor authentication
+func withMFARequiredRoles(roles ...string) testOption {
+	return func(c *testConfig) { c.mfaRequiredRoles = roles }
+}
+
+// withGracePeriod sets the grace period of account deletions
+func withGracePeriod(gracePeriod time.Duration) testOption {
+	return func(c *testConfig) { c.gracePeriod = gracePeriod }
+}
+
+// withInvitationMailer sends invites through m instead of the memory mailer
+func withInvitationMailer(m mailer.Mailer) testOption {
+	return func(c *testConfig) { c.invitationMailer = m }
+}
+
+// withAudit records audit events in auditRepo
+func withAudit() testOption {
+	return func(c *testConfig) { c.audited = true }
+}
+
+// newTestServices builds every service on fresh mock repositories. Emails go
+// to the memory mailer, and nothing is audited unless withAudit is given.
+func newTestServices(opts ...testOption) *testServices {
+	cfg := testConfig{accountOptions: testAccountOptions, gracePeriod: time.Hour}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	s := &testServices{
+		userRepo:    mocks.NewMockUserRepository(),
+		teamRepo:    mocks.NewMockTeamRepository(),
+		serviceRepo: mocks.NewMockServiceRepository(),
+		versionRepo: mocks.NewMockServiceVersionRepository(),
+		apiKeyRepo:  mocks.NewMockAPIKeyRepository(),
+		auditRepo:   mocks.NewMockAuditRepository(),
+		mailer:      mailer.NewMemory(),
+		jwtManager:  jwt.NewManager("test-secret", 15*time.Minute, 24*time.Hour, "test"),
+	}
+	invitationMailer := mailer.Mailer(s.mailer)
+	if cfg.invitationMailer != nil {
+		invitationMailer = cfg.invitationMailer
+	}
+	if cfg.audited {
+		s.audit = service.NewAuditService(s.auditRepo)
+	}
+
+	tokenRepo := mocks.NewMockRefreshTokenRepository()
+	s.sessions = service.NewSessionService(mocks.NewMockSessionRepository(), tokenRepo, s.audit)
+	s.roles = service.NewRoleService(mocks.NewMockRoleRepository(), s.userRepo, s.audit)
+	if err := s.roles.EnsureBuiltInRoles(context.Background()); err != nil {
+		panic(err)
+	}
+	s.mfa = service.NewMFAService(mocks.NewMockMFARepository(), s.userRepo, "Test", cfg.mfaRequiredRoles, s.audit)
+	s.throttle = service.NewLoginThrottleService(mocks.NewMockLoginAttemptRepository(), testLoginPolicy, s.audit)
+	s.passwords = newTestPasswordService()
+	s.accounts = service.NewAccountService(s.userRepo, mocks.NewMockUserTokenRepository(), s.sessions, s.throttle, s.passwords, s.mailer, cfg.accountOptions, s.audit)
+	s.auth = service.NewAuthService(s.userRepo, tokenRepo, s.sessions, s.roles, s.mfa, s.throttle, s.accounts, s.passwords, s.jwtManager, s.audit)
+	s.users = service.NewUserService(s.userRepo, s.teamRepo, s.versionRepo, s.sessions, s.roles, s.throttle, s.accounts, s.passwords, s.audit)
+	s.teams = service.NewTeamService(s.teamRepo, s.userRepo, s.serviceRepo, s.roles, s.audit)
+	s.services = service.NewServiceService(s.serviceRepo, s.versionRepo, s.teams, s.audit)
+	s.apiKeys = service.NewAPIKeyService(s.apiKeyRepo, s.userRepo, s.roles, 24*time.Hour, s.audit)
+	s.invitations = service.NewInvitationService(mocks.NewMockInvitationRepository(), s.userRepo, s.users, s.passwords, invitationMailer, testInvitationOptions, s.audit)
+	s.personalData = service.NewPersonalDataService(s.userRepo, s.users, s.teamRepo, s.sessions, s.apiKeyRepo, s.serviceRepo, s.versionRepo, cfg.gracePeriod, s.audit)
+	return s
+}
+
+// registerTestUser registers user@example.com, which signs them in
+func registerTestUser(t *testing.T, svc *service.AuthService) *domain.AuthResponse {
+	t.Helper()
+	resp, err := svc.Register(context.Background(), domain.RegisterRequest{
+		Email:     "user@example.com",
+		Password:  "securepassword123",
+		FirstName: "Test",
+	}, testClient)
+	require.NoError(t, err)
+	return resp
+}
@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/services-api/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestImpersonationService_Impersonate(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository()
+	jwtManager := jwt.NewManager("test-secret", 15*time.Minute, 24*time.Hour, "test")
+	svc := service.NewImpersonationService(userRepo, newTestRoleService(userRepo), jwtManager, 5*time.Minute, nil)
+	admin := addTestUser(userRepo, "admin@example.com", domain.RoleAdmin)
+	user := addTestUser(userRepo, "user@example.com", domain.RoleUser)
+
+	ctx := context.WithValue(callerContext(admin), auth.UserEmailKey, admin.Email)
+	ctx = context.WithValue(ctx, auth.SessionIDKey, "admin-session")
+	resp, err := svc.Impersonate(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, resp.User.Email)
+	assert.Equal(t, admin.ID.Hex(), resp.ActorID)
+	assert.Equal(t, int64(300), resp.ExpiresIn)
+	assert.ElementsMatch(t, auth.ScopesForRole(domain.RoleUser), strings.Fields(resp.Scope))
+
+	claims, err := jwtManager.ValidateAccessToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID.Hex(), claims.UserID)
+	assert.Equal(t, "admin-session", claims.SessionID)
+	require.NotNil(t, claims.Actor)
+	assert.Equal(t, admin.ID.Hex(), claims.Actor.Subject)
+	assert.Equal(t, admin.Email, claims.Actor.Email)
+}
+
+func TestImpersonationService_NeverGrantsMoreThanTheActor(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository()
+	jwtManager := jwt.NewManager("test-secret", 15*time.Minute, 24*time.Hour, "test")
+	svc := service.NewImpersonationService(userRepo, newTestRoleService(userRepo), jwtManager, 5*time.Minute, nil)
+	admin := addTestUser(userRepo, "admin@example.com", domain.RoleAdmin)
+	other := addTestUser(userRepo, "other@example.com", domain.RoleAdmin)
+
+	// A token scoped down to users:admin can't be turned into full admin access
+	ctx := context.WithValue(userContext(domain.RoleAdmin, auth.ScopeUsersAdmin), auth.UserIDContextKey, admin.ID.Hex())
+	resp, err := svc.Impersonate(ctx, other.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, auth.ScopeUsersAdmin, resp.Scope)
+}
+
+func TestImpersonationService_Errors(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository()
+	jwtManager := jwt.NewManager("test-secret", 15*time.Minute, 24*time.Hour, "test")
+	svc := service.NewImpersonationService(userRepo, newTestRoleService(userRepo), jwtManager, 5*time.Minute, nil)
+	admin := addTestUser(userRepo, "admin@example.com", domain.RoleAdmin)
+	inactive := addTestUser(userRepo, "inactive@example.com", domain.RoleUser)
+	inactive.Active = false
+	pending := addTestUser(userRepo, "pending@example.com", domain.RoleUser)
+	pending.Pending = true
+	deleting := addTestUser(userRepo, "deleting@example.com", domain.RoleUser)
+	deleteAt := time.Now().Add(time.Hour)
+	deleting.DeletionScheduledAt = &deleteAt
+	ctx := callerContext(admin)
+
+	tests := []struct {
+		name     string
+		targetID string
+		wantErr  error
+	}{
+		{name: "self", targetID: admin.ID.Hex(), wantErr: domain.ErrImpersonateSelf},
+		{name: "unknown user", targetID: primitive.NewObjectID().Hex(), wantErr: domain.ErrUserNotFound},
+		{name: "inactive user", targetID: inactive.ID.Hex(), wantErr: domain.ErrImpersonationTargetInactive},
+		{name: "pending user", targetID: pending.ID.Hex(), wantErr: domain.ErrImpersonationTargetInactive},
+		{name: "user being deleted", targetID: deleting.ID.Hex(), wantErr: domain.ErrImpersonationTargetInactive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Impersonate(ctx, tt.targetID)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/logging"
+	"github.com/services-api/pkg/mailer"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InvitationOptions configures the invite emails sent by the InvitationService
+type InvitationOptions struct {
+	// URL is the page that invite links point to
+	URL string
+	// TTL is how long an invite link is valid
+	TTL time.Duration
+}
+
+// InvitationService invites users by email. An invited user is created
+// pending, without a password, and sets their own password through a
+// single-use link.
+type InvitationService struct {
+	invitationRepo domain.InvitationRepository
+	userRepo       domain.UserRepository
+	users          *UserService
+	passwords      *PasswordService
+	mailer         mailer.Mailer
+	options        InvitationOptions
+	audit          *AuditService
+}
+
+// NewInvitationService creates a new InvitationService
+func NewInvitationService(invitationRepo domain.InvitationRepository, userRepo domain.UserRepository, users *UserService, passwords *PasswordService, m mailer.Mailer, options InvitationOptions, audit *AuditService) *InvitationService {
+	return &InvitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		users:          users,
+		passwords:      passwords,
+		mailer:         m,
+		options:        options,
+		audit:          audit,
+	}
+}
+
+// Invite creates a pending user and emails them an invite link. If the email
+// can't be sent, neither the user nor the invitation is kept.
+func (s *InvitationService) Invite(ctx context.Context, inviterID string, req domain.InviteUserRequest) (*domain.Invitation, error) {
+	if req.Role == "" {
+		req.Role = domain.RoleUser
+	}
+
+	inviter, err := primitive.ObjectIDFromHex(inviterID)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	// Invited users are validated like users created with a password
+	if err := s.users.validateCreateRequest(ctx, domain.CreateUserRequest{
+		Email:     req.Email,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Role:      req.Role,
+	}); err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		Email:     strings.ToLower(req.Email),
+		FirstName: strings.TrimSpace(req.FirstName),
+		LastName:  strings.TrimSpace(req.LastName),
+		Role:      req.Role,
+		Active:    true,
+		Pending:   true,
+	}
+
+	// Check if email already exists
+	exists, err := s.userRepo.ExistsByEmail(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, domain.ErrEmailAlreadyExists
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	token := newEmailedToken()
+	invitation := &domain.Invitation{
+		ID:        user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		InvitedBy: inviter,
+		TokenHash: hashUserToken(token),
+		ExpiresAt: time.Now().Add(s.options.TTL),
+	}
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		// Don't leave a pending user that can never be activated
+		if deleteErr := s.userRepo.Delete(ctx, user.ID.Hex()); deleteErr != nil {
+			return nil, errors.Join(err, deleteErr)
+		}
+		return nil, err
+	}
+
+	if err := s.send(ctx, user, invitation, token); err != nil {
+		// Nobody got the link, so roll back and let the admin invite again
+		return nil, errors.Join(err,
+			s.invitationRepo.Delete(ctx, invitation.ID.Hex()),
+			s.userRepo.Delete(ctx, user.ID.Hex()))
+	}
+
+	// The invitation shares its ID with the pending user it creates
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionInvitationCreate,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   user.ID.Hex(),
+		After:      userAuditSummary(user),
+	})
+	return invitation, nil
+}
+
+// List retrieves pending invitations with pagination
+func (s *InvitationService) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.Invitation], error) {
+	return s.invitationRepo.List(ctx, params)
+}
+
+// Resend emails a new invite link with a new expiry to the current address
+// of a pending user. The previous link stops working.
+func (s *InvitationService) Resend(ctx context.Context, id string) (*domain.Invitation, error) {
+	invitation, err := s.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.pendingUser(ctx, invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	token := newEmailedToken()
+	invitation.Email = user.Email
+	invitation.TokenHash = hashUserToken(token)
+	invitation.ExpiresAt = time.Now().Add(s.options.TTL)
+	if err := s.invitationRepo.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	if err := s.send(ctx, user, invitation, token); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionInvitationResend,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   user.ID.Hex(),
+	})
+	return invitation, nil
+}
+
+// Revoke withdraws an invitation and deletes the pending user
+func (s *InvitationService) Revoke(ctx context.Context, id string) error {
+	invitation, err := s.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.invitationRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionInvitationRevoke,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   invitation.ID.Hex(),
+	})
+
+	// Users who signed in some other way meanwhile are kept
+	user, err := s.userRepo.GetByID(ctx, invitation.ID.Hex())
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.Pending {
+		return nil
+	}
+	return s.userRepo.Delete(ctx, user.ID.Hex())
+}
+
+// Accept sets the password of an invited user with the token from their
+// invite link and activates them. Following the link proved the user receives
+// email at the address, so it counts as verified.
+func (s *InvitationService) Accept(ctx context.Context, req domain.AcceptInvitationRequest) error {
+	if req.Token == "" {
+		return domain.ErrInvalidInvitation
+	}
+	hash := hashUserToken(req.Token)
+
+	invitation, err := s.invitationRepo.GetByTokenHash(ctx, hash)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, invitation.ID.Hex())
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidInvitation
+		}
+		return err
+	}
+	// The link must have been sent to the user's current address
+	if !user.Pending || !user.Active || user.Email != invitation.Email {
+		return domain.ErrInvalidInvitation
+	}
+
+	// Check the password before the invitation is used up
+	if err := s.passwords.Set(ctx, user, req.Password); err != nil {
+		return err
+	}
+	if err := s.invitationRepo.Consume(ctx, hash); err != nil {
+		return err
+	}
+
+	before := userAuditSummary(user)
+	user.Pending = false
+	user.EmailVerified = true
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Nobody is signed in, so the invited user is the actor
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionInvitationAccept,
+		ActorID:    user.ID.Hex(),
+		TargetType: domain.AuditTargetUser,
+		TargetID:   user.ID.Hex(),
+		Before:     before,
+		After:      userAuditSummary(user),
+	})
+	return nil
+}
+
+// pendingUser returns the user of an invitation, which no longer applies once
+// the user has been deleted or has signed in some other way. Invitations
+// that no longer apply are deleted.
+func (s *InvitationService) pendingUser(ctx context.Context, invitation *domain.Invitation) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, invitation.ID.Hex())
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+	if err == nil && user.Pending {
+		return user, nil
+	}
+
+	if err := s.invitationRepo.Delete(ctx, invitation.ID.Hex()); err != nil && !errors.Is(err, domain.ErrInvitationNotFound) {
+		return nil, err
+	}
+	return nil, domain.ErrInvitationNotFound
+}
+
+// send emails an invite link to a pending user, returning
+// domain.ErrInvitationNotSent when the mailer fails
+func (s *InvitationService) send(ctx context.Context, user *domain.User, invitation *domain.Invitation, token string) error {
+	inviter := "An administrator"
+	if admin, err := s.userRepo.GetByID(ctx, invitation.InvitedBy.Hex()); err == nil {
+		if name := strings.TrimSpace(admin.FirstName + " " + admin.LastName); name != "" {
+			inviter = name
+		}
+	}
+
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "You have been invited",
+		Body: fmt.Sprintf("Hi %s,\n\n%s invited you to create an account. Use the link below to choose your password. It expires in %s.\n\n%s\n",
+			user.FirstName, inviter, describeTTL(s.options.TTL), tokenLink(s.options.URL, token)),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to send invitation", "target_user_id", user.ID.Hex(), "error", err)
+		return domain.ErrInvitationNotSent
+	}
+	return nil
+}
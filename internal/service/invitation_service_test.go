@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testInvitationOptions = service.InvitationOptions{
+	URL: "https://app.example.com/accept-invitation",
+	TTL: 7 * 24 * time.Hour,
+}
+
+func TestInvitationService_Accept(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, invitations, m := s.auth, s.invitations, s.mailer
+	inviterID := registerTestUser(t, svc).User.ID
+
+	invitation, err := invitations.Invite(ctx, inviterID, domain.InviteUserRequest{
+		Email:     "New@Example.com",
+		FirstName: "New",
+		Role:      domain.RoleEditor,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", invitation.Email)
+	assert.Equal(t, inviterID, invitation.InvitedBy.Hex())
+
+	// The pending user can't sign in or be invited again
+	assert.ErrorIs(t, login(svc, "new@example.com", "anypassword123", testClient), domain.ErrInvalidCredentials)
+	_, err = invitations.Invite(ctx, inviterID, domain.InviteUserRequest{Email: "new@example.com", FirstName: "New"})
+	assert.ErrorIs(t, err, domain.ErrEmailAlreadyExists)
+
+	token := mailedToken(t, m, "new@example.com", "You have been invited")
+	msg, _ := m.Last("new@example.com")
+	assert.Contains(t, msg.Body, "expires in 168 hours")
+
+	// A password that breaks the policy doesn't use up the invitation
+	err = invitations.Accept(ctx, domain.AcceptInvitationRequest{Token: token, Password: "short"})
+	assert.ErrorIs(t, err, domain.ErrPasswordTooShort)
+	assert.ErrorIs(t, invitations.Accept(ctx, domain.AcceptInvitationRequest{Token: "unknown", Password: "newpassword123"}), domain.ErrInvalidInvitation)
+	require.NoError(t, invitations.Accept(ctx, domain.AcceptInvitationRequest{Token: token, Password: "newpassword123"}))
+
+	// Invitations are single use
+	assert.ErrorIs(t, invitations.Accept(ctx, domain.AcceptInvitationRequest{Token: token, Password: "otherpassword123"}), domain.ErrInvalidInvitation)
+
+	resp, _, err := svc.Login(ctx, domain.LoginRequest{Email: "new@example.com", Password: "newpassword123"}, testClient)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleEditor, resp.User.Role)
+	assert.False(t, resp.User.Pending)
+	assert.True(t, resp.User.EmailVerified)
+
+	result, err := invitations.List(ctx, domain.PaginationParams{Page: 1, Limit: 20})
+	require.NoError(t, err)
+	assert.Empty(t, result.Data)
+}
+
+func TestInvitationService_ResendAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	invitations, m := s.invitations, s.mailer
+	inviterID := registerTestUser(t, s.auth).User.ID
+
+	invitation, err := invitations.Invite(ctx, inviterID, domain.InviteUserRequest{Email: "new@example.com", FirstName: "New"})
+	require.NoError(t, err)
+	first := mailedToken(t, m, "new@example.com", "You have been invited")
+
+	// Resending replaces the first link
+	_, err = invitations.Resend(ctx, invitation.ID.Hex())
+	require.NoError(t, err)
+	second := mailedToken(t, m, "new@example.com", "You have been invited")
+	assert.NotEqual(t, first, second)
+	assert.ErrorIs(t, invitations.Accept(ctx, domain.AcceptInvitationRequest{Token: first, Password: "newpassword123"}), domain.ErrInvalidInvitation)
+
+	// Revoking deletes the pending user, so the address can be invited again
+	require.NoError(t, invitations.Revoke(ctx, invitation.ID.Hex()))
+	assert.ErrorIs(t, invitations.Revoke(ctx, invitation.ID.Hex()), domain.ErrInvitationNotFound)
+	assert.ErrorIs(t, invitations.Accept(ctx, domain.AcceptInvitationRequest{Token: second, Password: "newpassword123"}), domain.ErrInvalidInvitation)
+
+	_, err = invitations.Invite(ctx, inviterID, domain.InviteUserRequest{Email: "new@example.com", FirstName: "New"})
+	require.NoError(t, err)
+}
+
+// failingMailer fails to send every email
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	return errors.New("smtp: connection refused")
+}
+
+func TestInvitationService_InviteRollsBackUnsentInvitation(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(withInvitationMailer(failingMailer{}))
+	inviterID := registerTestUser(t, s.auth).User.ID
+
+	_, err := s.invitations.Invite(ctx, inviterID, domain.InviteUserRequest{Email: "new@example.com", FirstName: "New"})
+	require.ErrorIs(t, err, domain.ErrInvitationNotSent)
+
+	// Neither the pending user nor the invitation is left behind
+	_, err = s.userRepo.GetByEmail(ctx, "new@example.com")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	result, err := s.invitations.List(ctx, domain.PaginationParams{Page: 1, Limit: 20})
+	require.NoError(t, err)
+	assert.Empty(t, result.Data)
+}
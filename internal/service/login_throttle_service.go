@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/logging"
+)
+
+// maxLoginDelay caps the delay after a failed login, keeping it well below the server's write timeout
+const maxLoginDelay = 8 * time.Second
+
+// LoginPolicy configures the brute-force protection of logins
+type LoginPolicy struct {
+	// MaxFailures is how many failed logins lock an account
+	MaxFailures int
+	// IPMaxFailures is how many failed logins lock a client IP
+	IPMaxFailures int
+	// Window is how long failed logins are remembered
+	Window time.Duration
+	// LockoutDuration is how long a lockout lasts
+	LockoutDuration time.Duration
+	// Delay is the wait after a failed login, doubled with each further failure
+	Delay time.Duration
+}
+
+// LoginThrottleService counts failed logins per account and per client IP,
+// slowing down and eventually locking out repeated failures
+type LoginThrottleService struct {
+	repo   domain.LoginAttemptRepository
+	policy LoginPolicy
+	audit  *AuditService
+}
+
+// NewLoginThrottleService creates a new LoginThrottleService
+func NewLoginThrottleService(repo domain.LoginAttemptRepository, policy LoginPolicy, audit *AuditService) *LoginThrottleService {
+	return &LoginThrottleService{
+		repo:   repo,
+		policy: policy,
+		audit:  audit,
+	}
+}
+
+// Check returns domain.ErrInvalidCredentials if logins to the account or
+// from the client IP are locked. The error doesn't tell a locked account
+// apart from a wrong password.
+func (s *LoginThrottleService) Check(ctx context.Context, email, ip string) error {
+	keys := []string{domain.AccountLoginKey(email)}
+	if ip != "" {
+		keys = append(keys, domain.IPLoginKey(ip))
+	}
+
+	for _, key := range keys {
+		attempts, err := s.repo.Get(ctx, key)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if attempts.IsLocked(time.Now()) {
+			return domain.ErrInvalidCredentials
+		}
+	}
+	return nil
+}
+
+// Failure records a failed login, locks the account or client IP once it
+// reaches its limit and then waits, longer with each failure to the account.
+// userID is the ID of the account with the email, or empty if there is none.
+func (s *LoginThrottleService) Failure(ctx context.Context, email, userID, ip string) error {
+	account, err := s.repo.RecordFailure(ctx, domain.AccountLoginKey(email), s.policy.Window)
+	if err != nil {
+		return err
+	}
+	// Lockouts are audited by user ID, so that the log holds no email addresses
+	lockout := domain.AuditEvent{After: map[string]string{"scope": "account"}}
+	if userID != "" {
+		lockout.TargetType = domain.AuditTargetUser
+		lockout.TargetID = userID
+	}
+	if err := s.lockIfExceeded(ctx, account, s.policy.MaxFailures, lockout); err != nil {
+		return err
+	}
+
+	if ip != "" {
+		client, err := s.repo.RecordFailure(ctx, domain.IPLoginKey(ip), s.policy.Window)
+		if err != nil {
+			return err
+		}
+		lockout := domain.AuditEvent{After: map[string]string{"scope": "ip", "ip": ip}}
+		if err := s.lockIfExceeded(ctx, client, s.policy.IPMaxFailures, lockout); err != nil {
+			return err
+		}
+	}
+
+	return s.wait(ctx, account.Failures)
+}
+
+// Success forgets the failed logins to an account once a user logged in
+func (s *LoginThrottleService) Success(ctx context.Context, email string) error {
+	return s.repo.Reset(ctx, domain.AccountLoginKey(email))
+}
+
+// Unlock lifts the lockout of an account and forgets its failed logins.
+// userID is the ID of the account with the email. A non-empty ip lifts the
+// lockout of that client IP too; otherwise IP lockouts are left to expire.
+func (s *LoginThrottleService) Unlock(ctx context.Context, email, userID, ip string) error {
+	if err := s.repo.Reset(ctx, domain.AccountLoginKey(email)); err != nil {
+		return err
+	}
+	// Like lockouts, unlocks are logged by user ID, never by email address
+	logging.FromContext(ctx).Info("Logins to account were unlocked", "user_id", userID)
+
+	if ip != "" {
+		if err := s.repo.Reset(ctx, domain.IPLoginKey(ip)); err != nil {
+			return err
+		}
+		logging.FromContext(ctx).Info("Logins from client IP were unlocked", "ip", ip)
+	}
+	return nil
+}
+
+// lockIfExceeded locks a key that reached its failure limit and isn't locked
+// yet, and records the lockout in the audit log as described by event
+func (s *LoginThrottleService) lockIfExceeded(ctx context.Context, attempts *domain.LoginAttempts, limit int, event domain.AuditEvent) error {
+	if limit <= 0 || attempts.Failures < limit || attempts.IsLocked(time.Now()) {
+		return nil
+	}
+
+	until := time.Now().Add(s.policy.LockoutDuration)
+	if err := s.repo.Lock(ctx, attempts.Key, until); err != nil {
+		return err
+	}
+
+	// The key of an account holds its email address, so the lockout is
+	// logged by what it is audited by instead
+	args := []any{"scope", event.After["scope"], "until", until.UTC().Format(time.RFC3339), "failures", attempts.Failures}
+	if event.TargetID != "" {
+		args = append(args, "user_id", event.TargetID)
+	}
+	if ip, ok := event.After["ip"]; ok {
+		args = append(args, "ip", ip)
+	}
+	logging.FromContext(ctx).Warn("Locked logins after failed attempts", args...)
+	event.Action = domain.AuditActionLockout
+	event.After["failures"] = strconv.Itoa(attempts.Failures)
+	event.After["locked_until"] = until.UTC().Format(time.RFC3339)
+	s.audit.Record(ctx, event)
+	return nil
+}
+
+// wait delays the response to the given number of consecutive failures
+func (s *LoginThrottleService) wait(ctx context.Context, failures int) error {
+	if s.policy.Delay <= 0 || failures <= 0 {
+		return nil
+	}
+
+	delay := maxLoginDelay
+	if failures <= 16 {
+		delay = min(s.policy.Delay<<(failures-1), maxLoginDelay)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
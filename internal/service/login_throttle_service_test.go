@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLoginPolicy = service.LoginPolicy{
+	MaxFailures:     3,
+	IPMaxFailures:   5,
+	Window:          15 * time.Minute,
+	LockoutDuration: 15 * time.Minute,
+}
+
+func login(svc *service.AuthService, email, password string, client domain.ClientInfo) error {
+	_, _, err := svc.Login(context.Background(), domain.LoginRequest{Email: email, Password: password}, client)
+	return err
+}
+
+func TestLoginThrottle_LocksAccount(t *testing.T) {
+	s := newTestServices()
+	svc, userSvc := s.auth, s.users
+	userID := registerTestUser(t, svc).User.ID
+
+	for i := 0; i < testLoginPolicy.MaxFailures; i++ {
+		assert.ErrorIs(t, login(svc, "user@example.com", "wrong-password", testClient), domain.ErrInvalidCredentials)
+	}
+
+	// A locked account looks like a wrong password, even with the right one
+	assert.ErrorIs(t, login(svc, "User@Example.com", "securepassword123", testClient), domain.ErrInvalidCredentials)
+
+	require.NoError(t, userSvc.Unlock(context.Background(), userID, ""))
+	require.NoError(t, login(svc, "user@example.com", "securepassword123", testClient))
+}
+
+func TestLoginThrottle_LogsNoEmail(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := logging.NewContext(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+	s := newTestServices()
+	userID := registerTestUser(t, s.auth).User.ID
+
+	for i := 0; i < testLoginPolicy.MaxFailures; i++ {
+		_, _, err := s.auth.Login(ctx, domain.LoginRequest{Email: "user@example.com", Password: "wrong-password"}, testClient)
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+	require.NoError(t, s.users.Unlock(ctx, userID, ""))
+
+	// Lockouts and unlocks name the user by ID
+	assert.Contains(t, logs.String(), "Locked logins after failed attempts")
+	assert.Contains(t, logs.String(), "Logins to account were unlocked")
+	assert.Contains(t, logs.String(), "user_id="+userID)
+	assert.NotContains(t, logs.String(), "user@example.com")
+}
+
+func TestLoginThrottle_UnknownEmailsAreLockedToo(t *testing.T) {
+	svc := newTestServices().auth
+
+	for i := 0; i < testLoginPolicy.MaxFailures+1; i++ {
+		assert.ErrorIs(t, login(svc, "nobody@example.com", "wrong-password", testClient), domain.ErrInvalidCredentials)
+	}
+}
+
+func TestLoginThrottle_SuccessForgetsFailures(t *testing.T) {
+	svc := newTestServices().auth
+	registerTestUser(t, svc)
+
+	for round := 0; round < 2; round++ {
+		for i := 0; i < testLoginPolicy.MaxFailures-1; i++ {
+			assert.ErrorIs(t, login(svc, "user@example.com", "wrong-password", testClient), domain.ErrInvalidCredentials)
+		}
+		require.NoError(t, login(svc, "user@example.com", "securepassword123", testClient))
+	}
+}
+
+func TestLoginThrottle_LocksClientIP(t *testing.T) {
+	svc := newTestServices().auth
+	registerTestUser(t, svc)
+
+	// Spraying passwords across accounts locks the client IP
+	for i := 0; i < testLoginPolicy.IPMaxFailures; i++ {
+		email := string(rune('a'+i)) + "@example.com"
+		assert.ErrorIs(t, login(svc, email, "wrong-password", testClient), domain.ErrInvalidCredentials)
+	}
+
+	assert.ErrorIs(t, login(svc, "user@example.com", "securepassword123", testClient), domain.ErrInvalidCredentials)
+	otherClient := domain.ClientInfo{UserAgent: "test-agent", IPAddress: "198.51.100.7"}
+	require.NoError(t, login(svc, "user@example.com", "securepassword123", otherClient))
+}
+
+func TestLoginThrottle_UnlockClientIP(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	userID := registerTestUser(t, s.auth).User.ID
+	client := domain.ClientInfo{UserAgent: "test-agent", IPAddress: "2001:db8::7"}
+
+	for i := 0; i < testLoginPolicy.IPMaxFailures; i++ {
+		email := string(rune('a'+i)) + "@example.com"
+		assert.ErrorIs(t, login(s.auth, email, "wrong-password", client), domain.ErrInvalidCredentials)
+	}
+
+	// Unlocking the account alone leaves the IP locked
+	require.NoError(t, s.users.Unlock(ctx, userID, ""))
+	assert.ErrorIs(t, login(s.auth, "user@example.com", "securepassword123", client), domain.ErrInvalidCredentials)
+
+	assert.ErrorIs(t, s.users.Unlock(ctx, userID, "not-an-ip"), domain.ErrInvalidIPAddress)
+
+	// IPs match in any notation
+	require.NoError(t, s.users.Unlock(ctx, userID, "2001:DB8:0::7"))
+	require.NoError(t, login(s.auth, "user@example.com", "securepassword123", client))
+}
+
+func TestLoginThrottle_WrongMFACodesLockAccount(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, mfa := s.auth, s.mfa
+	secret, _ := enrollTOTP(t, mfa, registerTestUser(t, svc).User.ID)
+
+	_, challenge, err := svc.Login(ctx, domain.LoginRequest{Email: "user@example.com", Password: "securepassword123"}, testClient)
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+
+	for i := 0; i < testLoginPolicy.MaxFailures; i++ {
+		_, err := svc.VerifyMFA(ctx, domain.MFAVerifyRequest{MFAToken: challenge.MFAToken, Code: "000000"}, testClient)
+		assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+	}
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	_, err = svc.VerifyMFA(ctx, domain.MFAVerifyRequest{MFAToken: challenge.MFAToken, Code: code}, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+}
+
+func TestLoginThrottle_ProgressiveDelay(t *testing.T) {
+	ctx := context.Background()
+	policy := testLoginPolicy
+	policy.Delay = 10 * time.Millisecond
+	throttle := service.NewLoginThrottleService(mocks.NewMockLoginAttemptRepository(), policy, nil)
+
+	// 10ms, 20ms, then 40ms
+	for _, want := range []time.Duration{10, 20, 40} {
+		start := time.Now()
+		require.NoError(t, throttle.Failure(ctx, "user@example.com", "", ""))
+		assert.GreaterOrEqual(t, time.Since(start), want*time.Millisecond)
+	}
+
+	// The wait ends with the request
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, throttle.Failure(cancelled, "user@example.com", "", ""), context.Canceled)
+}
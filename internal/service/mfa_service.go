@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/logging"
+)
+
+const (
+	// totpPeriod is the lifetime of a TOTP code in seconds
+	totpPeriod = 30
+	// totpSkew is how many periods a code may be early or late, to allow for clock drift
+	totpSkew = 1
+	// recoveryCodeCount is how many recovery codes a user gets at a time
+	recoveryCodeCount = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAService manages TOTP multi-factor authentication and recovery codes
+type MFAService struct {
+	mfaRepo       domain.MFARepository
+	userRepo      domain.UserRepository
+	issuer        string
+	requiredRoles []string
+	audit         *AuditService
+}
+
+// NewMFAService creates a new MFAService. issuer names this API in
+// authenticator apps. Users with one of requiredRoles must set up MFA before
+// they are given the permissions of their role.
+func NewMFAService(mfaRepo domain.MFARepository, userRepo domain.UserRepository, issuer string, requiredRoles []string, audit *AuditService) *MFAService {
+	return &MFAService{
+		mfaRepo:       mfaRepo,
+		userRepo:      userRepo,
+		issuer:        issuer,
+		requiredRoles: requiredRoles,
+		audit:         audit,
+	}
+}
+
+// Required reports whether users with a role must use MFA
+func (s *MFAService) Required(role string) bool {
+	for _, required := range s.requiredRoles {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled reports whether a user has confirmed an authenticator app
+func (s *MFAService) Enabled(ctx context.Context, userID string) (bool, error) {
+	factor, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			return false, nil
+		}
+		return false, err
+	}
+	return factor.IsEnabled(), nil
+}
+
+// Status describes the multi-factor authentication of a user
+func (s *MFAService) Status(ctx context.Context, userID string) (*domain.MFAStatus, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.MFAStatus{Required: s.Required(user.Role)}
+	factor, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			return status, nil
+		}
+		return nil, err
+	}
+	if factor.IsEnabled() {
+		status.TOTPEnabled = true
+		status.ConfirmedAt = factor.ConfirmedAt
+		status.RecoveryCodesRemaining = len(factor.RecoveryCodes)
+	}
+	return status, nil
+}
+
+// EnrollTOTP generates a new authenticator app secret for a user. It is not
+// used for logins until confirmed with ConfirmTOTP; enrolling again before
+// that replaces the secret.
+func (s *MFAService) EnrollTOTP(ctx context.Context, userID string) (*domain.TOTPEnrollment, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled, err := s.Enabled(ctx, userID); err != nil {
+		return nil, err
+	} else if enabled {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.issuer,
+		AccountName: user.Email,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.Save(ctx, &domain.TOTPFactor{UserID: user.ID, Secret: key.Secret()}); err != nil {
+		return nil, err
+	}
+
+	return &domain.TOTPEnrollment{Secret: key.Secret(), URI: key.URL()}, nil
+}
+
+// ConfirmTOTP enables a pending authenticator app with a code from it and
+// returns the user's recovery codes
+func (s *MFAService) ConfirmTOTP(ctx context.Context, userID, code string) (*domain.RecoveryCodesResponse, error) {
+	factor, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor.IsEnabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	code = normalizeMFACode(code)
+	if code == "" {
+		return nil, domain.ErrMFACodeRequired
+	}
+	step, ok := matchTOTP(factor.Secret, code, time.Now())
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+
+	codes, hashes := generateRecoveryCodes(userID)
+	now := time.Now()
+	factor.ConfirmedAt = &now
+	factor.LastUsedStep = step
+	factor.RecoveryCodes = hashes
+	if err := s.mfaRepo.Save(ctx, factor); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Info("User enabled multi-factor authentication", "target_user_id", userID)
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionMFAEnable,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   userID,
+	})
+	return &domain.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after
+// checking a code from their authenticator app or a recovery code
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) (*domain.RecoveryCodesResponse, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	// Reload the factor, which Verify has updated
+	factor, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes := generateRecoveryCodes(userID)
+	factor.RecoveryCodes = hashes
+	if err := s.mfaRepo.Save(ctx, factor); err != nil {
+		return nil, err
+	}
+
+	return &domain.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable removes the authenticator app of a user after checking a code
+// from it or a recovery code. A pending enrollment is removed without a code.
+func (s *MFAService) Disable(ctx context.Context, userID, code string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	factor, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if factor.IsEnabled() {
+		if s.Required(user.Role) {
+			return domain.ErrMFARequired
+		}
+		if err := s.verify(ctx, factor, code); err != nil {
+			return err
+		}
+	}
+
+	if err := s.mfaRepo.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Info("User disabled multi-factor authentication", "target_user_id", userID)
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionMFADisable,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   userID,
+	})
+	return nil
+}
+
+// Reset removes the authenticator app of a user who lost it, so they can
+// log in with their password alone and set up a new one
+func (s *MFAService) Reset(ctx context.Context, userID string) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.Delete(ctx, userID); err != nil && !errors.Is(err, domain.ErrMFANotEnrolled) {
+		return err
+	}
+
+	logging.FromContext(ctx).Info("Multi-factor authentication of user was reset", "target_user_id", userID)
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionMFAReset,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   userID,
+	})
+	return nil
+}
+
+// Verify checks a code from the authenticator app of a user or one of their
+// recovery codes. Each code can only be used once.
+func (s *MFAService) Verify(ctx context.Context, userID, code string) error {
+	factor, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			return domain.ErrInvalidMFACode
+		}
+		return err
+	}
+	if !factor.IsEnabled() {
+		return domain.ErrInvalidMFACode
+	}
+
+	return s.verify(ctx, factor, code)
+}
+
+// verify checks a code against an enabled factor and marks it as used
+func (s *MFAService) verify(ctx context.Context, factor *domain.TOTPFactor, code string) error {
+	code = normalizeMFACode(code)
+	if code == "" {
+		return domain.ErrMFACodeRequired
+	}
+
+	userID := factor.UserID.Hex()
+	if isTOTPCode(code) {
+		step, ok := matchTOTP(factor.Secret, code, time.Now())
+		if !ok {
+			return domain.ErrInvalidMFACode
+		}
+		// Rejects a code that was already used, including by a concurrent request
+		return s.mfaRepo.UseStep(ctx, userID, step)
+	}
+
+	if err := s.mfaRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(userID, code)); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info("User used a recovery code", "target_user_id", userID, "recovery_codes_left", len(factor.RecoveryCodes)-1)
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionMFARecoveryCodeUsed,
+		ActorID:    userID, // Also used during login, before the user is authenticated
+		TargetType: domain.AuditTargetUser,
+		TargetID:   userID,
+	})
+	return nil
+}
+
+// matchTOTP returns the time step of the code if it is valid at now
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	if !isTOTPCode(code) {
+		return 0, false
+	}
+
+	for _, skew := range []int64{0, -totpSkew, totpSkew} {
+		at := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+		expected, err := totp.GenerateCodeCustom(secret, at, totp.ValidateOpts{Period: totpPeriod})
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(expected)) == 1 {
+			return at.Unix() / totpPeriod, true
+		}
+	}
+	return 0, false
+}
+
+// isTOTPCode reports whether a code looks like a 6-digit authenticator app code
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeMFACode removes the spaces and dashes users may type in a code
+func normalizeMFACode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+// generateRecoveryCodes returns new recovery codes formatted for display
+// along with their hashes for storage
+func generateRecoveryCodes(userID string) ([]string, []string) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 8)
+		rand.Read(b)
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:12]
+		codes[i] = code[:6] + "-" + code[6:]
+		hashes[i] = hashRecoveryCode(userID, code)
+	}
+	return codes, hashes
+}
+
+// hashRecoveryCode hashes a normalized recovery code for storage. Codes carry
+// 60 bits of entropy and are salted with the user ID, so a fast hash is sufficient.
+func hashRecoveryCode(userID, code string) string {
+	sum := sha256.Sum256([]byte(userID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
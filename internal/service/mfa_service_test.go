@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enrollTOTP sets up an authenticator app for a user and returns its secret
+// and recovery codes. The confirmation code is from the previous period so
+// that a code for the current period is still unused.
+func enrollTOTP(t *testing.T, mfa *service.MFAService, userID string) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	enrollment, err := mfa.EnrollTOTP(ctx, userID)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/")
+
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now().Add(-30*time.Second))
+	require.NoError(t, err)
+	recovery, err := mfa.ConfirmTOTP(ctx, userID, code)
+	require.NoError(t, err)
+	require.Len(t, recovery.RecoveryCodes, 10)
+	return enrollment.Secret, recovery.RecoveryCodes
+}
+
+func TestMFAService_LoginRequiresSecondFactor(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, mfa := s.auth, s.mfa
+	registered := registerTestUser(t, svc)
+	secret, _ := enrollTOTP(t, mfa, registered.User.ID)
+
+	login := domain.LoginRequest{Email: "user@example.com", Password: "securepassword123"}
+	resp, challenge, err := svc.Login(ctx, login, testClient)
+	require.NoError(t, err)
+	assert.Nil(t, resp)
+	require.NotNil(t, challenge)
+	assert.True(t, challenge.MFARequired)
+
+	// The challenge is not an access or refresh token
+	_, err = svc.RefreshToken(ctx, challenge.MFAToken, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	_, err = svc.VerifyMFA(ctx, domain.MFAVerifyRequest{MFAToken: challenge.MFAToken, Code: "000000"}, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	resp, err = svc.VerifyMFA(ctx, domain.MFAVerifyRequest{MFAToken: challenge.MFAToken, Code: code}, testClient)
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.AccessToken)
+
+	// A code can't be replayed
+	_, err = svc.VerifyMFA(ctx, domain.MFAVerifyRequest{MFAToken: challenge.MFAToken, Code: code}, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+}
+
+func TestMFAService_RecoveryCodesAreSingleUse(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, mfa := s.auth, s.mfa
+	userID := registerTestUser(t, svc).User.ID
+	_, codes := enrollTOTP(t, mfa, userID)
+
+	// Codes are accepted regardless of case and dashes
+	require.NoError(t, mfa.Verify(ctx, userID, codes[0]))
+	assert.ErrorIs(t, mfa.Verify(ctx, userID, codes[0]), domain.ErrInvalidMFACode)
+	require.NoError(t, mfa.Verify(ctx, userID, " "+codes[1][:6]+codes[1][7:]))
+
+	status, err := mfa.Status(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, status.TOTPEnabled)
+	assert.Equal(t, 8, status.RecoveryCodesRemaining)
+
+	// Regenerating invalidates the remaining codes
+	regenerated, err := mfa.RegenerateRecoveryCodes(ctx, userID, codes[2])
+	require.NoError(t, err)
+	assert.ErrorIs(t, mfa.Verify(ctx, userID, codes[3]), domain.ErrInvalidMFACode)
+	require.NoError(t, mfa.Verify(ctx, userID, regenerated.RecoveryCodes[0]))
+}
+
+func TestMFAService_EnrollAndDisable(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, mfa := s.auth, s.mfa
+	userID := registerTestUser(t, svc).User.ID
+
+	_, err := mfa.ConfirmTOTP(ctx, userID, "123456")
+	assert.ErrorIs(t, err, domain.ErrMFANotEnrolled)
+
+	_, codes := enrollTOTP(t, mfa, userID)
+	_, err = mfa.EnrollTOTP(ctx, userID)
+	assert.ErrorIs(t, err, domain.ErrMFAAlreadyEnabled)
+
+	assert.ErrorIs(t, mfa.Disable(ctx, userID, ""), domain.ErrMFACodeRequired)
+	assert.ErrorIs(t, mfa.Disable(ctx, userID, "not-a-code"), domain.ErrInvalidMFACode)
+	require.NoError(t, mfa.Disable(ctx, userID, codes[0]))
+
+	enabled, err := mfa.Enabled(ctx, userID)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	_, challenge, err := svc.Login(ctx, domain.LoginRequest{Email: "user@example.com", Password: "securepassword123"}, testClient)
+	require.NoError(t, err)
+	assert.Nil(t, challenge)
+}
+
+func TestMFAService_RequiredRoleMustEnroll(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices(withMFARequiredRoles(domain.RoleUser))
+	svc, mfa, jwtManager := s.auth, s.mfa, s.jwtManager
+	registered := registerTestUser(t, svc)
+	userID := registered.User.ID
+
+	// Until MFA is set up, tokens only allow setting it up
+	assert.True(t, registered.MFAEnrollmentRequired)
+	claims, err := jwtManager.ValidateAccessToken(registered.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{auth.ScopeMFAEnrollment}, claims.Scopes())
+
+	_, codes := enrollTOTP(t, mfa, userID)
+
+	refreshed, err := svc.RefreshToken(ctx, registered.RefreshToken, testClient)
+	require.NoError(t, err)
+	assert.False(t, refreshed.MFAEnrollmentRequired)
+	claims, err = jwtManager.ValidateAccessToken(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Contains(t, claims.Scopes(), auth.ScopeServicesRead)
+	assert.NotContains(t, claims.Scopes(), auth.ScopeMFAEnrollment)
+
+	// The policy can't be switched off by the user, only reset by an admin
+	assert.ErrorIs(t, mfa.Disable(ctx, userID, codes[0]), domain.ErrMFARequired)
+	require.NoError(t, mfa.Reset(ctx, userID))
+	require.NoError(t, mfa.Reset(ctx, userID))
+
+	status, err := mfa.Status(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, status.Required)
+	assert.False(t, status.TOTPEnabled)
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/logging"
+	"github.com/services-api/pkg/metrics"
+	"github.com/services-api/pkg/oidc"
+)
+
+// maxNameLength is the longest first or last name a user can have
+const maxNameLength = 100
+
+// GroupRole maps an identity provider group to a role
+type GroupRole struct {
+	Group string
+	Role  string
+}
+
+// OIDCService signs users in through an external OpenID Connect provider,
+// provisioning or linking local users by email
+type OIDCService struct {
+	provider    *oidc.Provider
+	userRepo    domain.UserRepository
+	auth        *AuthService
+	groupRoles  []GroupRole
+	defaultRole string
+}
+
+// NewOIDCService creates a new OIDCService. When groupRoles is not empty, the
+// role of a user is set from their groups at every login: the first matching
+// group wins and users in none of the groups get defaultRole. Otherwise new
+// users get defaultRole and existing users keep their role.
+func NewOIDCService(provider *oidc.Provider, userRepo domain.UserRepository, auth *AuthService, groupRoles []GroupRole, defaultRole string) *OIDCService {
+	return &OIDCService{
+		provider:    provider,
+		userRepo:    userRepo,
+		auth:        auth,
+		groupRoles:  groupRoles,
+		defaultRole: defaultRole,
+	}
+}
+
+// AuthCodeURL starts a login, returning the provider URL to send the user to
+// and the values to present again in Callback
+func (s *OIDCService) AuthCodeURL() (string, oidc.AuthRequest) {
+	req := oidc.NewAuthRequest()
+	return s.provider.AuthCodeURL(req), req
+}
+
+// Callback completes a login with the authorization code returned by the
+// provider and issues our own tokens for the user. Like a password login, it
+// returns an MFA challenge instead when the user has MFA enabled.
+func (s *OIDCService) Callback(ctx context.Context, code string, req oidc.AuthRequest, client domain.ClientInfo) (*domain.AuthResponse, *domain.MFAChallenge, error) {
+	identity, err := s.provider.Exchange(ctx, code, req)
+	if err != nil {
+		logging.FromContext(ctx).Warn("OIDC login failed", "error", err)
+		s.auth.audit.Record(ctx, domain.AuditEvent{Action: domain.AuditActionSSOLoginFailed})
+		metrics.Logins.WithLabelValues(metrics.ResultFailure).Inc()
+		return nil, nil, domain.ErrSSOFailed
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, nil, domain.ErrSSOEmailRequired
+	}
+
+	user, err := s.provisionUser(ctx, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !user.Active {
+		return nil, nil, domain.ErrInvalidCredentials
+	}
+
+	return s.auth.completeLogin(ctx, user, client)
+}
+
+// provisionUser returns the user with the identity's email, creating them on
+// their first login, updating their role from their groups and marking their
+// email address verified. A changed role revokes the user's other sessions.
+func (s *OIDCService) provisionUser(ctx context.Context, identity *oidc.Identity) (*domain.User, error) {
+	role, mapped := s.mapRole(identity.Groups)
+
+	user, err := s.userRepo.GetByEmail(ctx, identity.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		user, err = s.createUser(ctx, identity, role)
+		if errors.Is(err, domain.ErrEmailAlreadyExists) {
+			// Created by a concurrent login
+			user, err = s.userRepo.GetByEmail(ctx, identity.Email)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider verified the email address, so an existing user doesn't
+	// have to, and an invited user no longer needs to accept the invitation
+	changed := !user.EmailVerified || user.Pending
+	user.EmailVerified = true
+	user.Pending = false
+
+	before := userAuditSummary(user)
+	roleChanged := mapped && user.Role != role
+	if roleChanged {
+		logging.FromContext(ctx).Info("OIDC login changed the role of user", "target_user_id", user.ID.Hex(), "from", user.Role, "to", role)
+		user.Role = role
+		changed = true
+	}
+
+	if changed {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	if roleChanged {
+		s.auth.audit.Record(ctx, domain.AuditEvent{
+			Action:     domain.AuditActionUserRoleChange,
+			TargetType: domain.AuditTargetUser,
+			TargetID:   user.ID.Hex(),
+			Before:     before,
+			After:      userAuditSummary(user),
+		})
+
+		// Like a role change by an admin, this ends the sessions holding
+		// tokens for the old role
+		if err := s.auth.sessions.revokeAll(ctx, user.ID.Hex()); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// createUser provisions a user for an identity. SSO users have no password,
+// so they can't log in with one.
+func (s *OIDCService) createUser(ctx context.Context, identity *oidc.Identity, role string) (*domain.User, error) {
+	firstName := truncate(identity.FirstName, maxNameLength)
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	user := &domain.User{
+		Email:     identity.Email,
+		FirstName: firstName,
+		LastName:  truncate(identity.LastName, maxNameLength),
+		Role:      role,
+		Active:    true,
+
+		EmailVerified: true,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to provision user: %w", err)
+	}
+
+	logging.FromContext(ctx).Info("OIDC login provisioned user", "target_user_id", user.ID.Hex(), "role", role)
+	s.auth.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionUserCreate,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   user.ID.Hex(),
+		After:      userAuditSummary(user),
+	})
+	return user, nil
+}
+
+// mapRole returns the role of the first configured group the user is in. It
+// reports false when no group mapping is configured.
+func (s *OIDCService) mapRole(groups []string) (string, bool) {
+	if len(s.groupRoles) == 0 {
+		return s.defaultRole, false
+	}
+	for _, mapping := range s.groupRoles {
+		for _, group := range groups {
+			if group == mapping.Group {
+				return mapping.Role, true
+			}
+		}
+	}
+	return s.defaultRole, true
+}
+
+// truncate shortens s to at most n bytes without splitting a character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
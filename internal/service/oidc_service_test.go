@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/oidc"
+	"github.com/services-api/pkg/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOIDCService(t *testing.T, groupRoles ...service.GroupRole) (*service.OIDCService, *oidctest.Server, *testServices) {
+	t.Helper()
+	idp := oidctest.NewServer("api", "api-secret")
+	t.Cleanup(idp.Close)
+
+	provider, err := oidc.NewProvider(context.Background(), oidc.Config{
+		IssuerURL:    idp.Issuer(),
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://localhost:8080/api/v1/auth/oidc/callback",
+		GroupsClaim:  "groups",
+	})
+	require.NoError(t, err)
+
+	s := newTestServices()
+	return service.NewOIDCService(provider, s.userRepo, s.auth, groupRoles, domain.RoleUser), idp, s
+}
+
+// signIn runs a login through the provider and returns the callback result
+func signIn(t *testing.T, svc *service.OIDCService, idp *oidctest.Server) (*domain.AuthResponse, error) {
+	t.Helper()
+	authURL, req := svc.AuthCodeURL()
+	redirect, err := idp.Authorize(authURL)
+	require.NoError(t, err)
+	require.Equal(t, req.State, redirect.Query().Get("state"))
+	require.NotEmpty(t, redirect.Query().Get("code"), redirect.Query().Get("error_description"))
+	resp, challenge, err := svc.Callback(context.Background(), redirect.Query().Get("code"), req, testClient)
+	if err == nil {
+		require.Nil(t, challenge)
+	}
+	return resp, err
+}
+
+func TestOIDCService_Callback_ProvisionsAndLinksUser(t *testing.T) {
+	ctx := context.Background()
+	svc, idp, s := newTestOIDCService(t)
+	idp.SetUser(map[string]interface{}{
+		"sub":         "abc123",
+		"email":       "Jane@Example.com",
+		"given_name":  "Jane",
+		"family_name": "Doe",
+	})
+
+	first, err := signIn(t, svc, idp)
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.AccessToken)
+
+	user, err := s.userRepo.GetByEmail(ctx, "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", user.FirstName)
+	assert.Equal(t, "Doe", user.LastName)
+	assert.Equal(t, domain.RoleUser, user.Role)
+	assert.Empty(t, user.PasswordHash)
+
+	// The second login reuses the account
+	second, err := signIn(t, svc, idp)
+	require.NoError(t, err)
+	assert.Equal(t, first.User.ID, second.User.ID)
+}
+
+func TestOIDCService_Callback_MapsGroupsToRoles(t *testing.T) {
+	ctx := context.Background()
+	svc, idp, s := newTestOIDCService(t, service.GroupRole{Group: "platform-admins", Role: domain.RoleAdmin})
+
+	idp.SetUser(map[string]interface{}{"sub": "abc123", "email": "jane@example.com", "groups": []string{"staff", "platform-admins"}})
+	_, err := signIn(t, svc, idp)
+	require.NoError(t, err)
+	user, err := s.userRepo.GetByEmail(ctx, "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, user.Role)
+
+	// Logging in again with the same role keeps the session
+	_, err = signIn(t, svc, idp)
+	require.NoError(t, err)
+	adminSessions, err := s.sessions.List(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, adminSessions, 2)
+
+	// Leaving the group downgrades the user at their next login, which ends
+	// the sessions holding admin tokens
+	idp.SetUser(map[string]interface{}{"sub": "abc123", "email": "jane@example.com", "groups": "staff"})
+	resp, err := signIn(t, svc, idp)
+	require.NoError(t, err)
+	user, err = s.userRepo.GetByEmail(ctx, "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleUser, user.Role)
+
+	for _, session := range adminSessions {
+		assert.ErrorIs(t, s.sessions.ValidateSession(ctx, user.ID.Hex(), session.ID), domain.ErrSessionRevoked)
+	}
+	claims, err := s.jwtManager.ValidateAccessToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.NoError(t, s.sessions.ValidateSession(ctx, user.ID.Hex(), claims.SessionID))
+}
+
+func TestOIDCService_Callback_RequiresVerifiedEmail(t *testing.T) {
+	svc, idp, _ := newTestOIDCService(t)
+	idp.SetUser(map[string]interface{}{"sub": "abc123", "email": "jane@example.com", "email_verified": false})
+
+	_, err := signIn(t, svc, idp)
+	assert.ErrorIs(t, err, domain.ErrSSOEmailRequired)
+}
+
+func TestOIDCService_Callback_RejectsWrongVerifier(t *testing.T) {
+	svc, idp, _ := newTestOIDCService(t)
+	idp.SetUser(map[string]interface{}{"sub": "abc123", "email": "jane@example.com"})
+
+	authURL, req := svc.AuthCodeURL()
+	redirect, err := idp.Authorize(authURL)
+	require.NoError(t, err)
+
+	// A code intercepted from another login can't be redeemed without its verifier
+	other := oidc.NewAuthRequest()
+	other.State, other.Nonce = req.State, req.Nonce
+	_, _, err = svc.Callback(context.Background(), redirect.Query().Get("code"), other, testClient)
+	assert.ErrorIs(t, err, domain.ErrSSOFailed)
+}
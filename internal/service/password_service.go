@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/pwned"
+)
+
+// PasswordPolicy configures the rules passwords must follow
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters
+	MinLength int
+	// MinClasses is how many of lowercase letters, uppercase letters, digits
+	// and symbols a password must use
+	MinClasses int
+	// MinStrength is the minimum estimated strength, from 0 (trivial) to 4 (strong)
+	MinStrength int
+	// History is how many recent passwords, including the current one, can't be reused
+	History int
+}
+
+// PasswordService enforces the password policy wherever passwords are set
+type PasswordService struct {
+	history  domain.PasswordHistoryRepository
+	breached pwned.Ranger
+	policy   PasswordPolicy
+}
+
+// NewPasswordService creates a new PasswordService. Passwords are only checked
+// against a breached password list when breached is not nil.
+func NewPasswordService(history domain.PasswordHistoryRepository, breached pwned.Ranger, policy PasswordPolicy) *PasswordService {
+	return &PasswordService{
+		history:  history,
+		breached: breached,
+		policy:   policy,
+	}
+}
+
+// Validate checks a new password for a user against the password policy,
+// returning a *domain.PasswordPolicyError listing every rule it breaks. Reuse
+// is only checked for users that already have a password.
+func (s *PasswordService) Validate(ctx context.Context, user *domain.User, password string) error {
+	if password == "" {
+		return &domain.PasswordPolicyError{Violations: []domain.PasswordViolation{
+			domain.NewPasswordViolation(domain.PasswordRuleRequired, domain.ErrPasswordRequired.Error(), domain.ErrPasswordRequired),
+		}}
+	}
+
+	var violations []domain.PasswordViolation
+	if utf8.RuneCountInString(password) < s.policy.MinLength {
+		violations = append(violations, domain.NewPasswordViolation(domain.PasswordRuleMinLength,
+			fmt.Sprintf("password must be at least %d characters", s.policy.MinLength), domain.ErrPasswordTooShort))
+	}
+	if len(password) > domain.MaxPasswordLength {
+		violations = append(violations, domain.NewPasswordViolation(domain.PasswordRuleMaxLength,
+			fmt.Sprintf("password must be at most %d bytes", domain.MaxPasswordLength), domain.ErrPasswordTooLong))
+	}
+	if classes := len(characterClasses(password)); classes < s.policy.MinClasses {
+		violations = append(violations, domain.NewPasswordViolation(domain.PasswordRuleCharacters,
+			fmt.Sprintf("password must use at least %d of lowercase letters, uppercase letters, digits and symbols", s.policy.MinClasses),
+			domain.ErrPasswordTooFewClasses))
+	}
+	if s.policy.MinStrength > 0 && passwordStrength(password, userInputs(user)...) < s.policy.MinStrength {
+		violations = append(violations, domain.NewPasswordViolation(domain.PasswordRuleStrength,
+			"password is too easy to guess", domain.ErrPasswordTooWeak))
+	}
+
+	if s.breached != nil {
+		breached, err := pwned.Breached(ctx, s.breached, password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			violations = append(violations, domain.NewPasswordViolation(domain.PasswordRuleBreached,
+				"password appears in a data breach and must not be used", domain.ErrPasswordBreached))
+		}
+	}
+
+	reused, err := s.reused(ctx, user, password)
+	if err != nil {
+		return err
+	}
+	if reused {
+		violations = append(violations, domain.NewPasswordViolation(domain.PasswordRuleReused,
+			fmt.Sprintf("password must not be one of the last %d passwords", s.policy.History), domain.ErrPasswordReused))
+	}
+
+	if len(violations) > 0 {
+		return &domain.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Set validates a new password for a user and sets it, remembering the
+// previous password so that it can't be reused. The caller saves the user.
+func (s *PasswordService) Set(ctx context.Context, user *domain.User, password string) error {
+	if err := s.Validate(ctx, user, password); err != nil {
+		return err
+	}
+
+	previous := user.PasswordHash
+	if err := user.SetPassword(password); err != nil {
+		return err
+	}
+
+	// The current password is checked on the user itself, so the history holds the ones before it
+	if previous != "" && !user.ID.IsZero() && s.policy.History > 1 {
+		return s.history.Add(ctx, user.ID.Hex(), previous, s.policy.History-1)
+	}
+	return nil
+}
+
+// reused reports whether a password is the current or a recent password of a user
+func (s *PasswordService) reused(ctx context.Context, user *domain.User, password string) (bool, error) {
+	if s.policy.History <= 0 || user == nil || user.PasswordHash == "" {
+		return false, nil
+	}
+	if user.CheckPassword(password) {
+		return true, nil
+	}
+	if user.ID.IsZero() || s.policy.History == 1 {
+		return false, nil
+	}
+
+	hashes, err := s.history.List(ctx, user.ID.Hex(), s.policy.History-1)
+	if err != nil {
+		return false, err
+	}
+	for _, hash := range hashes {
+		if (&domain.User{PasswordHash: hash}).CheckPassword(password) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// userInputs returns the details of a user that make a password easier to guess
+func userInputs(user *domain.User) []string {
+	if user == nil {
+		return nil
+	}
+	local, _, _ := strings.Cut(user.Email, "@")
+	return []string{local, user.FirstName, user.LastName}
+}
+
+// Number of characters in each character class of passwords
+const (
+	classLower  = 26
+	classUpper  = 26
+	classDigit  = 10
+	classSymbol = 33
+)
+
+// characterClasses returns the sizes of the character classes a password uses
+func characterClasses(password string) []int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	var classes []int
+	for _, c := range []struct {
+		used bool
+		size int
+	}{{lower, classLower}, {upper, classUpper}, {digit, classDigit}, {symbol, classSymbol}} {
+		if c.used {
+			classes = append(classes, c.size)
+		}
+	}
+	return classes
+}
+
+// passwordStrength roughly estimates how hard a password is to guess, from 0
+// (trivial) to 4 (strong). It estimates the entropy of a brute-force guess
+// over the character classes used, where parts of the user's details, and
+// characters continuing a repeated or sequential run like "aaa" or "123", add
+// nothing. It doesn't know dictionary words; the breached password list
+// catches common passwords.
+func passwordStrength(password string, userInputs ...string) int {
+	pool := 0
+	for _, size := range characterClasses(password) {
+		pool += size
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	reduced := strings.ToLower(password)
+	for _, input := range userInputs {
+		if input = strings.ToLower(strings.TrimSpace(input)); utf8.RuneCountInString(input) >= 3 {
+			reduced = strings.ReplaceAll(reduced, input, "\x00")
+		}
+	}
+
+	length := 0
+	runes := []rune(reduced)
+	for i, r := range runes {
+		if i >= 2 && r-runes[i-1] == runes[i-1]-runes[i-2] && abs(r-runes[i-1]) <= 1 {
+			continue
+		}
+		length++
+	}
+
+	bits := float64(length) * math.Log2(float64(pool))
+	switch {
+	case bits < 30:
+		return 0
+	case bits < 45:
+		return 1
+	case bits < 60:
+		return 2
+	case bits < 75:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func abs(r rune) rune {
+	if r < 0 {
+		return -r
+	}
+	return r
+}
@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testPasswordPolicy = service.PasswordPolicy{MinLength: 8, MinClasses: 1}
+
+func newTestPasswordService() *service.PasswordService {
+	return service.NewPasswordService(mocks.NewMockPasswordHistoryRepository(), nil, testPasswordPolicy)
+}
+
+// breachedList is an in-memory list of breached passwords
+type breachedList []string
+
+func (l breachedList) Range(ctx context.Context, prefix string) ([]string, error) {
+	var suffixes []string
+	for _, password := range l {
+		sum := sha1.Sum([]byte(password))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		if strings.HasPrefix(hash, prefix) {
+			suffixes = append(suffixes, hash[len(prefix):])
+		}
+	}
+	return suffixes, nil
+}
+
+// violatedRules returns the rules listed by a password policy error
+func violatedRules(t *testing.T, err error) []string {
+	t.Helper()
+	var policyErr *domain.PasswordPolicyError
+	require.True(t, errors.As(err, &policyErr), "not a password policy error: %v", err)
+
+	rules := make([]string, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		rules[i] = v.Rule
+	}
+	return rules
+}
+
+func TestPasswordService_ListsEveryViolation(t *testing.T) {
+	ctx := context.Background()
+	passwords := service.NewPasswordService(mocks.NewMockPasswordHistoryRepository(), breachedList{"abc"}, service.PasswordPolicy{
+		MinLength:   12,
+		MinClasses:  3,
+		MinStrength: 3,
+	})
+
+	err := passwords.Validate(ctx, nil, "abc")
+	assert.Equal(t, []string{domain.PasswordRuleMinLength, domain.PasswordRuleCharacters, domain.PasswordRuleStrength, domain.PasswordRuleBreached}, violatedRules(t, err))
+	assert.ErrorIs(t, err, domain.ErrPasswordPolicy)
+	assert.ErrorIs(t, err, domain.ErrPasswordTooShort)
+	assert.ErrorIs(t, err, domain.ErrPasswordBreached)
+	assert.Contains(t, err.Error(), "at least 12 characters")
+
+	assert.Equal(t, []string{domain.PasswordRuleRequired}, violatedRules(t, passwords.Validate(ctx, nil, "")))
+	assert.Equal(t, []string{domain.PasswordRuleMaxLength}, violatedRules(t, passwords.Validate(ctx, nil, "Aa1-"+strings.Repeat("x7Q", 30))))
+	require.NoError(t, passwords.Validate(ctx, nil, "Correct-Horse-Battery-9"))
+}
+
+func TestPasswordService_Strength(t *testing.T) {
+	ctx := context.Background()
+	passwords := service.NewPasswordService(mocks.NewMockPasswordHistoryRepository(), nil, service.PasswordPolicy{MinStrength: 3})
+	user := &domain.User{Email: "jsmith@example.com", FirstName: "John", LastName: "Smith"}
+
+	for _, weak := range []string{"aaaaaaaaaaaaaaaa", "1234567890abcdef", "password"} {
+		assert.ErrorIs(t, passwords.Validate(ctx, nil, weak), domain.ErrPasswordTooWeak, weak)
+	}
+	require.NoError(t, passwords.Validate(ctx, nil, "correct horse battery staple"))
+
+	// The user's own details don't make a password stronger
+	require.NoError(t, passwords.Validate(ctx, nil, "johnsmith2024!"))
+	assert.ErrorIs(t, passwords.Validate(ctx, user, "JohnSmith2024!"), domain.ErrPasswordTooWeak)
+}
+
+func TestPasswordService_History(t *testing.T) {
+	ctx := context.Background()
+	passwords := service.NewPasswordService(mocks.NewMockPasswordHistoryRepository(), nil, service.PasswordPolicy{History: 3})
+	user := &domain.User{ID: primitive.NewObjectID()}
+
+	require.NoError(t, passwords.Set(ctx, user, "first-password"))
+	require.NoError(t, passwords.Set(ctx, user, "second-password"))
+	require.NoError(t, passwords.Set(ctx, user, "third-password"))
+
+	// The current and the two previous passwords can't be reused
+	for _, reused := range []string{"third-password", "second-password", "first-password"} {
+		err := passwords.Set(ctx, user, reused)
+		assert.ErrorIs(t, err, domain.ErrPasswordReused, reused)
+		assert.Equal(t, []string{domain.PasswordRuleReused}, violatedRules(t, err))
+	}
+
+	require.NoError(t, passwords.Set(ctx, user, "fourth-password"))
+	require.NoError(t, passwords.Set(ctx, user, "first-password"))
+}
+
+func TestUserService_ChangePasswordFollowsPolicy(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServices()
+	svc, userSvc := s.auth, s.users
+	userID := registerTestUser(t, svc).User.ID
+
+	err := userSvc.ChangePassword(ctx, userID, domain.ChangePasswordRequest{CurrentPassword: "securepassword123", NewPassword: "short"})
+	assert.Equal(t, []string{domain.PasswordRuleMinLength}, violatedRules(t, err))
+
+	// The current password is checked first
+	err = userSvc.ChangePassword(ctx, userID, domain.ChangePasswordRequest{CurrentPassword: "wrong-password", NewPassword: "newpassword123"})
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	require.NoError(t, userSvc.ChangePassword(ctx, userID, domain.ChangePasswordRequest{CurrentPassword: "securepassword123", NewPassword: "newpassword123"}))
+}
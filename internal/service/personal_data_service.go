@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// personalDataAPIKeyPage is how many API keys are read at a time when
+// building an export
+const personalDataAPIKeyPage = 100
+
+// PersonalDataService lets users download what is stored about them and
+// delete their own account. Deletion is scheduled rather than immediate so
+// that it can be cancelled during a grace period; PurgeDue removes the
+// accounts whose grace period has ended.
+type PersonalDataService struct {
+	userRepo    domain.UserRepository
+	users       *UserService
+	teamRepo    domain.TeamRepository
+	sessions    *SessionService
+	apiKeyRepo  domain.APIKeyRepository
+	serviceRepo domain.ServiceRepository
+	versionRepo domain.ServiceVersionRepository
+	gracePeriod time.Duration
+	audit       *AuditService
+}
+
+// NewPersonalDataService creates a new PersonalDataService
+func NewPersonalDataService(userRepo domain.UserRepository, users *UserService, teamRepo domain.TeamRepository, sessions *SessionService, apiKeyRepo domain.APIKeyRepository, serviceRepo domain.ServiceRepository, versionRepo domain.ServiceVersionRepository, gracePeriod time.Duration, audit *AuditService) *PersonalDataService {
+	return &PersonalDataService{
+		userRepo:    userRepo,
+		users:       users,
+		teamRepo:    teamRepo,
+		sessions:    sessions,
+		apiKeyRepo:  apiKeyRepo,
+		serviceRepo: serviceRepo,
+		versionRepo: versionRepo,
+		gracePeriod: gracePeriod,
+		audit:       audit,
+	}
+}
+
+// Export collects everything stored about a user
+func (s *PersonalDataService) Export(ctx context.Context, userID string) (*domain.PersonalDataExport, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := s.teamRepo.ListByMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessions.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := s.exportAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versionRepo.ListByAuthor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := s.exportServices(ctx, versions)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &domain.PersonalDataExport{
+		ExportedAt:      time.Now(),
+		User:            user.ToResponse(),
+		Teams:           domain.NewCurrentUserResponse(user, teams).Teams,
+		Sessions:        make([]domain.SessionResponse, 0, len(sessions)),
+		APIKeys:         apiKeys,
+		Services:        services,
+		ServiceVersions: make([]domain.ServiceVersionResponse, 0, len(versions)),
+	}
+	for i := range sessions {
+		export.Sessions = append(export.Sessions, sessions[i].ToResponse(""))
+	}
+	for i := range versions {
+		export.ServiceVersions = append(export.ServiceVersions, versions[i].ToResponse())
+	}
+
+	return export, nil
+}
+
+// ScheduleDeletion marks a user's account for deletion once the grace period
+// ends and signs them out everywhere. Users with a password must confirm it.
+// Scheduling an account that is already pending keeps the original date.
+func (s *PersonalDataService) ScheduleDeletion(ctx context.Context, userID string, req domain.DeleteAccountRequest) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.PasswordHash != "" {
+		if req.Password == "" {
+			return nil, domain.ErrPasswordRequired
+		}
+		if !user.CheckPassword(req.Password) {
+			return nil, domain.ErrInvalidCredentials
+		}
+	}
+
+	if user.DeletionScheduledAt != nil {
+		return user, nil
+	}
+
+	scheduledAt := time.Now().Add(s.gracePeriod)
+	user.DeletionScheduledAt = &scheduledAt
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionUserDeletionSchedule,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   userID,
+		After:      map[string]string{"deletion_scheduled_at": scheduledAt.UTC().Format(time.RFC3339)},
+	})
+
+	if err := s.sessions.revokeAll(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CancelDeletion keeps an account that was scheduled for deletion
+func (s *PersonalDataService) CancelDeletion(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return nil, domain.ErrDeletionNotPending
+	}
+
+	user.DeletionScheduledAt = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionUserDeletionCancel,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   userID,
+	})
+
+	return user, nil
+}
+
+// PurgeDue deletes the accounts whose grace period ended by now and returns
+// how many were deleted. A failure to delete one account does not stop the
+// others; it is retried on the next run.
+func (s *PersonalDataService) PurgeDue(ctx context.Context) (int, error) {
+	users, err := s.userRepo.ListDeletionDue(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for i := range users {
+		id := users[i].ID.Hex()
+		if err := s.users.Delete(ctx, id); err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				continue
+			}
+			logging.FromContext(ctx).Error("Failed to delete account", "target_user_id", id, "error", err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// exportAPIKeys reads all of a user's API keys, a page at a time
+func (s *PersonalDataService) exportAPIKeys(ctx context.Context, userID string) ([]domain.APIKeyResponse, error) {
+	keys := []domain.APIKeyResponse{}
+	params := domain.PaginationParams{Page: 1, Limit: personalDataAPIKeyPage}
+	for {
+		result, err := s.apiKeyRepo.List(ctx, userID, params)
+		if err != nil {
+			return nil, err
+		}
+		for i := range result.Data {
+			keys = append(keys, result.Data[i].ToResponse())
+		}
+		if len(result.Data) < params.Limit {
+			return keys, nil
+		}
+		params.Page++
+	}
+}
+
+// exportServices returns the services the user created or edited, in the
+// order they first touched them. Services deleted since are left out.
+func (s *PersonalDataService) exportServices(ctx context.Context, versions []domain.ServiceVersion) ([]domain.ServiceResponse, error) {
+	services := []domain.ServiceResponse{}
+	seen := make(map[primitive.ObjectID]bool)
+	for i := range versions {
+		if seen[versions[i].ServiceID] {
+			continue
+		}
+		seen[versions[i].ServiceID] = true
+
+		service, err := s.serviceRepo.GetByID(ctx, versions[i].ServiceID.Hex())
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		services = append(services, service.ToResponse())
+	}
+	return services, nil
+}
@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalDataService_Export(t *testing.T) {
+	f := newTestServices()
+	login := registerTestUser(t, f.auth)
+	user, err := f.userRepo.GetByID(context.Background(), login.User.ID)
+	require.NoError(t, err)
+	ctx := callerContext(user)
+
+	created, err := f.services.Create(ctx, domain.CreateServiceRequest{Name: "payments", Description: "Payments"})
+	require.NoError(t, err)
+	_, err = f.services.Update(ctx, created.ID.Hex(), domain.UpdateServiceRequest{Name: "payments", Description: "Card payments"})
+	require.NoError(t, err)
+	_, err = f.services.Create(context.Background(), domain.CreateServiceRequest{Name: "billing", Description: "Someone else's"})
+	require.NoError(t, err)
+	_, _, err = f.apiKeys.Create(ctx, login.User.ID, domain.CreateAPIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeServicesRead}})
+	require.NoError(t, err)
+
+	export, err := f.personalData.Export(ctx, login.User.ID)
+	require.NoError(t, err)
+	assert.Equal(t, login.User.Email, export.User.Email)
+	assert.Len(t, export.Sessions, 1)
+	require.Len(t, export.APIKeys, 1)
+	assert.Equal(t, "ci", export.APIKeys[0].Name)
+	require.Len(t, export.Services, 1)
+	assert.Equal(t, "Card payments", export.Services[0].Description)
+	require.Len(t, export.ServiceVersions, 2)
+	assert.Equal(t, 1, export.ServiceVersions[0].Revision)
+	assert.Equal(t, 2, export.ServiceVersions[1].Revision)
+	assert.Equal(t, login.User.ID, export.ServiceVersions[0].AuthorID)
+	assert.NotNil(t, export.Teams)
+}
+
+func TestPersonalDataService_ScheduleAndCancelDeletion(t *testing.T) {
+	ctx := context.Background()
+	f := newTestServices()
+	login := registerTestUser(t, f.auth)
+
+	_, err := f.personalData.ScheduleDeletion(ctx, login.User.ID, domain.DeleteAccountRequest{})
+	assert.ErrorIs(t, err, domain.ErrPasswordRequired)
+	_, err = f.personalData.ScheduleDeletion(ctx, login.User.ID, domain.DeleteAccountRequest{Password: "wrongpassword123"})
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	user, err := f.personalData.ScheduleDeletion(ctx, login.User.ID, domain.DeleteAccountRequest{Password: "securepassword123"})
+	require.NoError(t, err)
+	require.NotNil(t, user.DeletionScheduledAt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *user.DeletionScheduledAt, time.Minute)
+	scheduledAt := *user.DeletionScheduledAt
+
+	// Scheduling signs the user out everywhere
+	_, err = f.auth.RefreshToken(ctx, login.RefreshToken, testClient)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	// Scheduling again keeps the original date
+	user, err = f.personalData.ScheduleDeletion(ctx, login.User.ID, domain.DeleteAccountRequest{Password: "securepassword123"})
+	require.NoError(t, err)
+	assert.Equal(t, scheduledAt, *user.DeletionScheduledAt)
+
+	// The grace period has not ended, so nothing is purged
+	purged, err := f.personalData.PurgeDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	user, err = f.personalData.CancelDeletion(ctx, login.User.ID)
+	require.NoError(t, err)
+	assert.Nil(t, user.DeletionScheduledAt)
+
+	_, err = f.personalData.CancelDeletion(ctx, login.User.ID)
+	assert.ErrorIs(t, err, domain.ErrDeletionNotPending)
+}
+
+func TestPersonalDataService_PurgeAnonymizesHistory(t *testing.T) {
+	f := newTestServices(withGracePeriod(0))
+	login := registerTestUser(t, f.auth)
+	user, err := f.userRepo.GetByID(context.Background(), login.User.ID)
+	require.NoError(t, err)
+	ctx := callerContext(user)
+
+	created, err := f.services.Create(ctx, domain.CreateServiceRequest{Name: "payments", Description: "Payments"})
+	require.NoError(t, err)
+	_, plaintext, err := f.apiKeys.Create(ctx, login.User.ID, domain.CreateAPIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeServicesRead}})
+	require.NoError(t, err)
+
+	_, err = f.personalData.ScheduleDeletion(ctx, login.User.ID, domain.DeleteAccountRequest{Password: "securepassword123"})
+	require.NoError(t, err)
+
+	// API keys stop working as soon as deletion is scheduled
+	_, err = f.apiKeys.AuthenticateAPIKey(ctx, plaintext)
+	assert.ErrorIs(t, err, domain.ErrAPIKeyOwnerInactive)
+
+	purged, err := f.personalData.PurgeDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = f.userRepo.GetByID(ctx, login.User.ID)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	// The history of the service is kept without its author
+	version, err := f.versionRepo.GetByServiceIDAndRevision(ctx, created.ID.Hex(), 1)
+	require.NoError(t, err)
+	assert.Nil(t, version.AuthorID)
+	assert.True(t, version.AuthorDeleted)
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/auth"
+)
+
+// maxRoleDescriptionLength is the longest description a role can have
+const maxRoleDescriptionLength = 200
+
+// RoleService manages roles and authorizes requests against the permissions
+// of the caller's role
+type RoleService struct {
+	roleRepo domain.RoleRepository
+	userRepo domain.UserRepository
+	audit    *AuditService
+}
+
+// NewRoleService creates a new RoleService
+func NewRoleService(roleRepo domain.RoleRepository, userRepo domain.UserRepository, audit *AuditService) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		audit:    audit,
+	}
+}
+
+// EnsureBuiltInRoles creates the built-in roles that don't exist yet. Roles
+// that exist keep their edited permissions, except admin, which always has
+// every permission.
+func (s *RoleService) EnsureBuiltInRoles(ctx context.Context) error {
+	for _, def := range auth.BuiltInRoles {
+		err := s.roleRepo.Create(ctx, &domain.Role{
+			Name:        def.Name,
+			Description: def.Description,
+			Permissions: def.Permissions,
+			BuiltIn:     true,
+		})
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, domain.ErrRoleExists) {
+			return err
+		}
+
+		if def.Name == domain.RoleAdmin {
+			role, err := s.roleRepo.GetByName(ctx, def.Name)
+			if err != nil {
+				return err
+			}
+			if !sameScopes(role.Permissions, def.Permissions) {
+				role.Permissions = def.Permissions
+				if err := s.roleRepo.Update(ctx, role); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// List retrieves all roles
+func (s *RoleService) List(ctx context.Context) ([]domain.Role, error) {
+	return s.roleRepo.List(ctx)
+}
+
+// GetByName retrieves a role by its name
+func (s *RoleService) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	return s.roleRepo.GetByName(ctx, name)
+}
+
+// Create creates a custom role
+func (s *RoleService) Create(ctx context.Context, req domain.CreateRoleRequest) (*domain.Role, error) {
+	name := strings.TrimSpace(req.Name)
+	if !domain.IsValidRoleName(name) {
+		return nil, domain.ErrRoleNameInvalid
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if len(description) > maxRoleDescriptionLength {
+		return nil, domain.ErrRoleDescriptionTooLong
+	}
+
+	permissions, err := validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &domain.Role{
+		Name:        name,
+		Description: description,
+		Permissions: permissions,
+	}
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionRoleCreate,
+		TargetType: domain.AuditTargetRole,
+		TargetID:   role.Name,
+		After:      roleAuditSummary(role),
+	})
+	return role, nil
+}
+
+// Update replaces the description and permissions of a role. The admin role
+// can't be changed so that admins can't lock themselves out.
+func (s *RoleService) Update(ctx context.Context, name string, req domain.UpdateRoleRequest) (*domain.Role, error) {
+	if name == domain.RoleAdmin {
+		return nil, domain.ErrRoleImmutable
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if len(description) > maxRoleDescriptionLength {
+		return nil, domain.ErrRoleDescriptionTooLong
+	}
+
+	permissions, err := validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	before := roleAuditSummary(role)
+	role.Description = description
+	role.Permissions = permissions
+	if err := s.roleRepo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionRoleUpdate,
+		TargetType: domain.AuditTargetRole,
+		TargetID:   role.Name,
+		Before:     before,
+		After:      roleAuditSummary(role),
+	})
+	return role, nil
+}
+
+// Delete deletes a custom role that is not assigned to any user
+func (s *RoleService) Delete(ctx context.Context, name string) error {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return domain.ErrRoleBuiltIn
+	}
+
+	count, err := s.userRepo.CountByRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrRoleInUse
+	}
+
+	if err := s.roleRepo.Delete(ctx, name); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionRoleDelete,
+		TargetType: domain.AuditTargetRole,
+		TargetID:   role.Name,
+		Before:     roleAuditSummary(role),
+	})
+	return nil
+}
+
+// ValidateRole returns domain.ErrInvalidRole unless the role exists
+func (s *RoleService) ValidateRole(ctx context.Context, name string) error {
+	_, err := s.roleRepo.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrRoleNotFound) {
+		return domain.ErrInvalidRole
+	}
+	return err
+}
+
+// Permissions returns the permissions granted by a role. Unknown roles grant none.
+func (s *RoleService) Permissions(ctx context.Context, name string) ([]string, error) {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, domain.ErrRoleNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return role.Permissions, nil
+}
+
+// Authorize checks that the caller holds every permission. The caller holds
+// a permission when it was granted to their token or API key and, for user
+// tokens, their role still grants it, so permissions removed from a role stop
+// working immediately. It implements auth.Authorizer.
+func (s *RoleService) Authorize(ctx context.Context, permissions ...string) error {
+	granted, _ := auth.GetScopes(ctx)
+	if role, ok := auth.GetUserRole(ctx); ok {
+		rolePermissions, err := s.Permissions(ctx, role)
+		if err != nil {
+			return err
+		}
+		granted = intersectScopes(granted, rolePermissions)
+	}
+
+	var missing []string
+	for _, permission := range permissions {
+		if !containsScope(granted, permission) {
+			missing = append(missing, permission)
+		}
+	}
+	if len(missing) > 0 {
+		return &auth.PermissionError{Missing: missing}
+	}
+
+	return nil
+}
+
+// validatePermissions checks that permissions are known, returning them
+// without duplicates
+func validatePermissions(permissions []string) ([]string, error) {
+	result := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !auth.IsValidScope(permission) {
+			return nil, domain.ErrInvalidPermission
+		}
+		if !containsScope(result, permission) {
+			result = append(result, permission)
+		}
+	}
+	return result, nil
+}
+
+// sameScopes checks if two scope lists contain the same scopes
+func sameScopes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, scope := range a {
+		if !containsScope(b, scope) {
+			return false
+		}
+	}
+	return true
+}
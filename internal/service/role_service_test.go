@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRoleService creates a RoleService seeded with the built-in roles
+func newTestRoleService(userRepo domain.UserRepository) *service.RoleService {
+	roles := service.NewRoleService(mocks.NewMockRoleRepository(), userRepo, nil)
+	if err := roles.EnsureBuiltInRoles(context.Background()); err != nil {
+		panic(err)
+	}
+	return roles
+}
+
+// userContext returns the context of a request made with a user token
+func userContext(role string, scopes ...string) context.Context {
+	ctx := context.WithValue(context.Background(), auth.UserRoleKey, role)
+	return context.WithValue(ctx, auth.ScopesKey, scopes)
+}
+
+func TestRoleService_Authorize(t *testing.T) {
+	ctx := context.Background()
+	roles := newTestRoleService(mocks.NewMockUserRepository())
+
+	editor := userContext(domain.RoleEditor, auth.ScopeServicesRead, auth.ScopeServicesWrite)
+	assert.NoError(t, roles.Authorize(editor, auth.ScopeServicesRead, auth.ScopeServicesWrite))
+
+	err := roles.Authorize(editor, auth.ScopeServicesWrite, auth.ScopeServicesAdmin)
+	var permissionErr *auth.PermissionError
+	require.ErrorAs(t, err, &permissionErr)
+	assert.Equal(t, []string{auth.ScopeServicesAdmin}, permissionErr.Missing)
+
+	// Permissions removed from a role stop working for tokens already issued
+	_, err = roles.Update(ctx, domain.RoleEditor, domain.UpdateRoleRequest{Permissions: []string{auth.ScopeServicesRead}})
+	require.NoError(t, err)
+	assert.Error(t, roles.Authorize(editor, auth.ScopeServicesWrite))
+	assert.NoError(t, roles.Authorize(editor, auth.ScopeServicesRead))
+
+	// Permissions added to a role need a new token
+	_, err = roles.Update(ctx, domain.RoleEditor, domain.UpdateRoleRequest{Permissions: auth.AllScopes})
+	require.NoError(t, err)
+	assert.Error(t, roles.Authorize(editor, auth.ScopeUsersAdmin))
+
+	// API keys are limited to their scopes
+	apiKey := context.WithValue(ctx, auth.ScopesKey, []string{auth.ScopeServicesRead})
+	assert.NoError(t, roles.Authorize(apiKey, auth.ScopeServicesRead))
+	assert.Error(t, roles.Authorize(apiKey, auth.ScopeServicesWrite))
+
+	// Roles that no longer exist grant nothing
+	assert.Error(t, roles.Authorize(userContext("deleted-role", auth.ScopeServicesRead), auth.ScopeServicesRead))
+}
+
+func TestRoleService_CreateUpdateDelete(t *testing.T) {
+	ctx := context.Background()
+	userRepo := mocks.NewMockUserRepository()
+	roles := newTestRoleService(userRepo)
+
+	role, err := roles.Create(ctx, domain.CreateRoleRequest{
+		Name:        "release-manager",
+		Description: " Releases services ",
+		Permissions: []string{auth.ScopeServicesRead, auth.ScopeServicesWrite, auth.ScopeServicesRead},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Releases services", role.Description)
+	assert.Equal(t, []string{auth.ScopeServicesRead, auth.ScopeServicesWrite}, role.Permissions)
+	assert.False(t, role.BuiltIn)
+
+	_, err = roles.Create(ctx, domain.CreateRoleRequest{Name: "release-manager"})
+	assert.ErrorIs(t, err, domain.ErrRoleExists)
+	_, err = roles.Create(ctx, domain.CreateRoleRequest{Name: "Release Manager"})
+	assert.ErrorIs(t, err, domain.ErrRoleNameInvalid)
+	_, err = roles.Create(ctx, domain.CreateRoleRequest{Name: "deployer", Permissions: []string{"services:deploy"}})
+	assert.ErrorIs(t, err, domain.ErrInvalidPermission)
+
+	// The admin role can't be changed and built-in roles can't be deleted
+	_, err = roles.Update(ctx, domain.RoleAdmin, domain.UpdateRoleRequest{Permissions: []string{auth.ScopeServicesRead}})
+	assert.ErrorIs(t, err, domain.ErrRoleImmutable)
+	assert.ErrorIs(t, roles.Delete(ctx, domain.RoleViewer), domain.ErrRoleBuiltIn)
+
+	// Roles assigned to users can't be deleted
+	user := &domain.User{Email: "release@example.com", Role: "release-manager", Active: true}
+	require.NoError(t, userRepo.Create(ctx, user))
+	assert.ErrorIs(t, roles.Delete(ctx, "release-manager"), domain.ErrRoleInUse)
+
+	require.NoError(t, userRepo.Delete(ctx, user.ID.Hex()))
+	require.NoError(t, roles.Delete(ctx, "release-manager"))
+	assert.ErrorIs(t, roles.ValidateRole(ctx, "release-manager"), domain.ErrInvalidRole)
+}
+
+func TestRoleService_EnsureBuiltInRoles(t *testing.T) {
+	ctx := context.Background()
+	roleRepo := mocks.NewMockRoleRepository()
+	roles := service.NewRoleService(roleRepo, mocks.NewMockUserRepository(), nil)
+	require.NoError(t, roles.EnsureBuiltInRoles(ctx))
+
+	// Edited built-in roles are kept, but admin is reset to every permission
+	_, err := roles.Update(ctx, domain.RoleViewer, domain.UpdateRoleRequest{Permissions: []string{}})
+	require.NoError(t, err)
+	admin, err := roleRepo.GetByName(ctx, domain.RoleAdmin)
+	require.NoError(t, err)
+	admin.Permissions = []string{auth.ScopeServicesRead}
+	require.NoError(t, roleRepo.Update(ctx, admin))
+
+	require.NoError(t, roles.EnsureBuiltInRoles(ctx))
+
+	viewer, err := roles.GetByName(ctx, domain.RoleViewer)
+	require.NoError(t, err)
+	assert.Empty(t, viewer.Permissions)
+	admin, err = roles.GetByName(ctx, domain.RoleAdmin)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, auth.AllScopes, admin.Permissions)
+
+	list, err := roles.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, len(auth.BuiltInRoles))
+}
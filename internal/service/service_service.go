@@ -3,22 +3,36 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/logging"
+	"github.com/services-api/pkg/metrics"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// A snapshot waits for the snapshot of the previous revision, which a
+// concurrent change may still be storing, before failing
+const (
+	versionChainAttempts   = 5
+	versionChainRetryDelay = 20 * time.Millisecond
+)
+
 // ServiceService handles business logic for services
 type ServiceService struct {
 	serviceRepo domain.ServiceRepository
 	versionRepo domain.ServiceVersionRepository
+	teams       *TeamService
+	audit       *AuditService
 }
 
 // NewServiceService creates a new ServiceService
-func NewServiceService(serviceRepo domain.ServiceRepository, versionRepo domain.ServiceVersionRepository) *ServiceService {
+func NewServiceService(serviceRepo domain.ServiceRepository, versionRepo domain.ServiceVersionRepository, teams *TeamService, audit *AuditService) *ServiceService {
 	return &ServiceService{
 		serviceRepo: serviceRepo,
 		versionRepo: versionRepo,
+		teams:       teams,
+		audit:       audit,
 	}
 }
 
@@ -29,23 +43,38 @@ func (s *ServiceService) Create(ctx context.Context, req domain.CreateServiceReq
 		return nil, err
 	}
 
+	teamID, err := s.assignTeam(ctx, req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
 	service := &domain.Service{
 		Name:        req.Name,
 		Description: req.Description,
+		TeamID:      teamID,
 	}
 
 	if err := s.serviceRepo.Create(ctx, service); err != nil {
 		return nil, err
 	}
 
-	// Create initial version snapshot (revision 1)
-	version := domain.NewServiceVersion(service)
-	if err := s.versionRepo.Create(ctx, version); err != nil {
-		// Log error but don't fail the service creation
-		// In production, you might want to handle this differently
-		return service, nil
+	// Create initial version snapshot (revision 1). A service is only kept
+	// with its snapshot, so that failing requests can be retried.
+	if err := s.createVersion(ctx, service); err != nil {
+		logVersionError(ctx, service, err)
+		if err := s.serviceRepo.Delete(context.WithoutCancel(ctx), service.ID.Hex()); err != nil {
+			logging.FromContext(ctx).Error("Failed to remove service without a version",
+				"service_id", service.ID.Hex(), "error", err)
+		}
+		return nil, err
 	}
 
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionServiceCreate,
+		TargetType: domain.AuditTargetService,
+		TargetID:   service.ID.Hex(),
+		After:      serviceAuditSummary(service),
+	})
 	return service, nil
 }
 
@@ -67,29 +96,22 @@ func (s *ServiceService) Update(ctx context.Context, id string, req domain.Updat
 		return nil, err
 	}
 
-	// Update fields
-	service.Name = req.Name
-	service.Description = req.Description
-
-	if err := s.serviceRepo.Update(ctx, service); err != nil {
+	// Only the owning team can change a service or hand it to another team
+	if err := s.teams.AuthorizeService(ctx, service.TeamID); err != nil {
 		return nil, err
 	}
-
-	// Re-fetch to get updated timestamps and revision
-	updatedService, err := s.serviceRepo.GetByID(ctx, id)
+	teamID, err := s.assignTeam(ctx, req.TeamID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create version snapshot with the new state
-	version := domain.NewServiceVersion(updatedService)
-	if err := s.versionRepo.Create(ctx, version); err != nil {
-		// Log error but don't fail the update
-		// In production, you might want to handle this differently
-		return updatedService, nil
-	}
+	// Update fields
+	previous := *service
+	service.Name = req.Name
+	service.Description = req.Description
+	service.TeamID = teamID
 
-	return updatedService, nil
+	return s.update(ctx, &previous, service)
 }
 
 // Patch performs a partial update of a service (increments revision and creates version snapshot)
@@ -100,7 +122,13 @@ func (s *ServiceService) Patch(ctx context.Context, id string, req domain.PatchS
 		return nil, err
 	}
 
+	// Only the owning team can change a service or hand it to another team
+	if err := s.teams.AuthorizeService(ctx, service.TeamID); err != nil {
+		return nil, err
+	}
+
 	// Update only provided fields
+	previous := *service
 	if req.Name != nil {
 		if len(*req.Name) == 0 {
 			return nil, domain.ErrNameRequired
@@ -121,40 +149,48 @@ func (s *ServiceService) Patch(ctx context.Context, id string, req domain.PatchS
 		service.Description = *req.Description
 	}
 
-	if err := s.serviceRepo.Update(ctx, service); err != nil {
-		return nil, err
-	}
-
-	// Re-fetch to get updated timestamps and revision
-	updatedService, err := s.serviceRepo.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create version snapshot with the new state
-	version := domain.NewServiceVersion(updatedService)
-	if err := s.versionRepo.Create(ctx, version); err != nil {
-		// Log error but don't fail the update
-		// In production, you might want to handle this differently
-		return updatedService, nil
+	if req.TeamID != nil {
+		teamID, err := s.assignTeam(ctx, *req.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		service.TeamID = teamID
 	}
 
-	return updatedService, nil
+	return s.update(ctx, &previous, service)
 }
 
-// Delete deletes a service and all its versions
+// Delete deletes a service. Its versions are kept, ending with a tombstone
+// version that records the deletion, so that its history stays verifiable.
 func (s *ServiceService) Delete(ctx context.Context, id string) error {
 	// Validate ID format
 	if _, err := primitive.ObjectIDFromHex(id); err != nil {
 		return domain.ErrInvalidID
 	}
 
-	// Delete all versions first
-	if err := s.versionRepo.DeleteByServiceID(ctx, id); err != nil {
-		// Log error but continue with service deletion
+	service, err := s.serviceRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	tombstone := domain.NewServiceTombstone(service, versionAuthor(ctx))
+	if err := s.appendVersion(ctx, tombstone); err != nil {
+		logging.FromContext(ctx).Error("Failed to create service tombstone version",
+			"service_id", id, "revision", tombstone.Revision, "error", err)
+		return err
 	}
 
-	return s.serviceRepo.Delete(ctx, id)
+	if err := s.serviceRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionServiceDelete,
+		TargetType: domain.AuditTargetService,
+		TargetID:   id,
+		Before:     serviceAuditSummary(service),
+	})
+	return nil
 }
 
 // List retrieves services with filtering, sorting, and pagination
@@ -164,6 +200,13 @@ func (s *ServiceService) List(ctx context.Context, params domain.ListParams) (*d
 		return nil, domain.ErrInvalidSortField
 	}
 
+	// Validate team filter
+	if params.Team != "" {
+		if _, err := primitive.ObjectIDFromHex(params.Team); err != nil {
+			return nil, domain.ErrInvalidID
+		}
+	}
+
 	// Apply defaults
 	if params.Sort == "" {
 		params.Sort = "created_at"
@@ -186,6 +229,19 @@ func (s *ServiceService) List(ctx context.Context, params domain.ListParams) (*d
 	return s.serviceRepo.List(ctx, params)
 }
 
+// Count returns the number of services
+func (s *ServiceService) Count(ctx context.Context) (int64, error) {
+	result, err := s.serviceRepo.List(ctx, domain.ListParams{
+		Sort:       "created_at",
+		Order:      "desc",
+		Pagination: domain.PaginationParams{Page: 1, Limit: 1},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.Pagination.Total, nil
+}
+
 // GetVersions retrieves all versions for a service
 func (s *ServiceService) GetVersions(ctx context.Context, serviceID string, params domain.PaginationParams) (*domain.PaginatedResult[domain.ServiceVersion], error) {
 	// Verify service exists
@@ -219,6 +275,156 @@ func (s *ServiceService) GetVersion(ctx context.Context, serviceID string, revis
 	return s.versionRepo.GetByServiceIDAndRevision(ctx, serviceID, revision)
 }
 
+// VerifyVersions walks the hash chain of a service's versions, newest first,
+// and reports the oldest broken link. The versions of a deleted service remain
+// behind its tombstone, so its chain can still be verified.
+func (s *ServiceService) VerifyVersions(ctx context.Context, serviceID string) (*domain.ChainVerification, error) {
+	var verifier domain.ChainVerifier
+	params := domain.PaginationParams{Page: 1, Limit: 100}
+	for {
+		result, err := s.versionRepo.ListByServiceID(ctx, serviceID, params)
+		if err != nil {
+			return nil, err
+		}
+		if result.Pagination.Total == 0 {
+			// Without versions there is only a chain to report if the service exists
+			if _, err := s.serviceRepo.GetByID(ctx, serviceID); err != nil {
+				return nil, err
+			}
+		}
+		for i := range result.Data {
+			verifier.Add(result.Data[i].ChainLink())
+		}
+		if params.Page >= result.Pagination.TotalPages {
+			return verifier.Result(), nil
+		}
+		params.Page++
+	}
+}
+
+// update stores the changed state of a service together with its version
+// snapshot. If the snapshot can't be stored, the service is restored to its
+// previous state, so that it doesn't keep a revision without history.
+func (s *ServiceService) update(ctx context.Context, previous, service *domain.Service) (*domain.Service, error) {
+	if err := s.serviceRepo.Update(ctx, service); err != nil {
+		return nil, err
+	}
+
+	if err := s.createVersion(ctx, service); err != nil {
+		logVersionError(ctx, service, err)
+		if err := s.serviceRepo.Restore(context.WithoutCancel(ctx), previous); err != nil {
+			// A concurrent update went ahead; its snapshot starts a new
+			// segment of the chain, and verification reports the gap
+			logging.FromContext(ctx).Error("Failed to restore service without a version",
+				"service_id", service.ID.Hex(), "revision", service.Revision, "error", err)
+		}
+		return nil, err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionServiceUpdate,
+		TargetType: domain.AuditTargetService,
+		TargetID:   service.ID.Hex(),
+		Before:     serviceAuditSummary(previous),
+		After:      serviceAuditSummary(service),
+	})
+
+	// Re-fetch to get updated timestamps
+	return s.serviceRepo.GetByID(ctx, service.ID.Hex())
+}
+
+// logVersionError logs a failure to snapshot a service
+func logVersionError(ctx context.Context, service *domain.Service, err error) {
+	logging.FromContext(ctx).Error("Failed to create service version",
+		"service_id", service.ID.Hex(), "revision", service.Revision, "error", err)
+}
+
+// createVersion records a snapshot of a service, chained onto the snapshot of
+// its previous revision
+func (s *ServiceService) createVersion(ctx context.Context, service *domain.Service) error {
+	return s.appendVersion(ctx, domain.NewServiceVersion(service, versionAuthor(ctx)))
+}
+
+// appendVersion chains a version onto the one of the previous revision and
+// stores it. A concurrent change may still be storing that version, so it is
+// waited for; if it doesn't show up in time it was lost, for example by a
+// change made before versions were stored with their service, and the version
+// starts a new segment of the chain. Verification reports the gap.
+func (s *ServiceService) appendVersion(ctx context.Context, version *domain.ServiceVersion) error {
+	for attempt := 1; ; attempt++ {
+		err := s.chainVersion(ctx, version, attempt == versionChainAttempts)
+		if err == nil {
+			metrics.VersionsCreated.Inc()
+			return nil
+		}
+		if !errors.Is(err, domain.ErrPreviousVersionMissing) || attempt == versionChainAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(versionChainRetryDelay):
+		}
+	}
+}
+
+// chainVersion sets the previous and own hash of a version and stores it.
+// The repository only stores it if the previous version still has that hash.
+// Unless allowGap is set, it fails with domain.ErrPreviousVersionMissing if
+// there is no previous version.
+func (s *ServiceService) chainVersion(ctx context.Context, version *domain.ServiceVersion, allowGap bool) error {
+	version.PrevHash = ""
+	if version.Revision > 1 {
+		prev, err := s.versionRepo.GetByServiceIDAndRevision(ctx, version.ServiceID.Hex(), version.Revision-1)
+		switch {
+		case err == nil:
+			version.PrevHash = prev.Hash
+		case !errors.Is(err, domain.ErrNotFound):
+			return err
+		case !allowGap:
+			return domain.ErrPreviousVersionMissing
+		default:
+			logging.FromContext(ctx).Warn("Previous service version is missing, starting a new chain segment",
+				"service_id", version.ServiceID.Hex(), "revision", version.Revision)
+		}
+	}
+	version.Hash = version.ComputeHash()
+
+	return s.versionRepo.Create(ctx, version)
+}
+
+// assignTeam resolves the team a request assigns a service to, which the
+// caller must be allowed to change services of. An empty ID means no team.
+func (s *ServiceService) assignTeam(ctx context.Context, id string) (*primitive.ObjectID, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	teamID, err := s.teams.ValidateTeamID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.teams.AuthorizeService(ctx, teamID); err != nil {
+		return nil, err
+	}
+	return teamID, nil
+}
+
+// versionAuthor returns the user to record as the author of a change made by
+// the caller, if the caller is a user or an API key
+func versionAuthor(ctx context.Context) *primitive.ObjectID {
+	caller, ok := callerID(ctx)
+	if !ok {
+		return nil
+	}
+	author, err := primitive.ObjectIDFromHex(caller)
+	if err != nil {
+		return nil
+	}
+	return &author
+}
+
 // validateCreateServiceRequest validates a create service request
 func validateCreateServiceRequest(req domain.CreateServiceRequest) error {
 	if req.Name == "" {
@@ -265,5 +471,6 @@ func IsValidationError(err error) bool {
 		errors.Is(err, domain.ErrNameTooLong) ||
 		errors.Is(err, domain.ErrDescriptionTooLong) ||
 		errors.Is(err, domain.ErrInvalidSortField) ||
-		errors.Is(err, domain.ErrInvalidID)
+		errors.Is(err, domain.ErrInvalidID) ||
+		errors.Is(err, domain.ErrTeamNotFound)
 }
@@ -2,12 +2,15 @@ package service_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/services-api/internal/domain"
 	"github.com/services-api/internal/repository/mocks"
 	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -65,7 +68,7 @@ func TestServiceService_Create(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			serviceRepo := mocks.NewMockServiceRepository()
 			versionRepo := mocks.NewMockServiceVersionRepository()
-			svc := service.NewServiceService(serviceRepo, versionRepo)
+			svc := service.NewServiceService(serviceRepo, versionRepo, newTestTeamService(mocks.NewMockUserRepository(), serviceRepo), nil)
 
 			ctx := context.Background()
 			result, err := svc.Create(ctx, tt.req)
@@ -117,7 +120,7 @@ func TestServiceService_GetByID(t *testing.T) {
 			serviceRepo := mocks.NewMockServiceRepository()
 			tt.setupRepo(serviceRepo)
 			versionRepo := mocks.NewMockServiceVersionRepository()
-			svc := service.NewServiceService(serviceRepo, versionRepo)
+			svc := service.NewServiceService(serviceRepo, versionRepo, newTestTeamService(mocks.NewMockUserRepository(), serviceRepo), nil)
 
 			ctx := context.Background()
 
@@ -213,7 +216,7 @@ func TestServiceService_Update(t *testing.T) {
 			serviceRepo := mocks.NewMockServiceRepository()
 			id := tt.setupRepo(serviceRepo)
 			versionRepo := mocks.NewMockServiceVersionRepository()
-			svc := service.NewServiceService(serviceRepo, versionRepo)
+			svc := service.NewServiceService(serviceRepo, versionRepo, newTestTeamService(mocks.NewMockUserRepository(), serviceRepo), nil)
 
 			ctx := context.Background()
 			result, err := svc.Update(ctx, id, tt.req)
@@ -310,7 +313,7 @@ func TestServiceService_Patch(t *testing.T) {
 			serviceRepo := mocks.NewMockServiceRepository()
 			id := tt.setupRepo(serviceRepo)
 			versionRepo := mocks.NewMockServiceVersionRepository()
-			svc := service.NewServiceService(serviceRepo, versionRepo)
+			svc := service.NewServiceService(serviceRepo, versionRepo, newTestTeamService(mocks.NewMockUserRepository(), serviceRepo), nil)
 
 			ctx := context.Background()
 			result, err := svc.Patch(ctx, id, tt.req)
@@ -367,7 +370,7 @@ func TestServiceService_Delete(t *testing.T) {
 			serviceRepo := mocks.NewMockServiceRepository()
 			id := tt.setupRepo(serviceRepo)
 			versionRepo := mocks.NewMockServiceVersionRepository()
-			svc := service.NewServiceService(serviceRepo, versionRepo)
+			svc := service.NewServiceService(serviceRepo, versionRepo, newTestTeamService(mocks.NewMockUserRepository(), serviceRepo), nil)
 
 			ctx := context.Background()
 			err := svc.Delete(ctx, id)
@@ -379,11 +382,122 @@ func TestServiceService_Delete(t *testing.T) {
 				// Verify service is deleted
 				_, err := serviceRepo.GetByID(ctx, id)
 				assert.ErrorIs(t, err, domain.ErrNotFound)
+				// and its deletion recorded in a tombstone version
+				tombstone, err := versionRepo.GetByServiceIDAndRevision(ctx, id, 1)
+				require.NoError(t, err)
+				assert.True(t, tombstone.Deleted)
 			}
 		})
 	}
 }
 
+func TestServiceService_DeleteKeepsVersionHistory(t *testing.T) {
+	s := newTestServices()
+	admin := addTestUser(s.userRepo, "admin@example.com", domain.RoleAdmin)
+	ctx := callerContext(admin)
+
+	created, err := s.services.Create(ctx, domain.CreateServiceRequest{Name: "payments", Description: "Handles payments"})
+	require.NoError(t, err)
+	id := created.ID.Hex()
+	_, err = s.services.Update(ctx, id, domain.UpdateServiceRequest{Name: "payments", Description: "Handles all payments"})
+	require.NoError(t, err)
+	require.NoError(t, s.services.Delete(ctx, id))
+
+	// The versions stay, chained onto by a tombstone naming who deleted the service
+	result, err := s.versionRepo.ListByServiceID(context.Background(), id, domain.DefaultPaginationParams())
+	require.NoError(t, err)
+	require.Len(t, result.Data, 3)
+	tombstone := result.Data[0]
+	assert.True(t, tombstone.Deleted)
+	assert.Equal(t, 3, tombstone.Revision)
+	assert.Equal(t, "payments", tombstone.Name)
+	require.NotNil(t, tombstone.AuthorID)
+	assert.Equal(t, admin.ID, *tombstone.AuthorID)
+
+	var verifier domain.ChainVerifier
+	for i := range result.Data {
+		verifier.Add(result.Data[i].ChainLink())
+	}
+	assert.True(t, verifier.Result().Valid)
+}
+
+func TestServiceService_UpdateAfterMissingVersion(t *testing.T) {
+	ctx := context.Background()
+	serviceRepo := mocks.NewMockServiceRepository()
+	versionRepo := mocks.NewMockServiceVersionRepository()
+	svc := service.NewServiceService(serviceRepo, versionRepo, newTestTeamService(mocks.NewMockUserRepository(), serviceRepo), nil)
+
+	// A service whose second version was never stored
+	created, err := svc.Create(ctx, domain.CreateServiceRequest{Name: "payments", Description: "Handles payments"})
+	require.NoError(t, err)
+	id := created.ID.Hex()
+	stored, err := serviceRepo.GetByID(ctx, id)
+	require.NoError(t, err)
+	stored.Revision = 2
+
+	// The service can still be changed; its version starts a new segment
+	updated, err := svc.Update(ctx, id, domain.UpdateServiceRequest{Name: "payments", Description: "Handles all payments"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, updated.Revision)
+	version, err := versionRepo.GetByServiceIDAndRevision(ctx, id, 3)
+	require.NoError(t, err)
+	assert.Empty(t, version.PrevHash)
+
+	// Verification reports the gap
+	result, err := svc.VerifyVersions(ctx, id)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, 3, result.BrokenLink.Revision)
+	assert.Equal(t, domain.ChainBreakMissingPrev, result.BrokenLink.Reason)
+}
+
+func TestServiceService_VersionFailureRollsBack(t *testing.T) {
+	ctx := context.Background()
+	serviceRepo := mocks.NewMockServiceRepository()
+	versionRepo := mocks.NewMockServiceVersionRepository()
+	svc := service.NewServiceService(serviceRepo, versionRepo, newTestTeamService(mocks.NewMockUserRepository(), serviceRepo), nil)
+
+	created, err := svc.Create(ctx, domain.CreateServiceRequest{Name: "payments", Description: "Handles payments"})
+	require.NoError(t, err)
+	id := created.ID.Hex()
+
+	storeErr := errors.New("connection reset")
+	versionRepo.CreateFunc = func(ctx context.Context, version *domain.ServiceVersion) error {
+		return storeErr
+	}
+
+	// A created service is removed again, so that a retry doesn't duplicate it
+	_, err = svc.Create(ctx, domain.CreateServiceRequest{Name: "billing", Description: "Handles billing"})
+	assert.ErrorIs(t, err, storeErr)
+	list, err := serviceRepo.List(ctx, domain.DefaultListParams())
+	require.NoError(t, err)
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, created.ID, list.Data[0].ID)
+
+	// An update or patch is undone, revision included
+	_, err = svc.Update(ctx, id, domain.UpdateServiceRequest{Name: "payments", Description: "Handles all payments"})
+	assert.ErrorIs(t, err, storeErr)
+	name := "payments-v2"
+	_, err = svc.Patch(ctx, id, domain.PatchServiceRequest{Name: &name})
+	assert.ErrorIs(t, err, storeErr)
+
+	stored, err := serviceRepo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "payments", stored.Name)
+	assert.Equal(t, "Handles payments", stored.Description)
+	assert.Equal(t, 1, stored.Revision)
+
+	// Once versions can be stored again, the chain continues without a gap
+	versionRepo.CreateFunc = nil
+	_, err = svc.Patch(ctx, id, domain.PatchServiceRequest{Name: &name})
+	require.NoError(t, err)
+	result, err := svc.VerifyVersions(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, 2, result.Checked)
+}
+
 func TestServiceService_List(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -460,7 +574,7 @@ func TestServiceService_List(t *testing.T) {
 			serviceRepo := mocks.NewMockServiceRepository()
 			tt.setupRepo(serviceRepo)
 			versionRepo := mocks.NewMockServiceVersionRepository()
-			svc := service.NewServiceService(serviceRepo, versionRepo)
+			svc := service.NewServiceService(serviceRepo, versionRepo, newTestTeamService(mocks.NewMockUserRepository(), serviceRepo), nil)
 
 			ctx := context.Background()
 			result, err := svc.List(ctx, tt.params)
@@ -474,3 +588,77 @@ func TestServiceService_List(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceService_VerifyVersions(t *testing.T) {
+	ctx := context.Background()
+	serviceRepo := mocks.NewMockServiceRepository()
+	versionRepo := mocks.NewMockServiceVersionRepository()
+	svc := service.NewServiceService(serviceRepo, versionRepo, newTestTeamService(mocks.NewMockUserRepository(), serviceRepo), nil)
+
+	created, err := svc.Create(ctx, domain.CreateServiceRequest{Name: "payments", Description: "Handles payments"})
+	require.NoError(t, err)
+	id := created.ID.Hex()
+	_, err = svc.Update(ctx, id, domain.UpdateServiceRequest{Name: "payments", Description: "Handles all payments"})
+	require.NoError(t, err)
+	name := "payments-v2"
+	_, err = svc.Patch(ctx, id, domain.PatchServiceRequest{Name: &name})
+	require.NoError(t, err)
+
+	// Each version is chained onto the previous revision
+	first, err := versionRepo.GetByServiceIDAndRevision(ctx, id, 1)
+	require.NoError(t, err)
+	second, err := versionRepo.GetByServiceIDAndRevision(ctx, id, 2)
+	require.NoError(t, err)
+	assert.Empty(t, first.PrevHash)
+	assert.Equal(t, first.Hash, second.PrevHash)
+
+	result, err := svc.VerifyVersions(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, 3, result.Checked)
+
+	// Editing a version in the database breaks the chain
+	second.Description = "Edited after the fact"
+	result, err = svc.VerifyVersions(ctx, id)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, 2, result.BrokenLink.Revision)
+	assert.Equal(t, second.ID.Hex(), result.BrokenLink.ID)
+	assert.Equal(t, domain.ChainBreakHashMismatch, result.BrokenLink.Reason)
+
+	// The chain of a deleted service ends in its tombstone and can still be verified
+	deleted, err := svc.Create(ctx, domain.CreateServiceRequest{Name: "billing", Description: "Handles billing"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Delete(ctx, deleted.ID.Hex()))
+	result, err = svc.VerifyVersions(ctx, deleted.ID.Hex())
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, 2, result.Checked)
+
+	_, err = svc.VerifyVersions(ctx, primitive.NewObjectID().Hex())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestServiceService_CountAndVersionMetrics(t *testing.T) {
+	ctx := context.Background()
+	serviceRepo := mocks.NewMockServiceRepository()
+	svc := service.NewServiceService(serviceRepo, mocks.NewMockServiceVersionRepository(), newTestTeamService(mocks.NewMockUserRepository(), serviceRepo), nil)
+	versions := testutil.ToFloat64(metrics.VersionsCreated)
+
+	count, err := svc.Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	for _, name := range []string{"payments", "billing"} {
+		created, err := svc.Create(ctx, domain.CreateServiceRequest{Name: name, Description: "Handles " + name})
+		require.NoError(t, err)
+		_, err = svc.Update(ctx, created.ID.Hex(), domain.UpdateServiceRequest{Name: name, Description: "Handles all " + name})
+		require.NoError(t, err)
+	}
+
+	count, err = svc.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, versions+4, testutil.ToFloat64(metrics.VersionsCreated))
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/jwt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SessionService manages login sessions and their revocation
+type SessionService struct {
+	sessionRepo      domain.SessionRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+	audit            *AuditService
+}
+
+// NewSessionService creates a new SessionService
+func NewSessionService(sessionRepo domain.SessionRepository, refreshTokenRepo domain.RefreshTokenRepository, audit *AuditService) *SessionService {
+	return &SessionService{
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		audit:            audit,
+	}
+}
+
+// Start creates a session for a new login from the given client
+func (s *SessionService) Start(ctx context.Context, userID primitive.ObjectID, client domain.ClientInfo, expiresAt time.Time) (*domain.Session, error) {
+	now := time.Now()
+	session := &domain.Session{
+		ID:         jwt.NewTokenID(),
+		UserID:     userID,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Touch records that a session was refreshed from the given client and extends its expiry
+func (s *SessionService) Touch(ctx context.Context, id string, client domain.ClientInfo, expiresAt time.Time) error {
+	return s.sessionRepo.Touch(ctx, id, client, expiresAt)
+}
+
+// ValidateSession returns an error unless the session exists, belongs to the
+// user and is still active. It implements auth.SessionValidator.
+func (s *SessionService) ValidateSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.UserID.Hex() != userID {
+		return domain.ErrSessionNotFound
+	}
+	if !session.IsActive() {
+		return domain.ErrSessionRevoked
+	}
+
+	return nil
+}
+
+// List retrieves the active sessions of a user
+func (s *SessionService) List(ctx context.Context, userID string) ([]domain.Session, error) {
+	return s.sessionRepo.ListByUser(ctx, userID)
+}
+
+// Revoke revokes a session of a user along with its refresh tokens
+func (s *SessionService) Revoke(ctx context.Context, userID, sessionID string) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// Sessions of other users are reported as missing so their IDs can't be probed
+	if session.UserID.Hex() != userID || !session.IsActive() {
+		return domain.ErrSessionNotFound
+	}
+
+	// Refresh tokens go first so the session can't be renewed while it is being revoked
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, sessionID); err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.Revoke(ctx, sessionID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionSessionRevoke,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   userID,
+		Before:     map[string]string{"session_id": sessionID},
+	})
+	return nil
+}
+
+// RevokeAll revokes every session of a user along with their refresh tokens
+func (s *SessionService) RevokeAll(ctx context.Context, userID string) error {
+	if err := s.revokeAll(ctx, userID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionSessionRevokeAll,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   userID,
+	})
+	return nil
+}
+
+// revokeAll revokes every session of a user without recording it, for
+// actions that revoke sessions as part of an audited change to the user
+func (s *SessionService) revokeAll(ctx context.Context, userID string) error {
+	if err := s.refreshTokenRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeAllForUser(ctx, userID)
+}
+
+// revokeQuietly revokes a session, treating one that is already gone as revoked
+func (s *SessionService) revokeQuietly(ctx context.Context, userID, sessionID string) error {
+	if err := s.Revoke(ctx, userID, sessionID); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return err
+	}
+	return nil
+}
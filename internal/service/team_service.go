@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/pkg/auth"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxTeamDescriptionLength is the longest description a team can have
+const maxTeamDescriptionLength = 200
+
+// TeamService manages teams and their membership, and decides who may change
+// the services a team owns. Team maintainers and holders of users:admin
+// manage a team; its members and holders of services:admin change its
+// services.
+type TeamService struct {
+	teamRepo    domain.TeamRepository
+	userRepo    domain.UserRepository
+	serviceRepo domain.ServiceRepository
+	authorizer  auth.Authorizer
+	audit       *AuditService
+}
+
+// NewTeamService creates a new TeamService
+func NewTeamService(teamRepo domain.TeamRepository, userRepo domain.UserRepository, serviceRepo domain.ServiceRepository, authorizer auth.Authorizer, audit *AuditService) *TeamService {
+	return &TeamService{
+		teamRepo:    teamRepo,
+		userRepo:    userRepo,
+		serviceRepo: serviceRepo,
+		authorizer:  authorizer,
+		audit:       audit,
+	}
+}
+
+// Create creates a team with its creator as the first maintainer
+func (s *TeamService) Create(ctx context.Context, creatorID string, req domain.CreateTeamRequest) (*domain.Team, error) {
+	creator, err := primitive.ObjectIDFromHex(creatorID)
+	if err != nil {
+		return nil, domain.ErrInvalidID
+	}
+
+	team := &domain.Team{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Members:     []domain.TeamMember{{UserID: creator, Role: domain.TeamRoleMaintainer}},
+	}
+	if err := validateTeam(team); err != nil {
+		return nil, err
+	}
+
+	if err := s.teamRepo.Create(ctx, team); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionTeamCreate,
+		TargetType: domain.AuditTargetTeam,
+		TargetID:   team.ID.Hex(),
+		After:      teamAuditSummary(team),
+	})
+	return team, nil
+}
+
+// GetByID retrieves a team by its ID
+func (s *TeamService) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	return s.teamRepo.GetByID(ctx, id)
+}
+
+// List retrieves teams with pagination
+func (s *TeamService) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.Team], error) {
+	return s.teamRepo.List(ctx, params)
+}
+
+// ListByMember retrieves the teams a user belongs to
+func (s *TeamService) ListByMember(ctx context.Context, userID string) ([]domain.Team, error) {
+	return s.teamRepo.ListByMember(ctx, userID)
+}
+
+// Update renames a team and changes its description
+func (s *TeamService) Update(ctx context.Context, id string, req domain.UpdateTeamRequest) (*domain.Team, error) {
+	team, err := s.manage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := teamAuditSummary(team)
+	team.Name = strings.TrimSpace(req.Name)
+	team.Description = strings.TrimSpace(req.Description)
+	if err := validateTeam(team); err != nil {
+		return nil, err
+	}
+
+	if err := s.teamRepo.Update(ctx, team); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionTeamUpdate,
+		TargetType: domain.AuditTargetTeam,
+		TargetID:   id,
+		Before:     before,
+		After:      teamAuditSummary(team),
+	})
+	return team, nil
+}
+
+// Delete deletes a team. Teams that own services can't be deleted.
+func (s *TeamService) Delete(ctx context.Context, id string) error {
+	team, err := s.manage(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	owned, err := s.serviceRepo.List(ctx, domain.ListParams{
+		Team:       id,
+		Pagination: domain.PaginationParams{Page: 1, Limit: 1},
+	})
+	if err != nil {
+		return err
+	}
+	if owned.Pagination.Total > 0 {
+		return domain.ErrTeamHasServices
+	}
+
+	if err := s.teamRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionTeamDelete,
+		TargetType: domain.AuditTargetTeam,
+		TargetID:   id,
+		Before:     teamAuditSummary(team),
+	})
+	return nil
+}
+
+// SetMember adds a user to a team or changes their team role
+func (s *TeamService) SetMember(ctx context.Context, teamID, userID string, req domain.SetTeamMemberRequest) (*domain.Team, error) {
+	if req.Role == "" {
+		req.Role = domain.TeamRoleMember
+	}
+	if !domain.IsValidTeamRole(req.Role) {
+		return nil, domain.ErrInvalidTeamRole
+	}
+
+	team, err := s.manage(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := team.Member(userID)
+	if existing != nil && existing.Role == domain.TeamRoleMaintainer &&
+		req.Role != domain.TeamRoleMaintainer && team.Maintainers() == 1 {
+		return nil, domain.ErrLastMaintainer
+	}
+
+	member := domain.TeamMember{UserID: user.ID, Role: req.Role}
+	if err := s.teamRepo.SetMember(ctx, teamID, member); err != nil {
+		return nil, err
+	}
+
+	event := domain.AuditEvent{
+		Action:     domain.AuditActionTeamMemberSet,
+		TargetType: domain.AuditTargetTeam,
+		TargetID:   teamID,
+		After:      teamMemberAuditSummary(&member),
+	}
+	if existing != nil {
+		event.Before = teamMemberAuditSummary(existing)
+	}
+	s.audit.Record(ctx, event)
+
+	return s.teamRepo.GetByID(ctx, teamID)
+}
+
+// RemoveMember removes a user from a team. Members can always leave a team
+// themselves, unless they are its last maintainer.
+func (s *TeamService) RemoveMember(ctx context.Context, teamID, userID string) error {
+	var team *domain.Team
+	var err error
+	if caller, ok := callerID(ctx); ok && caller == userID {
+		team, err = s.teamRepo.GetByID(ctx, teamID)
+	} else {
+		team, err = s.manage(ctx, teamID)
+	}
+	if err != nil {
+		return err
+	}
+
+	member := team.Member(userID)
+	if member == nil {
+		return domain.ErrTeamMemberNotFound
+	}
+	if member.Role == domain.TeamRoleMaintainer && team.Maintainers() == 1 {
+		return domain.ErrLastMaintainer
+	}
+
+	if err := s.teamRepo.RemoveMember(ctx, teamID, userID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionTeamMemberRemove,
+		TargetType: domain.AuditTargetTeam,
+		TargetID:   teamID,
+		Before:     teamMemberAuditSummary(member),
+	})
+	return nil
+}
+
+// AuthorizeService checks that the caller may change a service owned by a
+// team, returning ErrTeamAccessDenied if they may not. Services without a
+// team are open to every caller allowed to change services.
+func (s *TeamService) AuthorizeService(ctx context.Context, teamID *primitive.ObjectID) error {
+	if teamID == nil {
+		return nil
+	}
+
+	allowed, err := s.granted(ctx, auth.ScopeServicesAdmin)
+	if err != nil || allowed {
+		return err
+	}
+
+	team, err := s.teamRepo.GetByID(ctx, teamID.Hex())
+	if err != nil {
+		if errors.Is(err, domain.ErrTeamNotFound) {
+			return domain.ErrTeamAccessDenied
+		}
+		return err
+	}
+
+	if caller, ok := callerID(ctx); ok && team.Member(caller) != nil {
+		return nil
+	}
+	return domain.ErrTeamAccessDenied
+}
+
+// ValidateTeamID checks that a team exists, returning ErrTeamNotFound if it doesn't
+func (s *TeamService) ValidateTeamID(ctx context.Context, id string) (*primitive.ObjectID, error) {
+	team, err := s.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &team.ID, nil
+}
+
+// manage returns a team the caller may manage, returning ErrTeamAccessDenied
+// unless they are one of its maintainers or hold users:admin
+func (s *TeamService) manage(ctx context.Context, id string) (*domain.Team, error) {
+	team, err := s.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if caller, ok := callerID(ctx); ok {
+		if m := team.Member(caller); m != nil && m.Role == domain.TeamRoleMaintainer {
+			return team, nil
+		}
+	}
+
+	allowed, err := s.granted(ctx, auth.ScopeUsersAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, domain.ErrTeamAccessDenied
+	}
+	return team, nil
+}
+
+// granted reports whether the caller holds a permission
+func (s *TeamService) granted(ctx context.Context, permission string) (bool, error) {
+	err := s.authorizer.Authorize(ctx, permission)
+	if err == nil {
+		return true, nil
+	}
+
+	var permissionErr *auth.PermissionError
+	if errors.As(err, &permissionErr) {
+		return false, nil
+	}
+	return false, err
+}
+
+// callerID returns the ID of the user making a request, who for API keys is
+// the owner of the key
+func callerID(ctx context.Context) (string, bool) {
+	if userID, ok := auth.GetUserID(ctx); ok && userID != "" {
+		return userID, true
+	}
+	if principal, ok := auth.GetAPIKeyPrincipal(ctx); ok && principal.OwnerID != "" {
+		return principal.OwnerID, true
+	}
+	return "", false
+}
+
+// validateTeam checks the name and description of a team
+func validateTeam(team *domain.Team) error {
+	if !domain.IsValidTeamName(team.Name) {
+		return domain.ErrTeamNameInvalid
+	}
+	if len(team.Description) > maxTeamDescriptionLength {
+		return domain.ErrTeamDescriptionTooLong
+	}
+	return nil
+}
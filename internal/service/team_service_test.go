@@ -0,0 +1,151 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/services-api/internal/domain"
+	"github.com/services-api/internal/repository/mocks"
+	"github.com/services-api/internal/service"
+	"github.com/services-api/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestTeamService creates a TeamService that authorizes callers by their role
+func newTestTeamService(userRepo domain.UserRepository, serviceRepo domain.ServiceRepository) *service.TeamService {
+	return service.NewTeamService(mocks.NewMockTeamRepository(), userRepo, serviceRepo, newTestRoleService(userRepo), nil)
+}
+
+// callerContext returns the context of a request made by a user with a token
+// holding every permission of their built-in role
+func callerContext(user *domain.User) context.Context {
+	ctx := userContext(user.Role, auth.ScopesForRole(user.Role)...)
+	return context.WithValue(ctx, auth.UserIDContextKey, user.ID.Hex())
+}
+
+// addTestUser stores a user with a role
+func addTestUser(userRepo *mocks.MockUserRepository, email, role string) *domain.User {
+	user := &domain.User{ID: primitive.NewObjectID(), Email: email, FirstName: "Test", Role: role, Active: true}
+	userRepo.AddUser(user)
+	return user
+}
+
+func TestTeamService_Membership(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository()
+	teams := newTestTeamService(userRepo, mocks.NewMockServiceRepository())
+	alice := addTestUser(userRepo, "alice@example.com", domain.RoleEditor)
+	bob := addTestUser(userRepo, "bob@example.com", domain.RoleEditor)
+	carol := addTestUser(userRepo, "carol@example.com", domain.RoleEditor)
+	admin := addTestUser(userRepo, "admin@example.com", domain.RoleAdmin)
+
+	_, err := teams.Create(callerContext(alice), alice.ID.Hex(), domain.CreateTeamRequest{Name: "Payments"})
+	assert.ErrorIs(t, err, domain.ErrTeamNameInvalid)
+
+	// The creator becomes the first maintainer
+	team, err := teams.Create(callerContext(alice), alice.ID.Hex(), domain.CreateTeamRequest{Name: "payments", Description: "Payment services"})
+	require.NoError(t, err)
+	require.Len(t, team.Members, 1)
+	assert.Equal(t, domain.TeamRoleMaintainer, team.Member(alice.ID.Hex()).Role)
+	id := team.ID.Hex()
+
+	_, err = teams.Create(callerContext(bob), bob.ID.Hex(), domain.CreateTeamRequest{Name: "payments"})
+	assert.ErrorIs(t, err, domain.ErrTeamExists)
+
+	// Only maintainers manage the team
+	_, err = teams.Update(callerContext(bob), id, domain.UpdateTeamRequest{Name: "billing"})
+	assert.ErrorIs(t, err, domain.ErrTeamAccessDenied)
+
+	team, err = teams.SetMember(callerContext(alice), id, bob.ID.Hex(), domain.SetTeamMemberRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, domain.TeamRoleMember, team.Member(bob.ID.Hex()).Role)
+
+	_, err = teams.SetMember(callerContext(bob), id, carol.ID.Hex(), domain.SetTeamMemberRequest{Role: domain.TeamRoleMember})
+	assert.ErrorIs(t, err, domain.ErrTeamAccessDenied)
+
+	// Holders of users:admin manage every team
+	_, err = teams.SetMember(callerContext(admin), id, carol.ID.Hex(), domain.SetTeamMemberRequest{Role: domain.TeamRoleMember})
+	require.NoError(t, err)
+
+	_, err = teams.SetMember(callerContext(alice), id, carol.ID.Hex(), domain.SetTeamMemberRequest{Role: "owner"})
+	assert.ErrorIs(t, err, domain.ErrInvalidTeamRole)
+	_, err = teams.SetMember(callerContext(alice), id, primitive.NewObjectID().Hex(), domain.SetTeamMemberRequest{})
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	// A team keeps at least one maintainer
+	_, err = teams.SetMember(callerContext(alice), id, alice.ID.Hex(), domain.SetTeamMemberRequest{Role: domain.TeamRoleMember})
+	assert.ErrorIs(t, err, domain.ErrLastMaintainer)
+	assert.ErrorIs(t, teams.RemoveMember(callerContext(alice), id, alice.ID.Hex()), domain.ErrLastMaintainer)
+
+	// Members can leave, but not remove others
+	assert.ErrorIs(t, teams.RemoveMember(callerContext(bob), id, carol.ID.Hex()), domain.ErrTeamAccessDenied)
+	require.NoError(t, teams.RemoveMember(callerContext(bob), id, bob.ID.Hex()))
+	assert.ErrorIs(t, teams.RemoveMember(callerContext(alice), id, bob.ID.Hex()), domain.ErrTeamMemberNotFound)
+
+	memberships, err := teams.ListByMember(context.Background(), carol.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, "payments", memberships[0].Name)
+
+	memberships, err = teams.ListByMember(context.Background(), bob.ID.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, memberships)
+}
+
+func TestTeamService_ServiceAuthorization(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository()
+	serviceRepo := mocks.NewMockServiceRepository()
+	teams := newTestTeamService(userRepo, serviceRepo)
+	services := service.NewServiceService(serviceRepo, mocks.NewMockServiceVersionRepository(), teams, nil)
+	alice := addTestUser(userRepo, "alice@example.com", domain.RoleEditor)
+	bob := addTestUser(userRepo, "bob@example.com", domain.RoleEditor)
+	owner := addTestUser(userRepo, "owner@example.com", domain.RoleServiceOwner)
+
+	team, err := teams.Create(callerContext(alice), alice.ID.Hex(), domain.CreateTeamRequest{Name: "payments"})
+	require.NoError(t, err)
+	teamID := team.ID.Hex()
+
+	// Only members can assign services to the team
+	_, err = services.Create(callerContext(bob), domain.CreateServiceRequest{Name: "payment-service", Description: "Payments", TeamID: teamID})
+	assert.ErrorIs(t, err, domain.ErrTeamAccessDenied)
+	_, err = services.Create(callerContext(alice), domain.CreateServiceRequest{Name: "payment-service", Description: "Payments", TeamID: primitive.NewObjectID().Hex()})
+	assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+
+	svc, err := services.Create(callerContext(alice), domain.CreateServiceRequest{Name: "payment-service", Description: "Payments", TeamID: teamID})
+	require.NoError(t, err)
+	require.NotNil(t, svc.TeamID)
+	_, err = services.Create(callerContext(bob), domain.CreateServiceRequest{Name: "user-service", Description: "Users"})
+	require.NoError(t, err)
+
+	// Only members and holders of services:admin change the team's services
+	description := "Payment processing"
+	_, err = services.Patch(callerContext(bob), svc.ID.Hex(), domain.PatchServiceRequest{Description: &description})
+	assert.ErrorIs(t, err, domain.ErrTeamAccessDenied)
+	_, err = services.Update(callerContext(bob), svc.ID.Hex(), domain.UpdateServiceRequest{Name: "payment-service", Description: description})
+	assert.ErrorIs(t, err, domain.ErrTeamAccessDenied)
+	_, err = services.Patch(callerContext(owner), svc.ID.Hex(), domain.PatchServiceRequest{Description: &description})
+	require.NoError(t, err)
+
+	_, err = teams.SetMember(callerContext(alice), teamID, bob.ID.Hex(), domain.SetTeamMemberRequest{})
+	require.NoError(t, err)
+	_, err = services.Patch(callerContext(bob), svc.ID.Hex(), domain.PatchServiceRequest{Description: &description})
+	require.NoError(t, err)
+
+	// Filter by team
+	result, err := services.List(context.Background(), domain.ListParams{Team: teamID})
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "payment-service", result.Data[0].Name)
+	_, err = services.List(context.Background(), domain.ListParams{Team: "invalid-id"})
+	assert.ErrorIs(t, err, domain.ErrInvalidID)
+
+	// Teams that own services can't be deleted
+	assert.ErrorIs(t, teams.Delete(callerContext(alice), teamID), domain.ErrTeamHasServices)
+	noTeam := ""
+	updated, err := services.Patch(callerContext(bob), svc.ID.Hex(), domain.PatchServiceRequest{TeamID: &noTeam})
+	require.NoError(t, err)
+	assert.Nil(t, updated.TeamID)
+	assert.ErrorIs(t, teams.Delete(callerContext(bob), teamID), domain.ErrTeamAccessDenied)
+	require.NoError(t, teams.Delete(callerContext(alice), teamID))
+}
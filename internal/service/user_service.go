@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"net/netip"
 	"regexp"
 	"strings"
 
@@ -12,31 +13,42 @@ var userEmailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-z
 
 // UserService handles user management operations
 type UserService struct {
-	userRepo domain.UserRepository
+	userRepo    domain.UserRepository
+	teamRepo    domain.TeamRepository
+	versionRepo domain.ServiceVersionRepository
+	sessions    *SessionService
+	roles       *RoleService
+	throttle    *LoginThrottleService
+	accounts    *AccountService
+	passwords   *PasswordService
+	audit       *AuditService
 }
 
 // NewUserService creates a new UserService
-func NewUserService(userRepo domain.UserRepository) *UserService {
+func NewUserService(userRepo domain.UserRepository, teamRepo domain.TeamRepository, versionRepo domain.ServiceVersionRepository, sessions *SessionService, roles *RoleService, throttle *LoginThrottleService, accounts *AccountService, passwords *PasswordService, audit *AuditService) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:    userRepo,
+		teamRepo:    teamRepo,
+		versionRepo: versionRepo,
+		sessions:    sessions,
+		roles:       roles,
+		throttle:    throttle,
+		accounts:    accounts,
+		passwords:   passwords,
+		audit:       audit,
 	}
 }
 
-// Create creates a new user (admin operation)
+// Create creates a new user (admin operation) and emails a link to verify their address
 func (s *UserService) Create(ctx context.Context, req domain.CreateUserRequest) (*domain.User, error) {
-	// Validate request
-	if err := s.validateCreateRequest(req); err != nil {
-		return nil, err
+	if req.Role == "" {
+		req.Role = domain.RoleUser
 	}
 
-	// Check if email already exists
-	exists, err := s.userRepo.ExistsByEmail(ctx, strings.ToLower(req.Email))
-	if err != nil {
+	// Validate request
+	if err := s.validateCreateRequest(ctx, req); err != nil {
 		return nil, err
 	}
-	if exists {
-		return nil, domain.ErrEmailAlreadyExists
-	}
 
 	// Create user
 	user := &domain.User{
@@ -48,15 +60,35 @@ func (s *UserService) Create(ctx context.Context, req domain.CreateUserRequest)
 	}
 
 	// Set password
-	if err := user.SetPassword(req.Password); err != nil {
+	if err := s.passwords.Set(ctx, user, req.Password); err != nil {
 		return nil, err
 	}
 
+	// Check if email already exists
+	exists, err := s.userRepo.ExistsByEmail(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, domain.ErrEmailAlreadyExists
+	}
+
 	// Save user
 	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, err
 	}
 
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionUserCreate,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   user.ID.Hex(),
+		After:      userAuditSummary(user),
+	})
+
+	if err := s.accounts.SendVerification(ctx, user); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
@@ -68,7 +100,7 @@ func (s *UserService) GetByID(ctx context.Context, id string) (*domain.User, err
 // Update updates a user (admin operation)
 func (s *UserService) Update(ctx context.Context, id string, req domain.UpdateUserRequest) (*domain.User, error) {
 	// Validate request
-	if err := s.validateUpdateRequest(req); err != nil {
+	if err := s.validateUpdateRequest(ctx, req); err != nil {
 		return nil, err
 	}
 
@@ -90,6 +122,16 @@ func (s *UserService) Update(ctx context.Context, id string, req domain.UpdateUs
 		}
 	}
 
+	// Outstanding tokens carry the role, so they must not outlive a role change or deactivation
+	wasActive, previousRole := user.Active, user.Role
+	before := userAuditSummary(user)
+
+	// A new email address has to be verified again
+	emailChanged := newEmail != user.Email
+	if emailChanged {
+		user.EmailVerified = false
+	}
+
 	// Update fields
 	user.Email = newEmail
 	user.FirstName = strings.TrimSpace(req.FirstName)
@@ -104,16 +146,122 @@ func (s *UserService) Update(ctx context.Context, id string, req domain.UpdateUs
 		return nil, err
 	}
 
+	action := domain.AuditActionUserUpdate
+	if previousRole != user.Role {
+		action = domain.AuditActionUserRoleChange
+	}
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     action,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   id,
+		Before:     before,
+		After:      userAuditSummary(user),
+	})
+
+	if (wasActive && !user.Active) || previousRole != user.Role {
+		if err := s.sessions.revokeAll(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if emailChanged && user.Active && !user.Pending {
+		if err := s.accounts.SendVerification(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
 	return user, nil
 }
 
-// Delete deletes a user
+// Delete deletes a user and revokes their sessions. The service versions
+// they authored are kept, with the author anonymized.
 func (s *UserService) Delete(ctx context.Context, id string) error {
-	return s.userRepo.Delete(ctx, id)
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.versionRepo.AnonymizeAuthor(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionUserDelete,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   id,
+		Before:     userAuditSummary(user),
+	})
+
+	if err := s.teamRepo.RemoveUser(ctx, id); err != nil {
+		return err
+	}
+
+	return s.sessions.revokeAll(ctx, id)
 }
 
-// List retrieves users with pagination
-func (s *UserService) List(ctx context.Context, params domain.PaginationParams) (*domain.PaginatedResult[domain.User], error) {
+// Unlock lifts a lockout caused by failed logins to a user's account and,
+// when ip isn't empty, the lockout of the client IP they log in from
+func (s *UserService) Unlock(ctx context.Context, id, ip string) error {
+	if ip != "" {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return domain.ErrInvalidIPAddress
+		}
+		// Client IPs are recorded in their canonical form
+		ip = addr.String()
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.throttle.Unlock(ctx, user.Email, id, ip); err != nil {
+		return err
+	}
+
+	event := domain.AuditEvent{
+		Action:     domain.AuditActionUnlock,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   id,
+	}
+	if ip != "" {
+		event.After = map[string]string{"ip": ip}
+	}
+	s.audit.Record(ctx, event)
+	return nil
+}
+
+// List retrieves users with filtering, sorting, and pagination
+func (s *UserService) List(ctx context.Context, params domain.UserListParams) (*domain.PaginatedResult[domain.User], error) {
+	// Validate sort field
+	if params.Sort != "" && !domain.IsValidUserSortField(params.Sort) {
+		return nil, domain.ErrInvalidSortField
+	}
+
+	// Apply defaults
+	if params.Sort == "" {
+		params.Sort = "created_at"
+	}
+	if params.Order == "" {
+		params.Order = "desc"
+	}
+	if params.Pagination.Limit == 0 {
+		params.Pagination.Limit = 20
+	}
+	if params.Pagination.Page == 0 {
+		params.Pagination.Page = 1
+	}
+
+	// Cap limit at 100
+	if params.Pagination.Limit > 100 {
+		params.Pagination.Limit = 100
+	}
+
 	return s.userRepo.List(ctx, params)
 }
 
@@ -123,15 +271,6 @@ func (s *UserService) ChangePassword(ctx context.Context, userID string, req dom
 	if req.CurrentPassword == "" {
 		return domain.ErrPasswordRequired
 	}
-	if req.NewPassword == "" {
-		return domain.ErrPasswordRequired
-	}
-	if len(req.NewPassword) < 8 {
-		return domain.ErrPasswordTooShort
-	}
-	if len(req.NewPassword) > 72 {
-		return domain.ErrPasswordTooLong
-	}
 
 	// Get user
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -145,16 +284,25 @@ func (s *UserService) ChangePassword(ctx context.Context, userID string, req dom
 	}
 
 	// Set new password
-	if err := user.SetPassword(req.NewPassword); err != nil {
+	if err := s.passwords.Set(ctx, user, req.NewPassword); err != nil {
 		return err
 	}
 
 	// Save user
-	return s.userRepo.Update(ctx, user)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditEvent{
+		Action:     domain.AuditActionPasswordChange,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   userID,
+	})
+	return nil
 }
 
 // validateCreateRequest validates the create user request
-func (s *UserService) validateCreateRequest(req domain.CreateUserRequest) error {
+func (s *UserService) validateCreateRequest(ctx context.Context, req domain.CreateUserRequest) error {
 	// Validate email
 	if req.Email == "" {
 		return domain.ErrEmailRequired
@@ -166,17 +314,6 @@ func (s *UserService) validateCreateRequest(req domain.CreateUserRequest) error
 		return domain.ErrEmailInvalid
 	}
 
-	// Validate password
-	if req.Password == "" {
-		return domain.ErrPasswordRequired
-	}
-	if len(req.Password) < 8 {
-		return domain.ErrPasswordTooShort
-	}
-	if len(req.Password) > 72 {
-		return domain.ErrPasswordTooLong
-	}
-
 	// Validate first name
 	if strings.TrimSpace(req.FirstName) == "" {
 		return domain.ErrFirstNameRequired
@@ -191,18 +328,11 @@ func (s *UserService) validateCreateRequest(req domain.CreateUserRequest) error
 	}
 
 	// Validate role
-	if req.Role == "" {
-		req.Role = domain.RoleUser
-	}
-	if !domain.IsValidRole(req.Role) {
-		return domain.ErrInvalidRole
-	}
-
-	return nil
+	return s.roles.ValidateRole(ctx, req.Role)
 }
 
 // validateUpdateRequest validates the update user request
-func (s *UserService) validateUpdateRequest(req domain.UpdateUserRequest) error {
+func (s *UserService) validateUpdateRequest(ctx context.Context, req domain.UpdateUserRequest) error {
 	// Validate email
 	if req.Email == "" {
 		return domain.ErrEmailRequired
@@ -228,9 +358,5 @@ func (s *UserService) validateUpdateRequest(req domain.UpdateUserRequest) error
 	}
 
 	// Validate role
-	if !domain.IsValidRole(req.Role) {
-		return domain.ErrInvalidRole
-	}
-
-	return nil
+	return s.roles.ValidateRole(ctx, req.Role)
 }
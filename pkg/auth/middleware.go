@@ -8,6 +8,7 @@ import (
 
 	"github.com/services-api/pkg/config"
 	"github.com/services-api/pkg/jwt"
+	"github.com/services-api/pkg/logging"
 )
 
 // ContextKey is a custom type for context keys
@@ -22,11 +23,16 @@ const (
 	BearerPrefix = "Bearer "
 
 	// Context keys
-	APIKeyContextKey ContextKey = "api_key_id"
-	UserIDContextKey ContextKey = "user_id"
-	UserEmailKey     ContextKey = "user_email"
-	UserRoleKey      ContextKey = "user_role"
-	AuthTypeKey      ContextKey = "auth_type"
+	APIKeyContextKey   ContextKey = "api_key_id"
+	UserIDContextKey   ContextKey = "user_id"
+	UserEmailKey       ContextKey = "user_email"
+	UserRoleKey        ContextKey = "user_role"
+	SessionIDKey       ContextKey = "session_id"
+	APIKeyPrincipalKey ContextKey = "api_key_principal"
+	ScopesKey          ContextKey = "scopes"
+	AuthTypeKey        ContextKey = "auth_type"
+	ActorIDKey         ContextKey = "actor_id"
+	ActorEmailKey      ContextKey = "actor_email"
 )
 
 // AuthType represents the type of authentication used
@@ -37,10 +43,29 @@ const (
 	AuthTypeJWT    AuthType = "jwt"
 )
 
+// SessionValidator checks that the session an access token was issued for is still active
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, userID, sessionID string) error
+}
+
+// APIKeyPrincipal describes the owner and scopes of a stored API key
+type APIKeyPrincipal struct {
+	KeyID   string
+	OwnerID string
+	Scopes  []string
+}
+
+// APIKeyAuthenticator resolves stored API keys
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*APIKeyPrincipal, error)
+}
+
 // Middleware creates an authentication middleware
 type Middleware struct {
 	config     *config.Config
 	jwtManager *jwt.Manager
+	sessions   SessionValidator
+	apiKeys    APIKeyAuthenticator
 }
 
 // NewMiddleware creates a new auth middleware
@@ -51,6 +76,20 @@ func NewMiddleware(cfg *config.Config, jwtManager *jwt.Manager) *Middleware {
 	}
 }
 
+// WithSessionValidator makes the middleware reject access tokens whose session
+// is missing or revoked. Tokens without a session ID are rejected as well.
+func (m *Middleware) WithSessionValidator(sessions SessionValidator) *Middleware {
+	m.sessions = sessions
+	return m
+}
+
+// WithAPIKeyAuthenticator makes the middleware accept stored API keys. Keys
+// configured in API_KEYS keep working as a legacy fallback.
+func (m *Middleware) WithAPIKeyAuthenticator(apiKeys APIKeyAuthenticator) *Middleware {
+	m.apiKeys = apiKeys
+	return m
+}
+
 // Authenticate is the middleware function that validates API keys or JWT tokens
 func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -99,17 +138,50 @@ func (m *Middleware) authenticateJWT(ctx context.Context, token string) (context
 		return ctx, false
 	}
 
+	// Signature checks alone can't tell whether the session was revoked since
+	// the token was issued. Impersonation tokens belong to the actor's session.
+	if m.sessions != nil {
+		if claims.SessionID == "" {
+			return ctx, false
+		}
+		sessionUserID := claims.UserID
+		if claims.Actor != nil {
+			sessionUserID = claims.Actor.Subject
+		}
+		if err := m.sessions.ValidateSession(ctx, sessionUserID, claims.SessionID); err != nil {
+			logging.FromContext(ctx).Info("Rejecting token for session", "session_id", claims.SessionID, "error", err)
+			return ctx, false
+		}
+	}
+
 	// Add user info to context
 	ctx = context.WithValue(ctx, UserIDContextKey, claims.UserID)
 	ctx = context.WithValue(ctx, UserEmailKey, claims.Email)
 	ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
+	ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
+	ctx = context.WithValue(ctx, ScopesKey, tokenScopes(claims))
 	ctx = context.WithValue(ctx, AuthTypeKey, AuthTypeJWT)
+	if claims.Actor != nil {
+		ctx = context.WithValue(ctx, ActorIDKey, claims.Actor.Subject)
+		ctx = context.WithValue(ctx, ActorEmailKey, claims.Actor.Email)
+	}
 
 	return ctx, true
 }
 
 // authenticateAPIKey validates an API key and returns an updated context
 func (m *Middleware) authenticateAPIKey(ctx context.Context, apiKey string) (context.Context, bool) {
+	// Stored keys take precedence over the legacy API_KEYS list
+	if m.apiKeys != nil {
+		principal, err := m.apiKeys.AuthenticateAPIKey(ctx, apiKey)
+		if err == nil {
+			ctx = context.WithValue(ctx, APIKeyPrincipalKey, principal)
+			ctx = context.WithValue(ctx, ScopesKey, principal.Scopes)
+			ctx = context.WithValue(ctx, AuthTypeKey, AuthTypeAPIKey)
+			return ctx, true
+		}
+	}
+
 	if !m.config.HasAPIKeys() {
 		return ctx, false
 	}
@@ -121,11 +193,34 @@ func (m *Middleware) authenticateAPIKey(ctx context.Context, apiKey string) (con
 
 	// Add API key info to context
 	ctx = context.WithValue(ctx, APIKeyContextKey, keyIndex)
+	ctx = context.WithValue(ctx, ScopesKey, LegacyAPIKeyScopes)
 	ctx = context.WithValue(ctx, AuthTypeKey, AuthTypeAPIKey)
 
 	return ctx, true
 }
 
+// ForbidImpersonation is a middleware that rejects requests made while
+// impersonating a user with 403, for actions only the user themselves may take.
+// It must run after Authenticate.
+func ForbidImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsImpersonated(r.Context()) {
+			writeForbiddenResponse(w, "not allowed while impersonating a user")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tokenScopes returns the scopes of an access token. Tokens issued without a
+// scope claim are granted the scopes of their role.
+func tokenScopes(claims *jwt.Claims) []string {
+	if scopes := claims.Scopes(); len(scopes) > 0 {
+		return scopes
+	}
+	return ScopesForRole(claims.Role)
+}
+
 // findValidKeyIndex returns the index of the matching key, or -1 if not found
 // Uses constant-time comparison to prevent timing attacks
 func (m *Middleware) findValidKeyIndex(providedKey string) int {
@@ -149,6 +244,13 @@ func writeUnauthorizedResponse(w http.ResponseWriter, message string) {
 	w.Write([]byte(`{"error":"unauthorized","message":"` + escapeJSON(message) + `"}`))
 }
 
+// writeForbiddenResponse writes a 403 response with proper format
+func writeForbiddenResponse(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	w.Write([]byte(`{"error":"forbidden","message":"` + escapeJSON(message) + `"}`))
+}
+
 // escapeJSON escapes special characters in JSON strings
 func escapeJSON(s string) string {
 	s = strings.ReplaceAll(s, "\\", "\\\\")
@@ -156,12 +258,18 @@ func escapeJSON(s string) string {
 	return s
 }
 
-// GetAPIKeyID retrieves the API key identifier from the request context
+// GetAPIKeyID retrieves the index of the legacy API_KEYS key from the request context
 func GetAPIKeyID(ctx context.Context) (int, bool) {
 	keyID, ok := ctx.Value(APIKeyContextKey).(int)
 	return keyID, ok
 }
 
+// GetAPIKeyPrincipal retrieves the stored API key principal from the request context
+func GetAPIKeyPrincipal(ctx context.Context) (*APIKeyPrincipal, bool) {
+	principal, ok := ctx.Value(APIKeyPrincipalKey).(*APIKeyPrincipal)
+	return principal, ok
+}
+
 // GetUserID retrieves the user ID from the request context
 func GetUserID(ctx context.Context) (string, bool) {
 	userID, ok := ctx.Value(UserIDContextKey).(string)
@@ -180,6 +288,33 @@ func GetUserRole(ctx context.Context) (string, bool) {
 	return role, ok
 }
 
+// GetSessionID retrieves the session ID of the access token from the request context
+func GetSessionID(ctx context.Context) (string, bool) {
+	sessionID, ok := ctx.Value(SessionIDKey).(string)
+	return sessionID, ok && sessionID != ""
+}
+
+// GetActorID retrieves the ID of the user impersonating the authenticated
+// user from the request context
+func GetActorID(ctx context.Context) (string, bool) {
+	actorID, ok := ctx.Value(ActorIDKey).(string)
+	return actorID, ok && actorID != ""
+}
+
+// GetActorEmail retrieves the email of the user impersonating the
+// authenticated user from the request context
+func GetActorEmail(ctx context.Context) (string, bool) {
+	email, ok := ctx.Value(ActorEmailKey).(string)
+	return email, ok
+}
+
+// IsImpersonated checks if the request was made by a user impersonating the
+// authenticated user
+func IsImpersonated(ctx context.Context) bool {
+	_, ok := GetActorID(ctx)
+	return ok
+}
+
 // GetAuthType retrieves the authentication type from the request context
 func GetAuthType(ctx context.Context) (AuthType, bool) {
 	authType, ok := ctx.Value(AuthTypeKey).(AuthType)
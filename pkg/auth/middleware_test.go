@@ -1,6 +1,8 @@
 package auth_test
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -261,6 +263,190 @@ func TestMiddleware_JWTRefreshTokenNotAllowed(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+// sessionValidatorFunc adapts a function to auth.SessionValidator
+type sessionValidatorFunc func(ctx context.Context, userID, sessionID string) error
+
+func (f sessionValidatorFunc) ValidateSession(ctx context.Context, userID, sessionID string) error {
+	return f(ctx, userID, sessionID)
+}
+
+func TestMiddleware_JWTSessionValidation(t *testing.T) {
+	cfg := &config.Config{}
+	jwtManager := newTestJWTManager()
+	revoked := map[string]bool{"session-revoked": true}
+	middleware := auth.NewMiddleware(cfg, jwtManager).WithSessionValidator(
+		sessionValidatorFunc(func(ctx context.Context, userID, sessionID string) error {
+			if revoked[sessionID] {
+				return errors.New("session has been revoked")
+			}
+			return nil
+		}),
+	)
+
+	var capturedSessionID string
+	handler := middleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedSessionID, _ = auth.GetSessionID(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	activeToken, err := jwtManager.GenerateAccessTokenForSession("user123", "test@example.com", "user", "session-active", nil)
+	assert.NoError(t, err)
+	revokedToken, err := jwtManager.GenerateAccessTokenForSession("user123", "test@example.com", "user", "session-revoked", nil)
+	assert.NoError(t, err)
+	sessionlessToken, err := jwtManager.GenerateAccessToken("user123", "test@example.com", "user")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		token        string
+		expectedCode int
+	}{
+		{name: "active session", token: activeToken, expectedCode: http.StatusOK},
+		{name: "revoked session", token: revokedToken, expectedCode: http.StatusUnauthorized},
+		{name: "token without session", token: sessionlessToken, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/services", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+
+	assert.Equal(t, "session-active", capturedSessionID)
+}
+
+func TestMiddleware_Impersonation(t *testing.T) {
+	cfg := &config.Config{}
+	jwtManager := newTestJWTManager()
+	var validatedUserID string
+	middleware := auth.NewMiddleware(cfg, jwtManager).WithSessionValidator(
+		sessionValidatorFunc(func(ctx context.Context, userID, sessionID string) error {
+			validatedUserID = userID
+			return nil
+		}),
+	)
+
+	var userID, actorID, actorEmail string
+	var impersonated bool
+	handler := middleware.Authenticate(auth.ForbidImpersonation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	inspect := middleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ = auth.GetUserID(r.Context())
+		actorID, _ = auth.GetActorID(r.Context())
+		actorEmail, _ = auth.GetActorEmail(r.Context())
+		impersonated = auth.IsImpersonated(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	token, err := jwtManager.GenerateImpersonationToken("user123", "test@example.com", "user", "admin-session", nil,
+		jwt.Actor{Subject: "admin456", Email: "admin@example.com"}, 5*time.Minute)
+	assert.NoError(t, err)
+	ownToken, err := jwtManager.GenerateAccessTokenForSession("user123", "test@example.com", "user", "user-session", nil)
+	assert.NoError(t, err)
+
+	// Both identities are exposed, and the session checked is the actor's
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	inspect.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user123", userID)
+	assert.Equal(t, "admin456", actorID)
+	assert.Equal(t, "admin@example.com", actorEmail)
+	assert.True(t, impersonated)
+	assert.Equal(t, "admin456", validatedUserID)
+
+	// ForbidImpersonation only rejects impersonated requests
+	tests := []struct {
+		name         string
+		token        string
+		expectedCode int
+	}{
+		{name: "impersonated", token: token, expectedCode: http.StatusForbidden},
+		{name: "user's own token", token: ownToken, expectedCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/password", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+	assert.Equal(t, "user123", validatedUserID)
+}
+
+type apiKeyAuthenticatorFunc func(ctx context.Context, key string) (*auth.APIKeyPrincipal, error)
+
+func (f apiKeyAuthenticatorFunc) AuthenticateAPIKey(ctx context.Context, key string) (*auth.APIKeyPrincipal, error) {
+	return f(ctx, key)
+}
+
+func TestMiddleware_StoredAPIKeys(t *testing.T) {
+	cfg := &config.Config{
+		APIKeys: []string{"legacy-key"},
+	}
+	middleware := auth.NewMiddleware(cfg, newTestJWTManager()).WithAPIKeyAuthenticator(
+		apiKeyAuthenticatorFunc(func(ctx context.Context, key string) (*auth.APIKeyPrincipal, error) {
+			if key != "sk_0123456789ab_secret" {
+				return nil, errors.New("invalid api key")
+			}
+			return &auth.APIKeyPrincipal{KeyID: "key1", OwnerID: "user123", Scopes: []string{auth.ScopeServicesRead}}, nil
+		}),
+	)
+
+	tests := []struct {
+		name          string
+		apiKey        string
+		expectedCode  int
+		expectedKeyID string
+		expectLegacy  bool
+	}{
+		{name: "stored key", apiKey: "sk_0123456789ab_secret", expectedCode: http.StatusOK, expectedKeyID: "key1"},
+		{name: "legacy fallback", apiKey: "legacy-key", expectedCode: http.StatusOK, expectLegacy: true},
+		{name: "unknown key", apiKey: "sk_0123456789ab_wrong", expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var principal *auth.APIKeyPrincipal
+			var legacy bool
+			handler := middleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal, _ = auth.GetAPIKeyPrincipal(r.Context())
+				_, legacy = auth.GetAPIKeyID(r.Context())
+				assert.True(t, auth.IsAPIKeyAuth(r.Context()))
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/services", nil)
+			req.Header.Set("X-API-Key", tt.apiKey)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.expectLegacy, legacy)
+			if tt.expectedKeyID != "" {
+				if assert.NotNil(t, principal) {
+					assert.Equal(t, tt.expectedKeyID, principal.KeyID)
+				}
+			} else {
+				assert.Nil(t, principal)
+			}
+		})
+	}
+}
+
 func TestGetUserID_NotSet(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/test", nil)
 	userID, ok := auth.GetUserID(req.Context())
@@ -321,3 +507,113 @@ func TestIsAPIKeyAuth(t *testing.T) {
 
 	assert.True(t, isAPIKey)
 }
+
+func TestRequireScopes(t *testing.T) {
+	cfg := &config.Config{
+		APIKeys: []string{"legacy-key"},
+	}
+	jwtManager := newTestJWTManager()
+	middleware := auth.NewMiddleware(cfg, jwtManager).WithAPIKeyAuthenticator(
+		apiKeyAuthenticatorFunc(func(ctx context.Context, key string) (*auth.APIKeyPrincipal, error) {
+			if key != "sk_0123456789ab_readonly" {
+				return nil, errors.New("invalid api key")
+			}
+			return &auth.APIKeyPrincipal{KeyID: "key1", OwnerID: "user123", Scopes: []string{auth.ScopeServicesRead}}, nil
+		}),
+	)
+
+	readWriteToken, err := jwtManager.GenerateAccessTokenForSession("user123", "test@example.com", "admin", "session1", []string{auth.ScopeServicesRead, auth.ScopeServicesWrite})
+	assert.NoError(t, err)
+	userToken, err := jwtManager.GenerateAccessToken("user123", "test@example.com", "user")
+	assert.NoError(t, err)
+	adminToken, err := jwtManager.GenerateAccessToken("admin123", "admin@example.com", "admin")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		scopes          []string
+		token           string
+		apiKey          string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{name: "token with scope", scopes: []string{auth.ScopeServicesWrite}, token: readWriteToken, expectedStatus: http.StatusOK},
+		{name: "token claim limits role", scopes: []string{auth.ScopeUsersAdmin}, token: readWriteToken, expectedStatus: http.StatusForbidden, expectedMessage: "missing required scope: users:admin"},
+		{name: "token without claim uses role", scopes: []string{auth.ScopeServicesWrite}, token: userToken, expectedStatus: http.StatusOK},
+		{name: "user role lacks admin scope", scopes: []string{auth.ScopeServicesAdmin}, token: userToken, expectedStatus: http.StatusForbidden, expectedMessage: "missing required scope: services:admin"},
+		{name: "admin role has every scope", scopes: auth.AllScopes, token: adminToken, expectedStatus: http.StatusOK},
+		{name: "stored key with scope", scopes: []string{auth.ScopeServicesRead}, apiKey: "sk_0123456789ab_readonly", expectedStatus: http.StatusOK},
+		{name: "stored key missing scopes", scopes: []string{auth.ScopeServicesRead, auth.ScopeServicesWrite, auth.ScopeServicesAdmin}, apiKey: "sk_0123456789ab_readonly", expectedStatus: http.StatusForbidden, expectedMessage: "missing required scope: services:write services:admin"},
+		{name: "legacy key manages services", scopes: []string{auth.ScopeServicesAdmin}, apiKey: "legacy-key", expectedStatus: http.StatusOK},
+		{name: "legacy key can't manage users", scopes: []string{auth.ScopeUsersAdmin}, apiKey: "legacy-key", expectedStatus: http.StatusForbidden, expectedMessage: "missing required scope: users:admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextCalled := false
+			handler := middleware.Authenticate(auth.RequireScopes(tt.scopes...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+				w.WriteHeader(http.StatusOK)
+			})))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/services", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, nextCalled)
+			if tt.expectedMessage != "" {
+				assert.JSONEq(t, `{"error":"forbidden","message":"`+tt.expectedMessage+`"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireScopes_Unauthenticated(t *testing.T) {
+	handler := auth.RequireScopes(auth.ScopeServicesRead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("next handler should not be called")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/services", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRequireAnyScope(t *testing.T) {
+	tests := []struct {
+		name           string
+		scopes         []string
+		expectedStatus int
+	}{
+		{name: "one of the scopes", scopes: []string{auth.ScopeServicesRead}, expectedStatus: http.StatusOK},
+		{name: "MFA enrollment only", scopes: []string{auth.ScopeMFAEnrollment}, expectedStatus: http.StatusForbidden},
+		{name: "no scopes", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := auth.RequireAnyScope(auth.AllScopes...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/api-keys", nil)
+			ctx := context.WithValue(req.Context(), auth.AuthTypeKey, auth.AuthTypeJWT)
+			ctx = context.WithValue(ctx, auth.ScopesKey, tt.scopes)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req.WithContext(ctx))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Scopes granted to user tokens and API keys. Scopes double as the
+// permissions that roles grant.
+const (
+	ScopeServicesRead  = "services:read"
+	ScopeServicesWrite = "services:write"
+	ScopeServicesAdmin = "services:admin"
+	ScopeUsersRead     = "users:read"
+	ScopeUsersAdmin    = "users:admin"
+)
+
+// ScopeMFAEnrollment is the only scope of tokens issued to users whose role
+// requires multi-factor authentication they haven't set up. It is not a
+// permission: it grants nothing, and roles and API keys can't hold it, but
+// unlike an empty scope claim it doesn't fall back to the role's permissions.
+const ScopeMFAEnrollment = "mfa:enroll"
+
+// Permission describes a scope in the permission registry
+type Permission struct {
+	Name        string `json:"name" example:"services:read"`
+	Description string `json:"description" example:"List and view services and their versions"`
+}
+
+// Permissions is the registry of every known scope
+var Permissions = []Permission{
+	{Name: ScopeServicesRead, Description: "List and view services and their versions"},
+	{Name: ScopeServicesWrite, Description: "Create and update services"},
+	{Name: ScopeServicesAdmin, Description: "Delete services"},
+	{Name: ScopeUsersRead, Description: "List and view users, their sessions and roles"},
+	{Name: ScopeUsersAdmin, Description: "Manage users, their sessions and roles"},
+}
+
+// AllScopes lists every known scope
+var AllScopes = []string{ScopeServicesRead, ScopeServicesWrite, ScopeServicesAdmin, ScopeUsersRead, ScopeUsersAdmin}
+
+// LegacyAPIKeyScopes are granted to keys configured in API_KEYS, which have
+// always had full access to services and none to user management
+var LegacyAPIKeyScopes = []string{ScopeServicesRead, ScopeServicesWrite, ScopeServicesAdmin}
+
+// RoleDefinition describes a built-in role and its default permissions
+type RoleDefinition struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// BuiltInRoles are the roles every deployment starts with. Their permissions
+// can be changed at runtime, except those of admin.
+var BuiltInRoles = []RoleDefinition{
+	{Name: "viewer", Description: "Read-only access to services", Permissions: []string{ScopeServicesRead}},
+	{Name: "editor", Description: "Create and update services", Permissions: []string{ScopeServicesRead, ScopeServicesWrite}},
+	{Name: "service-owner", Description: "Full control over services", Permissions: []string{ScopeServicesRead, ScopeServicesWrite, ScopeServicesAdmin}},
+	{Name: "auditor", Description: "Read-only access to services and users", Permissions: []string{ScopeServicesRead, ScopeUsersRead}},
+	{Name: "user", Description: "Default role of registered users", Permissions: []string{ScopeServicesRead, ScopeServicesWrite}},
+	{Name: "admin", Description: "Full access", Permissions: AllScopes},
+}
+
+// IsValidScope checks if a scope is known
+func IsValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopesForRole returns the default permissions of a built-in role, or none
+// for other roles
+func ScopesForRole(role string) []string {
+	for _, def := range BuiltInRoles {
+		if def.Name == role {
+			return def.Permissions
+		}
+	}
+	return nil
+}
+
+// GetScopes retrieves the scopes granted to the request from the request context
+func GetScopes(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(ScopesKey).([]string)
+	return scopes, ok
+}
+
+// HasScope checks if the request was granted a scope
+func HasScope(ctx context.Context, scope string) bool {
+	scopes, _ := GetScopes(ctx)
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScopes is a middleware that rejects requests missing any of the given
+// scopes with 403. It must run after Authenticate.
+func RequireScopes(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := GetAuthType(r.Context()); !ok {
+				writeUnauthorizedResponse(w, "authentication required")
+				return
+			}
+
+			var missing []string
+			for _, scope := range scopes {
+				if !HasScope(r.Context(), scope) {
+					missing = append(missing, scope)
+				}
+			}
+			if len(missing) > 0 {
+				writeForbiddenResponse(w, "missing required scope: "+strings.Join(missing, " "))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAnyScope is a middleware that rejects requests holding none of the
+// given scopes with 403, such as those made with tokens only allowed to set up
+// MFA. It must run after Authenticate.
+func RequireAnyScope(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := GetAuthType(r.Context()); !ok {
+				writeUnauthorizedResponse(w, "authentication required")
+				return
+			}
+
+			for _, scope := range scopes {
+				if HasScope(r.Context(), scope) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			writeForbiddenResponse(w, "missing required scope: one of "+strings.Join(scopes, " "))
+		})
+	}
+}
+
+// Authorizer decides whether the caller of a request holds permissions. It
+// returns a *PermissionError naming the permissions that are missing.
+type Authorizer interface {
+	Authorize(ctx context.Context, permissions ...string) error
+}
+
+// PermissionError reports the permissions a request is missing
+type PermissionError struct {
+	Missing []string
+}
+
+// Error implements the error interface
+func (e *PermissionError) Error() string {
+	return "missing required permission: " + strings.Join(e.Missing, " ")
+}
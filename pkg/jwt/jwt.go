@@ -1,7 +1,10 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,6 +14,7 @@ import (
 const (
 	TokenTypeAccess  = "access"
 	TokenTypeRefresh = "refresh"
+	TokenTypeMFA     = "mfa"
 )
 
 // Errors
@@ -19,6 +23,7 @@ var (
 	ErrExpiredToken         = errors.New("token has expired")
 	ErrInvalidTokenType     = errors.New("invalid token type")
 	ErrInvalidSigningMethod = errors.New("invalid signing method")
+	ErrUnknownKey           = errors.New("unknown signing key")
 )
 
 // Claims represents the JWT claims
@@ -27,21 +32,60 @@ type Claims struct {
 	Email     string `json:"email"`
 	Role      string `json:"role"`
 	TokenType string `json:"token_type"`
+	SessionID string `json:"sid,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	Actor     *Actor `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Actor is the act claim of a token issued to one user acting as another
+// (RFC 8693): the user the token was issued to, while the other claims
+// describe the user they act as
+type Actor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+}
+
+// Scopes returns the space-delimited scope claim as a list
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
 // Manager handles JWT token operations
 type Manager struct {
-	secretKey          []byte
+	signingKey         *Key
+	keys               map[string]*Key
+	jwks               JWKSet
 	accessTokenExpiry  time.Duration
 	refreshTokenExpiry time.Duration
 	issuer             string
 }
 
-// NewManager creates a new JWT manager
+// NewManager creates a new JWT manager signing with an HS256 shared secret
 func NewManager(secretKey string, accessExpiry, refreshExpiry time.Duration, issuer string) *Manager {
+	return NewManagerWithKeys(NewHMACKey(DefaultHMACKeyID, []byte(secretKey)), nil, accessExpiry, refreshExpiry, issuer)
+}
+
+// NewManagerWithKeys creates a new JWT manager that signs with signingKey and
+// also accepts tokens signed with any of verificationKeys, so that tokens
+// signed before a key rotation stay valid until they expire
+func NewManagerWithKeys(signingKey *Key, verificationKeys []*Key, accessExpiry, refreshExpiry time.Duration, issuer string) *Manager {
+	keys := map[string]*Key{}
+	jwks := JWKSet{Keys: []JWK{}}
+	for _, key := range append([]*Key{signingKey}, verificationKeys...) {
+		if _, ok := keys[key.ID]; ok {
+			continue
+		}
+		keys[key.ID] = key
+		if jwk, ok := key.JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
 	return &Manager{
-		secretKey:          []byte(secretKey),
+		signingKey:         signingKey,
+		keys:               keys,
+		jwks:               jwks,
 		accessTokenExpiry:  accessExpiry,
 		refreshTokenExpiry: refreshExpiry,
 		issuer:             issuer,
@@ -50,43 +94,93 @@ func NewManager(secretKey string, accessExpiry, refreshExpiry time.Duration, iss
 
 // GenerateAccessToken creates a new access token for a user
 func (m *Manager) GenerateAccessToken(userID, email, role string) (string, error) {
-	return m.generateToken(userID, email, role, TokenTypeAccess, m.accessTokenExpiry)
+	return m.generateToken(userID, email, role, TokenTypeAccess, NewTokenID(), "", nil, m.accessTokenExpiry)
+}
+
+// GenerateAccessTokenForSession creates a new access token bound to a session (sid claim),
+// so it stops being accepted once the session is revoked, and granting the given scopes
+func (m *Manager) GenerateAccessTokenForSession(userID, email, role, sessionID string, scopes []string) (string, error) {
+	return m.generateToken(userID, email, role, TokenTypeAccess, NewTokenID(), sessionID, scopes, m.accessTokenExpiry)
 }
 
 // GenerateRefreshToken creates a new refresh token for a user
 func (m *Manager) GenerateRefreshToken(userID, email, role string) (string, error) {
-	return m.generateToken(userID, email, role, TokenTypeRefresh, m.refreshTokenExpiry)
+	return m.generateToken(userID, email, role, TokenTypeRefresh, NewTokenID(), "", nil, m.refreshTokenExpiry)
+}
+
+// GenerateRefreshTokenWithID creates a new refresh token carrying the given token ID (jti),
+// so the caller can track it server-side
+func (m *Manager) GenerateRefreshTokenWithID(userID, email, role, tokenID string) (string, error) {
+	return m.generateToken(userID, email, role, TokenTypeRefresh, tokenID, "", nil, m.refreshTokenExpiry)
+}
+
+// GenerateMFAToken creates a short-lived token proving that a user passed the
+// first login step, to be exchanged for real tokens with a second factor
+func (m *Manager) GenerateMFAToken(userID, email, role string, expiry time.Duration) (string, error) {
+	return m.generateToken(userID, email, role, TokenTypeMFA, NewTokenID(), "", nil, expiry)
+}
+
+// GenerateImpersonationToken creates an access token that lets actor act as
+// another user. It is bound to the actor's session rather than one of the
+// user's, and expires after expiry.
+func (m *Manager) GenerateImpersonationToken(userID, email, role, sessionID string, scopes []string, actor Actor, expiry time.Duration) (string, error) {
+	claims := m.newClaims(userID, email, role, TokenTypeAccess, NewTokenID(), sessionID, scopes, expiry)
+	claims.Actor = &actor
+	return m.sign(claims)
+}
+
+// NewTokenID returns a random token ID suitable for the jti claim
+func NewTokenID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
 
 // generateToken creates a token with the specified parameters
-func (m *Manager) generateToken(userID, email, role, tokenType string, expiry time.Duration) (string, error) {
+func (m *Manager) generateToken(userID, email, role, tokenType, tokenID, sessionID string, scopes []string, expiry time.Duration) (string, error) {
+	return m.sign(m.newClaims(userID, email, role, tokenType, tokenID, sessionID, scopes, expiry))
+}
+
+// newClaims builds the claims of a token with the specified parameters
+func (m *Manager) newClaims(userID, email, role, tokenType, tokenID, sessionID string, scopes []string, expiry time.Duration) *Claims {
 	now := time.Now()
-	claims := &Claims{
+	return &Claims{
 		UserID:    userID,
 		Email:     email,
 		Role:      role,
 		TokenType: tokenType,
+		SessionID: sessionID,
+		Scope:     strings.Join(scopes, " "),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    m.issuer,
 			Subject:   userID,
+			ID:        tokenID,
 		},
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secretKey)
+// sign signs claims with the signing key
+func (m *Manager) sign(claims *Claims) (string, error) {
+	token := jwt.NewWithClaims(m.signingKey.method, claims)
+	token.Header["kid"] = m.signingKey.ID
+	return token.SignedString(m.signingKey.signKey)
 }
 
 // ValidateToken validates a token and returns its claims
 func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		key, err := m.verificationKey(token)
+		if err != nil {
+			return nil, err
+		}
+		// Validate signing method, so a public key can't be used as an HMAC secret
+		if token.Method.Alg() != key.Algorithm() {
 			return nil, ErrInvalidSigningMethod
 		}
-		return m.secretKey, nil
+		return key.verifyKey, nil
 	})
 
 	if err != nil {
@@ -104,6 +198,27 @@ func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// verificationKey returns the key named by the kid header of a token. Tokens
+// without a known kid, issued before kid headers were added or before HS256
+// keys were named by JWT_KEY_ID, are checked against an HS256 signing key.
+func (m *Manager) verificationKey(token *jwt.Token) (*Key, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keys[kid]
+	if !ok {
+		if m.signingKey.Algorithm() == AlgorithmHS256 {
+			return m.signingKey, nil
+		}
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// JWKS returns the public keys tokens may be signed with, for publishing at
+// /.well-known/jwks.json. It is empty when signing with HS256.
+func (m *Manager) JWKS() JWKSet {
+	return m.jwks
+}
+
 // ValidateAccessToken validates an access token
 func (m *Manager) ValidateAccessToken(tokenString string) (*Claims, error) {
 	claims, err := m.ValidateToken(tokenString)
@@ -132,6 +247,25 @@ func (m *Manager) ValidateRefreshToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// ValidateMFAToken validates an MFA challenge token
+func (m *Manager) ValidateMFAToken(tokenString string) (*Claims, error) {
+	claims, err := m.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeMFA {
+		return nil, ErrInvalidTokenType
+	}
+
+	return claims, nil
+}
+
+// GetRefreshTokenExpiry returns the refresh token expiry duration
+func (m *Manager) GetRefreshTokenExpiry() time.Duration {
+	return m.refreshTokenExpiry
+}
+
 // GetAccessTokenExpiry returns the access token expiry duration in seconds
 func (m *Manager) GetAccessTokenExpiry() int64 {
 	return int64(m.accessTokenExpiry.Seconds())
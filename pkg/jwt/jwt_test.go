@@ -0,0 +1,258 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/services-api/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func privateKeyPEM(t *testing.T, key interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicKeyPEM(t *testing.T, key interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func newManager(signingKey *jwt.Key, verificationKeys ...*jwt.Key) *jwt.Manager {
+	return jwt.NewManagerWithKeys(signingKey, verificationKeys, 15*time.Minute, time.Hour, "test")
+}
+
+func TestManager_AsymmetricAlgorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		pem       []byte
+		algorithm string
+		kty       string
+	}{
+		{name: "RSA PKCS#8", pem: privateKeyPEM(t, rsaKey), algorithm: jwt.AlgorithmRS256, kty: "RSA"},
+		{
+			name:      "RSA PKCS#1",
+			pem:       pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+			algorithm: jwt.AlgorithmRS256,
+			kty:       "RSA",
+		},
+		{name: "ECDSA P-256", pem: privateKeyPEM(t, ecKey), algorithm: jwt.AlgorithmES256, kty: "EC"},
+		{name: "Ed25519", pem: privateKeyPEM(t, edKey), algorithm: jwt.AlgorithmEdDSA, kty: "OKP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := jwt.ParsePrivateKeyPEM(tt.pem)
+			require.NoError(t, err)
+			assert.Equal(t, tt.algorithm, key.Algorithm())
+			assert.True(t, key.CanSign())
+
+			m := newManager(key)
+			token, err := m.GenerateAccessTokenForSession("user-1", "user@example.com", "user", "session-1", []string{"services:read"})
+			require.NoError(t, err)
+
+			parsed, _, err := gojwt.NewParser().ParseUnverified(token, &jwt.Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.algorithm, parsed.Method.Alg())
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+
+			claims, err := m.ValidateAccessToken(token)
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.UserID)
+			assert.Equal(t, []string{"services:read"}, claims.Scopes())
+
+			jwks := m.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, key.ID, jwks.Keys[0].Kid)
+			assert.Equal(t, tt.kty, jwks.Keys[0].Kty)
+			assert.Equal(t, tt.algorithm, jwks.Keys[0].Alg)
+		})
+	}
+}
+
+func TestManager_KeyRotation(t *testing.T) {
+	oldRSA, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newEC, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	oldKey, err := jwt.ParsePrivateKeyPEM(privateKeyPEM(t, oldRSA))
+	require.NoError(t, err)
+	newKey, err := jwt.ParsePrivateKeyPEM(privateKeyPEM(t, newEC))
+	require.NoError(t, err)
+
+	oldToken, err := newManager(oldKey).GenerateAccessToken("user-1", "user@example.com", "user")
+	require.NoError(t, err)
+
+	// Before the old public key is listed, its tokens are rejected
+	_, err = newManager(newKey).ValidateAccessToken(oldToken)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+
+	oldPublic, err := jwt.ParsePublicKeyPEM(publicKeyPEM(t, &oldRSA.PublicKey))
+	require.NoError(t, err)
+	assert.Equal(t, oldKey.ID, oldPublic.ID)
+	assert.False(t, oldPublic.CanSign())
+
+	m := newManager(newKey, oldPublic)
+	_, err = m.ValidateAccessToken(oldToken)
+	assert.NoError(t, err)
+
+	newToken, err := m.GenerateAccessToken("user-1", "user@example.com", "user")
+	require.NoError(t, err)
+	_, err = m.ValidateAccessToken(newToken)
+	assert.NoError(t, err)
+
+	jwks := m.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, newKey.ID, jwks.Keys[0].Kid)
+	assert.Equal(t, oldKey.ID, jwks.Keys[1].Kid)
+}
+
+func TestManager_HS256(t *testing.T) {
+	m := jwt.NewManager("test-secret", 15*time.Minute, time.Hour, "test")
+	assert.Empty(t, m.JWKS().Keys)
+
+	token, err := m.GenerateAccessToken("user-1", "user@example.com", "user")
+	require.NoError(t, err)
+	_, err = m.ValidateAccessToken(token)
+	assert.NoError(t, err)
+
+	// Tokens issued before kid headers were added are still accepted
+	legacy := gojwt.NewWithClaims(gojwt.SigningMethodHS256, &jwt.Claims{
+		UserID:    "user-1",
+		TokenType: jwt.TokenTypeAccess,
+		RegisteredClaims: gojwt.RegisteredClaims{
+			ExpiresAt: gojwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	legacyToken, err := legacy.SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+	_, err = m.ValidateAccessToken(legacyToken)
+	assert.NoError(t, err)
+
+	// So are tokens naming a key by the thumbprint of the secret, as they did
+	// before HS256 keys got configured IDs
+	legacy.Header["kid"] = "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+	legacyToken, err = legacy.SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+	_, err = m.ValidateAccessToken(legacyToken)
+	assert.NoError(t, err)
+
+	_, err = jwt.NewManager("other-secret", 15*time.Minute, time.Hour, "test").ValidateAccessToken(token)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+}
+
+func TestNewHMACKey_IDIndependentOfSecret(t *testing.T) {
+	// A kid derived from the secret would let anyone holding a token check
+	// guesses of the secret against it
+	key := jwt.NewHMACKey("primary", []byte("test-secret"))
+	assert.Equal(t, "primary", key.ID)
+	assert.Equal(t, key.ID, jwt.NewHMACKey("primary", []byte("other-secret")).ID)
+
+	m := jwt.NewManagerWithKeys(key, nil, 15*time.Minute, time.Hour, "test")
+	token, err := m.GenerateAccessToken("user-1", "user@example.com", "user")
+	require.NoError(t, err)
+	parsed, _, err := gojwt.NewParser().ParseUnverified(token, &jwt.Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", parsed.Header["kid"])
+}
+
+func TestManager_MFATokenType(t *testing.T) {
+	m := jwt.NewManager("test-secret", 15*time.Minute, time.Hour, "test")
+
+	token, err := m.GenerateMFAToken("user-1", "user@example.com", "admin", 5*time.Minute)
+	require.NoError(t, err)
+	claims, err := m.ValidateMFAToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+
+	// A challenge token can't be used in place of an access or refresh token, nor the reverse
+	_, err = m.ValidateAccessToken(token)
+	assert.ErrorIs(t, err, jwt.ErrInvalidTokenType)
+	_, err = m.ValidateRefreshToken(token)
+	assert.ErrorIs(t, err, jwt.ErrInvalidTokenType)
+
+	access, err := m.GenerateAccessToken("user-1", "user@example.com", "admin")
+	require.NoError(t, err)
+	_, err = m.ValidateMFAToken(access)
+	assert.ErrorIs(t, err, jwt.ErrInvalidTokenType)
+}
+
+func TestManager_ImpersonationToken(t *testing.T) {
+	m := jwt.NewManager("test-secret", 15*time.Minute, time.Hour, "test")
+
+	token, err := m.GenerateImpersonationToken("user-1", "user@example.com", "user", "session-1", []string{"services:read"},
+		jwt.Actor{Subject: "admin-1", Email: "admin@example.com"}, 5*time.Minute)
+	require.NoError(t, err)
+
+	claims, err := m.ValidateAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, "session-1", claims.SessionID)
+	assert.Equal(t, []string{"services:read"}, claims.Scopes())
+	require.NotNil(t, claims.Actor)
+	assert.Equal(t, "admin-1", claims.Actor.Subject)
+	assert.Equal(t, "admin@example.com", claims.Actor.Email)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), claims.ExpiresAt.Time, time.Minute)
+
+	// Other access tokens have no actor
+	access, err := m.GenerateAccessToken("user-1", "user@example.com", "user")
+	require.NoError(t, err)
+	claims, err = m.ValidateAccessToken(access)
+	require.NoError(t, err)
+	assert.Nil(t, claims.Actor)
+}
+
+func TestManager_RejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := jwt.ParsePrivateKeyPEM(privateKeyPEM(t, rsaKey))
+	require.NoError(t, err)
+	m := newManager(key)
+
+	// An HS256 token keyed with the public key and naming the RSA kid
+	forged := gojwt.NewWithClaims(gojwt.SigningMethodHS256, &jwt.Claims{
+		UserID:    "attacker",
+		Role:      "admin",
+		TokenType: jwt.TokenTypeAccess,
+		RegisteredClaims: gojwt.RegisteredClaims{
+			ExpiresAt: gojwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	forged.Header["kid"] = key.ID
+	forgedToken, err := forged.SignedString(publicKeyPEM(t, &rsaKey.PublicKey))
+	require.NoError(t, err)
+
+	_, err = m.ValidateAccessToken(forgedToken)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+}
+
+func TestParseKeyPEM_Errors(t *testing.T) {
+	_, err := jwt.ParsePrivateKeyPEM([]byte("not a key"))
+	assert.ErrorIs(t, err, jwt.ErrInvalidPEM)
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = jwt.ParsePrivateKeyPEM(privateKeyPEM(t, weak))
+	assert.ErrorIs(t, err, jwt.ErrRSAKeyTooShort)
+}
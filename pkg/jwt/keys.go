@@ -0,0 +1,248 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// DefaultHMACKeyID is the kid of HS256 keys when none is configured
+const DefaultHMACKeyID = "hs256"
+
+// minRSAKeyBits is the smallest RSA key accepted for signing
+const minRSAKeyBits = 2048
+
+// Key errors
+var (
+	ErrUnsupportedKey = errors.New("unsupported key type")
+	ErrInvalidPEM     = errors.New("no PEM block found")
+	ErrRSAKeyTooShort = errors.New("RSA keys must be at least 2048 bits")
+)
+
+// Key is a key tokens are signed or verified with. Its ID, sent as the kid
+// header, is the RFC 7638 thumbprint of asymmetric keys.
+type Key struct {
+	ID        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty" example:"RSA"`
+	Use string `json:"use" example:"sig"`
+	Kid string `json:"kid" example:"NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"`
+	Alg string `json:"alg" example:"RS256"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is a set of public keys in JSON Web Key Set format
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewHMACKey creates an HS256 key from a shared secret. The ID is given rather
+// than derived from the secret, since anything derived from it would let
+// whoever sees a kid check guesses of the secret.
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{ID: id, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+}
+
+// ParsePrivateKeyPEM parses a PKCS#8, PKCS#1 (RSA) or SEC 1 (EC) private key.
+// The algorithm follows from the key: RS256 for RSA, ES256/ES384/ES512 for
+// P-256/P-384/P-521 and EdDSA for Ed25519.
+func ParsePrivateKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	var private interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+	key, err := newPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	key.signKey = private
+	return key, nil
+}
+
+// ParsePublicKeyPEM parses a PKIX public key, a certificate or a private key,
+// returning a key that only verifies tokens. It is used for keys that were
+// rotated out but may still have signed unexpired tokens.
+func ParsePublicKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		return newPublicKey(public)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		return newPublicKey(cert.PublicKey)
+	default:
+		key, err := ParsePrivateKeyPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		key.signKey = nil
+		return key, nil
+	}
+}
+
+// LoadPrivateKeyFile reads a signing key from a PEM file
+func LoadPrivateKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKeyPEM(data)
+}
+
+// LoadPublicKeyFile reads a verification key from a PEM file
+func LoadPublicKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKeyPEM(data)
+}
+
+// Algorithm returns the signing algorithm of the key
+func (k *Key) Algorithm() string {
+	return k.method.Alg()
+}
+
+// CanSign checks if the key holds the private half needed to sign tokens
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// JWK returns the public key in JSON Web Key format. HMAC keys are secret and
+// have no public form.
+func (k *Key) JWK() (JWK, bool) {
+	jwk := JWK{Use: "sig", Kid: k.ID, Alg: k.Algorithm()}
+	switch public := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty, jwk.N, jwk.E = rsaFields(public)
+	case *ecdsa.PublicKey:
+		jwk.Kty, jwk.Crv, jwk.X, jwk.Y = ecFields(public)
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv, jwk.X = "OKP", "Ed25519", encode(public)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
+
+// newPublicKey creates a verification key for a public key, picking the
+// algorithm from the key type
+func newPublicKey(public crypto.PublicKey) (*Key, error) {
+	key := &Key{verifyKey: public}
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSAKeyBits {
+			return nil, ErrRSAKeyTooShort
+		}
+		kty, n, e := rsaFields(public)
+		key.method = jwt.SigningMethodRS256
+		key.ID = thumbprint(map[string]string{"kty": kty, "n": n, "e": e})
+	case *ecdsa.PublicKey:
+		switch public.Curve {
+		case elliptic.P256():
+			key.method = jwt.SigningMethodES256
+		case elliptic.P384():
+			key.method = jwt.SigningMethodES384
+		case elliptic.P521():
+			key.method = jwt.SigningMethodES512
+		default:
+			return nil, ErrUnsupportedKey
+		}
+		kty, crv, x, y := ecFields(public)
+		key.ID = thumbprint(map[string]string{"kty": kty, "crv": crv, "x": x, "y": y})
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+		key.ID = thumbprint(map[string]string{"kty": "OKP", "crv": "Ed25519", "x": encode(public)})
+	default:
+		return nil, ErrUnsupportedKey
+	}
+	return key, nil
+}
+
+// rsaFields returns the JWK members of an RSA public key
+func rsaFields(public *rsa.PublicKey) (kty, n, e string) {
+	return "RSA", encode(public.N.Bytes()), encode(big.NewInt(int64(public.E)).Bytes())
+}
+
+// ecFields returns the JWK members of an EC public key, with coordinates
+// padded to the curve size as RFC 7518 requires
+func ecFields(public *ecdsa.PublicKey) (kty, crv, x, y string) {
+	size := (public.Curve.Params().BitSize + 7) / 8
+	return "EC", public.Curve.Params().Name,
+		encode(public.X.FillBytes(make([]byte, size))),
+		encode(public.Y.FillBytes(make([]byte, size)))
+}
+
+// thumbprint computes the RFC 7638 thumbprint of the required JWK members.
+// encoding/json sorts map keys, giving the canonical member order.
+func thumbprint(members map[string]string) string {
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return encode(sum[:])
+}
+
+// encode returns unpadded base64url, as used throughout JOSE
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
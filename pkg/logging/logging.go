@@ -0,0 +1,107 @@
+// Package logging builds the structured logger of the server and carries a
+// request-scoped logger in the context.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Redacted replaces the values of sensitive attributes
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are never logged, compared
+// case-insensitively. Keys containing "password" are redacted as well.
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"x-api-key":     true,
+	"api_key":       true,
+	"cookie":        true,
+	"set-cookie":    true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"secret":        true,
+	"client_secret": true,
+}
+
+// New creates a logger writing to w at the given level (debug, info, warn or
+// error) in the given format (text or json). Sensitive attributes are redacted.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redact}
+	switch strings.ToLower(format) {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q: must be %s or %s", format, FormatText, FormatJSON)
+	}
+}
+
+// IsSensitive reports whether the value of an attribute or header with the
+// given name must not be logged
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	return sensitiveKeys[key] || strings.Contains(key, "password")
+}
+
+// redact replaces the values of sensitive attributes, including those nested
+// in groups
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if IsSensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// contextKey is the context key of the request logger
+type contextKey struct{}
+
+// NewContext returns a context carrying a request-scoped logger. Attributes
+// added with AddAttrs anywhere down the request are visible to every holder
+// of the context, including middleware that ran before they were added.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	holder := &atomic.Pointer[slog.Logger]{}
+	holder.Store(logger)
+	return context.WithValue(ctx, contextKey{}, holder)
+}
+
+// FromContext returns the request-scoped logger of a context, or the default
+// logger outside of requests
+func FromContext(ctx context.Context) *slog.Logger {
+	if holder, ok := ctx.Value(contextKey{}).(*atomic.Pointer[slog.Logger]); ok {
+		return holder.Load()
+	}
+	return slog.Default()
+}
+
+// AddAttrs adds attributes to the request-scoped logger of a context. It does
+// nothing outside of requests.
+func AddAttrs(ctx context.Context, args ...any) {
+	holder, ok := ctx.Value(contextKey{}).(*atomic.Pointer[slog.Logger])
+	if !ok {
+		return
+	}
+	for {
+		logger := holder.Load()
+		if holder.CompareAndSwap(logger, logger.With(args...)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/services-api/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "warn", "json")
+	require.NoError(t, err)
+
+	logger.Info("skipped")
+	logger.Warn("written", "service_id", "svc-1")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "written", entry["msg"])
+	assert.Equal(t, "svc-1", entry["service_id"])
+
+	buf.Reset()
+	logger, err = logging.New(&buf, "DEBUG", "text")
+	require.NoError(t, err)
+	logger.Debug("written")
+	assert.Contains(t, buf.String(), "level=DEBUG msg=written")
+
+	_, err = logging.New(&buf, "verbose", "json")
+	assert.Error(t, err)
+	_, err = logging.New(&buf, "info", "xml")
+	assert.Error(t, err)
+}
+
+func TestNew_Redaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "info", "json")
+	require.NoError(t, err)
+
+	logger.Info("request",
+		"password", "hunter2",
+		"new_password", "hunter3",
+		slog.Group("headers", "Authorization", "Bearer abc", "X-API-Key", "sk_123", "Accept", "application/json"),
+		"user_id", "user-1",
+	)
+
+	assert.NotContains(t, buf.String(), "hunter")
+	assert.NotContains(t, buf.String(), "abc")
+	assert.NotContains(t, buf.String(), "sk_123")
+
+	var entry struct {
+		Password    string            `json:"password"`
+		NewPassword string            `json:"new_password"`
+		Headers     map[string]string `json:"headers"`
+		UserID      string            `json:"user_id"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, logging.Redacted, entry.Password)
+	assert.Equal(t, logging.Redacted, entry.NewPassword)
+	assert.Equal(t, logging.Redacted, entry.Headers["Authorization"])
+	assert.Equal(t, logging.Redacted, entry.Headers["X-API-Key"])
+	assert.Equal(t, "application/json", entry.Headers["Accept"])
+	assert.Equal(t, "user-1", entry.UserID)
+}
+
+func TestContext(t *testing.T) {
+	// Outside of requests the default logger is used
+	assert.Same(t, slog.Default(), logging.FromContext(context.Background()))
+	logging.AddAttrs(context.Background(), "ignored", true)
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "info", "json")
+	require.NoError(t, err)
+
+	ctx := logging.NewContext(context.Background(), logger.With("request_id", "req-1"))
+	// Attributes added down the request are seen through the original context
+	inner := context.WithValue(ctx, struct{}{}, "inner")
+	logging.AddAttrs(inner, "user_id", "user-1")
+	logging.FromContext(ctx).Info("done")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, "user-1", entry["user_id"])
+}
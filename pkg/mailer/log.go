@@ -0,0 +1,20 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogMailer writes emails to the log instead of sending them, for development
+type LogMailer struct{}
+
+// NewLog creates a new LogMailer
+func NewLog() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send logs a message
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "Email", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
@@ -0,0 +1,71 @@
+// Package mailer sends plain text emails to users
+package mailer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+const (
+	// asyncWorkers is the number of emails sent at the same time
+	asyncWorkers = 4
+	// asyncQueueSize is the number of emails waiting to be sent. Further
+	// emails are dropped, so that a slow server can't pile up goroutines.
+	asyncQueueSize = 256
+)
+
+// asyncMailer sends emails in the background
+type asyncMailer struct {
+	next  Mailer
+	queue chan asyncMessage
+}
+
+// asyncMessage is a queued email with the context it was sent with
+type asyncMessage struct {
+	ctx context.Context
+	msg Message
+}
+
+// Async returns a Mailer that sends in the background and logs failures.
+// Requests then take the same time whether or not an email was sent, which
+// keeps responses from revealing which email addresses have accounts. A fixed
+// number of workers send the queued emails; when the queue is full, further
+// emails are logged and dropped.
+func Async(next Mailer) Mailer {
+	m := &asyncMailer{next: next, queue: make(chan asyncMessage, asyncQueueSize)}
+	for range asyncWorkers {
+		go m.work()
+	}
+	return m
+}
+
+// Send queues a message and returns immediately
+func (m *asyncMailer) Send(ctx context.Context, msg Message) error {
+	select {
+	case m.queue <- asyncMessage{ctx: context.WithoutCancel(ctx), msg: msg}:
+	default:
+		slog.Error("Dropped email, send queue is full", "subject", msg.Subject)
+	}
+	return nil
+}
+
+// work sends queued emails until the process exits
+func (m *asyncMailer) work() {
+	for queued := range m.queue {
+		if err := m.next.Send(queued.ctx, queued.msg); err != nil {
+			slog.Error("Failed to send email", "subject", queued.msg.Subject, "to", queued.msg.To, "error", err)
+		}
+	}
+}
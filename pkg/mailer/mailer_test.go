@@ -0,0 +1,79 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingMailer counts sends and blocks them until release is closed
+type blockingMailer struct {
+	started atomic.Int32
+	release chan struct{}
+}
+
+func (m *blockingMailer) Send(ctx context.Context, msg Message) error {
+	m.started.Add(1)
+	<-m.release
+	return nil
+}
+
+func TestAsync_BoundsConcurrency(t *testing.T) {
+	next := &blockingMailer{release: make(chan struct{})}
+	async := Async(next)
+
+	send := func() {
+		require.NoError(t, async.Send(context.Background(), Message{To: "user@example.com", Subject: "Hello"}))
+	}
+
+	// Only a fixed number of emails are sent at the same time
+	for range asyncWorkers + 1 {
+		send()
+	}
+	assert.Eventually(t, func() bool {
+		return next.started.Load() == asyncWorkers && len(async.(*asyncMailer).queue) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Sends never block, even with the queue full
+	for range asyncQueueSize + 10 {
+		send()
+	}
+	assert.Len(t, async.(*asyncMailer).queue, asyncQueueSize)
+
+	// Queued emails are sent once the workers are free, the overflow is dropped
+	close(next.release)
+	assert.Eventually(t, func() bool {
+		return next.started.Load() == asyncWorkers+asyncQueueSize
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSMTPMailer_SendHonorsDeadline(t *testing.T) {
+	// A server that accepts connections but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	m := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "noreply@example.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = m.Send(ctx, Message{To: "user@example.com", Subject: "Hello", Body: "Hi"})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
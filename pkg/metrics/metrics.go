@@ -0,0 +1,139 @@
+// Package metrics defines the Prometheus metrics of the server and serves
+// them in the Prometheus exposition format.
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the names of all metrics
+const namespace = "services_api"
+
+// countTimeout bounds the storage queries made while collecting gauges
+const countTimeout = 5 * time.Second
+
+// Outcomes of logins and refresh token uses
+const (
+	ResultSuccess  = "success"
+	ResultFailure  = "failure"
+	ResultRejected = "rejected"
+	ResultReused   = "reused"
+	ResultError    = "error"
+)
+
+// Outcomes of cache lookups
+const (
+	ResultHit  = "hit"
+	ResultMiss = "miss"
+)
+
+var (
+	// HTTPRequests counts handled requests by method, route pattern and status
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests handled, by method, route pattern and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes request latency by method, route pattern and status
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests, by method, route pattern and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// MongoCommandDuration observes MongoDB command latency by command and outcome
+	MongoCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "mongodb",
+		Name:      "command_duration_seconds",
+		Help:      "Latency of MongoDB commands, by command name and outcome.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"command", "result"})
+
+	// CacheLookups counts cache lookups by cache (services, sessions or roles),
+	// store and result (hit or miss)
+	CacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "lookups_total",
+		Help:      "Cache lookups, by cache, store and result.",
+	}, []string{"cache", "store", "result"})
+
+	// VersionsCreated counts service version snapshots
+	VersionsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "service_versions_created_total",
+		Help:      "Service versions created by creating or changing services.",
+	})
+
+	// Logins counts logins by result (success or failure)
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Logins with a password, second factor or single sign-on, by result.",
+	}, []string{"result"})
+
+	// RefreshTokens counts uses of refresh tokens by result (success,
+	// rejected, reused or error)
+	RefreshTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "refresh_tokens_total",
+		Help:      "Refresh token uses, by result.",
+	}, []string{"result"})
+)
+
+// servicesDesc describes the services gauge
+var servicesDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "services"),
+	"Services currently stored.",
+	nil, nil,
+)
+
+// Handler serves all registered metrics. A metric that fails to collect is
+// left out instead of failing the scrape.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+		ErrorLog:      slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	})
+}
+
+// RegisterServiceCount reports the number of services on every scrape, as
+// returned by count
+func RegisterServiceCount(count func(ctx context.Context) (int64, error)) error {
+	return prometheus.Register(&servicesCollector{count: count})
+}
+
+// servicesCollector collects the services gauge from storage
+type servicesCollector struct {
+	count func(ctx context.Context) (int64, error)
+}
+
+// Describe implements prometheus.Collector
+func (c *servicesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- servicesDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *servicesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	n, err := c.count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(servicesDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(servicesDesc, prometheus.GaugeValue, float64(n))
+}
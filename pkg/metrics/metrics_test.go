@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServicesCollector(t *testing.T) {
+	collector := &servicesCollector{count: func(ctx context.Context) (int64, error) {
+		return 42, nil
+	}}
+	expected := `
+# HELP services_api_services Services currently stored.
+# TYPE services_api_services gauge
+services_api_services 42
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	// A failing count is reported as a collection error
+	collector = &servicesCollector{count: func(ctx context.Context) (int64, error) {
+		return 0, errors.New("storage unavailable")
+	}}
+	assert.Error(t, testutil.CollectAndCompare(collector, strings.NewReader("")))
+}
@@ -0,0 +1,203 @@
+// Package oidc signs users in with an external OpenID Connect identity
+// provider using the authorization code flow with PKCE.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Errors
+var (
+	ErrMissingIDToken = errors.New("token response has no id_token")
+	ErrNonceMismatch  = errors.New("id token nonce does not match")
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+// requestTimeout bounds each request made to the identity provider
+const requestTimeout = 10 * time.Second
+
+// Config configures the identity provider and this client's registration with it
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+}
+
+// Identity is the verified identity of a user signed in at the provider
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	Groups        []string
+}
+
+// AuthRequest holds the values of one login that must survive the redirect
+// to the provider and back
+type AuthRequest struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// NewAuthRequest creates an AuthRequest with fresh random values
+func NewAuthRequest() AuthRequest {
+	return AuthRequest{
+		State:        randomString(),
+		Nonce:        randomString(),
+		CodeVerifier: oauth2.GenerateVerifier(),
+	}
+}
+
+// Provider is an OpenID Connect identity provider discovered from its issuer URL
+type Provider struct {
+	oauth2      oauth2.Config
+	verifier    *gooidc.IDTokenVerifier
+	groupsClaim string
+	client      *http.Client
+}
+
+// NewProvider discovers the provider's endpoints and signing keys
+func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	client := &http.Client{Timeout: requestTimeout}
+	provider, err := gooidc.NewProvider(gooidc.ClientContext(ctx, client), cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{gooidc.ScopeOpenID, "email", "profile"}
+	}
+
+	return &Provider{
+		oauth2: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		// The key set is fetched lazily and refreshed when a token names an unknown key
+		verifier:    provider.VerifierContext(gooidc.ClientContext(context.Background(), client), &gooidc.Config{ClientID: cfg.ClientID}),
+		groupsClaim: cfg.GroupsClaim,
+		client:      client,
+	}, nil
+}
+
+// AuthCodeURL returns the provider URL the user is sent to for signing in
+func (p *Provider) AuthCodeURL(req AuthRequest) string {
+	return p.oauth2.AuthCodeURL(req.State, gooidc.Nonce(req.Nonce), oauth2.S256ChallengeOption(req.CodeVerifier))
+}
+
+// Exchange redeems the authorization code returned to the redirect URL and
+// verifies the ID token that comes with it
+func (p *Provider) Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error) {
+	token, err := p.oauth2.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code, oauth2.VerifierOption(req.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrMissingIDToken
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if idToken.Nonce != req.Nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	return p.identity(idToken)
+}
+
+// identity reads the user's identity from the claims of a verified ID token
+func (p *Provider) identity(idToken *gooidc.IDToken) (*Identity, error) {
+	var claims struct {
+		Email         string      `json:"email"`
+		EmailVerified *stringBool `json:"email_verified"`
+		Name          string      `json:"name"`
+		GivenName     string      `json:"given_name"`
+		FamilyName    string      `json:"family_name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	identity := &Identity{
+		Subject:   idToken.Subject,
+		Email:     strings.ToLower(strings.TrimSpace(claims.Email)),
+		FirstName: strings.TrimSpace(claims.GivenName),
+		LastName:  strings.TrimSpace(claims.FamilyName),
+		// Providers that don't send email_verified only release verified addresses
+		EmailVerified: claims.EmailVerified == nil || bool(*claims.EmailVerified),
+	}
+	if identity.FirstName == "" && identity.LastName == "" {
+		identity.FirstName, identity.LastName, _ = strings.Cut(strings.TrimSpace(claims.Name), " ")
+	}
+
+	if p.groupsClaim != "" {
+		var all map[string]json.RawMessage
+		if err := idToken.Claims(&all); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+		}
+		identity.Groups = parseGroups(all[p.groupsClaim])
+	}
+
+	return identity, nil
+}
+
+// parseGroups reads a groups claim, which providers send as a list or as a
+// single string
+func parseGroups(raw json.RawMessage) []string {
+	var groups []string
+	if err := json.Unmarshal(raw, &groups); err == nil {
+		return groups
+	}
+	var group string
+	if err := json.Unmarshal(raw, &group); err == nil && group != "" {
+		return []string{group}
+	}
+	return nil
+}
+
+// stringBool is a boolean claim that some providers send as a string
+type stringBool bool
+
+// UnmarshalJSON accepts true, false, "true" and "false"
+func (b *stringBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+// randomString returns 32 random bytes encoded as unpadded base64url
+func randomString() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,156 @@
+// Package pwned checks passwords against lists of breached passwords using
+// the k-anonymity model of Have I Been Pwned: only the first 5 hex characters
+// of a password's SHA-1 hash are looked up, and the returned hash suffixes are
+// compared locally.
+package pwned
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// PrefixLength is the number of hex characters of a hash sent to a Ranger
+const PrefixLength = 5
+
+// maxLineLength bounds the length of a line in a list file
+const maxLineLength = 128
+
+// Ranger returns the SHA-1 hash suffixes of the breached passwords whose
+// hashes start with a prefix
+type Ranger interface {
+	Range(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Breached reports whether a password is in a list of breached passwords
+func Breached(ctx context.Context, r Ranger, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, err := r.Range(ctx, hash[:PrefixLength])
+	if err != nil {
+		return false, err
+	}
+	for _, suffix := range suffixes {
+		if suffix == hash[PrefixLength:] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// File is a list of breached passwords in a local file, with one upper-case
+// SHA-1 hash per line, optionally followed by a colon and a count, sorted by
+// hash. This is the format of the Have I Been Pwned password downloads.
+// Lookups binary-search the file, so it isn't loaded into memory.
+type File struct {
+	f    *os.File
+	size int64
+}
+
+// Open opens a list file
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &File{f: f, size: info.Size()}, nil
+}
+
+// Close closes the list file
+func (l *File) Close() error {
+	return l.f.Close()
+}
+
+// Range returns the hash suffixes in the file starting with a prefix
+func (l *File) Range(ctx context.Context, prefix string) ([]string, error) {
+	prefix = strings.ToUpper(prefix)
+
+	// Find the first line at or after the prefix. The line starting at or after
+	// an offset only grows with the offset, so it can be binary-searched.
+	lo, hi := int64(0), l.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		_, line, err := l.lineAt(mid)
+		if err != nil {
+			return nil, err
+		}
+		if line == nil || string(hashOf(line)) >= prefix {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	start, _, err := l.lineAt(lo)
+	if err != nil {
+		return nil, err
+	}
+
+	var suffixes []string
+	scanner := bufio.NewScanner(io.NewSectionReader(l.f, start, l.size-start))
+	for scanner.Scan() {
+		hash := string(hashOf(scanner.Bytes()))
+		if !strings.HasPrefix(hash, prefix) {
+			break
+		}
+		suffixes = append(suffixes, hash[len(prefix):])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return suffixes, ctx.Err()
+}
+
+// lineAt returns the offset and content of the first line starting at or
+// after an offset, or a nil line at the end of the file
+func (l *File) lineAt(offset int64) (int64, []byte, error) {
+	// Read from the previous byte, in case a line starts right at the offset
+	start := max(offset-1, 0)
+	buf := make([]byte, 2*maxLineLength)
+	n, err := l.f.ReadAt(buf, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, nil, err
+	}
+	buf = buf[:n]
+
+	if offset > 0 {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			if n < cap(buf) {
+				return l.size, nil, nil
+			}
+			return 0, nil, fmt.Errorf("line at offset %d is longer than %d bytes", start, maxLineLength)
+		}
+		start += int64(i) + 1
+		buf = buf[i+1:]
+	}
+	if len(buf) == 0 {
+		return start, nil, nil
+	}
+
+	if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+		buf = buf[:i]
+	}
+	return start, buf, nil
+}
+
+// hashOf returns the hash of a line, without its count
+func hashOf(line []byte) []byte {
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		line = line[:i]
+	}
+	return bytes.ToUpper(bytes.TrimSpace(line))
+}
@@ -0,0 +1,85 @@
+package pwned_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/services-api/pkg/pwned"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeList writes a sorted list file of the hashes of passwords
+func writeList(t *testing.T, passwords []string, newline string) string {
+	t.Helper()
+	hashes := make([]string, len(passwords))
+	for i, password := range passwords {
+		hashes[i] = sha1Hex(password)
+	}
+	sort.Strings(hashes)
+
+	var b strings.Builder
+	for i, hash := range hashes {
+		fmt.Fprintf(&b, "%s:%d%s", hash, i+1, newline)
+	}
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	require.NoError(t, os.WriteFile(path, []byte(b.String()), 0o600))
+	return path
+}
+
+func TestFile_Breached(t *testing.T) {
+	var passwords []string
+	for i := 0; i < 2000; i++ {
+		passwords = append(passwords, fmt.Sprintf("password%d", i))
+	}
+
+	for _, newline := range []string{"\n", "\r\n"} {
+		list, err := pwned.Open(writeList(t, passwords, newline))
+		require.NoError(t, err)
+		defer list.Close()
+
+		for _, password := range passwords {
+			breached, err := pwned.Breached(context.Background(), list, password)
+			require.NoError(t, err)
+			require.True(t, breached, password)
+		}
+
+		for _, password := range []string{"password-2000", "correct horse battery staple", ""} {
+			breached, err := pwned.Breached(context.Background(), list, password)
+			require.NoError(t, err)
+			assert.False(t, breached, password)
+		}
+	}
+}
+
+func TestFile_Range(t *testing.T) {
+	list, err := pwned.Open(writeList(t, []string{"password", "123456", "qwerty"}, "\n"))
+	require.NoError(t, err)
+	defer list.Close()
+
+	// Only the suffixes of the prefix's hashes are returned
+	hash := sha1Hex("password")
+	suffixes, err := list.Range(context.Background(), strings.ToLower(hash[:pwned.PrefixLength]))
+	require.NoError(t, err)
+	assert.Equal(t, []string{hash[pwned.PrefixLength:]}, suffixes)
+
+	suffixes, err = list.Range(context.Background(), "00000")
+	require.NoError(t, err)
+	assert.Empty(t, suffixes)
+	suffixes, err = list.Range(context.Background(), "FFFFF")
+	require.NoError(t, err)
+	assert.Empty(t, suffixes)
+}
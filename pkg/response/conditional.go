@@ -0,0 +1,99 @@
+package response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache-Control policies
+const (
+	// CacheRevalidate lets clients store a response but revalidate it on every use
+	CacheRevalidate = "private, no-cache"
+)
+
+// Validators holds the caching metadata of a representation
+type Validators struct {
+	// ETag is the quoted entity tag, see ETag and HashETag
+	ETag string
+	// LastModified is the modification time, ignored when zero
+	LastModified time.Time
+	// CacheControl is the Cache-Control policy, omitted when empty
+	CacheControl string
+}
+
+// ETag returns a strong entity tag for an opaque value such as a revision
+func ETag(value string) string {
+	return strconv.Quote(value)
+}
+
+// HashETag returns a strong entity tag over the given parts
+func HashETag(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// Conditional writes a 200 response with data and the given validators, or a
+// 304 Not Modified without a body when the request's If-None-Match or
+// If-Modified-Since headers show the client already has this representation
+func Conditional(w http.ResponseWriter, r *http.Request, v Validators, data interface{}) {
+	header := w.Header()
+	if v.ETag != "" {
+		header.Set("ETag", v.ETag)
+	}
+	if !v.LastModified.IsZero() {
+		header.Set("Last-Modified", v.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if v.CacheControl != "" {
+		header.Set("Cache-Control", v.CacheControl)
+	}
+
+	if NotModified(r, v) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	OK(w, data)
+}
+
+// NotModified reports whether a GET or HEAD request's preconditions match the
+// validators. If-None-Match takes precedence over If-Modified-Since (RFC 9110 13.2.2).
+func NotModified(r *http.Request, v Validators) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return v.ETag != "" && etagMatches(inm, v.ETag)
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !v.LastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		// HTTP dates have second precision
+		return !v.LastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
+
+// etagMatches performs the weak comparison used by If-None-Match
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
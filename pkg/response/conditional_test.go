@@ -0,0 +1,117 @@
+package response_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/services-api/pkg/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditional(t *testing.T) {
+	modified := time.Date(2024, 1, 15, 10, 30, 0, 500, time.UTC)
+	v := response.Validators{
+		ETag:         response.ETag("3"),
+		LastModified: modified,
+		CacheControl: response.CacheRevalidate,
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			name:           "no preconditions",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "matching If-None-Match",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-None-Match": `"3"`},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "weak and listed If-None-Match",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-None-Match": `"1", W/"3"`},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "wildcard If-None-Match",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-None-Match": "*"},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "stale If-None-Match",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-None-Match": `"2"`},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "If-None-Match takes precedence over If-Modified-Since",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-None-Match": `"2"`, "If-Modified-Since": modified.Format(http.TimeFormat)},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "If-Modified-Since at modification time",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "If-Modified-Since before modification",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-Modified-Since": modified.Add(-time.Minute).Format(http.TimeFormat)},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid If-Modified-Since",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-Modified-Since": "yesterday"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "non-GET requests are never 304",
+			method:         http.MethodPut,
+			headers:        map[string]string{"If-None-Match": `"3"`},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/services/1", nil)
+			for k, val := range tt.headers {
+				req.Header.Set(k, val)
+			}
+			rec := httptest.NewRecorder()
+
+			response.Conditional(rec, req, v, map[string]string{"id": "1"})
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+			assert.Equal(t, "Mon, 15 Jan 2024 10:30:00 GMT", rec.Header().Get("Last-Modified"))
+			assert.Equal(t, response.CacheRevalidate, rec.Header().Get("Cache-Control"))
+			if tt.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.NotEmpty(t, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHashETag(t *testing.T) {
+	a := response.HashETag("id1:1", "id2:3")
+	assert.Equal(t, a, response.HashETag("id1:1", "id2:3"))
+	assert.NotEqual(t, a, response.HashETag("id1:1", "id2:4"))
+	assert.NotEqual(t, a, response.HashETag("id1:1id2:3"))
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, a)
+}